@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/maps"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+)
+
+// audit is the fires of an item expected in a past window compared with the
+// runs actually created then.
+type audit struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Schedule  string `json:"schedule"`
+	Expected  int    `json:"expected"`
+	// ExpectedTruncated is set when there are more fires than Expected.
+	ExpectedTruncated bool `json:"expectedTruncated,omitempty"`
+	Actual            int  `json:"actual"`
+	// Delta is Actual minus Expected, negative for the lost runs.
+	Delta int `json:"delta"`
+	// Runs are the names of the runs created in the window.
+	Runs []string `json:"runs"`
+	// Phases is the number of the runs in each phase, e.g. Succeeded.
+	Phases map[string]int `json:"phases,omitempty"`
+}
+
+type auditReport struct {
+	ApiVersion string         `json:"apiVersion"`
+	Window     documentWindow `json:"window"`
+	Audits     []audit        `json:"audits"`
+}
+
+// runAudit prints the fires of the items expected in a past window, and the
+// Jobs and Workflows created by them then. now is the clock, replaced in the tests.
+func runAudit(stdout, stderr io.Writer, args []string, buildClients clientsFactory, now func() time.Time) (retErr error) {
+	// Parse flags
+	// -----------------
+	var (
+		lastFlag       time.Duration
+		fromFlag       string
+		toFlag         string
+		selectorFlag   string
+		outputFlag     string
+		noHeadersFlag  bool
+		serverTimeFlag bool
+		logFormatFlag  string
+		inClusterFlag  bool
+	)
+	fsets := pflag.NewFlagSet(commandName+" audit", pflag.ContinueOnError)
+	fsets.SetOutput(stderr)
+	durationVarP(fsets, &lastFlag, "last", "", 0, "The duration of the window ending now, e.g. 6h.")
+	fsets.StringVarP(&fromFlag, "from", "", "", "Start time of the past window in RFC3339 format, instead of '--last'.")
+	fsets.StringVarP(&toFlag, "to", "", "", "End time of the past window in RFC3339 format, instead of '--last'. It must not be after now.")
+	fsets.StringVarP(&selectorFlag, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2).")
+	fsets.StringVarP(&outputFlag, "output", "o", "", "Output format. One of: json|yaml.")
+	fsets.BoolVarP(&noHeadersFlag, "no-headers", "", false, "If present, print output without headers.")
+	fsets.BoolVarP(&serverTimeFlag, "server-time", "", false, "If present, take now from the clock of the API server instead of the local one.")
+	fsets.StringVarP(&logFormatFlag, "log-format", "", logFormatText, "The format of the logs written into stderr. One of: text|json.")
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.AddFlags(fsets)
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
+
+	fsets.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of %s audit:\n", commandName)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example")
+		fmt.Fprintln(stderr, "  $ kubectl-cls audit --last 24h -n default")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Flags")
+		fmt.Fprint(stderr, fsets.FlagUsages())
+	}
+
+	if err := fsets.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+	if err := validateLogFormat(logFormatFlag); err != nil {
+		return err
+	}
+	logger := newLogger(stderr, logFormatFlag, false)
+	defer func() { retErr = logCommandError(logger, logFormatFlag, retErr) }()
+
+	// Validation
+	// -----------------
+	// The window ends at the time of the API server with '--server-time', so
+	// the clients are built first.
+	var c *clients
+	if serverTimeFlag {
+		var err error
+		c, err = buildClients(cfgFlags)
+		if err != nil {
+			return err
+		}
+		now = getServerClock(context.Background(), c, now, logger)
+	}
+	from, to, err := parsePastWindow(fsets, lastFlag, fromFlag, toFlag, now())
+	if err != nil {
+		return err
+	}
+	if outputFlag != "" && outputFlag != "json" && outputFlag != "yaml" {
+		return fmt.Errorf("%s is unsupported output format", outputFlag)
+	}
+	if _, err := parseSelector("selector", selectorFlag); err != nil {
+		return err
+	}
+
+	// Audit
+	// -----------------
+	if c == nil {
+		c, err = buildClients(cfgFlags)
+		if err != nil {
+			return err
+		}
+	}
+	namespace := ""
+	if cfgFlags.Namespace != nil {
+		namespace = *cfgFlags.Namespace
+	}
+	ctx := context.Background()
+	items, err := listItems(ctx, c, namespace, selectorFlag)
+	if err != nil {
+		return err
+	}
+	audits, err := auditItems(ctx, c, items, from, to)
+	if err != nil {
+		return err
+	}
+
+	switch outputFlag {
+	case "json":
+		b, err := json.MarshalIndent(auditReport{ApiVersion: "v1", Window: documentWindow{From: from, To: to}, Audits: audits}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(auditReport{ApiVersion: "v1", Window: documentWindow{From: from, To: to}, Audits: audits})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+	return printAudits(stdout, noHeadersFlag, audits)
+}
+
+// Audit the items firing in the from-to window against the runs they created
+// in the window: the Jobs owned by the CronJobs, and the Workflows labeled
+// with the CronWorkflows.
+func auditItems(ctx context.Context, c *clients, items []item, from, to time.Time) ([]audit, error) {
+	audits := []audit{}
+	jobs := map[string][]batchv1.Job{}
+	for _, item := range items {
+		fires, truncated, err := getItemFires(item, from, to, boundaries{})
+		if err != nil {
+			return nil, err
+		}
+		if len(fires) == 0 {
+			continue
+		}
+		var runs []itemRun
+		switch {
+		case item.cronJob != nil:
+			runs, err = listCronJobRuns(ctx, c, jobs, item.cronJob, from, to)
+		case item.cronWorkflow != nil:
+			runs, err = listCronWorkflowRuns(ctx, c, item.cronWorkflow, from, to)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(runs, func(i, j int) bool { return runs[i].name < runs[j].name })
+		obj := item.object()
+		a := audit{
+			Kind:              item.kind(),
+			Namespace:         obj.GetNamespace(),
+			Name:              obj.GetName(),
+			Schedule:          item.schedule(),
+			Expected:          len(fires),
+			ExpectedTruncated: truncated,
+			Actual:            len(runs),
+			Delta:             len(runs) - len(fires),
+			Runs:              []string{},
+		}
+		for _, r := range runs {
+			a.Runs = append(a.Runs, r.name)
+			if a.Phases == nil {
+				a.Phases = map[string]int{}
+			}
+			a.Phases[r.phase]++
+		}
+		audits = append(audits, a)
+	}
+	return audits, nil
+}
+
+// itemRun is a Job or a Workflow created by an item.
+type itemRun struct {
+	name  string
+	phase string
+}
+
+// List the Jobs owned by the CronJob, by the UID in the ownerReferences,
+// scheduled in the from-to window. The Jobs are listed once for each
+// namespace, and kept in jobs.
+func listCronJobRuns(ctx context.Context, c *clients, jobs map[string][]batchv1.Job, cronjob *batchv1.CronJob, from, to time.Time) ([]itemRun, error) {
+	namespaceJobs, ok := jobs[cronjob.Namespace]
+	if !ok {
+		list, err := c.k8s.BatchV1().Jobs(cronjob.Namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Jobs in '%s' namespace: %w", cronjob.Namespace, err)
+		}
+		namespaceJobs = list.Items
+		jobs[cronjob.Namespace] = namespaceJobs
+	}
+	runs := []itemRun{}
+	for i := range namespaceJobs {
+		job := &namespaceJobs[i]
+		if isOwnedBy(job.OwnerReferences, cronjob.UID) && inPastWindow(getJobScheduledTime(job), from, to) {
+			runs = append(runs, itemRun{name: job.Name, phase: getJobPhase(job)})
+		}
+	}
+	return runs, nil
+}
+
+// List the Workflows labeled with the name of the CronWorkflow, scheduled in
+// the from-to window.
+func listCronWorkflowRuns(ctx context.Context, c *clients, cronworkflow *wfv1alpha1.CronWorkflow, from, to time.Time) ([]itemRun, error) {
+	selector := labels.Set{cronWorkflowLabel: cronworkflow.Name}.String()
+	list, err := c.argo.Workflows(cronworkflow.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Workflows in '%s' namespace: %w", cronworkflow.Namespace, err)
+	}
+	runs := []itemRun{}
+	for i := range list.Items {
+		workflow := &list.Items[i]
+		if inPastWindow(getWorkflowScheduledTime(workflow), from, to) {
+			phase := string(workflow.Status.Phase)
+			if phase == "" {
+				phase = string(wfv1alpha1.WorkflowPending)
+			}
+			runs = append(runs, itemRun{name: workflow.Name, phase: phase})
+		}
+	}
+	return runs, nil
+}
+
+func isOwnedBy(refs []metav1.OwnerReference, uid types.UID) bool {
+	for _, ref := range refs {
+		if ref.UID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// cronJobScheduledTimestampAnnotation is set to the scheduled time of the Jobs
+// created by the CronJobs since Kubernetes 1.28.
+const cronJobScheduledTimestampAnnotation = "batch.kubernetes.io/cronjob-scheduled-timestamp"
+
+// The label and the annotation of the Workflows created by the CronWorkflows,
+// with the name of the CronWorkflow and the scheduled time.
+const (
+	cronWorkflowLabel                   = "workflows.argoproj.io/cron-workflow"
+	cronWorkflowScheduledTimeAnnotation = "workflows.argoproj.io/scheduled-time"
+)
+
+// Get the time the Job was scheduled by the CronJob, or the creation time for
+// the Jobs without the annotation, which is a bit later.
+func getJobScheduledTime(job *batchv1.Job) time.Time {
+	return getScheduledTime(job.ObjectMeta, cronJobScheduledTimestampAnnotation)
+}
+
+// Get the time the Workflow was scheduled by the CronWorkflow, or the
+// creation time for the Workflows without the annotation.
+func getWorkflowScheduledTime(workflow *wfv1alpha1.Workflow) time.Time {
+	return getScheduledTime(workflow.ObjectMeta, cronWorkflowScheduledTimeAnnotation)
+}
+
+func getScheduledTime(obj metav1.ObjectMeta, annotation string) time.Time {
+	if v, ok := obj.Annotations[annotation]; ok {
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t
+		}
+	}
+	return obj.CreationTimestamp.Time
+}
+
+// Get the phase of the Job like the Workflows: Succeeded or Failed by the
+// conditions, otherwise Running.
+func getJobPhase(job *batchv1.Job) string {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return "Succeeded"
+		case batchv1.JobFailed:
+			return "Failed"
+		}
+	}
+	return "Running"
+}
+
+// Whether the time is in the from-to window, both inclusive.
+func inPastWindow(t, from, to time.Time) bool {
+	return !t.Before(from) && !t.After(to)
+}
+
+func printAudits(stdout io.Writer, noHeaders bool, audits []audit) error {
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Schedule", "Kind", "Expected", "Actual", "Delta", "Phases"}, "\t"))
+	}
+	for _, a := range audits {
+		expected := strconv.Itoa(a.Expected)
+		if a.ExpectedTruncated {
+			expected = ">" + expected
+		}
+		delta := strconv.Itoa(a.Delta)
+		if a.Delta > 0 {
+			delta = "+" + delta
+		}
+		fmt.Fprintln(tw, strings.Join([]string{a.Namespace, a.Name, a.Schedule, a.Kind, expected, strconv.Itoa(a.Actual), delta, formatPhases(a.Phases)}, "\t"))
+	}
+	return tw.Flush()
+}
+
+// Format the number of the runs in each phase in the order of the phases,
+// e.g. "Failed=1,Succeeded=2", or "<none>" without runs.
+func formatPhases(phases map[string]int) string {
+	if len(phases) == 0 {
+		return "<none>"
+	}
+	names := maps.Keys(phases)
+	sort.Strings(names)
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, fmt.Sprintf("%s=%d", name, phases[name]))
+	}
+	return strings.Join(values, ",")
+}
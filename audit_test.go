@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func getJob(namespace, name string, owner types.UID, created string, condition batchv1.JobConditionType) *batchv1.Job {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(getTime(created)),
+		},
+	}
+	if owner != "" {
+		job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "owner", UID: owner}}
+	}
+	if condition != "" {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: condition, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func getWorkflow(namespace, name, cronworkflow, scheduled string, phase wfv1alpha1.WorkflowPhase) *wfv1alpha1.Workflow {
+	return &wfv1alpha1.Workflow{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			Labels:            map[string]string{cronWorkflowLabel: cronworkflow},
+			Annotations:       map[string]string{cronWorkflowScheduledTimeAnnotation: scheduled},
+			CreationTimestamp: metav1.NewTime(getTime(scheduled).Add(time.Second)),
+		},
+		Status: wfv1alpha1.WorkflowStatus{Phase: phase},
+	}
+}
+
+// newAuditClients returns the fake clients with an hourly CronJob which lost
+// runs, a daily CronJob which ran, a CronJob not firing in the window, an
+// hourly CronWorkflow which lost a run, and the Jobs and Workflows created by
+// them and unrelated ones.
+func newAuditClients(t *testing.T) *clients {
+	t.Helper()
+	hourly := getCronJob("ns-a", "hourly", "0 * * * *", false)
+	hourly.UID = "uid-hourly"
+	daily := getCronJob("ns-b", "daily", "0 1 * * *", false)
+	daily.UID = "uid-daily"
+	noon := getCronJob("ns-b", "noon", "0 12 * * *", false)
+	noon.UID = "uid-noon"
+	etl := getCronWorkflow("ns-a", "etl", "30 * * * *", false)
+	c := newFakeClients([]batchv1.CronJob{hourly, daily, noon}, []wfv1alpha1.CronWorkflow{etl})
+
+	scheduled := getJob("ns-a", "hourly-3", "uid-hourly", "2023-01-24T03:00:05Z", batchv1.JobFailed)
+	scheduled.Annotations = map[string]string{cronJobScheduledTimestampAnnotation: "2023-01-24T03:00:00Z"}
+	jobs := []*batchv1.Job{
+		getJob("ns-a", "hourly-1", "uid-hourly", "2023-01-24T01:00:03Z", batchv1.JobComplete),
+		scheduled,
+		getJob("ns-a", "hourly-0", "uid-hourly", "2023-01-23T23:00:02Z", batchv1.JobComplete), // before the window
+		getJob("ns-a", "manual", "", "2023-01-24T02:00:00Z", ""),                              // unrelated
+		getJob("ns-a", "other", "uid-other", "2023-01-24T02:00:00Z", ""),                      // owned by another one
+		getJob("ns-b", "daily-1", "uid-daily", "2023-01-24T01:00:01Z", ""),
+		getJob("ns-b", "noon-1", "uid-noon", "2023-01-23T12:00:01Z", batchv1.JobComplete),
+	}
+	for _, job := range jobs {
+		if _, err := c.k8s.BatchV1().Jobs(job.Namespace).Create(context.Background(), job, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	workflows := []*wfv1alpha1.Workflow{
+		getWorkflow("ns-a", "etl-1", "etl", "2023-01-24T00:30:00Z", wfv1alpha1.WorkflowSucceeded),
+		getWorkflow("ns-a", "etl-2", "etl", "2023-01-24T01:30:00Z", wfv1alpha1.WorkflowFailed),
+		getWorkflow("ns-a", "etl-4", "etl", "2023-01-24T03:30:00Z", wfv1alpha1.WorkflowRunning),
+		getWorkflow("ns-a", "etl-0", "etl", "2023-01-23T23:30:00Z", wfv1alpha1.WorkflowSucceeded),     // before the window
+		getWorkflow("ns-a", "other-1", "other", "2023-01-24T02:30:00Z", wfv1alpha1.WorkflowSucceeded), // of another one
+	}
+	for _, workflow := range workflows {
+		if _, err := c.argo.Workflows(workflow.Namespace).Create(context.Background(), workflow, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+// Run the audit subcommand with the clients and the clock at now.
+func runFakeAudit(c *clients, now string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+		return c, nil
+	}
+	clock := func() time.Time { return getTime(now) }
+	err := runAudit(&stdout, &stderr, append([]string{"audit"}, args...), buildClients, clock)
+	return stdout.String(), err
+}
+
+func Test_runAudit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "last",
+			args: []string{"--last", "4h"},
+			want: "" +
+				"Namespace   Name     Schedule     Kind           Expected   Actual   Delta   Phases\n" +
+				"ns-a        etl      30 * * * *   CronWorkflow   4          3        -1      Failed=1,Running=1,Succeeded=1\n" +
+				"ns-a        hourly   0 * * * *    CronJob        5          2        -3      Failed=1,Succeeded=1\n" +
+				"ns-b        daily    0 1 * * *    CronJob        1          1        0       Running=1\n",
+		},
+		{
+			name: "namespace",
+			args: []string{"--no-headers", "-n", "ns-b", "--from", "2023-01-23T00:00:00Z", "--to", "2023-01-24T04:00:00Z"},
+			want: "" +
+				"ns-b   daily   0 1 * * *    CronJob   2   1   -1   Running=1\n" +
+				"ns-b   noon    0 12 * * *   CronJob   1   1   0    Succeeded=1\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := runFakeAudit(newAuditClients(t), "2023-01-24T04:00:00Z", tt.args...)
+			if err != nil {
+				t.Fatalf("runAudit() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_runAudit_json(t *testing.T) {
+	t.Parallel()
+	got, err := runFakeAudit(newAuditClients(t), "2023-01-24T04:00:00Z", "--last", "4h", "-n", "ns-a", "-o", "json")
+	if err != nil {
+		t.Fatalf("runAudit() error = %v", err)
+	}
+	var report auditReport
+	if err := json.Unmarshal([]byte(got), &report); err != nil {
+		t.Fatal(err)
+	}
+	want := auditReport{
+		ApiVersion: "v1",
+		Window:     documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T04:00:00Z")},
+		Audits: []audit{
+			{
+				Kind: "CronWorkflow", Namespace: "ns-a", Name: "etl", Schedule: "30 * * * *", Expected: 4, Actual: 3, Delta: -1,
+				Runs:   []string{"etl-1", "etl-2", "etl-4"},
+				Phases: map[string]int{"Succeeded": 1, "Failed": 1, "Running": 1},
+			},
+			{
+				Kind: "CronJob", Namespace: "ns-a", Name: "hourly", Schedule: "0 * * * *", Expected: 5, Actual: 2, Delta: -3,
+				Runs:   []string{"hourly-1", "hourly-3"},
+				Phases: map[string]int{"Succeeded": 1, "Failed": 1},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// auditLogSuccess is the result of the mutations which succeeded.
+const auditLogSuccess = "success"
+
+// auditLogEntry is a line of '--audit-log', a mutation attempted on an item.
+type auditLogEntry struct {
+	Timestamp time.Time      `json:"timestamp"`
+	User      string         `json:"user"`
+	Verb      string         `json:"verb"`
+	Target    auditLogTarget `json:"target"`
+	DryRun    bool           `json:"dryRun"`
+	// Result is "success" or the error.
+	Result string `json:"result"`
+}
+
+type auditLogTarget struct {
+	Group     string `json:"group"`
+	Version   string `json:"version"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// auditLog appends the entries to the file as JSON lines, synced by each
+// batch of the mutations.
+type auditLog struct {
+	f *os.File
+	// user is the user of the kubeconfig context, see getKubeconfigUser.
+	user string
+	// now is the clock, replaced in the tests.
+	now func() time.Time
+}
+
+// Open the audit log file for appending, creating it when missing.
+func openAuditLog(path, user string) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the audit log: %w", err)
+	}
+	return &auditLog{f: f, user: user, now: time.Now}, nil
+}
+
+// Append the mutation of the item, successful when err is nil.
+func (l *auditLog) record(c *clients, verb string, item item, dryRun bool, err error) error {
+	obj := item.object()
+	gvk := getItemGroupVersionKind(c, item)
+	entry := auditLogEntry{
+		Timestamp: l.now().UTC(),
+		User:      l.user,
+		Verb:      verb,
+		Target: auditLogTarget{
+			Group:     gvk.Group,
+			Version:   gvk.Version,
+			Kind:      gvk.Kind,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		},
+		DryRun: dryRun,
+		Result: auditLogSuccess,
+	}
+	if err != nil {
+		entry.Result = err.Error()
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the audit log entry: %w", err)
+	}
+	if _, err := l.f.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("failed to write the audit log: %w", err)
+	}
+	return nil
+}
+
+// Sync the entries of the batch to the disk.
+func (l *auditLog) sync() error {
+	if err := l.f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync the audit log: %w", err)
+	}
+	return nil
+}
+
+func (l *auditLog) Close() error {
+	return l.f.Close()
+}
+
+// Get the group, the version, and the kind the item is patched in, e.g.
+// batch/v1beta1 for the CronJobs of the legacy clusters. c is nil without a
+// cluster, e.g. with '--fixtures'.
+func getItemGroupVersionKind(c *clients, item item) schema.GroupVersionKind {
+	gv := wfv1alpha1.SchemeGroupVersion
+	if item.cronJob != nil {
+		gv = batchv1.SchemeGroupVersion
+		if c != nil {
+			gv, _ = schema.ParseGroupVersion(c.cronJobVersion())
+		}
+	}
+	return gv.WithKind(item.kind())
+}
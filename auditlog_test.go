@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// Read the entries of the audit log, with the timestamps checked to be set and
+// cleared.
+func readAuditLog(t *testing.T, path string) []auditLogEntry {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the audit log: %v", err)
+	}
+	entries := []auditLogEntry{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(b), "\n"), "\n") {
+		var entry auditLogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("failed to decode the audit log line %q: %v", line, err)
+		}
+		if entry.Timestamp.IsZero() {
+			t.Errorf("want the timestamp set, got %q", line)
+		}
+		entry.Timestamp = time.Time{}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func Test_run_auditLog(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	if err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+current-context: prod
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: alice
+clusters:
+- name: prod
+  cluster:
+    server: https://127.0.0.1:6443
+users:
+- name: alice
+  user: {}
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "audit.log")
+
+	c := newRunClients()
+	args := append([]string{"--kubeconfig", kubeconfig, "--audit-log", path, "-n", "ns-a", "--suspend"}, runPeriod...)
+	if _, _, err := runFake(c, append(args, "--dry-run")...); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if _, _, err := runFake(c, append(args, "--yes")...); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+
+	target := auditLogTarget{Group: "batch", Version: "v1", Kind: "CronJob", Namespace: "ns-a", Name: "backup"}
+	want := []auditLogEntry{
+		{User: "alice", Verb: "suspend", Target: target, DryRun: true, Result: auditLogSuccess},
+		{User: "alice", Verb: "suspend", Target: target, Result: auditLogSuccess},
+	}
+	if diff := cmp.Diff(want, readAuditLog(t, path)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_applyPatchPlans_auditLog(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{
+		getCronJob("ns-a", "backup", "0 1 * * *", false),
+		getCronJob("ns-a", "broken", "0 2 * * *", false),
+		getCronJob("ns-a", "paused", "0 3 * * *", true),
+	}
+	cronworkflows := []wfv1alpha1.CronWorkflow{getCronWorkflow("ns-b", "report", "0 3 * * *", false)}
+	c := newFakeMutateClients(cronjobs, cronworkflows)
+	c.k8s.PrependReactor("patch", "cronjobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.(k8stesting.PatchAction).GetName() == "broken" {
+			return true, nil, errors.New("forbidden")
+		}
+		return false, nil, nil
+	})
+	path := filepath.Join(t.TempDir(), "audit.log")
+	log, err := openAuditLog(path, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.now = func() time.Time { return getTime("2023-01-24T00:00:00Z") }
+	defer log.Close()
+
+	plans, err := planSuspend(mergeItems(cronjobs, cronworkflows), suspendMarker{RunID: "0123456789abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = applyPatchPlans(context.Background(), c.clients, plans, patchOptions{yes: true, auditLog: log, verb: "suspend"})
+	if err == nil || err.Error() != "failed to patch 1 items" {
+		t.Errorf("want the error of the failed patch, got %v", err)
+	}
+
+	// The skipped item is not recorded.
+	want := []string{
+		`{"timestamp":"2023-01-24T00:00:00Z","user":"alice","verb":"suspend","target":{"group":"batch","version":"v1","kind":"CronJob","namespace":"ns-a","name":"backup"},"dryRun":false,"result":"success"}`,
+		`{"timestamp":"2023-01-24T00:00:00Z","user":"alice","verb":"suspend","target":{"group":"batch","version":"v1","kind":"CronJob","namespace":"ns-a","name":"broken"},"dryRun":false,"result":"failed to patch CronJob 'ns-a/broken': forbidden"}`,
+		`{"timestamp":"2023-01-24T00:00:00Z","user":"alice","verb":"suspend","target":{"group":"argoproj.io","version":"v1alpha1","kind":"CronWorkflow","namespace":"ns-b","name":"report"},"dryRun":false,"result":"success"}`,
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_auditLog_requiresAction(t *testing.T) {
+	t.Parallel()
+	_, _, err := runFake(newRunClients(), append([]string{"--audit-log", filepath.Join(t.TempDir(), "audit.log")}, runPeriod...)...)
+	want := "'--audit-log' requires '--shift', '--set-timezone', '--suspend', '--resume', or '--resume-all'"
+	if err == nil || err.Error() != want {
+		t.Errorf("want %q, got %v", want, err)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// cacheIdentity tells the clusters and the credentials apart, so that a list
+// is never reused for another cluster, or for another user who may be allowed
+// to see other objects.
+type cacheIdentity struct {
+	Server      string `json:"server"`
+	Context     string `json:"context"`
+	User        string `json:"user"`
+	Impersonate string `json:"impersonate"`
+}
+
+// cacheKey is the key of a single List call.
+type cacheKey struct {
+	Identity  cacheIdentity `json:"identity"`
+	Resource  string        `json:"resource"`
+	Namespace string        `json:"namespace"`
+	Selector  string        `json:"selector"`
+	// Table is set for the partial CronJobs built from the server-side Tables,
+	// which must not be reused for the full objects.
+	Table bool `json:"table"`
+}
+
+// cacheEntry is the file content of a cached list.
+type cacheEntry struct {
+	Key      cacheKey        `json:"key"`
+	StoredAt time.Time       `json:"storedAt"`
+	Items    json.RawMessage `json:"items"`
+}
+
+// listCache stores the listed objects in files of dir, keyed by the hash of
+// cacheKey, and reuses them within ttl.
+//
+// The cached lists are not revalidated against the resourceVersion of the
+// objects, which would need a request anyway, so the changes made in the
+// cluster within ttl are not seen.
+type listCache struct {
+	dir      string
+	ttl      time.Duration
+	identity cacheIdentity
+	now      func() time.Time
+	// logger logs the cache hits as debug records, and the failures to write
+	// the cache.
+	logger *slog.Logger
+}
+
+// Returns nil, which disables the cache, when ttl isn't greater than zero or
+// with noCache.
+func newListCache(cfgFlags *genericclioptions.ConfigFlags, ttl time.Duration, noCache bool, logger *slog.Logger) (*listCache, error) {
+	if ttl <= 0 || noCache {
+		return nil, nil
+	}
+	dir, err := getCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	identity, err := getCacheIdentity(cfgFlags)
+	if err != nil {
+		return nil, err
+	}
+	return &listCache{dir: dir, ttl: ttl, identity: identity, now: time.Now, logger: logger}, nil
+}
+
+// Get the default cache directory, e.g. ~/.cache/kubectl-cls on Linux.
+func getCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the cache directory: %w", err)
+	}
+	return filepath.Join(dir, commandName), nil
+}
+
+// Get the identity of the cluster and the credentials of the flags.
+func getCacheIdentity(cfgFlags *genericclioptions.ConfigFlags) (cacheIdentity, error) {
+	cfg, err := getRESTConfig(cfgFlags, podEnv)
+	if err != nil {
+		return cacheIdentity{}, err
+	}
+	kubeContext, user, err := getKubeconfigUser(cfgFlags)
+	if err != nil {
+		return cacheIdentity{}, err
+	}
+	return cacheIdentity{
+		Server:      cfg.Host,
+		Context:     kubeContext,
+		User:        user,
+		Impersonate: cfg.Impersonate.UserName,
+	}, nil
+}
+
+// Get the context of the kubeconfig and its user, or those of the
+// '--context' and '--user' flags.
+func getKubeconfigUser(cfgFlags *genericclioptions.ConfigFlags) (kubeContext, user string, err error) {
+	raw, err := cfgFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	kubeContext = raw.CurrentContext
+	if cfgFlags.Context != nil && *cfgFlags.Context != "" {
+		kubeContext = *cfgFlags.Context
+	}
+	if ctx, ok := raw.Contexts[kubeContext]; ok {
+		user = ctx.AuthInfo
+	}
+	if cfgFlags.AuthInfoName != nil && *cfgFlags.AuthInfoName != "" {
+		user = *cfgFlags.AuthInfoName
+	}
+	return kubeContext, user, nil
+}
+
+func (lc *listCache) path(key cacheKey) string {
+	b, _ := json.Marshal(key)
+	sum := sha256.Sum256(b)
+	return filepath.Join(lc.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Load the items of key into v, or returns false when they aren't cached or
+// have expired.
+func (lc *listCache) load(key cacheKey, v any) bool {
+	b, err := os.ReadFile(lc.path(key))
+	if err != nil {
+		return false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(b, &entry); err != nil || entry.Key != key {
+		return false
+	}
+	age := lc.now().Sub(entry.StoredAt)
+	if age < 0 || age >= lc.ttl {
+		return false
+	}
+	if err := json.Unmarshal(entry.Items, v); err != nil {
+		return false
+	}
+	lc.logger.Debug(fmt.Sprintf("using the cached %s in '%s' namespace, listed %s ago", key.Resource, displayNamespace(key.Namespace), age.Round(time.Second)),
+		"resource", key.Resource, "namespace", key.Namespace, "age", age.Round(time.Second))
+	return true
+}
+
+func (lc *listCache) store(key cacheKey, v any) error {
+	items, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(cacheEntry{Key: key, StoredAt: lc.now(), Items: items})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(lc.dir, 0o700); err != nil {
+		return err
+	}
+	// Write into a temporary file and rename it, so that a concurrent run
+	// never reads a partial file.
+	f, err := os.CreateTemp(lc.dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), lc.path(key))
+}
+
+// Call list unless its items are cached for key, and cache them. A nil lc
+// always calls list. A failure to write the cache is only logged as a warning.
+func cachedList[T any](lc *listCache, key cacheKey, list func() ([]T, error)) ([]T, error) {
+	if lc == nil {
+		return list()
+	}
+	key.Identity = lc.identity
+
+	var items []T
+	if lc.load(key, &items) {
+		return items, nil
+	}
+	items, err := list()
+	if err != nil {
+		return nil, err
+	}
+	if err := lc.store(key, items); err != nil {
+		lc.logger.Warn(fmt.Sprintf("failed to write the cache: %s", err), "resource", key.Resource, "namespace", key.Namespace)
+	}
+	return items, nil
+}
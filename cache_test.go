@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func newTestListCache(t *testing.T, now *time.Time) (*listCache, *bytes.Buffer) {
+	t.Helper()
+	var stderr bytes.Buffer
+	return &listCache{
+		dir:      t.TempDir(),
+		ttl:      time.Minute,
+		identity: cacheIdentity{Server: "https://127.0.0.1:6443", Context: "kind-kind", User: "kind-kind"},
+		now:      func() time.Time { return *now },
+		logger:   newLogger(&stderr, logFormatText, true),
+	}, &stderr
+}
+
+// countedList returns the CronJobs and counts the calls.
+func countedList(calls *int, cronjobs ...batchv1.CronJob) func() ([]batchv1.CronJob, error) {
+	return func() ([]batchv1.CronJob, error) {
+		*calls++
+		return cronjobs, nil
+	}
+}
+
+func Test_cachedList(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{getCronJob("ns-a", "cj1", "0 1 * * *", false)}
+	key := cacheKey{Resource: "cronjobs", Namespace: "ns-a"}
+
+	t.Run("hit", func(t *testing.T) {
+		t.Parallel()
+		now := getTime("2023-01-24T00:00:00Z")
+		lc, stderr := newTestListCache(t, &now)
+		calls := 0
+		if _, err := cachedList(lc, key, countedList(&calls, cronjobs...)); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(30 * time.Second)
+		got, err := cachedList(lc, key, countedList(&calls, cronjobs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 1 {
+			t.Errorf("want 1 list call, got %d", calls)
+		}
+		if diff := cmp.Diff(cronjobs, got); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		want := "using the cached cronjobs in 'ns-a' namespace, listed 30s ago\n"
+		if diff := cmp.Diff(want, stderr.String()); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		t.Parallel()
+		now := getTime("2023-01-24T00:00:00Z")
+		lc, _ := newTestListCache(t, &now)
+		calls := 0
+		if _, err := cachedList(lc, key, countedList(&calls, cronjobs...)); err != nil {
+			t.Fatal(err)
+		}
+		others := []cacheKey{
+			{Resource: "cronjobs", Namespace: "ns-b"},
+			{Resource: "cronjobs", Namespace: "ns-a", Selector: "app=a"},
+			{Resource: "cronjobs", Namespace: "ns-a", Table: true},
+			{Resource: "cronworkflows", Namespace: "ns-a"},
+		}
+		for _, other := range others {
+			if _, err := cachedList(lc, other, countedList(&calls)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// Another cluster
+		lc.identity.Server = "https://10.0.0.1:6443"
+		if _, err := cachedList(lc, key, countedList(&calls)); err != nil {
+			t.Fatal(err)
+		}
+		if want := 1 + len(others) + 1; calls != want {
+			t.Errorf("want %d list calls, got %d", want, calls)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		t.Parallel()
+		now := getTime("2023-01-24T00:00:00Z")
+		lc, stderr := newTestListCache(t, &now)
+		calls := 0
+		if _, err := cachedList(lc, key, countedList(&calls, cronjobs...)); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Minute)
+		got, err := cachedList(lc, key, countedList(&calls))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 2 {
+			t.Errorf("want 2 list calls, got %d", calls)
+		}
+		if len(got) != 0 {
+			t.Errorf("want the listed items, got the expired %v", got)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("want no cache hit, got %s", stderr.String())
+		}
+	})
+
+	t.Run("write failure", func(t *testing.T) {
+		t.Parallel()
+		now := getTime("2023-01-24T00:00:00Z")
+		lc, stderr := newTestListCache(t, &now)
+		lc.dir = "/dev/null/cache"
+		calls := 0
+		got, err := cachedList(lc, key, countedList(&calls, cronjobs...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(cronjobs, got); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		if !strings.HasPrefix(stderr.String(), "warning: failed to write the cache: ") {
+			t.Errorf("want the write failure, got %s", stderr.String())
+		}
+	})
+}
+
+func Test_newListCache_bypass(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		noCache bool
+	}{
+		{name: "no ttl", ttl: 0},
+		{name: "no cache", ttl: time.Minute, noCache: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			lc, err := newListCache(genericclioptions.NewConfigFlags(false), tt.ttl, tt.noCache, newLogger(&bytes.Buffer{}, logFormatText, true))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if lc != nil {
+				t.Fatalf("want the cache disabled, got %v", lc)
+			}
+			calls := 0
+			for i := 0; i < 2; i++ {
+				if _, err := cachedList(lc, cacheKey{Resource: "cronjobs"}, countedList(&calls)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if calls != 2 {
+				t.Errorf("want 2 list calls, got %d", calls)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// The characters which need no quoting in bash.
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=@%+,-]+$`)
+
+// Quote the value as a single word for bash. Single quotes keep everything
+// literal, so only the single quotes themselves need to be closed and escaped.
+func shellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", `'"'"'`) + "'"
+}
+
+// Print the plans as a bash script of the kubectl commands, one per line. The
+// refused and skipped items are printed as comments, so that the reviewer of
+// the script sees them.
+func printPatchCommands(stdout io.Writer, plans []patchPlan) error {
+	fmt.Fprintln(stdout, "#!/usr/bin/env bash")
+	fmt.Fprintln(stdout, "set -euo pipefail")
+	for _, plan := range plans {
+		obj := plan.item.object()
+		switch {
+		case plan.refusal != "":
+			fmt.Fprintf(stdout, "# %s %s/%s: %s\n", plan.item.kind(), obj.GetNamespace(), obj.GetName(), oneLine(plan.refusal))
+			continue
+		case plan.patch == nil:
+			fmt.Fprintf(stdout, "# %s %s/%s: %s\n", plan.item.kind(), obj.GetNamespace(), obj.GetName(), patchResultSkipped)
+			continue
+		}
+		patch, err := json.Marshal(plan.patch)
+		if err != nil {
+			return fmt.Errorf("failed to marshal the patch: %w", err)
+		}
+		args := []string{"kubectl", "-n", obj.GetNamespace(), "patch", strings.ToLower(plan.item.kind()), obj.GetName(), "--type=merge", "-p", string(patch)}
+		for i := range args {
+			args[i] = shellQuote(args[i])
+		}
+		fmt.Fprintln(stdout, strings.Join(args, " "))
+	}
+	return nil
+}
+
+// Replace the newlines so that the value stays in a comment.
+func oneLine(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
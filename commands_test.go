@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var shellQuoteTests = []struct {
+	value string
+	want  string
+}{
+	{value: "ns-a", want: "ns-a"},
+	{value: "--type=merge", want: "--type=merge"},
+	{value: "", want: "''"},
+	{value: "0 1 * * *", want: "'0 1 * * *'"},
+	{value: `{"spec":{"schedule":"0 1 * * *"}}`, want: `'{"spec":{"schedule":"0 1 * * *"}}'`},
+	{value: "it's", want: `'it'"'"'s'`},
+	{value: "$(rm -rf /) `id` \\ !", want: "'$(rm -rf /) `id` \\ !'"},
+}
+
+func Test_shellQuote(t *testing.T) {
+	t.Parallel()
+	for _, tt := range shellQuoteTests {
+		if got := shellQuote(tt.value); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+// The quoted values are read back by bash as is.
+func Test_shellQuote_bash(t *testing.T) {
+	t.Parallel()
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash is not found")
+	}
+	for _, tt := range shellQuoteTests {
+		out, err := exec.Command(bash, "-c", "printf %s "+shellQuote(tt.value)).Output()
+		if err != nil {
+			t.Fatalf("bash failed for %q: %s", tt.value, err)
+		}
+		if string(out) != tt.value {
+			t.Errorf("bash read %q back as %q", tt.value, out)
+		}
+	}
+}
+
+func Test_printPatchCommands(t *testing.T) {
+	t.Parallel()
+	shiftCronJobs, shiftCronWorkflows := getShiftFixtures()
+	tzCronJobs, tzCronWorkflows := getSetTimezoneFixtures()
+	tests := []struct {
+		golden string
+		plans  []patchPlan
+	}{
+		{golden: "commands.shift.golden.sh", plans: planShift(mergeItems(shiftCronJobs, shiftCronWorkflows), 2*time.Hour)},
+		{golden: "commands.set-timezone.golden.sh", plans: planSetTimezone(mergeItems(tzCronJobs, tzCronWorkflows), "Asia/Tokyo", false)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.golden, func(t *testing.T) {
+			t.Parallel()
+			var got bytes.Buffer
+			if err := printPatchCommands(&got, tt.plans); err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tt.golden, got.Bytes())
+		})
+	}
+}
+
+func Test_printPatchCommands_noAPICalls(t *testing.T) {
+	t.Parallel()
+	cronjobs, cronworkflows := getShiftFixtures()
+	c := newFakeMutateClients(cronjobs, cronworkflows)
+	var got bytes.Buffer
+	if err := printPatchCommands(&got, planShift(mergeItems(cronjobs, cronworkflows), time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(0, len(c.k8s.Actions())+len(c.argo.Actions())); diff != "" {
+		t.Errorf("actions (-want +got):\n%s", diff)
+	}
+}
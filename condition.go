@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// secondsCondition is a condition on an optional number of seconds, in the
+// 'set|unset|lt=SECONDS|gt=SECONDS' form shared by the filters on the
+// seconds fields of the specs.
+type secondsCondition struct {
+	op    string // one of: set, unset, lt, gt
+	value int64
+}
+
+// Parse the value of the flag as a secondsCondition.
+func parseSecondsCondition(flag, value string) (secondsCondition, error) {
+	switch value {
+	case "set", "unset":
+		return secondsCondition{op: value}, nil
+	}
+	op, seconds, ok := strings.Cut(value, "=")
+	if !ok || (op != "lt" && op != "gt") {
+		return secondsCondition{}, fmt.Errorf("failed to parse '--%s' value: '%s' is not one of set|unset|lt=SECONDS|gt=SECONDS", flag, value)
+	}
+	n, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return secondsCondition{}, fmt.Errorf("failed to parse '--%s' value: %w", flag, err)
+	}
+	return secondsCondition{op: op, value: n}, nil
+}
+
+// An unset value doesn't match lt nor gt.
+func (c secondsCondition) match(seconds *int64) bool {
+	switch c.op {
+	case "set":
+		return seconds != nil
+	case "unset":
+		return seconds == nil
+	case "lt":
+		return seconds != nil && *seconds < c.value
+	case "gt":
+		return seconds != nil && *seconds > c.value
+	}
+	return false
+}
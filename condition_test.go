@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func Test_secondsCondition(t *testing.T) {
+	t.Parallel()
+	ptr := func(v int64) *int64 { return &v }
+	tests := []struct {
+		value   string
+		seconds *int64
+		want    bool
+		wantErr bool
+	}{
+		{value: "set", seconds: ptr(0), want: true},
+		{value: "set", seconds: nil, want: false},
+		{value: "unset", seconds: nil, want: true},
+		{value: "unset", seconds: ptr(0), want: false},
+		{value: "lt=3600", seconds: ptr(0), want: true},
+		{value: "lt=3600", seconds: ptr(3600), want: false},
+		{value: "lt=3600", seconds: nil, want: false},
+		{value: "gt=0", seconds: ptr(0), want: false},
+		{value: "gt=0", seconds: ptr(1), want: true},
+		{value: "gt=0", seconds: nil, want: false},
+		{value: "eq=0", wantErr: true},
+		{value: "lt=an-hour", wantErr: true},
+		{value: "lt", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			cond, err := parseSecondsCondition("ttl", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseSecondsCondition() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := cond.match(tt.seconds); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
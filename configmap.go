@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// fieldManager is the field manager name used for server-side apply.
+	fieldManager = commandName
+
+	// configMapMaxSize is the maximum size of the data stored in a ConfigMap.
+	configMapMaxSize = 1 << 20 // 1MiB
+
+	configMapResultsKey = "results.json"
+	configMapFromKey    = "from"
+	configMapToKey      = "to"
+)
+
+// Parse the '--write-configmap' value in the NAMESPACE/NAME form.
+func parseConfigMapRef(value string) (namespace, name string, err error) {
+	namespace, name, ok := strings.Cut(value, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("failed to parse '--write-configmap' value '%s': must be in the NAMESPACE/NAME form", value)
+	}
+	return namespace, name, nil
+}
+
+// Write the JSON output document and the from-to period into the ConfigMap by
+// server-side apply. The ConfigMap is created if it does not exist.
+//
+// The write fails instead of truncating the document when the data exceeds the
+// ConfigMap size limit, because a truncated JSON document is unusable.
+func writeConfigMap(ctx context.Context, client kubernetes.Interface, namespace, name string, document []byte, from, to time.Time) error {
+	data := map[string]string{
+		configMapResultsKey: string(document),
+		configMapFromKey:    from.Format(time.RFC3339),
+		configMapToKey:      to.Format(time.RFC3339),
+	}
+
+	size := 0
+	for k, v := range data {
+		size += len(k) + len(v)
+	}
+	if size > configMapMaxSize {
+		return fmt.Errorf("failed to write ConfigMap '%s/%s': the data is %d bytes and exceeds the ConfigMap limit of %d bytes, narrow down the results with '--selector' or '--namespace'", namespace, name, size, configMapMaxSize)
+	}
+
+	cm := corev1ac.ConfigMap(name, namespace).
+		WithLabels(map[string]string{"app.kubernetes.io/managed-by": commandName}).
+		WithData(data)
+	if _, err := client.CoreV1().ConfigMaps(namespace).Apply(ctx, cm, metav1.ApplyOptions{FieldManager: fieldManager, Force: true}); err != nil {
+		return fmt.Errorf("failed to write ConfigMap '%s/%s': %w", namespace, name, err)
+	}
+	return nil
+}
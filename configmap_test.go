@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// The fake clientset can apply to an existing object only, so emulate the
+// creation by server-side apply.
+func newApplyFakeClientset(objects ...runtime.Object) *k8sfake.Clientset {
+	client := k8sfake.NewSimpleClientset(objects...)
+	client.PrependReactor("patch", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		patch := action.(k8stesting.PatchAction)
+		if patch.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+		_, err := client.Tracker().Get(patch.GetResource(), patch.GetNamespace(), patch.GetName())
+		if !apierrors.IsNotFound(err) {
+			return false, nil, nil
+		}
+		cm := &corev1.ConfigMap{}
+		if err := json.Unmarshal(patch.GetPatch(), cm); err != nil {
+			return true, nil, err
+		}
+		return true, cm, client.Tracker().Create(patch.GetResource(), cm, patch.GetNamespace())
+	})
+	return client
+}
+
+func Test_parseConfigMapRef(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value         string
+		wantNamespace string
+		wantName      string
+		wantErr       bool
+	}{
+		{value: "ns-a/results", wantNamespace: "ns-a", wantName: "results"},
+		{value: "results", wantErr: true},
+		{value: "/results", wantErr: true},
+		{value: "ns-a/", wantErr: true},
+		{value: "ns-a/results/extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			namespace, name, err := parseConfigMapRef(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseConfigMapRef() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("parseConfigMapRef() = %s, %s, want %s, %s", namespace, name, tt.wantNamespace, tt.wantName)
+			}
+		})
+	}
+}
+
+func Test_writeConfigMap(t *testing.T) {
+	t.Parallel()
+	from := getTime("2023-01-24T00:00:00Z")
+	to := getTime("2023-01-24T01:00:00Z")
+
+	tests := []struct {
+		name     string
+		existing []runtime.Object
+		document string
+		wantData map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "create",
+			document: `{"apiVersion":"v1","items":[]}`,
+			wantData: map[string]string{
+				"results.json": `{"apiVersion":"v1","items":[]}`,
+				"from":         "2023-01-24T00:00:00Z",
+				"to":           "2023-01-24T01:00:00Z",
+			},
+		},
+		{
+			name: "update",
+			existing: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "results"},
+					Data: map[string]string{
+						"results.json": `{"apiVersion":"v1","items":["old"]}`,
+						"from":         "2023-01-23T00:00:00Z",
+						"to":           "2023-01-23T01:00:00Z",
+					},
+				},
+			},
+			document: `{"apiVersion":"v1","items":["new"]}`,
+			wantData: map[string]string{
+				"results.json": `{"apiVersion":"v1","items":["new"]}`,
+				"from":         "2023-01-24T00:00:00Z",
+				"to":           "2023-01-24T01:00:00Z",
+			},
+		},
+		{
+			name:     "oversize",
+			document: strings.Repeat("x", configMapMaxSize),
+			wantErr:  "exceeds the ConfigMap limit of 1048576 bytes",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := newApplyFakeClientset(tt.existing...)
+			err := writeConfigMap(context.Background(), client, "ns-a", "results", []byte(tt.document), from, to)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("writeConfigMap() error = %v, want %q", err, tt.wantErr)
+				}
+				if len(client.Actions()) != 0 {
+					t.Errorf("writeConfigMap() sent requests %v, want none", client.Actions())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var patched bool
+			for _, action := range client.Actions() {
+				patch, ok := action.(k8stesting.PatchAction)
+				if ok && patch.GetPatchType() == types.ApplyPatchType {
+					patched = true
+				}
+			}
+			if !patched {
+				t.Errorf("writeConfigMap() did not use server-side apply: %v", client.Actions())
+			}
+
+			got, err := client.CoreV1().ConfigMaps("ns-a").Get(context.Background(), "results", metav1.GetOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantData, got.Data); diff != "" {
+				t.Errorf("ConfigMap data mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
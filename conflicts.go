@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// expectedDurationAnnotation overrides '--expected-duration' per item.
+const expectedDurationAnnotation = "kubectl-cls.unblee.github.com/expected-duration"
+
+const (
+	outcomeSkipped  = "skipped"
+	outcomeReplaced = "replaced"
+)
+
+// conflict is a fire time at which the previous run is predicted to be still
+// running, and what the concurrencyPolicy does about it.
+type conflict struct {
+	Kind              string    `json:"kind"`
+	Namespace         string    `json:"namespace"`
+	Name              string    `json:"name"`
+	ConcurrencyPolicy string    `json:"concurrencyPolicy"`
+	FireTime          time.Time `json:"fireTime"`
+	Outcome           string    `json:"outcome"`
+}
+
+type conflictReport struct {
+	ApiVersion string     `json:"apiVersion"`
+	Conflicts  []conflict `json:"conflicts"`
+}
+
+// Get the expected run duration of the item from its annotation, or
+// defaultDuration.
+func getExpectedDuration(item item, defaultDuration time.Duration) (time.Duration, error) {
+	value, ok := item.object().GetAnnotations()[expectedDurationAnnotation]
+	if !ok {
+		return defaultDuration, nil
+	}
+	d, err := parseDuration(value)
+	if err != nil {
+		obj := item.object()
+		return 0, fmt.Errorf("failed to parse '%s' annotation of %s '%s/%s': %w", expectedDurationAnnotation, item.kind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return d, nil
+}
+
+// Simulate the runs of the Forbid and Replace items in the from-to period,
+// each lasting its expected duration, and report the fire times at which the
+// previous run is still running. With Forbid, the new run is skipped and the
+// previous one keeps running; with Replace, the previous run is killed and
+// the new one starts. Items with the other policies are not reported.
+func findConflicts(items []item, from, to time.Time, bounds boundaries, defaultDuration time.Duration) ([]conflict, error) {
+	conflicts := []conflict{}
+	for _, item := range items {
+		policy := item.concurrencyPolicy()
+		if policy != "Forbid" && policy != "Replace" {
+			continue
+		}
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return nil, err
+		}
+		duration, err := getExpectedDuration(item, defaultDuration)
+		if err != nil {
+			return nil, err
+		}
+
+		var runningUntil time.Time
+		for t := sched.Next(bounds.start(from)); !t.IsZero() && bounds.beforeEnd(t, to); t = sched.Next(t) {
+			if !t.Before(runningUntil) {
+				runningUntil = t.Add(duration)
+				continue
+			}
+			obj := item.object()
+			c := conflict{
+				Kind:              item.kind(),
+				Namespace:         obj.GetNamespace(),
+				Name:              obj.GetName(),
+				ConcurrencyPolicy: policy,
+				FireTime:          t.UTC(),
+			}
+			if policy == "Forbid" {
+				c.Outcome = outcomeSkipped
+			} else {
+				c.Outcome = outcomeReplaced
+				runningUntil = t.Add(duration)
+			}
+			conflicts = append(conflicts, c)
+		}
+	}
+	return conflicts, nil
+}
+
+func printConflicts(stdout io.Writer, output string, noHeaders bool, tf timeFormat, conflicts []conflict) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(conflictReport{ApiVersion: "v1", Conflicts: conflicts}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(conflictReport{ApiVersion: "v1", Conflicts: conflicts})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Kind", "Concurrency", "Fire Time", "Outcome"}, "\t"))
+	}
+	for _, c := range conflicts {
+		fireTime := metav1.NewTime(c.FireTime)
+		fmt.Fprintln(tw, strings.Join([]string{c.Namespace, c.Name, c.Kind, c.ConcurrencyPolicy, tf.format(&fireTime), c.Outcome}, "\t"))
+	}
+	return tw.Flush()
+}
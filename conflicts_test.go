@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_findConflicts(t *testing.T) {
+	t.Parallel()
+	from := getTime("2023-01-24T00:00:00Z")
+	to := getTime("2023-01-24T01:00:00Z")
+
+	forbid := getCronJob("ns-a", "forbid", "*/10 * * * *", false)
+	forbid.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	replace := getCronWorkflow("ns-a", "replace", "*/10 * * * *", false)
+	replace.Spec.ConcurrencyPolicy = wfv1alpha1.ReplaceConcurrent
+	allow := getCronJob("ns-a", "allow", "*/10 * * * *", false)
+	// The run ends right at the next fire time, which is not a conflict. The
+	// annotation overrides '--expected-duration'.
+	exact := getCronJob("ns-a", "exact", "*/10 * * * *", false)
+	exact.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	exact.Annotations = map[string]string{expectedDurationAnnotation: "10m"}
+	annotated := getCronJob("ns-b", "annotated", "0,20,40 * * * *", false)
+	annotated.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	annotated.Annotations = map[string]string{expectedDurationAnnotation: "45m"}
+
+	items := mergeItems([]batchv1.CronJob{forbid, allow, exact, annotated}, []wfv1alpha1.CronWorkflow{replace})
+	got, err := findConflicts(items, from, to, boundaries{}, 25*time.Minute)
+	if err != nil {
+		t.Fatalf("findConflicts() error = %v", err)
+	}
+
+	c := func(kind, namespace, name, policy, fireTime, outcome string) conflict {
+		return conflict{Kind: kind, Namespace: namespace, Name: name, ConcurrencyPolicy: policy, FireTime: getTime(fireTime), Outcome: outcome}
+	}
+	want := []conflict{
+		// Runs at 00:00 and 00:30 keep running until 00:25 and 00:55, and
+		// 01:00 starts a new run.
+		c("CronJob", "ns-a", "forbid", "Forbid", "2023-01-24T00:10:00Z", "skipped"),
+		c("CronJob", "ns-a", "forbid", "Forbid", "2023-01-24T00:20:00Z", "skipped"),
+		c("CronJob", "ns-a", "forbid", "Forbid", "2023-01-24T00:40:00Z", "skipped"),
+		c("CronJob", "ns-a", "forbid", "Forbid", "2023-01-24T00:50:00Z", "skipped"),
+		// Every run is killed 10 minutes later by the next one.
+		c("CronWorkflow", "ns-a", "replace", "Replace", "2023-01-24T00:10:00Z", "replaced"),
+		c("CronWorkflow", "ns-a", "replace", "Replace", "2023-01-24T00:20:00Z", "replaced"),
+		c("CronWorkflow", "ns-a", "replace", "Replace", "2023-01-24T00:30:00Z", "replaced"),
+		c("CronWorkflow", "ns-a", "replace", "Replace", "2023-01-24T00:40:00Z", "replaced"),
+		c("CronWorkflow", "ns-a", "replace", "Replace", "2023-01-24T00:50:00Z", "replaced"),
+		c("CronWorkflow", "ns-a", "replace", "Replace", "2023-01-24T01:00:00Z", "replaced"),
+		// The run at 00:00 lasts until 00:45, so 00:20 and 00:40 are skipped
+		// and 01:00 runs.
+		c("CronJob", "ns-b", "annotated", "Forbid", "2023-01-24T00:20:00Z", "skipped"),
+		c("CronJob", "ns-b", "annotated", "Forbid", "2023-01-24T00:40:00Z", "skipped"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("findConflicts() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_findConflicts_invalidAnnotation(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "n-1", "*/10 * * * *", false)
+	cronjob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronjob.Annotations = map[string]string{expectedDurationAnnotation: "a while"}
+	_, err := findConflicts([]item{{cronJob: &cronjob}}, getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T01:00:00Z"), boundaries{}, time.Minute)
+	if err == nil {
+		t.Error("findConflicts() error = nil, want error")
+	}
+}
+
+func Test_printConflicts(t *testing.T) {
+	t.Parallel()
+	conflicts := []conflict{
+		{Kind: "CronJob", Namespace: "ns-a", Name: "forbid", ConcurrencyPolicy: "Forbid", FireTime: getTime("2023-01-24T00:10:00Z"), Outcome: "skipped"},
+		{Kind: "CronWorkflow", Namespace: "ns-a", Name: "replace", ConcurrencyPolicy: "Replace", FireTime: getTime("2023-01-24T00:20:00Z"), Outcome: "replaced"},
+	}
+
+	var table bytes.Buffer
+	if err := printConflicts(&table, "", false, timeFormat{}, conflicts); err != nil {
+		t.Fatal(err)
+	}
+	wantTable := "" +
+		"Namespace   Name      Kind           Concurrency   Fire Time              Outcome\n" +
+		"ns-a        forbid    CronJob        Forbid        2023-01-24T00:10:00Z   skipped\n" +
+		"ns-a        replace   CronWorkflow   Replace       2023-01-24T00:20:00Z   replaced\n"
+	if diff := cmp.Diff(wantTable, table.String()); diff != "" {
+		t.Errorf("printConflicts() table mismatch (-want +got):\n%s", diff)
+	}
+
+	var document bytes.Buffer
+	if err := printConflicts(&document, "json", false, timeFormat{}, conflicts); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "conflicts.golden.json", document.Bytes())
+}
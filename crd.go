@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/unblee/kubectl-cls/pkg/cls"
+	"golang.org/x/exp/maps"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// crdKind is a kind of custom resources with cron schedules, listed with
+// '--include-crds'. The objects are read as unstructured, since the types are
+// operator-specific.
+type crdKind struct {
+	// kind is shown in the KIND column.
+	kind     string
+	resource schema.GroupVersionResource
+	// schedules extracts the schedules of the object. The name is empty when
+	// the kind has a single unnamed schedule.
+	schedules func(obj *unstructured.Unstructured) []namedSchedule
+	// suspended is whether the object is paused, or nil for the kinds which
+	// can't be paused.
+	suspended func(obj *unstructured.Unstructured) bool
+}
+
+// namedSchedule is a schedule of a custom resource, e.g. a session of a
+// KubeStash BackupConfiguration.
+type namedSchedule struct {
+	name     string
+	schedule string
+}
+
+// crdKinds are the kinds of each '--include-crds' value.
+var crdKinds = map[string][]crdKind{
+	"kubestash": {kubeStashBackupConfiguration, stashBackupConfiguration},
+	"k8up":      {k8upSchedule},
+	"percona":   {perconaXtraDBCluster, perconaServerMongoDB},
+}
+
+// Get the kinds of the '--include-crds' values, in the order of the values.
+func parseCRDKinds(values []string) ([]crdKind, error) {
+	kinds := []crdKind{}
+	for _, value := range values {
+		k, ok := crdKinds[value]
+		if !ok {
+			names := maps.Keys(crdKinds)
+			sort.Strings(names)
+			return nil, fmt.Errorf("%s is unsupported '--include-crds', must be one of %s", value, strings.Join(names, ", "))
+		}
+		kinds = append(kinds, k...)
+	}
+	return kinds, nil
+}
+
+// crdSource lists the custom resources of the kind. It lists nothing when the
+// CRD is not installed.
+type crdSource struct {
+	c    *clients
+	kind crdKind
+}
+
+func (s crdSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	list, err := s.c.dynamic.Resource(s.kind.resource).Namespace(opts.Namespace).List(ctx, metav1.ListOptions{LabelSelector: opts.Selector})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s in '%s' namespace: %w", s.kind.kind, displayNamespace(opts.Namespace), err)
+	}
+	objects := []cls.Scheduled{}
+	for i := range list.Items {
+		if obj := s.kind.object(&list.Items[i]); obj != nil {
+			objects = append(objects, obj)
+		}
+	}
+	return objects, nil
+}
+
+// Get the custom resource as a providedObject, or nil when it has no
+// schedule.
+func (k crdKind) object(u *unstructured.Unstructured) *providedObject {
+	schedules := k.schedules(u)
+	if len(schedules) == 0 {
+		return nil
+	}
+	obj := &providedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: u.GetNamespace(),
+			Name:      u.GetName(),
+			Labels:    u.GetLabels(),
+		},
+		kind: k.kind,
+	}
+	named := false
+	for _, s := range schedules {
+		obj.schedules = append(obj.schedules, s.schedule)
+		named = named || s.name != ""
+	}
+	if named {
+		for _, s := range schedules {
+			obj.scheduleNames = append(obj.scheduleNames, s.name)
+		}
+	}
+	if k.suspended != nil {
+		obj.suspend = k.suspended(u)
+	}
+	return obj
+}
+
+// KubeStash BackupConfigurations have a scheduler for each of the sessions.
+var kubeStashBackupConfiguration = crdKind{
+	kind:     "BackupConfiguration (kubestash)",
+	resource: schema.GroupVersionResource{Group: "core.kubestash.com", Version: "v1alpha1", Resource: "backupconfigurations"},
+	schedules: func(obj *unstructured.Unstructured) []namedSchedule {
+		sessions, _, _ := unstructured.NestedSlice(obj.Object, "spec", "sessions")
+		schedules := []namedSchedule{}
+		for _, session := range sessions {
+			session, ok := session.(map[string]any)
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(session, "name")
+			schedule, _, _ := unstructured.NestedString(session, "scheduler", "schedule")
+			if schedule != "" {
+				schedules = append(schedules, namedSchedule{name: name, schedule: schedule})
+			}
+		}
+		return schedules
+	},
+	suspended: specPaused,
+}
+
+// The BackupConfigurations of the older Stash have a single schedule.
+var stashBackupConfiguration = crdKind{
+	kind:     "BackupConfiguration (stash)",
+	resource: schema.GroupVersionResource{Group: "stash.appscode.com", Version: "v1beta1", Resource: "backupconfigurations"},
+	schedules: func(obj *unstructured.Unstructured) []namedSchedule {
+		schedule, _, _ := unstructured.NestedString(obj.Object, "spec", "schedule")
+		if schedule == "" {
+			return nil
+		}
+		return []namedSchedule{{schedule: schedule}}
+	},
+	suspended: specPaused,
+}
+
+// k8upJobTypes are the sub-schedules of K8up Schedules, in the order shown.
+var k8upJobTypes = []string{"backup", "check", "prune", "archive", "restore"}
+
+// K8up Schedules have a sub-schedule for each type of the jobs. K8up fires a
+// '-random' descriptor such as '@weekly-random' at a time derived from the
+// Schedule within the period of the base descriptor, so it is approximated by
+// the base descriptor '@weekly', which fires at the beginning of the period.
+var k8upSchedule = crdKind{
+	kind:     "Schedule (k8up)",
+	resource: schema.GroupVersionResource{Group: "k8up.io", Version: "v1", Resource: "schedules"},
+	schedules: func(obj *unstructured.Unstructured) []namedSchedule {
+		schedules := []namedSchedule{}
+		for _, jobType := range k8upJobTypes {
+			schedule, _, _ := unstructured.NestedString(obj.Object, "spec", jobType, "schedule")
+			if schedule == "" {
+				continue
+			}
+			name := jobType
+			if strings.HasSuffix(schedule, "-random") {
+				name += " (random)"
+				schedule = strings.TrimSuffix(schedule, "-random")
+			}
+			schedules = append(schedules, namedSchedule{name: name, schedule: schedule})
+		}
+		return schedules
+	},
+}
+
+// The clusters of the Percona operators have the backup schedules in
+// spec.backup.schedules, unless spec.backup.enabled is false.
+var (
+	perconaXtraDBCluster = crdKind{
+		kind:      "PerconaXtraDBCluster",
+		resource:  schema.GroupVersionResource{Group: "pxc.percona.com", Version: "v1", Resource: "perconaxtradbclusters"},
+		schedules: perconaBackupSchedules,
+	}
+	perconaServerMongoDB = crdKind{
+		kind:      "PerconaServerMongoDB",
+		resource:  schema.GroupVersionResource{Group: "psmdb.percona.com", Version: "v1", Resource: "perconaservermongodbs"},
+		schedules: perconaBackupSchedules,
+	}
+)
+
+func perconaBackupSchedules(obj *unstructured.Unstructured) []namedSchedule {
+	if enabled, found, _ := unstructured.NestedBool(obj.Object, "spec", "backup", "enabled"); found && !enabled {
+		return nil
+	}
+	entries, _, _ := unstructured.NestedSlice(obj.Object, "spec", "backup", "schedules")
+	schedules := []namedSchedule{}
+	for _, entry := range entries {
+		entry, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(entry, "name")
+		schedule, _, _ := unstructured.NestedString(entry, "schedule")
+		if schedule != "" {
+			schedules = append(schedules, namedSchedule{name: name, schedule: schedule})
+		}
+	}
+	return schedules
+}
+
+func specPaused(obj *unstructured.Unstructured) bool {
+	paused, _, _ := unstructured.NestedBool(obj.Object, "spec", "paused")
+	return paused
+}
+
+// crdFlags are the flags which can be used with '--include-crds' in addition
+// to the ones of '--provider', as the custom resources are listed in the
+// namespaces like CronJobs.
+var crdFlags = []string{
+	"namespace-selector",
+	"namespaces",
+}
+
+// Validate the flags used with '--include-crds'. The custom resources are
+// items like the ones of the providers, so only tableFlags, providerFlags and
+// crdFlags can be used.
+func validateCRDFlags(fsets *pflag.FlagSet) error {
+	if name := unsupportedFlag(fsets, tableFlags, providerFlags, crdFlags); name != "" {
+		return fmt.Errorf("'--include-crds' can't be used with '--%s'", name)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/pflag"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func getUnstructured(apiVersion, kind, namespace, name string, spec map[string]any) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]any{"namespace": namespace, "name": name},
+		"spec":       spec,
+	}}
+}
+
+func getKubeStashBackupConfiguration(namespace, name string, sessions ...namedSchedule) *unstructured.Unstructured {
+	list := []any{}
+	for _, s := range sessions {
+		session := map[string]any{"name": s.name}
+		if s.schedule != "" {
+			session["scheduler"] = map[string]any{"schedule": s.schedule}
+		}
+		list = append(list, session)
+	}
+	return getUnstructured("core.kubestash.com/v1alpha1", "BackupConfiguration", namespace, name, map[string]any{"sessions": list})
+}
+
+// Get the fake clients with the custom resources of the kinds.
+func newCRDClients(kinds []crdKind, objects ...runtime.Object) *clients {
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, k := range kinds {
+		listKinds[k.resource] = strings.SplitN(k.kind, " ", 2)[0] + "List"
+	}
+	c := newFakeClients([]batchv1.CronJob{getCronJob("default", "nightly", "0 1 * * *", false)}, nil)
+	c.dynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+	c.crdKinds = kinds
+	return c
+}
+
+func Test_crdKind_object(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		kind crdKind
+		obj  *unstructured.Unstructured
+		want *providedObject
+	}{
+		{
+			name: "kubestash sessions",
+			kind: kubeStashBackupConfiguration,
+			obj: getKubeStashBackupConfiguration("default", "db",
+				namedSchedule{name: "hourly", schedule: "0 * * * *"},
+				namedSchedule{name: "manual"},
+				namedSchedule{name: "weekly", schedule: "0 3 * * 0"},
+			),
+			want: &providedObject{
+				kind:          "BackupConfiguration (kubestash)",
+				schedules:     []string{"0 * * * *", "0 3 * * 0"},
+				scheduleNames: []string{"hourly", "weekly"},
+			},
+		},
+		{
+			name: "kubestash without sessions",
+			kind: kubeStashBackupConfiguration,
+			obj:  getKubeStashBackupConfiguration("default", "db"),
+		},
+		{
+			name: "stash paused",
+			kind: stashBackupConfiguration,
+			obj: getUnstructured("stash.appscode.com/v1beta1", "BackupConfiguration", "default", "db",
+				map[string]any{"schedule": "*/30 * * * *", "paused": true}),
+			want: &providedObject{
+				kind:      "BackupConfiguration (stash)",
+				schedules: []string{"*/30 * * * *"},
+				suspend:   true,
+			},
+		},
+		{
+			name: "k8up plain and random",
+			kind: k8upSchedule,
+			obj: getUnstructured("k8up.io/v1", "Schedule", "default", "db", map[string]any{
+				"backup":  map[string]any{"schedule": "@weekly-random"},
+				"prune":   map[string]any{"schedule": "0 4 * * *"},
+				"check":   map[string]any{"schedule": "@daily-random"},
+				"archive": map[string]any{},
+			}),
+			want: &providedObject{
+				kind:          "Schedule (k8up)",
+				schedules:     []string{"@weekly", "@daily", "0 4 * * *"},
+				scheduleNames: []string{"backup (random)", "check (random)", "prune"},
+			},
+		},
+		{
+			name: "percona schedules",
+			kind: perconaXtraDBCluster,
+			obj: getUnstructured("pxc.percona.com/v1", "PerconaXtraDBCluster", "default", "db", map[string]any{
+				"backup": map[string]any{"schedules": []any{
+					map[string]any{"name": "daily-backup", "schedule": "0 0 * * *", "keep": int64(5), "storageName": "s3"},
+					map[string]any{"name": "sat-night-backup", "schedule": "0 0 * * 6", "keep": int64(3)},
+				}},
+			}),
+			want: &providedObject{
+				kind:          "PerconaXtraDBCluster",
+				schedules:     []string{"0 0 * * *", "0 0 * * 6"},
+				scheduleNames: []string{"daily-backup", "sat-night-backup"},
+			},
+		},
+		{
+			name: "percona backups disabled",
+			kind: perconaServerMongoDB,
+			obj: getUnstructured("psmdb.percona.com/v1", "PerconaServerMongoDB", "default", "db", map[string]any{
+				"backup": map[string]any{"enabled": false, "schedules": []any{
+					map[string]any{"name": "daily-backup", "schedule": "0 0 * * *"},
+				}},
+			}),
+		},
+		{
+			name: "percona without backup",
+			kind: perconaServerMongoDB,
+			obj:  getUnstructured("psmdb.percona.com/v1", "PerconaServerMongoDB", "default", "db", map[string]any{}),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := tt.kind.object(tt.obj)
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("want nil, got %+v", got)
+				}
+				return
+			}
+			tt.want.Namespace, tt.want.Name = "default", "db"
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(providedObject{})); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_listScheduleIncluded_crds(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	kinds, err := parseCRDKinds([]string{"kubestash"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("sessions", func(t *testing.T) {
+		t.Parallel()
+		c := newCRDClients(kinds,
+			getKubeStashBackupConfiguration("default", "db",
+				namedSchedule{name: "weekly", schedule: "0 3 * * 0"},
+				namedSchedule{name: "daily", schedule: "0 2 * * *"},
+			),
+			getKubeStashBackupConfiguration("default", "files",
+				namedSchedule{name: "evening", schedule: "0 20 * * *"},
+			),
+			getUnstructured("stash.appscode.com/v1beta1", "BackupConfiguration", "team-a", "legacy",
+				map[string]any{"schedule": "30 4 * * *"}),
+		)
+		got, err := listScheduleIncluded(context.Background(), c, "", "", from, to, boundaries{})
+		if err != nil {
+			t.Fatalf("listScheduleIncluded() error = %v", err)
+		}
+		want := []string{
+			"BackupConfiguration (kubestash)/default/db",
+			"CronJob/default/nightly",
+			"BackupConfiguration (stash)/team-a/legacy",
+		}
+		if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+
+		opts := printListOptions{window: &documentWindow{From: from, To: to}}
+		if got := formatSchedules(got[0], opts); got != "daily: 0 2 * * * (+1 more)" {
+			t.Errorf("formatSchedules() = %q", got)
+		}
+		opts.wide = true
+		if got := formatSchedules(got[0], opts); got != "daily: 0 2 * * *; weekly: 0 3 * * 0" {
+			t.Errorf("formatSchedules() wide = %q", got)
+		}
+		doc := got[0].provided.document(got[0].matchedScheduleIndex(from, to, boundaries{}))
+		if diff := cmp.Diff([]string{"daily", "weekly"}, doc.ScheduleNames); diff != "" {
+			t.Errorf("scheduleNames mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("k8up", func(t *testing.T) {
+		t.Parallel()
+		kinds, err := parseCRDKinds([]string{"k8up"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := newCRDClients(kinds,
+			getUnstructured("k8up.io/v1", "Schedule", "default", "db", map[string]any{
+				"backup": map[string]any{"schedule": "@weekly-random"},
+				"check":  map[string]any{"schedule": "@daily-random"},
+			}),
+			getUnstructured("k8up.io/v1", "Schedule", "default", "files", map[string]any{
+				"backup": map[string]any{"schedule": "@weekly-random"},
+				"prune":  map[string]any{"schedule": "0 12 * * *"},
+			}),
+		)
+		got, err := listScheduleIncluded(context.Background(), c, "default", "", from, to, boundaries{})
+		if err != nil {
+			t.Fatalf("listScheduleIncluded() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"Schedule (k8up)/default/db", "CronJob/default/nightly"}, getItemNames(got)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+		opts := printListOptions{window: &documentWindow{From: from, To: to}}
+		if got := formatSchedules(got[0], opts); got != "check (random): @daily (+1 more)" {
+			t.Errorf("formatSchedules() = %q", got)
+		}
+	})
+
+	t.Run("percona", func(t *testing.T) {
+		t.Parallel()
+		kinds, err := parseCRDKinds([]string{"percona"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := newCRDClients(kinds,
+			getUnstructured("pxc.percona.com/v1", "PerconaXtraDBCluster", "default", "mysql", map[string]any{
+				"backup": map[string]any{"schedules": []any{
+					map[string]any{"name": "sat-night-backup", "schedule": "0 0 * * 6", "keep": int64(3)},
+					map[string]any{"name": "daily-backup", "schedule": "0 3 * * *", "keep": int64(5)},
+				}},
+			}),
+			getUnstructured("psmdb.percona.com/v1", "PerconaServerMongoDB", "default", "mongo", map[string]any{
+				"backup": map[string]any{"enabled": false, "schedules": []any{
+					map[string]any{"name": "daily-backup", "schedule": "0 3 * * *"},
+				}},
+			}),
+		)
+		got, err := listScheduleIncluded(context.Background(), c, "", "", from, to, boundaries{})
+		if err != nil {
+			t.Fatalf("listScheduleIncluded() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"PerconaXtraDBCluster/default/mysql", "CronJob/default/nightly"}, getItemNames(got)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+		opts := printListOptions{window: &documentWindow{From: from, To: to}}
+		if got := formatSchedules(got[0], opts); got != "daily-backup: 0 3 * * * (+1 more)" {
+			t.Errorf("formatSchedules() = %q", got)
+		}
+	})
+
+	t.Run("CRD not installed", func(t *testing.T) {
+		t.Parallel()
+		c := newCRDClients(kinds)
+		c.dynamic.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "backupconfigurations", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(action.GetResource().GroupResource(), "")
+		})
+		got, err := listScheduleIncluded(context.Background(), c, "default", "", from, to, boundaries{})
+		if err != nil {
+			t.Fatalf("listScheduleIncluded() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"CronJob/default/nightly"}, getItemNames(got)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func Test_parseCRDKinds(t *testing.T) {
+	t.Parallel()
+	if _, err := parseCRDKinds([]string{"velero"}); err == nil || !strings.HasPrefix(err.Error(), "velero is unsupported '--include-crds', must be one of ") {
+		t.Errorf("want an unsupported error, got %v", err)
+	}
+}
+
+func Test_validateCRDFlags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--include-crds", "velero", "--namespace-selector", "team=a", "--context", "x"}, want: ""},
+		{args: []string{"--include-crds", "velero", "--unowned"}, want: "'--include-crds' can't be used with '--unowned'"},
+		{args: []string{"--include-crds", "velero", "--sort-by", "name"}, want: "'--include-crds' can't be used with '--sort-by'"},
+	}
+	for _, tt := range tests {
+		fsets := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fsets.StringSlice("include-crds", nil, "")
+		fsets.String("namespace-selector", "", "")
+		fsets.String("context", "", "")
+		fsets.Bool("unowned", false, "")
+		fsets.String("sort-by", "", "")
+		if err := fsets.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if err := validateCRDFlags(fsets); err != nil {
+			got = err.Error()
+		}
+		if got != tt.want {
+			t.Errorf("%v: validateCRDFlags() = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/unblee/kubectl-cls/pkg/cls"
+	"golang.org/x/exp/slog"
+)
+
+// The codes of the diagnostics of lintSchedule, which are also the codes of
+// their warnings in the json/yaml output document.
+const (
+	lintWhitespace      = "SCHEDULE_WHITESPACE"
+	lintFieldCount      = "SCHEDULE_FIELD_COUNT"
+	lintQuestionMark    = "SCHEDULE_QUESTION_MARK"
+	lintQuartzToken     = "SCHEDULE_QUARTZ_TOKEN"
+	lintQuartzDayOfWeek = "SCHEDULE_QUARTZ_DAY_OF_WEEK"
+	lintSundayAsSeven   = "SCHEDULE_SUNDAY_AS_7"
+	lintFullName        = "SCHEDULE_FULL_NAME"
+)
+
+// standardFields are the fields of the standard form.
+const standardFields = "minute hour day-of-month month day-of-week"
+
+var (
+	// The 'L', 'LW', 'L-3' and '15W' of the day of month of Quartz.
+	quartzDayOfMonthToken = regexp.MustCompile(`(?i)^(L|LW|L-\d+|\d+W)$`)
+	// The '5L' and '5#3' of the day of week of Quartz.
+	quartzDayOfWeekToken = regexp.MustCompile(`(?i)^([0-7]|[a-z]{3})?L$|#`)
+	fullDayName          = regexp.MustCompile(`(?i)\b(sunday|monday|tuesday|wednesday|thursday|friday|saturday)\b`)
+	fullMonthName        = regexp.MustCompile(`(?i)\b(january|february|march|april|june|july|august|september|october|november|december)\b`)
+	numberToken          = regexp.MustCompile(`\d+`)
+)
+
+// scheduleDiagnostic is a nonstandard or suspicious syntax of a schedule.
+type scheduleDiagnostic struct {
+	code    string
+	message string
+}
+
+// Classify the common mistakes of a schedule pasted from a crontab or from
+// Quartz before it's parsed, such as the wrong number of fields, the '?' and
+// 'L' of Quartz, 7 as Sunday, and extra whitespace. It returns the
+// diagnostics and the standard form of the schedule, which is empty when the
+// schedule can't be fixed automatically or when it's already standard. The
+// schedule is still parsed as is, as some of them are accepted silently.
+func lintSchedule(schedule string) ([]scheduleDiagnostic, string) {
+	var diags []scheduleDiagnostic
+	fields := strings.Fields(schedule)
+	if len(fields) == 0 {
+		return nil, ""
+	}
+	if strings.Join(fields, " ") != schedule {
+		diags = append(diags, scheduleDiagnostic{code: lintWhitespace, message: "the fields are separated by extra or nonstandard whitespace"})
+	}
+	var prefix []string
+	if strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=") {
+		prefix, fields = fields[:1], fields[1:]
+	}
+	if len(fields) == 0 || strings.HasPrefix(fields[0], "@") {
+		// The descriptors such as '@daily' have no fields.
+		return diags, suggestForm(diags, prefix, fields)
+	}
+
+	quartz := false
+	switch len(fields) {
+	case 5:
+	case 6:
+		quartz = true
+		diags = append(diags, scheduleDiagnostic{code: lintFieldCount, message: fmt.Sprintf("6 fields are given, the first one looks like the seconds of Quartz, while the standard form has 5 fields (%s)", standardFields)})
+		fields = fields[1:]
+	case 7:
+		quartz = true
+		diags = append(diags, scheduleDiagnostic{code: lintFieldCount, message: fmt.Sprintf("7 fields are given, the first and the last ones look like the seconds and the year of Quartz, while the standard form has 5 fields (%s)", standardFields)})
+		fields = fields[1:6]
+	default:
+		diags = append(diags, scheduleDiagnostic{code: lintFieldCount, message: fmt.Sprintf("%d fields are given, while the standard form has 5 fields (%s)", len(fields), standardFields)})
+		return diags, ""
+	}
+	fields = append([]string{}, fields...)
+
+	fixable := true
+	if strings.Contains(fields[2], "?") || strings.Contains(fields[4], "?") {
+		diags = append(diags, scheduleDiagnostic{code: lintQuestionMark, message: "'?' is the 'no specific value' of Quartz, use '*' instead"})
+		fields[2] = strings.ReplaceAll(fields[2], "?", "*")
+		fields[4] = strings.ReplaceAll(fields[4], "?", "*")
+	}
+	if hasToken(fields[2], quartzDayOfMonthToken) || hasToken(fields[4], quartzDayOfWeekToken) {
+		diags = append(diags, scheduleDiagnostic{code: lintQuartzToken, message: "'L', 'W' and '#' of Quartz are unsupported, list the days explicitly instead"})
+		fixable = false
+	}
+	if fullDayName.MatchString(fields[4]) || fullMonthName.MatchString(fields[3]) {
+		diags = append(diags, scheduleDiagnostic{code: lintFullName, message: "the full names of the days and the months are unsupported, use the first three letters such as 'sun' and 'jan'"})
+		fields[4] = fullDayName.ReplaceAllStringFunc(fields[4], func(s string) string { return strings.ToLower(s[:3]) })
+		fields[3] = fullMonthName.ReplaceAllStringFunc(fields[3], func(s string) string { return strings.ToLower(s[:3]) })
+	}
+	if quartz && fixable {
+		if shifted := mapDayOfWeek(fields[4], func(n int) string { return strconv.Itoa(n - 1) }); shifted != fields[4] {
+			diags = append(diags, scheduleDiagnostic{code: lintQuartzDayOfWeek, message: "Quartz numbers the days of the week 1-7 from Sunday, while the standard form numbers them 0-6 from Sunday"})
+			fields[4] = shifted
+		}
+	} else if !quartz && hasSundayAsSeven(fields[4]) {
+		diags = append(diags, scheduleDiagnostic{code: lintSundayAsSeven, message: "7 as Sunday is unsupported, use 0 instead"})
+		fields[4] = fixSundayAsSeven(fields[4])
+		// A step to 7 such as '1-7/2' is left to be fixed by hand.
+		fixable = fixable && !hasSundayAsSeven(fields[4])
+	}
+
+	if !fixable {
+		return diags, ""
+	}
+	return diags, suggestForm(diags, prefix, fields)
+}
+
+// Get the standard form of the fixed fields, empty without the diagnostics.
+func suggestForm(diags []scheduleDiagnostic, prefix, fields []string) string {
+	if len(diags) == 0 {
+		return ""
+	}
+	return strings.Join(append(append([]string{}, prefix...), fields...), " ")
+}
+
+// Whether any element of the list in the field matches the token.
+func hasToken(field string, token *regexp.Regexp) bool {
+	for _, part := range strings.Split(field, ",") {
+		if token.MatchString(part) {
+			return true
+		}
+	}
+	return false
+}
+
+// Replace the numbers of the days in the field, keeping the steps of '/'.
+func mapDayOfWeek(field string, f func(int) string) string {
+	parts := strings.Split(field, ",")
+	for i, part := range parts {
+		base, step, hasStep := strings.Cut(part, "/")
+		base = numberToken.ReplaceAllStringFunc(base, func(s string) string {
+			n, _ := strconv.Atoi(s)
+			return f(n)
+		})
+		if hasStep {
+			base += "/" + step
+		}
+		parts[i] = base
+	}
+	return strings.Join(parts, ",")
+}
+
+// Whether the day of week has 7, which is Sunday in some crontabs.
+func hasSundayAsSeven(field string) bool {
+	return mapDayOfWeek(field, func(n int) string {
+		if n == 7 {
+			return "x"
+		}
+		return strconv.Itoa(n)
+	}) != field
+}
+
+// Replace 7 with 0, and a range to 7 such as '5-7' with '5-6,0'.
+func fixSundayAsSeven(field string) string {
+	parts := strings.Split(field, ",")
+	for i, part := range parts {
+		base, step, hasStep := strings.Cut(part, "/")
+		switch {
+		case base == "7":
+			base = "0"
+		case strings.HasSuffix(base, "-7") && !hasStep:
+			start := strings.TrimSuffix(base, "-7")
+			if start == "6" {
+				base = "6,0"
+			} else {
+				base = start + "-6,0"
+			}
+		}
+		if hasStep {
+			base += "/" + step
+		}
+		parts[i] = base
+	}
+	return strings.Join(parts, ",")
+}
+
+// scheduleLints collects the warnings of the schedules of the evaluated
+// objects. It is safe for concurrent use, as the namespaces are listed in
+// parallel, and a nil scheduleLints collects nothing.
+type scheduleLints struct {
+	mu       sync.Mutex
+	seen     map[string]bool
+	warnings []documentWarning
+}
+
+// Lint the schedules of the object once.
+func (l *scheduleLints) add(obj cls.Scheduled) {
+	if l == nil {
+		return
+	}
+	meta := obj.Meta()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, schedule := range obj.Schedules() {
+		key := strings.Join([]string{obj.Kind(), meta.GetNamespace(), meta.GetName(), schedule}, "/")
+		if l.seen[key] {
+			continue
+		}
+		if l.seen == nil {
+			l.seen = map[string]bool{}
+		}
+		l.seen[key] = true
+		diags, suggestion := lintSchedule(schedule)
+		for _, d := range diags {
+			l.warnings = append(l.warnings, documentWarning{
+				Code:       d.code,
+				Object:     &objectReference{Kind: obj.Kind(), Namespace: meta.GetNamespace(), Name: meta.GetName()},
+				Message:    d.message,
+				Schedule:   schedule,
+				Suggestion: suggestion,
+			})
+		}
+	}
+}
+
+// Lint the schedules of the items.
+func (l *scheduleLints) addItems(items []item) {
+	for _, item := range items {
+		l.add(item.scheduled())
+	}
+}
+
+// Get the warnings sorted by the object, nil without them.
+func (l *scheduleLints) list() []documentWarning {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.warnings) == 0 {
+		return nil
+	}
+	ret := append([]documentWarning{}, l.warnings...)
+	sort.SliceStable(ret, func(i, j int) bool {
+		a, b := ret[i].Object, ret[j].Object
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Kind < b.Kind
+	})
+	return ret
+}
+
+// Log the warnings with the suggested standard forms.
+func (l *scheduleLints) warn(logger *slog.Logger) {
+	for _, w := range l.list() {
+		obj := w.Object
+		msg := fmt.Sprintf("schedule '%s' of %s '%s/%s': %s", w.Schedule, obj.Kind, obj.Namespace, obj.Name, w.Message)
+		attrs := []any{"kind", obj.Kind, "namespace", obj.Namespace, "name", obj.Name, "code", w.Code}
+		if w.Suggestion != "" {
+			msg += fmt.Sprintf(", the standard form is '%s'", w.Suggestion)
+			attrs = append(attrs, "suggestion", w.Suggestion)
+		}
+		logger.Warn(msg, attrs...)
+	}
+}
+
+// lintSource lints the schedules of the listed objects before they are
+// parsed by cls.Match, so that the warnings explain a failed parse.
+type lintSource struct {
+	cls.Source
+	lints *scheduleLints
+}
+
+func (s lintSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	objects, err := s.Source.List(ctx, opts)
+	for _, obj := range objects {
+		s.lints.add(obj)
+	}
+	return objects, err
+}
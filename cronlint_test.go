@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_lintSchedule(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		schedule       string
+		wantCodes      []string
+		wantSuggestion string
+	}{
+		{schedule: "0 1 * * *"},
+		{schedule: "CRON_TZ=Asia/Tokyo 0 1 * * 1-5"},
+		{schedule: "@daily"},
+		{schedule: "0 1 * * sun,mon"},
+		{schedule: "*/15 * * * *"},
+		{
+			schedule:       " 0  1 * *\t* ",
+			wantCodes:      []string{lintWhitespace},
+			wantSuggestion: "0 1 * * *",
+		},
+		{
+			schedule:       "TZ=UTC  @hourly",
+			wantCodes:      []string{lintWhitespace},
+			wantSuggestion: "TZ=UTC @hourly",
+		},
+		{
+			schedule:       "0 30 2 * * *",
+			wantCodes:      []string{lintFieldCount},
+			wantSuggestion: "30 2 * * *",
+		},
+		{
+			schedule:       "0 0 12 ? * MON-FRI",
+			wantCodes:      []string{lintFieldCount, lintQuestionMark},
+			wantSuggestion: "0 12 * * MON-FRI",
+		},
+		{
+			schedule:       "0 15 10 ? * 2-6 2023",
+			wantCodes:      []string{lintFieldCount, lintQuestionMark, lintQuartzDayOfWeek},
+			wantSuggestion: "15 10 * * 1-5",
+		},
+		{
+			schedule:       "0 0 12 1/5 * ?",
+			wantCodes:      []string{lintFieldCount, lintQuestionMark},
+			wantSuggestion: "0 12 1/5 * *",
+		},
+		{
+			schedule:  "0 1 * *",
+			wantCodes: []string{lintFieldCount},
+		},
+		{
+			schedule:       "0 1 ? * *",
+			wantCodes:      []string{lintQuestionMark},
+			wantSuggestion: "0 1 * * *",
+		},
+		{
+			schedule:  "0 1 L * *",
+			wantCodes: []string{lintQuartzToken},
+		},
+		{
+			schedule:  "0 1 15W * *",
+			wantCodes: []string{lintQuartzToken},
+		},
+		{
+			schedule:  "0 0 1 ? * 6L",
+			wantCodes: []string{lintFieldCount, lintQuestionMark, lintQuartzToken},
+		},
+		{
+			schedule:  "0 1 * * MON#2",
+			wantCodes: []string{lintQuartzToken},
+		},
+		{
+			schedule:       "0 1 * * 7",
+			wantCodes:      []string{lintSundayAsSeven},
+			wantSuggestion: "0 1 * * 0",
+		},
+		{
+			schedule:       "0 1 * * 5-7",
+			wantCodes:      []string{lintSundayAsSeven},
+			wantSuggestion: "0 1 * * 5-6,0",
+		},
+		{
+			schedule:       "0 1 * * 1,7",
+			wantCodes:      []string{lintSundayAsSeven},
+			wantSuggestion: "0 1 * * 1,0",
+		},
+		{
+			schedule:  "0 1 * * 1-7/2",
+			wantCodes: []string{lintSundayAsSeven},
+		},
+		{
+			schedule:       "0 1 * * Sunday",
+			wantCodes:      []string{lintFullName},
+			wantSuggestion: "0 1 * * sun",
+		},
+		{
+			schedule:       "0 1 1 January,march *",
+			wantCodes:      []string{lintFullName},
+			wantSuggestion: "0 1 1 jan,mar *",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.schedule, func(t *testing.T) {
+			t.Parallel()
+			diags, suggestion := lintSchedule(tt.schedule)
+			var codes []string
+			for _, d := range diags {
+				codes = append(codes, d.code)
+			}
+			if diff := cmp.Diff(tt.wantCodes, codes); diff != "" {
+				t.Errorf("codes (-want +got):\n%s", diff)
+			}
+			if suggestion != tt.wantSuggestion {
+				t.Errorf("want the suggestion %q, got %q", tt.wantSuggestion, suggestion)
+			}
+			if suggestion != "" {
+				if _, err := cron.ParseStandard(suggestion); err != nil {
+					t.Errorf("the suggestion %q isn't standard: %s", suggestion, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_run_lintWarnings(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{
+			getCronJob("ns-a", "backup", "0 1 ? * *", false),
+			getCronJob("ns-a", "cleanup", "0 2 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{},
+	)
+	stdout, stderr, err := runFake(c, append([]string{"-n", "ns-a", "-o", "json"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	wantStderr := "warning: schedule '0 1 ? * *' of CronJob 'ns-a/backup': '?' is the 'no specific value' of Quartz, use '*' instead, the standard form is '0 1 * * *'\n"
+	if diff := cmp.Diff(wantStderr, stderr); diff != "" {
+		t.Errorf("stderr (-want +got):\n%s", diff)
+	}
+	var doc printformat
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("failed to decode the output: %s", err)
+	}
+	want := []documentWarning{{
+		Code:       lintQuestionMark,
+		Object:     &objectReference{Kind: "CronJob", Namespace: "ns-a", Name: "backup"},
+		Message:    "'?' is the 'no specific value' of Quartz, use '*' instead",
+		Schedule:   "0 1 ? * *",
+		Suggestion: "0 1 * * *",
+	}}
+	if diff := cmp.Diff(want, doc.Warnings); diff != "" {
+		t.Errorf("warnings (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_lintWarnings_failedParse(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{getCronJob("ns-a", "report", "0 0 12 ? * MON-FRI", false)},
+		[]wfv1alpha1.CronWorkflow{},
+	)
+	_, stderr, err := runFake(c, append([]string{"-n", "ns-a"}, runPeriod...)...)
+	if err == nil || !strings.Contains(err.Error(), "failed to parse schedule spec") {
+		t.Fatalf("want the parse error, got %v", err)
+	}
+	// The warnings explain the error with the standard form.
+	if !strings.Contains(stderr, "6 fields are given") || !strings.Contains(stderr, "the standard form is '0 12 * * MON-FRI'") {
+		t.Errorf("want the warnings with the suggestion, got %q", stderr)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The classes of the startingDeadlineSeconds of '--deadline-risk'.
+const (
+	deadlineUnset    = "unset"
+	deadlineOK       = "ok"
+	deadlineTooTight = "too-tight"
+	deadlineTooLoose = "too-loose"
+)
+
+// The CronJob controller checks the start times around every 10s, and gives
+// up on the start times missed more than 100 times since the later of the last
+// schedule and the deadline.
+const (
+	controllerSyncPeriod = 10 * time.Second
+	maxMissedStartTimes  = 100
+)
+
+type deadlineRisk struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Schedule  string `json:"schedule"`
+	// Interval is the typical interval of the schedule, or "" when unknown.
+	Interval                string `json:"interval,omitempty"`
+	ConcurrencyPolicy       string `json:"concurrencyPolicy"`
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds"`
+	Risk                    string `json:"risk"`
+	Reason                  string `json:"reason"`
+}
+
+type deadlineRiskReport struct {
+	ApiVersion string         `json:"apiVersion"`
+	Risks      []deadlineRisk `json:"risks"`
+}
+
+// Classify the startingDeadlineSeconds of the items against their typical
+// intervals from start, in the order of items.
+func findDeadlineRisks(items []item, start time.Time) ([]deadlineRisk, error) {
+	risks := []deadlineRisk{}
+	for _, item := range items {
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return nil, err
+		}
+		interval, _ := getTypicalInterval(sched, start)
+		obj := item.object()
+		deadline := item.startingDeadlineSeconds()
+		risk, reason := classifyDeadline(deadline, interval, item.concurrencyPolicy())
+		r := deadlineRisk{
+			Kind:                    item.kind(),
+			Namespace:               obj.GetNamespace(),
+			Name:                    obj.GetName(),
+			Schedule:                item.schedule(),
+			ConcurrencyPolicy:       item.concurrencyPolicy(),
+			StartingDeadlineSeconds: deadline,
+			Risk:                    risk,
+			Reason:                  reason,
+		}
+		if interval > 0 {
+			r.Interval = interval.String()
+		}
+		risks = append(risks, r)
+	}
+	return risks, nil
+}
+
+// Classify the deadline of an item firing every interval, 0 when unknown, and
+// return the class with the reason.
+func classifyDeadline(deadline *int64, interval time.Duration, concurrencyPolicy string) (string, string) {
+	if deadline == nil {
+		return deadlineUnset, fmt.Sprintf("the missed start times are counted since the last schedule, and the scheduling stops after %d of them", maxMissedStartTimes)
+	}
+	d := time.Duration(*deadline) * time.Second
+	switch {
+	case d < controllerSyncPeriod:
+		return deadlineTooTight, fmt.Sprintf("%s is shorter than the %s the controller may take to notice a start time, so the runs may be dropped", d, controllerSyncPeriod)
+	case interval == 0:
+		return deadlineOK, "the interval is unknown"
+	case concurrencyPolicy == "Forbid" && d < interval:
+		return deadlineTooTight, fmt.Sprintf("%s is shorter than the interval %s with Forbid, so a run delayed by the previous one more than that is dropped silently", d, interval)
+	case d > maxMissedStartTimes*interval:
+		return deadlineTooLoose, fmt.Sprintf("%s is longer than %d times the interval %s, so an outage may miss more than %d start times and stop the scheduling", d, maxMissedStartTimes, interval, maxMissedStartTimes)
+	}
+	return deadlineOK, fmt.Sprintf("%s is within %d times the interval %s", d, maxMissedStartTimes, interval)
+}
+
+func printDeadlineRisks(stdout io.Writer, output string, noHeaders bool, risks []deadlineRisk) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(deadlineRiskReport{ApiVersion: "v1", Risks: risks}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(deadlineRiskReport{ApiVersion: "v1", Risks: risks})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Schedule", "Kind", "Interval", "Concurrency", "Deadline", "Risk", "Reason"}, "\t"))
+	}
+	for _, r := range risks {
+		interval, deadline := "<unknown>", "<unset>"
+		if r.Interval != "" {
+			interval = r.Interval
+		}
+		if r.StartingDeadlineSeconds != nil {
+			deadline = strconv.FormatInt(*r.StartingDeadlineSeconds, 10) + "s"
+		}
+		fmt.Fprintln(tw, strings.Join([]string{r.Namespace, r.Name, r.Schedule, r.Kind, interval, r.ConcurrencyPolicy, deadline, r.Risk, r.Reason}, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_getTypicalInterval(t *testing.T) {
+	t.Parallel()
+	start := getTime("2023-01-24T00:00:00Z")
+	tests := []struct {
+		spec   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{spec: "*/5 * * * *", want: 5 * time.Minute, wantOK: true},
+		// The weekends are the longer gaps of a week.
+		{spec: "0 2 * * 1-5", want: 24 * time.Hour, wantOK: true},
+		{spec: "0 0 1 * *", want: 31 * 24 * time.Hour, wantOK: true},
+		{spec: "0 0 30 2 *", wantOK: false},
+	}
+	for _, tt := range tests {
+		sched, err := cron.ParseStandard(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := getTypicalInterval(sched, start)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("getTypicalInterval(%s) = %s, %v, want %s, %v", tt.spec, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func Test_classifyDeadline(t *testing.T) {
+	t.Parallel()
+	int64Ptr := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name              string
+		deadline          *int64
+		interval          time.Duration
+		concurrencyPolicy string
+		want              string
+	}{
+		{name: "unset", deadline: nil, interval: time.Hour, concurrencyPolicy: "Allow", want: deadlineUnset},
+		{name: "ok", deadline: int64Ptr(300), interval: time.Hour, concurrencyPolicy: "Allow", want: deadlineOK},
+		{name: "ok with forbid", deadline: int64Ptr(3600), interval: time.Hour, concurrencyPolicy: "Forbid", want: deadlineOK},
+		{name: "ok with unknown interval", deadline: int64Ptr(300), concurrencyPolicy: "Forbid", want: deadlineOK},
+		{name: "tight with forbid", deadline: int64Ptr(300), interval: time.Hour, concurrencyPolicy: "Forbid", want: deadlineTooTight},
+		{name: "tight for the controller", deadline: int64Ptr(5), interval: time.Hour, concurrencyPolicy: "Allow", want: deadlineTooTight},
+		{name: "loose", deadline: int64Ptr(86400), interval: 5 * time.Minute, concurrencyPolicy: "Allow", want: deadlineTooLoose},
+		{name: "at 100 times", deadline: int64Ptr(30000), interval: 5 * time.Minute, concurrencyPolicy: "Allow", want: deadlineOK},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, reason := classifyDeadline(tt.deadline, tt.interval, tt.concurrencyPolicy)
+			if got != tt.want || reason == "" {
+				t.Errorf("classifyDeadline() = %s, %q, want %s", got, reason, tt.want)
+			}
+		})
+	}
+}
+
+func Test_run_deadlineRisk(t *testing.T) {
+	t.Parallel()
+	tight := getCronJob("ns-a", "tight", "*/10 * * * *", false)
+	tight.Spec.ConcurrencyPolicy = "Forbid"
+	deadline := int64(60)
+	tight.Spec.StartingDeadlineSeconds = &deadline
+	c := newFakeClients([]batchv1.CronJob{tight}, nil)
+	stdout, _, err := runFake(c, append(runPeriod, "--deadline-risk", "--no-headers")...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "ns-a   tight   */10 * * * *   CronJob   10m0s   Forbid   60s   too-tight   1m0s is shorter than the interval 10m0s with Forbid, so a run delayed by the previous one more than that is dropped silently\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/exp/slog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultDemandBucket is the bucket of '--demand' without a value.
+const defaultDemandBucket = "5m"
+
+// demandBucket is the sum of the requests of the fires in [Time, Time+bucket).
+type demandBucket struct {
+	Time   time.Time         `json:"time"`
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+	Fires  int               `json:"fires"`
+}
+
+type demandReport struct {
+	ApiVersion string         `json:"apiVersion"`
+	Bucket     string         `json:"bucket"`
+	Buckets    []demandBucket `json:"buckets"`
+	// The buckets with the largest CPU and memory, nil without buckets.
+	PeakCPU    *demandBucket `json:"peakCPU"`
+	PeakMemory *demandBucket `json:"peakMemory"`
+	// Skipped is the number of items without cpu nor memory requests.
+	Skipped int `json:"skipped"`
+}
+
+// Get the requests of a single run of the item. See getCronJobRequest and
+// getCronWorkflowRequest.
+func getItemRequests(item item) (cpu, memory resource.Quantity) {
+	if item.cronJob != nil {
+		return getCronJobRequest(item.cronJob, corev1.ResourceCPU), getCronJobRequest(item.cronJob, corev1.ResourceMemory)
+	}
+	return getCronWorkflowRequest(item.cronWorkflow, corev1.ResourceCPU), getCronWorkflowRequest(item.cronWorkflow, corev1.ResourceMemory)
+}
+
+// Sum the requests of the fires in the from-to period per bucket. The buckets
+// are aligned to the wall clock like '--round', and only the buckets with
+// fires are returned, sorted by time.
+func projectDemand(items []item, from, to time.Time, bounds boundaries, bucket time.Duration) (demandReport, error) {
+	report := demandReport{ApiVersion: "v1", Bucket: bucket.String(), Buckets: []demandBucket{}}
+	start := floorTime(from, bucket)
+	byIndex := map[int64]*demandBucket{}
+	for _, item := range items {
+		cpu, memory := getItemRequests(item)
+		if cpu.IsZero() && memory.IsZero() {
+			report.Skipped++
+			continue
+		}
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return demandReport{}, err
+		}
+		for t := sched.Next(bounds.start(from)); !t.IsZero() && bounds.beforeEnd(t, to); t = sched.Next(t) {
+			i := int64(t.Sub(start) / bucket)
+			b, ok := byIndex[i]
+			if !ok {
+				b = &demandBucket{Time: start.Add(time.Duration(i) * bucket).UTC()}
+				byIndex[i] = b
+			}
+			b.CPU.Add(cpu)
+			b.Memory.Add(memory)
+			b.Fires++
+		}
+	}
+
+	for i := int64(0); len(report.Buckets) < len(byIndex); i++ {
+		if b, ok := byIndex[i]; ok {
+			report.Buckets = append(report.Buckets, *b)
+		}
+	}
+	for i := range report.Buckets {
+		b := &report.Buckets[i]
+		if report.PeakCPU == nil || b.CPU.Cmp(report.PeakCPU.CPU) > 0 {
+			report.PeakCPU = b
+		}
+		if report.PeakMemory == nil || b.Memory.Cmp(report.PeakMemory.Memory) > 0 {
+			report.PeakMemory = b
+		}
+	}
+	return report, nil
+}
+
+func printDemand(stdout io.Writer, logger *slog.Logger, output string, noHeaders bool, tf timeFormat, report demandReport) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Time", "CPU", "Memory", "Fires", "Peak"}, "\t"))
+	}
+	for _, b := range report.Buckets {
+		peaks := []string{}
+		if report.PeakCPU != nil && report.PeakCPU.Time.Equal(b.Time) {
+			peaks = append(peaks, "cpu")
+		}
+		if report.PeakMemory != nil && report.PeakMemory.Time.Equal(b.Time) {
+			peaks = append(peaks, "memory")
+		}
+		t := metav1.NewTime(b.Time)
+		fmt.Fprintln(tw, strings.Join([]string{tf.format(&t), b.CPU.String(), b.Memory.String(), strconv.Itoa(b.Fires), strings.Join(peaks, ",")}, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if report.Skipped > 0 {
+		logger.Info(fmt.Sprintf("%d items without cpu nor memory requests were skipped", report.Skipped), "skipped", report.Skipped)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func getDemandFixtures() []item {
+	requests := func(cpu, memory string) corev1.Container {
+		list := corev1.ResourceList{}
+		if cpu != "" {
+			list[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			list[corev1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return corev1.Container{Resources: corev1.ResourceRequirements{Requests: list}}
+	}
+
+	// Every 10 minutes, in millicores and mebibytes.
+	small := getCronJob("default", "small", "*/10 * * * *", false)
+	small.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{requests("500m", "512Mi")}
+	// Hourly, in cores and gibibytes.
+	large := getCronJob("default", "large", "0 * * * *", false)
+	large.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{requests("2", "1Gi"), requests("", "1Gi")}
+	// Without requests, skipped.
+	none := getCronJob("default", "none", "* * * * *", false)
+
+	// At :05 past the hour, with the largest template.
+	cw := getCronWorkflow("default", "report", "5 * * * *", false)
+	cw.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "a", Container: &corev1.Container{Resources: requests("250m", "8Gi").Resources}},
+		{Name: "b", Container: &corev1.Container{Resources: requests("1", "1Gi").Resources}},
+	}
+
+	return mergeItems([]batchv1.CronJob{small, large, none}, []wfv1alpha1.CronWorkflow{cw})
+}
+
+func Test_projectDemand(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2023, 1, 24, 0, 0, 0, 0, time.UTC)
+	report, err := projectDemand(getDemandFixtures(), from, from.Add(30*time.Minute), boundaries{}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type bucket struct {
+		time   time.Time
+		cpu    string
+		memory string
+		fires  int
+	}
+	got := []bucket{}
+	for _, b := range report.Buckets {
+		got = append(got, bucket{b.Time, b.CPU.String(), b.Memory.String(), b.Fires})
+	}
+	want := []bucket{
+		// small at :00 and :10, large at :00, report at :05.
+		{from, "4", "11Gi", 4},
+		// small at :20.
+		{from.Add(15 * time.Minute), "500m", "512Mi", 1},
+		// small at :30.
+		{from.Add(30 * time.Minute), "500m", "512Mi", 1},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(bucket{})); diff != "" {
+		t.Errorf("buckets (-want +got):\n%s", diff)
+	}
+	if report.Skipped != 1 {
+		t.Errorf("want 1 skipped, got %d", report.Skipped)
+	}
+	if report.PeakCPU == nil || !report.PeakCPU.Time.Equal(from) {
+		t.Errorf("want the cpu peak at %s, got %v", from, report.PeakCPU)
+	}
+	if report.PeakMemory == nil || !report.PeakMemory.Time.Equal(from) {
+		t.Errorf("want the memory peak at %s, got %v", from, report.PeakMemory)
+	}
+}
+
+func Test_printDemand(t *testing.T) {
+	t.Parallel()
+	from := time.Date(2023, 1, 24, 0, 0, 0, 0, time.UTC)
+	report, err := projectDemand(getDemandFixtures(), from, from.Add(30*time.Minute), boundaries{}, 15*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := printDemand(&stdout, newLogger(&stderr, logFormatText, false), "", false, timeFormat{}, report); err != nil {
+		t.Fatal(err)
+	}
+	want := `Time                   CPU    Memory   Fires   Peak
+2023-01-24T00:00:00Z   4      11Gi     4       cpu,memory
+2023-01-24T00:15:00Z   500m   512Mi    1       
+2023-01-24T00:30:00Z   500m   512Mi    1       
+`
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("table (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff("1 items without cpu nor memory requests were skipped\n", stderr.String()); diff != "" {
+		t.Errorf("stderr (-want +got):\n%s", diff)
+	}
+}
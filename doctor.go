@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/pflag"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// The statuses of the checks of the doctor subcommand. Only a failed check is
+// a hard failure.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// errDoctorFailed is returned when a check of the doctor subcommand failed.
+var errDoctorFailed = errors.New("some checks failed")
+
+// checkResult is the result of a check of the doctor subcommand, with the hint
+// to fix it unless it passed.
+type checkResult struct {
+	name    string
+	status  string
+	message string
+	hint    string
+}
+
+func runDoctor(stdout, stderr io.Writer, args []string) error {
+	// Parse flags
+	// -----------------
+	fsets := pflag.NewFlagSet(commandName+" doctor", pflag.ContinueOnError)
+	fsets.SetOutput(stderr)
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.AddFlags(fsets)
+	var inClusterFlag bool
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
+
+	fsets.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of %s doctor:\n", commandName)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example")
+		fmt.Fprintln(stderr, "  $ kubectl-cls doctor -n default")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Flags")
+		fmt.Fprint(stderr, fsets.FlagUsages())
+	}
+
+	if err := fsets.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+
+	// Check
+	// -----------------
+	results := []checkResult{}
+	cfg, result := checkRESTConfig(cfgFlags)
+	results = append(results, result)
+	if cfg != nil {
+		namespace := ""
+		if cfgFlags.Namespace != nil {
+			namespace = *cfgFlags.Namespace
+		}
+		k8sClient, err := kubernetes.NewForConfig(cfg)
+		if err != nil {
+			results = append(results, checkResult{
+				name:    "client",
+				status:  checkFail,
+				message: fmt.Sprintf("failed to get kubernetes client: %v", err),
+				hint:    "check the server and the credentials of the context in the kubeconfig",
+			})
+		} else {
+			results = append(results, runClusterChecks(context.Background(), k8sClient, namespace)...)
+		}
+	}
+	printChecks(stdout, results)
+	for _, r := range results {
+		if r.status == checkFail {
+			return errDoctorFailed
+		}
+	}
+	return nil
+}
+
+// Run the checks against the cluster. The CronWorkflows are only checked for
+// the access when the CRD is installed.
+func runClusterChecks(ctx context.Context, k8sClient kubernetes.Interface, namespace string) []checkResult {
+	results := []checkResult{checkCronJobAPI(k8sClient.Discovery())}
+	results = append(results, checkListAccess(ctx, k8sClient, namespace, "batch", "cronjobs", checkFail))
+	crd := checkCronWorkflowAPI(k8sClient.Discovery())
+	results = append(results, crd)
+	if crd.status == checkPass {
+		// The CronJobs are still listed without the access to the CronWorkflows.
+		results = append(results, checkListAccess(ctx, k8sClient, namespace, "argoproj.io", "cronworkflows", checkWarn))
+	}
+	return results
+}
+
+// Check the REST config can be built from the kubeconfig and the flags, and
+// return it when it can.
+func checkRESTConfig(cfgFlags *genericclioptions.ConfigFlags) (*rest.Config, checkResult) {
+	cfg, err := getRESTConfig(cfgFlags, podEnv)
+	if err != nil {
+		return nil, checkResult{
+			name:    "kubeconfig",
+			status:  checkFail,
+			message: err.Error(),
+			hint:    "set KUBECONFIG or '--kubeconfig', and select a context with '--context' or 'kubectl config use-context', or use '--in-cluster' in a pod",
+		}
+	}
+	return cfg, checkResult{name: "kubeconfig", status: checkPass, message: fmt.Sprintf("the server is %s", cfg.Host)}
+}
+
+// Check the CronJobs are served in batch/v1, or in batch/v1beta1 by the
+// clusters before v1.21.
+func checkCronJobAPI(d discovery.DiscoveryInterface) checkResult {
+	name := "cronjob-api"
+	version := batchv1.SchemeGroupVersion.String()
+	served, err := servesCronJobs(d, version)
+	if err == nil && !served {
+		version = batchv1beta1.SchemeGroupVersion.String()
+		served, err = servesCronJobs(d, version)
+	}
+	switch {
+	case err != nil:
+		return checkResult{name: name, status: checkFail, message: err.Error(), hint: "check the server is reachable, e.g. with 'kubectl version'"}
+	case !served:
+		return checkResult{name: name, status: checkFail, message: "the server serves the CronJobs in neither batch/v1 nor batch/v1beta1", hint: "check the server is a Kubernetes API server"}
+	}
+	return checkResult{name: name, status: checkPass, message: fmt.Sprintf("the CronJobs are served in %s", version)}
+}
+
+// Check the CronWorkflow CRD of Argo Workflows is installed. It is a warning
+// without it, since only the CronJobs are listed then.
+func checkCronWorkflowAPI(d discovery.DiscoveryInterface) checkResult {
+	name := "cronworkflow-api"
+	served, err := servesResource(d, "argoproj.io/v1alpha1", "cronworkflows")
+	switch {
+	case err != nil:
+		return checkResult{name: name, status: checkWarn, message: err.Error(), hint: "check the server is reachable, e.g. with 'kubectl version'"}
+	case !served:
+		return checkResult{name: name, status: checkWarn, message: "the CronWorkflow CRD of Argo Workflows is not installed", hint: "the CronWorkflows are reported as failed to be listed; use it with the CronJobs only, or install Argo Workflows"}
+	}
+	return checkResult{name: name, status: checkPass, message: "the CronWorkflows are served in argoproj.io/v1alpha1"}
+}
+
+// Check the resources can be listed in the namespace, or in all namespaces
+// when it is empty, with a SelfSubjectAccessReview. denied is the status when
+// they can't.
+func checkListAccess(ctx context.Context, k8sClient kubernetes.Interface, namespace, group, resource, denied string) checkResult {
+	name := "list-" + resource
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "list",
+				Group:     group,
+				Resource:  resource,
+			},
+		},
+	}
+	res, err := k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return checkResult{name: name, status: denied, message: fmt.Sprintf("failed to review the access: %v", err), hint: "check the server is reachable, e.g. with 'kubectl version'"}
+	}
+	if !res.Status.Allowed {
+		message := fmt.Sprintf("can't list %s in '%s' namespace", resource, displayNamespace(namespace))
+		if res.Status.Reason != "" {
+			message += ": " + res.Status.Reason
+		}
+		return checkResult{
+			name:    name,
+			status:  denied,
+			message: message,
+			hint:    fmt.Sprintf("ask for a Role or ClusterRole with 'list' on '%s', or set '-n' to a namespace you can list", resource),
+		}
+	}
+	return checkResult{name: name, status: checkPass, message: fmt.Sprintf("can list %s in '%s' namespace", resource, displayNamespace(namespace))}
+}
+
+func printChecks(stdout io.Writer, results []checkResult) {
+	for _, r := range results {
+		fmt.Fprintf(stdout, "[%s] %s: %s\n", r.status, r.name, r.message)
+		if r.status != checkPass && r.hint != "" {
+			fmt.Fprintf(stdout, "       hint: %s\n", r.hint)
+		}
+	}
+}
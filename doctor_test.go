@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+const doctorKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://127.0.0.1:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: secret
+`
+
+func Test_checkRESTConfig(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(doctorKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("pass", func(t *testing.T) {
+		t.Parallel()
+		cfgFlags := genericclioptions.NewConfigFlags(false)
+		cfgFlags.KubeConfig = &path
+		cfg, got := checkRESTConfig(cfgFlags)
+		if cfg == nil || got.status != checkPass || got.message != "the server is https://127.0.0.1:6443" {
+			t.Errorf("checkRESTConfig() = %v, %+v", cfg, got)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		t.Parallel()
+		missing := filepath.Join(dir, "missing")
+		cfgFlags := genericclioptions.NewConfigFlags(false)
+		cfgFlags.KubeConfig = &missing
+		cfg, got := checkRESTConfig(cfgFlags)
+		if cfg != nil || got.status != checkFail || got.hint == "" {
+			t.Errorf("checkRESTConfig() = %v, %+v", cfg, got)
+		}
+	})
+}
+
+func Test_checkCronJobAPI(t *testing.T) {
+	t.Parallel()
+	cronjobs := metav1.APIResource{Name: "cronjobs"}
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		status    string
+		message   string
+	}{
+		{
+			name:      "batch/v1",
+			resources: []*metav1.APIResourceList{{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{cronjobs}}},
+			status:    checkPass,
+			message:   "the CronJobs are served in batch/v1",
+		},
+		{
+			name:      "batch/v1beta1",
+			resources: []*metav1.APIResourceList{{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{cronjobs}}},
+			status:    checkPass,
+			message:   "the CronJobs are served in batch/v1beta1",
+		},
+		{
+			name:      "neither",
+			resources: []*metav1.APIResourceList{{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "jobs"}}}},
+			status:    checkFail,
+			message:   "the server serves the CronJobs in neither batch/v1 nor batch/v1beta1",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d := k8sfake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			d.Resources = tt.resources
+			got := checkCronJobAPI(d)
+			if got.status != tt.status || got.message != tt.message {
+				t.Errorf("checkCronJobAPI() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_checkCronWorkflowAPI(t *testing.T) {
+	t.Parallel()
+	t.Run("installed", func(t *testing.T) {
+		t.Parallel()
+		d := k8sfake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+		d.Resources = []*metav1.APIResourceList{{GroupVersion: "argoproj.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "cronworkflows"}}}}
+		if got := checkCronWorkflowAPI(d); got.status != checkPass {
+			t.Errorf("checkCronWorkflowAPI() = %+v", got)
+		}
+	})
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+		d := k8sfake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+		if got := checkCronWorkflowAPI(d); got.status != checkWarn || got.message != "the CronWorkflow CRD of Argo Workflows is not installed" {
+			t.Errorf("checkCronWorkflowAPI() = %+v", got)
+		}
+	})
+}
+
+// Get the fake clientset answering the SelfSubjectAccessReviews with allowed,
+// recording the reviewed attributes, or failing with err.
+func newReviewClientset(allowed func(*authorizationv1.ResourceAttributes) bool, err error) *k8sfake.Clientset {
+	c := k8sfake.NewSimpleClientset()
+	c.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if err != nil {
+			return true, nil, err
+		}
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed(review.Spec.ResourceAttributes)
+		if !review.Status.Allowed {
+			review.Status.Reason = "RBAC: access denied"
+		}
+		return true, review, nil
+	})
+	return c
+}
+
+func Test_checkListAccess(t *testing.T) {
+	t.Parallel()
+	allowNamespace := func(attrs *authorizationv1.ResourceAttributes) bool {
+		return attrs.Namespace == "ns-a" && attrs.Verb == "list" && attrs.Group == "batch" && attrs.Resource == "cronjobs"
+	}
+	tests := []struct {
+		name      string
+		namespace string
+		err       error
+		want      checkResult
+	}{
+		{
+			name:      "allowed",
+			namespace: "ns-a",
+			want:      checkResult{name: "list-cronjobs", status: checkPass, message: "can list cronjobs in 'ns-a' namespace"},
+		},
+		{
+			name:      "denied",
+			namespace: "",
+			want: checkResult{
+				name:    "list-cronjobs",
+				status:  checkFail,
+				message: "can't list cronjobs in 'all' namespace: RBAC: access denied",
+				hint:    "ask for a Role or ClusterRole with 'list' on 'cronjobs', or set '-n' to a namespace you can list",
+			},
+		},
+		{
+			name:      "error",
+			namespace: "ns-a",
+			err:       errors.New("connection refused"),
+			want: checkResult{
+				name:    "list-cronjobs",
+				status:  checkFail,
+				message: "failed to review the access: connection refused",
+				hint:    "check the server is reachable, e.g. with 'kubectl version'",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := checkListAccess(context.Background(), newReviewClientset(allowNamespace, tt.err), tt.namespace, "batch", "cronjobs", checkFail)
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(checkResult{})); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_runClusterChecks(t *testing.T) {
+	t.Parallel()
+	c := newReviewClientset(func(attrs *authorizationv1.ResourceAttributes) bool { return attrs.Resource == "cronjobs" }, nil)
+	c.Discovery().(*fakediscovery.FakeDiscovery).Resources = []*metav1.APIResourceList{
+		{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{{Name: "cronjobs"}}},
+		{GroupVersion: "argoproj.io/v1alpha1", APIResources: []metav1.APIResource{{Name: "cronworkflows"}}},
+	}
+	var stdout bytes.Buffer
+	printChecks(&stdout, runClusterChecks(context.Background(), c, "ns-a"))
+	want := strings.Join([]string{
+		"[pass] cronjob-api: the CronJobs are served in batch/v1",
+		"[pass] list-cronjobs: can list cronjobs in 'ns-a' namespace",
+		"[pass] cronworkflow-api: the CronWorkflows are served in argoproj.io/v1alpha1",
+		"[warn] list-cronworkflows: can't list cronworkflows in 'ns-a' namespace: RBAC: access denied",
+		"       hint: ask for a Role or ClusterRole with 'list' on 'cronworkflows', or set '-n' to a namespace you can list",
+	}, "\n") + "\n"
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_runDoctor_kubeconfig(t *testing.T) {
+	t.Parallel()
+	var stdout, stderr bytes.Buffer
+	err := runDoctor(&stdout, &stderr, []string{"doctor", "--kubeconfig", filepath.Join(t.TempDir(), "missing")})
+	if !errors.Is(err, errDoctorFailed) {
+		t.Errorf("want errDoctorFailed, got %v", err)
+	}
+	if !strings.HasPrefix(stdout.String(), "[fail] kubeconfig: ") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"regexp"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// documentOptions controls how the objects are written into the json/yaml
+// output document.
+type documentOptions struct {
+	// raw writes the objects untouched. It takes precedence over keepStatus.
+	raw bool
+	// keepStatus keeps the status, which is stripped by default.
+	keepStatus bool
+	// compact writes the json document without the indentation.
+	compact bool
+	// redact replaces the literal values of the env vars whose names match it.
+	// Nil disables the redaction.
+	redact *regexp.Regexp
+	// window is written as the metadata of the document when not nil, and the
+	// first and last fires of the evaluations are in it with the boundaries.
+	window *documentWindow
+	bounds boundaries
+	// timing is written as the metadata of the document when not nil.
+	timing []timingPhase
+	// warnings are written as the metadata of the document when not nil.
+	warnings []documentWarning
+	// groups are written as the metadata of the document when not nil.
+	groups []documentGroup
+}
+
+// cronJobDocument is batchv1.CronJob whose status can be omitted.
+type cronJobDocument struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              batchv1.CronJobSpec    `json:"spec,omitempty"`
+	Status            *batchv1.CronJobStatus `json:"status,omitempty"`
+}
+
+// cronWorkflowDocument is wfv1alpha1.CronWorkflow whose status can be omitted.
+type cronWorkflowDocument struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              wfv1alpha1.CronWorkflowSpec    `json:"spec,omitempty"`
+	Status            *wfv1alpha1.CronWorkflowStatus `json:"status,omitempty"`
+}
+
+// Build the document of the CronJob from a deep copy, so that the cleaning does
+// not mutate the object used elsewhere in the run.
+func newCronJobDocument(cronjob *batchv1.CronJob, opts documentOptions) cronJobDocument {
+	c := cronjob.DeepCopy()
+	doc := cronJobDocument{TypeMeta: c.TypeMeta, ObjectMeta: c.ObjectMeta, Spec: c.Spec, Status: &c.Status}
+	if opts.redact != nil {
+		redactPodSpec(&doc.Spec.JobTemplate.Spec.Template.Spec, opts.redact)
+		redactObjectMeta(&doc.ObjectMeta)
+	}
+	if !opts.raw {
+		cleanObjectMeta(&doc.ObjectMeta)
+		if !opts.keepStatus {
+			doc.Status = nil
+		}
+	}
+	return doc
+}
+
+// Build the document of the CronWorkflow from a deep copy, so that the
+// cleaning does not mutate the object used elsewhere in the run.
+func newCronWorkflowDocument(cronworkflow *wfv1alpha1.CronWorkflow, opts documentOptions) cronWorkflowDocument {
+	c := cronworkflow.DeepCopy()
+	doc := cronWorkflowDocument{TypeMeta: c.TypeMeta, ObjectMeta: c.ObjectMeta, Spec: c.Spec, Status: &c.Status}
+	if opts.redact != nil {
+		redactWorkflowSpec(&doc.Spec.WorkflowSpec, opts.redact)
+		redactObjectMeta(&doc.ObjectMeta)
+	}
+	if !opts.raw {
+		cleanObjectMeta(&doc.ObjectMeta)
+		if !opts.keepStatus {
+			doc.Status = nil
+		}
+	}
+	return doc
+}
+
+// Strip the server-populated metadata fields.
+func cleanObjectMeta(meta *metav1.ObjectMeta) {
+	meta.ManagedFields = nil
+	meta.ResourceVersion = ""
+	meta.UID = ""
+	meta.Generation = 0
+	meta.SelfLink = ""
+}
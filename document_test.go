@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getNoisyItems() []item {
+	managedFields := []metav1.ManagedFieldsEntry{{Manager: "kubectl", Operation: metav1.ManagedFieldsOperationApply}}
+	lastScheduleTime := metav1.NewTime(getTime("2023-01-23T00:00:00Z"))
+
+	cronjob := getCronJob("ns-a", "cj", "0 1 * * *", false)
+	cronjob.ManagedFields = managedFields
+	cronjob.UID = "0b9f2c5e-cronjob"
+	cronjob.ResourceVersion = "123"
+	cronjob.Generation = 2
+	cronjob.Status = batchv1.CronJobStatus{LastScheduleTime: &lastScheduleTime}
+
+	cronworkflow := getCronWorkflow("ns-a", "cwf", "0 2 * * *", false)
+	cronworkflow.ManagedFields = managedFields
+	cronworkflow.UID = "0b9f2c5e-cronworkflow"
+	cronworkflow.ResourceVersion = "456"
+	cronworkflow.Generation = 3
+	cronworkflow.Status = wfv1alpha1.CronWorkflowStatus{LastScheduledTime: &lastScheduleTime}
+
+	return mergeItems([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{cronworkflow})
+}
+
+func Test_printJSON_document(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		opts   documentOptions
+		golden string
+	}{
+		{
+			name:   "cleaned by default",
+			opts:   documentOptions{},
+			golden: "document.clean.golden.json",
+		},
+		{
+			name:   "keep status",
+			opts:   documentOptions{keepStatus: true},
+			golden: "document.status.golden.json",
+		},
+		{
+			name:   "raw",
+			opts:   documentOptions{raw: true},
+			golden: "document.raw.golden.json",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got bytes.Buffer
+			if err := printJSON(&got, getNoisyItems(), tt.opts); err != nil {
+				t.Fatalf("printJSON() error = %v", err)
+			}
+			assertGolden(t, tt.golden, got.Bytes())
+		})
+	}
+}
+
+func Test_buildPrintformat_noMutation(t *testing.T) {
+	t.Parallel()
+	items := getNoisyItems()
+	want := getNoisyItems()
+
+	buildPrintformat(items, documentOptions{})
+
+	for i := range items {
+		if diff := cmp.Diff(want[i].object(), items[i].object()); diff != "" {
+			t.Errorf("buildPrintformat() mutated the source object (-want +got):\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/pflag"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+)
+
+// The categories of a drift.
+const (
+	driftChanged    = "changed"
+	driftUnmanaged  = "unmanaged"
+	driftNotApplied = "not-applied"
+)
+
+type drift struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Drift     string `json:"drift"`
+	// The schedules with the 'CRON_TZ=' prefix when the time zone is set, or
+	// "" when the side doesn't have the item.
+	LiveSchedule     string `json:"liveSchedule,omitempty"`
+	ManifestSchedule string `json:"manifestSchedule,omitempty"`
+}
+
+type driftReport struct {
+	ApiVersion string  `json:"apiVersion"`
+	Drifts     []drift `json:"drifts"`
+}
+
+func runDrift(stdout, stderr io.Writer, args []string) error {
+	// Parse flags
+	// -----------------
+	var (
+		fromFlag      string
+		toFlag        string
+		filenameFlag  []string
+		recursiveFlag bool
+		selectorFlag  string
+		outputFlag    string
+		noHeadersFlag bool
+		inClusterFlag bool
+	)
+	fsets := pflag.NewFlagSet(commandName+" drift", pflag.ContinueOnError)
+	fsets.SetOutput(stderr)
+	fsets.StringVarP(&fromFlag, "from", "", "", "Start time of the period in RFC3339 format.")
+	fsets.StringVarP(&toFlag, "to", "", "", "End time of the period in RFC3339 format.")
+	fsets.StringArrayVarP(&filenameFlag, "filename", "f", nil, "The manifest files or directories to compare with the cluster.")
+	fsets.BoolVarP(&recursiveFlag, "recursive", "R", false, "If present, read the directories of '--filename' recursively.")
+	fsets.StringVarP(&selectorFlag, "selector", "l", "", "Selector (label query) to filter on both sides.")
+	fsets.StringVarP(&outputFlag, "output", "o", "", "Output format. One of: json|yaml.")
+	fsets.BoolVarP(&noHeadersFlag, "no-headers", "", false, "If present, print output without headers.")
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.AddFlags(fsets)
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
+
+	fsets.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of %s drift:\n", commandName)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example")
+		fmt.Fprintln(stderr, "  $ kubectl-cls drift --from 2023-01-24T00:00:00Z --to 2023-01-24T06:00:00Z -f ./deploy -R")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Flags")
+		fmt.Fprint(stderr, fsets.FlagUsages())
+	}
+
+	if err := fsets.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+
+	// Validation
+	// -----------------
+	if fromFlag == "" {
+		return errors.New("please set --from flag")
+	}
+	from, err := time.Parse(time.RFC3339, fromFlag)
+	if err != nil {
+		return fmt.Errorf("failed to parse '--from' value: %w", err)
+	}
+	from = from.UTC() // Convert to UTC for easy comparison with the schedule.
+	if toFlag == "" {
+		return errors.New("please set --to flag")
+	}
+	to, err := time.Parse(time.RFC3339, toFlag)
+	if err != nil {
+		return fmt.Errorf("failed to parse '--to' value: %w", err)
+	}
+	to = to.UTC() // Convert to UTC for easy comparison with the schedule.
+	if from.After(to) {
+		return errors.New("'--from' '--to' times are reversed")
+	}
+	if len(filenameFlag) == 0 {
+		return errors.New("please set --filename flag")
+	}
+	if outputFlag != "" && outputFlag != "json" && outputFlag != "yaml" {
+		return fmt.Errorf("%s is unsupported output format", outputFlag)
+	}
+	selector, err := parseSelector("selector", selectorFlag)
+	if err != nil {
+		return err
+	}
+
+	// Manifests without a namespace are applied to the namespace of the
+	// context, like kubectl apply.
+	namespace, err := getDefaultNamespace(cfgFlags, podEnv)
+	if err != nil {
+		return err
+	}
+	// Only compare the namespace of '-n' on both sides, otherwise all namespaces.
+	listNamespace := ""
+	if cfgFlags.Namespace != nil {
+		listNamespace = *cfgFlags.Namespace
+	}
+
+	// Compare
+	// -----------------
+	manifests, err := loadManifests(filenameFlag, recursiveFlag, namespace)
+	if err != nil {
+		return err
+	}
+	manifests = filterManifests(manifests, listNamespace, selector)
+
+	c, err := newClients(cfgFlags)
+	if err != nil {
+		return err
+	}
+	live, err := listItems(context.Background(), c, listNamespace, selectorFlag)
+	if err != nil {
+		return err
+	}
+
+	drifts, err := findDrifts(live, manifests, from, to)
+	if err != nil {
+		return err
+	}
+	return printDrifts(stdout, outputFlag, noHeadersFlag, drifts)
+}
+
+// List all the CronJobs and CronWorkflows regardless of the period. An empty
+// namespace means all namespaces.
+func listItems(ctx context.Context, c *clients, namespace, selector string) ([]item, error) {
+	start := time.Now()
+	cronjobs, err := listCronJobs(ctx, c, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CronJobs in '%s' namespace: %w", displayNamespace(namespace), err)
+	}
+	c.timings.record(timingList, "cronjobs", namespace, time.Since(start))
+	start = time.Now()
+	cronworkflows, err := listCronWorkflows(ctx, c, namespace, selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CronWorkflow in '%s' namespace: %w", displayNamespace(namespace), err)
+	}
+	c.timings.record(timingList, "cronworkflows", namespace, time.Since(start))
+	return mergeItems(cronjobs, cronworkflows), nil
+}
+
+// Load the CronJobs and CronWorkflows in the manifest files. The directories
+// are read for '.yaml', '.yml', and '.json' files, recursively when recursive.
+// The other kinds in the files are ignored, and a manifest without a namespace
+// is put in namespace.
+func loadManifests(paths []string, recursive bool, namespace string) ([]item, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifests: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != path && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			switch filepath.Ext(p) {
+			case ".yaml", ".yml", ".json":
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifests in '%s': %w", path, err)
+		}
+	}
+
+	cronjobs := []batchv1.CronJob{}
+	cronworkflows := []wfv1alpha1.CronWorkflow{}
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifests: %w", err)
+		}
+		reader := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+		for {
+			doc, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read manifests in '%s': %w", file, err)
+			}
+			var typeMeta metav1.TypeMeta
+			if err := yaml.Unmarshal(doc, &typeMeta); err != nil {
+				return nil, fmt.Errorf("failed to decode a manifest in '%s': %w", file, err)
+			}
+			switch {
+			case typeMeta.APIVersion == "batch/v1" && typeMeta.Kind == "CronJob":
+				var cronjob batchv1.CronJob
+				if err := yaml.Unmarshal(doc, &cronjob); err != nil {
+					return nil, fmt.Errorf("failed to decode a CronJob in '%s': %w", file, err)
+				}
+				if cronjob.Namespace == "" {
+					cronjob.Namespace = namespace
+				}
+				cronjobs = append(cronjobs, cronjob)
+			case typeMeta.APIVersion == "argoproj.io/v1alpha1" && typeMeta.Kind == "CronWorkflow":
+				var cronworkflow wfv1alpha1.CronWorkflow
+				if err := yaml.Unmarshal(doc, &cronworkflow); err != nil {
+					return nil, fmt.Errorf("failed to decode a CronWorkflow in '%s': %w", file, err)
+				}
+				if cronworkflow.Namespace == "" {
+					cronworkflow.Namespace = namespace
+				}
+				cronworkflows = append(cronworkflows, cronworkflow)
+			}
+		}
+	}
+	return mergeItems(cronjobs, cronworkflows), nil
+}
+
+// Keep the manifests in the namespace and matching the selector, as the live
+// items are listed. An empty namespace means all namespaces.
+func filterManifests(items []item, namespace string, selector labels.Selector) []item {
+	ret := []item{}
+	for _, item := range items {
+		obj := item.object()
+		if namespace != "" && obj.GetNamespace() != namespace {
+			continue
+		}
+		if !selector.Matches(labels.Set(obj.GetLabels())) {
+			continue
+		}
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+// Format the schedule of the item for comparison, with the time zone.
+func formatDriftSchedule(item item) string {
+	schedule := strings.Join(strings.Fields(item.schedule()), " ")
+	if tz := item.timezone(); tz != "" {
+		return "CRON_TZ=" + tz + " " + schedule
+	}
+	return schedule
+}
+
+// Compare the live items and the manifests by kind, namespace, and name. Only
+// the items executed during the from-to period on either side are compared,
+// and the items with the same schedule on both sides are not reported.
+func findDrifts(live, manifests []item, from, to time.Time) ([]drift, error) {
+	type key struct{ kind, namespace, name string }
+	type pair struct{ live, manifest *item }
+	pairs := map[key]*pair{}
+	get := func(item item) *pair {
+		obj := item.object()
+		k := key{kind: item.kind(), namespace: obj.GetNamespace(), name: obj.GetName()}
+		if pairs[k] == nil {
+			pairs[k] = &pair{}
+		}
+		return pairs[k]
+	}
+	for i := range live {
+		get(live[i]).live = &live[i]
+	}
+	for i := range manifests {
+		get(manifests[i]).manifest = &manifests[i]
+	}
+
+	included := func(item *item) (bool, error) {
+		if item == nil {
+			return false, nil
+		}
+		sched, err := cron.ParseStandard(item.schedule())
+		if err != nil {
+			obj := item.object()
+			return false, fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", item.schedule(), item.kind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		return isInclude(sched, from, to, boundaries{}), nil
+	}
+
+	drifts := []drift{}
+	for k, p := range pairs {
+		liveIncluded, err := included(p.live)
+		if err != nil {
+			return nil, err
+		}
+		manifestIncluded, err := included(p.manifest)
+		if err != nil {
+			return nil, err
+		}
+		if !liveIncluded && !manifestIncluded {
+			continue
+		}
+
+		d := drift{Kind: k.kind, Namespace: k.namespace, Name: k.name}
+		if p.live != nil {
+			d.LiveSchedule = formatDriftSchedule(*p.live)
+		}
+		if p.manifest != nil {
+			d.ManifestSchedule = formatDriftSchedule(*p.manifest)
+		}
+		switch {
+		case p.manifest == nil:
+			d.Drift = driftUnmanaged
+		case p.live == nil:
+			d.Drift = driftNotApplied
+		case d.LiveSchedule != d.ManifestSchedule:
+			d.Drift = driftChanged
+		default:
+			continue
+		}
+		drifts = append(drifts, d)
+	}
+
+	// sort
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Namespace != drifts[j].Namespace {
+			return drifts[i].Namespace < drifts[j].Namespace
+		}
+		if drifts[i].Name != drifts[j].Name {
+			return drifts[i].Name < drifts[j].Name
+		}
+		return drifts[i].Kind < drifts[j].Kind
+	})
+	return drifts, nil
+}
+
+func printDrifts(stdout io.Writer, output string, noHeaders bool, drifts []drift) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(driftReport{ApiVersion: "v1", Drifts: drifts}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(driftReport{ApiVersion: "v1", Drifts: drifts})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	orNone := func(s string) string {
+		if s == "" {
+			return "<none>"
+		}
+		return s
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Kind", "Drift", "Live", "Manifest"}, "\t"))
+	}
+	for _, d := range drifts {
+		fmt.Fprintln(tw, strings.Join([]string{d.Namespace, d.Name, d.Kind, d.Drift, orNone(d.LiveSchedule), orNone(d.ManifestSchedule)}, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newDriftFakeClients() *clients {
+	backup := getCronJob("default", "backup", "0 2 * * *", false)
+	cleanup := getCronJob("default", "cleanup", "0 3 * * *", false)
+	tzJob := getCronJob("default", "tz-job", "0 5 * * *", false)
+	nightly := getCronJob("default", "nightly", "0 22 * * *", false)
+	moved := getCronJob("default", "moved", "0 20 * * *", false)
+	orphanOld := getCronJob("ns-b", "orphan-old", "0 23 * * *", false)
+	report := getCronWorkflow("default", "report", "0 2 * * *", false)
+	orphan := getCronWorkflow("ns-b", "orphan", "0 0 * * *", false)
+	return &clients{
+		k8s:  k8sfake.NewSimpleClientset(&backup, &cleanup, &tzJob, &nightly, &moved, &orphanOld),
+		argo: argofake.NewSimpleClientset(&report, &orphan).ArgoprojV1alpha1(),
+	}
+}
+
+func Test_loadManifests(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		recursive bool
+		want      []string
+	}{
+		{
+			name: "top-level only",
+			want: []string{"CronJob default/backup", "CronJob default/cleanup", "CronJob default/moved", "CronJob default/nightly", "CronJob default/tz-job", "CronJob ns-a/new-job"},
+		},
+		{
+			name:      "recursive",
+			recursive: true,
+			want:      []string{"CronJob default/backup", "CronJob default/cleanup", "CronJob default/moved", "CronJob default/nightly", "CronWorkflow default/report", "CronJob default/tz-job", "CronJob ns-a/new-job"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			items, err := loadManifests([]string{"testdata/drift"}, tt.recursive, "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, item := range items {
+				got = append(got, item.kind()+" "+item.object().GetNamespace()+"/"+item.object().GetName())
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_findDrifts(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	manifests, err := loadManifests([]string{"testdata/drift"}, true, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	live, err := listItems(context.Background(), newDriftFakeClients(), "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := findDrifts(live, manifests, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []drift{
+		{Kind: "CronJob", Namespace: "default", Name: "backup", Drift: driftChanged, LiveSchedule: "0 2 * * *", ManifestSchedule: "0 1 * * *"},
+		{Kind: "CronJob", Namespace: "default", Name: "moved", Drift: driftChanged, LiveSchedule: "0 20 * * *", ManifestSchedule: "0 2 * * *"},
+		{Kind: "CronWorkflow", Namespace: "default", Name: "report", Drift: driftChanged, LiveSchedule: "0 2 * * *", ManifestSchedule: "0 1 * * *"},
+		{Kind: "CronJob", Namespace: "default", Name: "tz-job", Drift: driftChanged, LiveSchedule: "0 5 * * *", ManifestSchedule: "CRON_TZ=Asia/Tokyo 0 5 * * *"},
+		{Kind: "CronJob", Namespace: "ns-a", Name: "new-job", Drift: driftNotApplied, ManifestSchedule: "0 4 * * *"},
+		{Kind: "CronWorkflow", Namespace: "ns-b", Name: "orphan", Drift: driftUnmanaged, LiveSchedule: "0 0 * * *"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_filterManifests(t *testing.T) {
+	t.Parallel()
+	labeled := getCronJob("ns-a", "labeled", "0 0 * * *", false)
+	labeled.Labels = map[string]string{"team": "a"}
+	items := mergeItems(
+		[]batchv1.CronJob{labeled},
+		[]wfv1alpha1.CronWorkflow{getCronWorkflow("ns-b", "unlabeled", "0 0 * * *", false)},
+	)
+
+	selector, err := labels.Parse("team=a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := filterManifests(items, "", selector); len(got) != 1 || got[0].object().GetName() != "labeled" {
+		t.Errorf("want only 'labeled' by the selector, got %v", got)
+	}
+	if got := filterManifests(items, "ns-b", labels.Everything()); len(got) != 1 || got[0].object().GetName() != "unlabeled" {
+		t.Errorf("want only 'unlabeled' in 'ns-b', got %v", got)
+	}
+}
+
+func Test_printDrifts(t *testing.T) {
+	t.Parallel()
+	drifts := []drift{
+		{Kind: "CronJob", Namespace: "default", Name: "backup", Drift: driftChanged, LiveSchedule: "0 2 * * *", ManifestSchedule: "0 1 * * *"},
+		{Kind: "CronJob", Namespace: "ns-a", Name: "new-job", Drift: driftNotApplied, ManifestSchedule: "0 4 * * *"},
+	}
+	var got bytes.Buffer
+	if err := printDrifts(&got, "", false, drifts); err != nil {
+		t.Fatal(err)
+	}
+	want := `Namespace   Name      Kind      Drift         Live        Manifest
+default     backup    CronJob   changed       0 2 * * *   0 1 * * *
+ns-a        new-job   CronJob   not-applied   <none>      0 4 * * *
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
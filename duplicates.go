@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	duplicateKeyScheduleImage = "schedule+image"
+	duplicateKeyScheduleName  = "schedule+name"
+)
+
+type duplicateMember struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// duplicateGroup is the items sharing the same similarity key.
+type duplicateGroup struct {
+	Key   string            `json:"key"`
+	Items []duplicateMember `json:"items"`
+}
+
+type duplicateReport struct {
+	ApiVersion string           `json:"apiVersion"`
+	KeyType    string           `json:"keyType"`
+	Duplicates []duplicateGroup `json:"duplicates"`
+}
+
+// Get the image of the first container, not counting init containers. For
+// CronWorkflows, the first container of the templates in the inline workflow
+// spec. Empty when there is none.
+func getFirstImage(item item) string {
+	if item.cronJob != nil {
+		containers := item.cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers
+		if len(containers) == 0 {
+			return ""
+		}
+		return containers[0].Image
+	}
+	for _, tmpl := range item.cronWorkflow.Spec.WorkflowSpec.Templates {
+		switch {
+		case tmpl.Container != nil:
+			return tmpl.Container.Image
+		case tmpl.Script != nil:
+			return tmpl.Script.Image
+		case tmpl.ContainerSet != nil && len(tmpl.ContainerSet.Containers) > 0:
+			return tmpl.ContainerSet.Containers[0].Image
+		}
+	}
+	return ""
+}
+
+// Get the similarity key of the item, or "" when the item has nothing to be
+// compared by. The schedule is compared by its fields, so the spacing doesn't
+// matter.
+func getDuplicateKey(item item, keyType string) string {
+	schedule := strings.Join(strings.Fields(item.schedule()), " ")
+	switch keyType {
+	case duplicateKeyScheduleImage:
+		image := getFirstImage(item)
+		if image == "" {
+			return ""
+		}
+		return schedule + " " + image
+	case duplicateKeyScheduleName:
+		return schedule + " " + item.object().GetName()
+	}
+	return ""
+}
+
+// Group the items by the similarity key and keep the groups with more than one
+// member, sorted by the key. The members keep the order of the items.
+func findDuplicates(items []item, keyType string) []duplicateGroup {
+	members := map[string][]duplicateMember{}
+	for _, item := range items {
+		key := getDuplicateKey(item, keyType)
+		if key == "" {
+			continue
+		}
+		obj := item.object()
+		members[key] = append(members[key], duplicateMember{Kind: item.kind(), Namespace: obj.GetNamespace(), Name: obj.GetName()})
+	}
+
+	groups := []duplicateGroup{}
+	for key, m := range members {
+		if len(m) > 1 {
+			groups = append(groups, duplicateGroup{Key: key, Items: m})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+func printDuplicates(stdout io.Writer, output string, noHeaders bool, keyType string, groups []duplicateGroup) error {
+	report := duplicateReport{ApiVersion: "v1", KeyType: keyType, Duplicates: groups}
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Group", "Namespace", "Name", "Kind", "Key"}, "\t"))
+	}
+	for i, g := range groups {
+		for _, m := range g.Items {
+			fmt.Fprintln(tw, strings.Join([]string{fmt.Sprint(i + 1), m.Namespace, m.Name, m.Kind, g.Key}, "\t"))
+		}
+	}
+	return tw.Flush()
+}
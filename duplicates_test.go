@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func getDuplicateFixtures() []item {
+	withImage := func(cronjob batchv1.CronJob, images ...string) batchv1.CronJob {
+		for _, image := range images {
+			cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers = append(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers, corev1.Container{Image: image})
+		}
+		return cronjob
+	}
+
+	// True duplicates, deployed by two teams.
+	teamA := withImage(getCronJob("team-a", "settle", "0 1 * * *", false), "ghcr.io/example/settle:v1")
+	teamB := withImage(getCronJob("team-b", "settle-payments", "0  1 * * *", false), "ghcr.io/example/settle:v1", "sidecar:v1")
+	// Near-misses: another tag, another schedule, and the same image only as
+	// an init container.
+	otherTag := withImage(getCronJob("team-c", "settle", "0 1 * * *", false), "ghcr.io/example/settle:v2")
+	otherSchedule := withImage(getCronJob("team-d", "settle", "0 2 * * *", false), "ghcr.io/example/settle:v1")
+	initOnly := getCronJob("team-e", "settle", "0 1 * * *", false)
+	initOnly.Spec.JobTemplate.Spec.Template.Spec.InitContainers = []corev1.Container{{Image: "ghcr.io/example/settle:v1"}}
+	initOnly.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Image: "busybox"}}
+	// Without any container, never grouped by image.
+	noImage := getCronJob("team-f", "settle", "0 1 * * *", false)
+
+	// Cross-kind duplicate of teamA.
+	cw := getCronWorkflow("team-g", "settle", "0 1 * * *", false)
+	cw.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{{Name: "main", Container: &corev1.Container{Image: "ghcr.io/example/settle:v1"}}}
+
+	return mergeItems(
+		[]batchv1.CronJob{teamA, teamB, otherTag, otherSchedule, initOnly, noImage},
+		[]wfv1alpha1.CronWorkflow{cw},
+	)
+}
+
+func Test_findDuplicates(t *testing.T) {
+	t.Parallel()
+	items := getDuplicateFixtures()
+	tests := []struct {
+		keyType string
+		want    []duplicateGroup
+	}{
+		{
+			keyType: duplicateKeyScheduleImage,
+			want: []duplicateGroup{
+				{
+					Key: "0 1 * * * ghcr.io/example/settle:v1",
+					Items: []duplicateMember{
+						{Kind: "CronJob", Namespace: "team-a", Name: "settle"},
+						{Kind: "CronJob", Namespace: "team-b", Name: "settle-payments"},
+						{Kind: "CronWorkflow", Namespace: "team-g", Name: "settle"},
+					},
+				},
+			},
+		},
+		{
+			keyType: duplicateKeyScheduleName,
+			want: []duplicateGroup{
+				{
+					Key: "0 1 * * * settle",
+					Items: []duplicateMember{
+						{Kind: "CronJob", Namespace: "team-a", Name: "settle"},
+						{Kind: "CronJob", Namespace: "team-c", Name: "settle"},
+						{Kind: "CronJob", Namespace: "team-e", Name: "settle"},
+						{Kind: "CronJob", Namespace: "team-f", Name: "settle"},
+						{Kind: "CronWorkflow", Namespace: "team-g", Name: "settle"},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.keyType, func(t *testing.T) {
+			t.Parallel()
+			got := findDuplicates(items, tt.keyType)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("findDuplicates() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_findDuplicates_none(t *testing.T) {
+	t.Parallel()
+	items := mergeItems([]batchv1.CronJob{getCronJob("ns-a", "n-1", "0 1 * * *", false), getCronJob("ns-b", "n-2", "0 1 * * *", false)}, nil)
+	if got := findDuplicates(items, duplicateKeyScheduleName); len(got) != 0 {
+		t.Errorf("findDuplicates() = %v, want no groups", got)
+	}
+}
+
+func Test_printDuplicates(t *testing.T) {
+	t.Parallel()
+	var got bytes.Buffer
+	groups := findDuplicates(getDuplicateFixtures(), duplicateKeyScheduleImage)
+	if err := printDuplicates(&got, "", false, duplicateKeyScheduleImage, groups); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"Group   Namespace   Name              Kind           Key\n" +
+		"1       team-a      settle            CronJob        0 1 * * * ghcr.io/example/settle:v1\n" +
+		"1       team-b      settle-payments   CronJob        0 1 * * * ghcr.io/example/settle:v1\n" +
+		"1       team-g      settle            CronWorkflow   0 1 * * * ghcr.io/example/settle:v1\n"
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("printDuplicates() mismatch (-want +got):\n%s", diff)
+	}
+}
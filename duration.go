@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Parse a duration either in the Go syntax or as an ISO 8601 duration:
+//
+//   - A value starting with 'P', after an optional sign, is an ISO 8601
+//     duration such as 'PT2H30M' or 'P1D'. The years and months are rejected,
+//     as they have no fixed length.
+//   - Any other value is in the Go syntax such as '2h30m', which may start
+//     with days such as '1d' or '1d12h'.
+//
+// A day is always 24h in both syntaxes, so 'P1D', '1d' and '24h' are the same,
+// regardless of the daylight saving time.
+func parseDuration(value string) (time.Duration, error) {
+	unsigned := strings.TrimLeft(value, "+-")
+	if strings.HasPrefix(unsigned, "P") {
+		d, err := parseISODuration(value)
+		if err != nil {
+			return 0, err
+		}
+		if d.years != 0 || d.months != 0 {
+			return 0, fmt.Errorf("years and months have no fixed length: %s", value)
+		}
+		ret := time.Duration(d.days)*24*time.Hour + d.clock
+		if d.negative {
+			ret = -ret
+		}
+		return ret, nil
+	}
+
+	// The days are only accepted first, as in '1d12h'.
+	sign, rest := "", value
+	if strings.HasPrefix(rest, "-") || strings.HasPrefix(rest, "+") {
+		sign, rest = rest[:1], rest[1:]
+	}
+	i := strings.IndexByte(rest, 'd')
+	if i < 0 {
+		return time.ParseDuration(value)
+	}
+	days, err := strconv.ParseFloat(rest[:i], 64)
+	if err != nil || rest[:i] == "" || strings.ContainsAny(rest[:i], "eE+-") {
+		return 0, fmt.Errorf("invalid duration %q: the days must be a number at the start", value)
+	}
+	ret := time.Duration(days * float64(24*time.Hour))
+	if clock := rest[i+1:]; clock != "" {
+		d, err := time.ParseDuration(clock)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", value, errors.Unwrap(err))
+		}
+		if strings.HasPrefix(clock, "-") || strings.HasPrefix(clock, "+") {
+			return 0, fmt.Errorf("invalid duration %q: the sign must be at the start", value)
+		}
+		ret += d
+	}
+	if sign == "-" {
+		ret = -ret
+	}
+	return ret, nil
+}
+
+// durationValue is a pflag.Value of parseDuration.
+type durationValue time.Duration
+
+func (d *durationValue) Set(s string) error {
+	v, err := parseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = durationValue(v)
+	return nil
+}
+
+func (d *durationValue) String() string { return time.Duration(*d).String() }
+
+func (d *durationValue) Type() string { return "duration" }
+
+// durationVarP is pflag.FlagSet.DurationVarP accepting the syntaxes of
+// parseDuration.
+func durationVarP(fsets *pflag.FlagSet, p *time.Duration, name, shorthand string, value time.Duration, usage string) {
+	*p = value
+	fsets.VarP((*durationValue)(p), name, shorthand, usage)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/pflag"
+)
+
+func Test_parseDuration(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "go syntax", value: "2h30m", want: 2*time.Hour + 30*time.Minute},
+		{name: "go syntax negative", value: "-30m", want: -30 * time.Minute},
+		{name: "days", value: "1d", want: 24 * time.Hour},
+		{name: "fractional days", value: "1.5d", want: 36 * time.Hour},
+		{name: "days and clock", value: "1d12h", want: 36 * time.Hour},
+		{name: "negative days", value: "-1d12h", want: -36 * time.Hour},
+		{name: "iso clock", value: "PT2H30M", want: 2*time.Hour + 30*time.Minute},
+		{name: "iso days", value: "P1D", want: 24 * time.Hour},
+		{name: "iso weeks", value: "P1W", want: 7 * 24 * time.Hour},
+		{name: "iso negative", value: "-PT2H", want: -2 * time.Hour},
+		{name: "iso comma", value: "PT0,5S", want: 500 * time.Millisecond},
+		{name: "iso months", value: "P1M", wantErr: true},
+		{name: "iso years", value: "P1Y", wantErr: true},
+		{name: "iso empty", value: "P", wantErr: true},
+		{name: "iso empty clock", value: "PT", wantErr: true},
+		{name: "sign after days", value: "1d-2h", wantErr: true},
+		{name: "days without number", value: "d", wantErr: true},
+		{name: "days not first", value: "12h1d", wantErr: true},
+		{name: "unknown unit", value: "1w", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseDuration(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parseDuration() value is mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_durationVarP(t *testing.T) {
+	t.Parallel()
+	var d time.Duration
+	fsets := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	durationVarP(fsets, &d, "window", "", time.Hour, "")
+	if diff := cmp.Diff(time.Hour, d); diff != "" {
+		t.Errorf("default value is mismatch (-want +got):\n%s", diff)
+	}
+	if err := fsets.Parse([]string{"--window", "P1DT6H"}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(30*time.Hour, d); diff != "" {
+		t.Errorf("parsed value is mismatch (-want +got):\n%s", diff)
+	}
+	if err := fsets.Parse([]string{"--window", "P1M"}); err == nil {
+		t.Error("expected an error for months")
+	}
+}
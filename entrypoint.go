@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// templateKey identifies the template referenced by workflowTemplateRef.
+// The namespace is empty for a ClusterWorkflowTemplate.
+type templateKey struct {
+	namespace string
+	name      string
+}
+
+func getTemplateKey(cronworkflow *wfv1alpha1.CronWorkflow) (templateKey, bool) {
+	ref := cronworkflow.Spec.WorkflowSpec.WorkflowTemplateRef
+	if ref == nil {
+		return templateKey{}, false
+	}
+	if ref.ClusterScope {
+		return templateKey{name: ref.Name}, true
+	}
+	return templateKey{namespace: cronworkflow.Namespace, name: ref.Name}, true
+}
+
+// Get the entrypoints of the templates referenced by the CronWorkflows without
+// their own entrypoint. Each template is got once. A template which is not
+// found is left out, so its CronWorkflows are left unresolved.
+func resolveTemplateEntrypoints(ctx context.Context, c *clients, items []item) (map[templateKey]string, error) {
+	entrypoints := map[templateKey]string{}
+	seen := map[templateKey]bool{}
+	for _, item := range items {
+		if item.cronWorkflow == nil || item.cronWorkflow.Spec.WorkflowSpec.Entrypoint != "" {
+			continue
+		}
+		key, ok := getTemplateKey(item.cronWorkflow)
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var spec wfv1alpha1.WorkflowSpec
+		if key.namespace == "" {
+			tmpl, err := c.argo.ClusterWorkflowTemplates().Get(ctx, key.name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get ClusterWorkflowTemplate '%s': %w", key.name, err)
+			}
+			spec = tmpl.Spec
+		} else {
+			tmpl, err := c.argo.WorkflowTemplates(key.namespace).Get(ctx, key.name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to get WorkflowTemplate '%s/%s': %w", key.namespace, key.name, err)
+			}
+			spec = tmpl.Spec
+		}
+		entrypoints[key] = spec.Entrypoint
+	}
+	return entrypoints, nil
+}
+
+// Keep only CronWorkflows whose entrypoint is name. The entrypoint of the
+// workflow spec wins, as it overrides the one of workflowTemplateRef. When
+// there is none, the entrypoint of the referenced template is looked up in
+// resolved. CronJobs have no entrypoint and are never kept.
+func entrypointFilter(name string, resolved map[templateKey]string) filter {
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return false
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			if entrypoint := cronworkflow.Spec.WorkflowSpec.Entrypoint; entrypoint != "" {
+				return entrypoint == name
+			}
+			key, ok := getTemplateKey(cronworkflow)
+			if !ok {
+				return false
+			}
+			entrypoint, ok := resolved[key]
+			return ok && entrypoint == name
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func Test_entrypointFilter(t *testing.T) {
+	t.Parallel()
+	inline := getCronWorkflow("ns-a", "inline", "0 0 * * *", false)
+	inline.Spec.WorkflowSpec.Entrypoint = "reindex"
+	backup := getCronWorkflow("ns-a", "backup", "0 0 * * *", false)
+	backup.Spec.WorkflowSpec.Entrypoint = "backup"
+	templateRef := getCronWorkflow("ns-a", "template-ref", "0 0 * * *", false)
+	templateRef.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "reindex-tmpl"}
+	clusterTemplateRef := getCronWorkflow("ns-b", "cluster-template-ref", "0 0 * * *", false)
+	clusterTemplateRef.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "shared", ClusterScope: true}
+	// The entrypoint of the workflow spec overrides the template's one.
+	overridden := getCronWorkflow("ns-a", "overridden", "0 0 * * *", false)
+	overridden.Spec.WorkflowSpec.Entrypoint = "backup"
+	overridden.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "reindex-tmpl"}
+	missing := getCronWorkflow("ns-a", "missing", "0 0 * * *", false)
+	missing.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "deleted"}
+
+	items := mergeItems(
+		[]batchv1.CronJob{getCronJob("ns-a", "cronjob", "0 0 * * *", false)},
+		[]wfv1alpha1.CronWorkflow{inline, backup, templateRef, clusterTemplateRef, overridden, missing},
+	)
+
+	argoClient := argofake.NewSimpleClientset(
+		&wfv1alpha1.WorkflowTemplate{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "reindex-tmpl"},
+			Spec:       wfv1alpha1.WorkflowSpec{Entrypoint: "reindex"},
+		},
+		&wfv1alpha1.ClusterWorkflowTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "shared"},
+			Spec:       wfv1alpha1.WorkflowSpec{Entrypoint: "reindex"},
+		},
+	)
+	c := &clients{k8s: k8sfake.NewSimpleClientset(), argo: argoClient.ArgoprojV1alpha1()}
+	resolved, err := resolveTemplateEntrypoints(context.Background(), c, items)
+	if err != nil {
+		t.Fatalf("resolveTemplateEntrypoints() error = %v", err)
+	}
+
+	// One Get per referenced template, only for the CronWorkflows without
+	// their own entrypoint.
+	gets := 0
+	for _, action := range argoClient.Actions() {
+		if action.GetVerb() == "get" {
+			gets++
+		}
+	}
+	if gets != 3 {
+		t.Errorf("got templates %d times, want 3", gets)
+	}
+
+	tests := []struct {
+		name     string
+		resolved map[templateKey]string
+		want     []string
+	}{
+		{
+			name:     "inline only",
+			resolved: map[templateKey]string{},
+			want:     []string{"CronWorkflow/ns-a/inline"},
+		},
+		{
+			name:     "resolve templates",
+			resolved: resolved,
+			want:     []string{"CronWorkflow/ns-a/inline", "CronWorkflow/ns-a/template-ref", "CronWorkflow/ns-b/cluster-template-ref"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters([]filter{entrypointFilter("reindex", tt.resolved)}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_resolveTemplateEntrypoints_error(t *testing.T) {
+	t.Parallel()
+	cronworkflow := getCronWorkflow("ns-a", "template-ref", "0 0 * * *", false)
+	cronworkflow.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "tmpl"}
+
+	argoClient := argofake.NewSimpleClientset()
+	argoClient.PrependReactor("get", "workflowtemplates", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, context.DeadlineExceeded
+	})
+	c := &clients{k8s: k8sfake.NewSimpleClientset(), argo: argoClient.ArgoprojV1alpha1()}
+	if _, err := resolveTemplateEntrypoints(context.Background(), c, []item{{cronWorkflow: &cronworkflow}}); err == nil {
+		t.Error("resolveTemplateEntrypoints() error = nil, want error")
+	}
+}
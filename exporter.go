@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slog"
+)
+
+type exporterOptions struct {
+	listen    string
+	interval  time.Duration
+	window    time.Duration
+	namespace string
+	selector  string
+}
+
+func runExporter(logger *slog.Logger, c *clients, opts exporterOptions) error {
+	if opts.interval <= 0 {
+		return fmt.Errorf("'--exporter-interval' must be greater than zero")
+	}
+	if opts.window <= 0 {
+		return fmt.Errorf("'--exporter-window' must be greater than zero")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	e := newExporter(c, opts)
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	srv := &http.Server{
+		Addr:              opts.listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info(fmt.Sprintf("listening on %s", opts.listen), "address", opts.listen)
+		errCh <- srv.ListenAndServe()
+	}()
+	go e.loop(ctx, logger)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shutdown server: %w", err)
+		}
+		return nil
+	}
+}
+
+// metricKey is the label set of the per-namespace, per-kind gauges.
+type metricKey struct {
+	namespace string
+	kind      string
+}
+
+// exporter evaluates the sliding window (now, now+window) periodically and
+// serves the result of the last evaluation in the Prometheus text format.
+type exporter struct {
+	clients *clients
+	opts    exporterOptions
+	now     func() time.Time
+
+	mu               sync.RWMutex
+	items            map[metricKey]int
+	fires            map[metricKey]int
+	suspended        map[metricKey]int
+	lastSuccess      bool
+	lastTimestamp    time.Time
+	lastDuration     time.Duration
+	evaluationsTotal int
+	errorsTotal      int
+}
+
+func newExporter(c *clients, opts exporterOptions) *exporter {
+	return &exporter{
+		clients:   c,
+		opts:      opts,
+		now:       time.Now,
+		items:     map[metricKey]int{},
+		fires:     map[metricKey]int{},
+		suspended: map[metricKey]int{},
+	}
+}
+
+func (e *exporter) loop(ctx context.Context, logger *slog.Logger) {
+	ticker := time.NewTicker(e.opts.interval)
+	defer ticker.Stop()
+	for {
+		if err := e.evaluate(ctx); err != nil && ctx.Err() == nil {
+			logger.Error(err.Error(), nil)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// evaluate lists the CronJobs and CronWorkflows in the window and replaces the
+// gauges. On failure the previous gauges are kept and only the health metrics
+// are updated.
+func (e *exporter) evaluate(ctx context.Context) error {
+	start := e.now()
+	from := start.UTC()
+	to := from.Add(e.opts.window)
+
+	items := map[metricKey]int{}
+	fires := map[metricKey]int{}
+	suspended := map[metricKey]int{}
+	err := func() error {
+		matched, err := listScheduleIncluded(ctx, e.clients, e.opts.namespace, e.opts.selector, from, to, boundaries{})
+		if err != nil {
+			return err
+		}
+		for _, item := range matched {
+			obj := item.object()
+			key := metricKey{namespace: obj.GetNamespace(), kind: item.kind()}
+			sched, err := cron.ParseStandard(item.schedule())
+			if err != nil {
+				return fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", item.schedule(), item.kind(), obj.GetNamespace(), obj.GetName(), err)
+			}
+			items[key]++
+			fires[key] += countInclude(sched, from, to, boundaries{})
+			if item.suspended() {
+				suspended[key]++
+			}
+		}
+		return nil
+	}()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.evaluationsTotal++
+	e.lastTimestamp = start
+	e.lastDuration = e.now().Sub(start)
+	e.lastSuccess = err == nil
+	if err != nil {
+		e.errorsTotal++
+		return fmt.Errorf("failed to evaluate the exporter window: %w", err)
+	}
+	e.items = items
+	e.fires = fires
+	e.suspended = suspended
+	return nil
+}
+
+func (e *exporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+
+	writeGaugeVec(w, "cls_scheduled_items", "Number of items scheduled to fire in the window.", e.items)
+	writeGaugeVec(w, "cls_scheduled_fires", "Number of scheduled starts in the window.", e.fires)
+	writeGaugeVec(w, "cls_suspended_items", "Number of suspended items scheduled to fire in the window.", e.suspended)
+
+	success := 0
+	if e.lastSuccess {
+		success = 1
+	}
+	lastTimestamp := 0.0
+	if !e.lastTimestamp.IsZero() {
+		lastTimestamp = float64(e.lastTimestamp.UnixNano()) / 1e9
+	}
+	writeMetric(w, "cls_last_evaluation_success", "gauge", "Whether the last evaluation succeeded.", success)
+	writeMetric(w, "cls_last_evaluation_timestamp_seconds", "gauge", "Unix time of the last evaluation.", lastTimestamp)
+	writeMetric(w, "cls_last_evaluation_duration_seconds", "gauge", "Duration of the last evaluation.", e.lastDuration.Seconds())
+	writeMetric(w, "cls_evaluations_total", "counter", "Total number of evaluations.", e.evaluationsTotal)
+	writeMetric(w, "cls_evaluation_errors_total", "counter", "Total number of failed evaluations.", e.errorsTotal)
+}
+
+func writeGaugeVec(w io.Writer, name, help string, values map[metricKey]int) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", name)
+
+	// sort
+	keys := maps.Keys(values)
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
+		}
+		return keys[i].kind < keys[j].kind
+	})
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{namespace=%q,kind=%q} %d\n", name, key.namespace, key.kind, values[key])
+	}
+}
+
+func writeMetric(w io.Writer, name, typ, help string, value any) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+	fmt.Fprintf(w, "%s %v\n", name, value)
+}
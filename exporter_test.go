@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func scrape(t *testing.T, h http.Handler) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	return rec.Body.String()
+}
+
+func Test_exporter(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{
+			getCronJob("ns-a", "n-1", "*/15 * * * *", false), // 00:00, 00:15, 00:30, 00:45, 01:00
+			getCronJob("ns-a", "n-2", "0 * * * *", true),     // 00:00, 01:00
+			getCronJob("ns-a", "n-3", "0 3 * * *", false),    // out of the window
+			getCronJob("ns-b", "n-4", "30 0 * * *", false),   // 00:30
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("ns-a", "n-5", "0 0 * * *", true), // 00:00
+		},
+	)
+	e := newExporter(c, exporterOptions{interval: time.Minute, window: time.Hour})
+	e.now = func() time.Time { return getTime("2023-01-24T00:00:00Z") }
+
+	if err := e.evaluate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	got := scrape(t, e)
+	wants := []string{
+		`cls_scheduled_items{namespace="ns-a",kind="CronJob"} 2`,
+		`cls_scheduled_items{namespace="ns-a",kind="CronWorkflow"} 1`,
+		`cls_scheduled_items{namespace="ns-b",kind="CronJob"} 1`,
+		`cls_scheduled_fires{namespace="ns-a",kind="CronJob"} 7`,
+		`cls_scheduled_fires{namespace="ns-a",kind="CronWorkflow"} 1`,
+		`cls_scheduled_fires{namespace="ns-b",kind="CronJob"} 1`,
+		`cls_suspended_items{namespace="ns-a",kind="CronJob"} 1`,
+		`cls_suspended_items{namespace="ns-a",kind="CronWorkflow"} 1`,
+		"# TYPE cls_scheduled_items gauge",
+		"cls_last_evaluation_success 1",
+		"cls_last_evaluation_timestamp_seconds 1.6745184e+09",
+		"cls_evaluations_total 1",
+		"cls_evaluation_errors_total 0",
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("metrics do not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `cls_suspended_items{namespace="ns-b"`) {
+		t.Errorf("metrics contain unexpected suspended items for ns-b:\n%s", got)
+	}
+}
+
+func Test_exporter_error(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients([]batchv1.CronJob{getCronJob("ns-a", "n-1", "0 0 * * *", false)}, nil)
+	e := newExporter(c, exporterOptions{interval: time.Minute, window: time.Hour})
+	e.now = func() time.Time { return getTime("2023-01-24T00:00:00Z") }
+	if err := e.evaluate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the next List fail. The previous gauges must be kept.
+	fake := c.k8s.(*k8sfake.Clientset)
+	fake.PrependReactor("list", "cronjobs", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("boom")
+	})
+	if err := e.evaluate(context.Background()); err == nil {
+		t.Fatal("evaluate() error = nil, want error")
+	}
+
+	got := scrape(t, e)
+	wants := []string{
+		`cls_scheduled_items{namespace="ns-a",kind="CronJob"} 1`,
+		"cls_last_evaluation_success 0",
+		"cls_evaluations_total 2",
+		"cls_evaluation_errors_total 1",
+	}
+	for _, want := range wants {
+		if !strings.Contains(got, want+"\n") {
+			t.Errorf("metrics do not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func Test_exporter_loop_cancel(t *testing.T) {
+	t.Parallel()
+	e := newExporter(newFakeClients(nil, nil), exporterOptions{interval: time.Hour, window: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		e.loop(ctx, newLogger(&strings.Builder{}, logFormatText, false))
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("loop() did not return after the context was canceled")
+	}
+}
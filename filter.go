@@ -0,0 +1,296 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// filter is a client-side filter applied to the items included in the
+// from-to period. An item is kept when the predicate of its
+// kind returns true.
+type filter struct {
+	cronJob      func(cronjob *batchv1.CronJob) bool
+	cronWorkflow func(cronworkflow *wfv1alpha1.CronWorkflow) bool
+}
+
+// Keep the items that pass all filters.
+func applyFilters(filters []filter, items []item) []item {
+	if len(filters) == 0 {
+		return items
+	}
+
+	kept := []item{}
+	for _, item := range items {
+		if passFilters(filters, item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+func passFilters(filters []filter, item item) bool {
+	for _, f := range filters {
+		if item.cronJob != nil && !f.cronJob(item.cronJob) {
+			return false
+		}
+		if item.cronWorkflow != nil && !f.cronWorkflow(item.cronWorkflow) {
+			return false
+		}
+	}
+	return true
+}
+
+// Keep only items where either history limit is unset.
+var missingHistoryLimitsFilter = filter{
+	cronJob: func(cronjob *batchv1.CronJob) bool {
+		return cronjob.Spec.SuccessfulJobsHistoryLimit == nil || cronjob.Spec.FailedJobsHistoryLimit == nil
+	},
+	cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+		return cronworkflow.Spec.SuccessfulJobsHistoryLimit == nil || cronworkflow.Spec.FailedJobsHistoryLimit == nil
+	},
+}
+
+// Keep only items whose priority class is one of names. An empty name matches
+// items without a priority class.
+func priorityClassFilter(names []string) filter {
+	match := func(name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return match(cronjob.Spec.JobTemplate.Spec.Template.Spec.PriorityClassName)
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return match(cronworkflow.Spec.WorkflowSpec.PodPriorityClassName)
+		},
+	}
+}
+
+// Keep only items whose resource request of name is min or above. See
+// getCronJobRequest and getCronWorkflowRequest for how the request of an item
+// is computed.
+func minRequestFilter(name corev1.ResourceName, min resource.Quantity) filter {
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			request := getCronJobRequest(cronjob, name)
+			return request.Cmp(min) >= 0
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			request := getCronWorkflowRequest(cronworkflow, name)
+			return request.Cmp(min) >= 0
+		},
+	}
+}
+
+// Get the sum of the requests of the containers in the Job template. Init
+// containers are not counted because they don't run alongside the containers.
+func getCronJobRequest(cronjob *batchv1.CronJob, name corev1.ResourceName) resource.Quantity {
+	return sumRequests(cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers, name)
+}
+
+// Get the largest request among the templates of the inline workflow spec.
+// Each template runs as its own pod, so the requests are summed per template.
+// A workflowTemplateRef is not resolved and counts as zero.
+func getCronWorkflowRequest(cronworkflow *wfv1alpha1.CronWorkflow, name corev1.ResourceName) resource.Quantity {
+	max := resource.Quantity{}
+	for _, tmpl := range cronworkflow.Spec.WorkflowSpec.Templates {
+		containers := []corev1.Container{}
+		if tmpl.Container != nil {
+			containers = append(containers, *tmpl.Container)
+		}
+		if tmpl.Script != nil {
+			containers = append(containers, tmpl.Script.Container)
+		}
+		if tmpl.ContainerSet != nil {
+			for _, c := range tmpl.ContainerSet.Containers {
+				containers = append(containers, c.Container)
+			}
+		}
+		if request := sumRequests(containers, name); request.Cmp(max) > 0 {
+			max = request
+		}
+	}
+	return max
+}
+
+// Containers without the request count as zero.
+func sumRequests(containers []corev1.Container, name corev1.ResourceName) resource.Quantity {
+	sum := resource.Quantity{}
+	for _, c := range containers {
+		if q, ok := c.Resources.Requests[name]; ok {
+			sum.Add(q)
+		}
+	}
+	return sum
+}
+
+// annotationRegex matches the value of the annotation key against pattern.
+type annotationRegex struct {
+	key     string
+	pattern *regexp.Regexp
+}
+
+// Parse the '--annotation-regex' values in the 'KEY=PATTERN' form.
+func parseAnnotationRegexes(values []string) ([]annotationRegex, error) {
+	matchers := make([]annotationRegex, 0, len(values))
+	for _, value := range values {
+		key, pattern, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("failed to parse '--annotation-regex %s': must be in the 'KEY=PATTERN' form", value)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse '--annotation-regex %s': %w", value, err)
+		}
+		matchers = append(matchers, annotationRegex{key: key, pattern: re})
+	}
+	return matchers, nil
+}
+
+// Keep only items whose annotations match all matchers. An item without the
+// annotation doesn't match.
+func annotationRegexFilter(matchers []annotationRegex) filter {
+	match := func(annotations map[string]string) bool {
+		for _, m := range matchers {
+			value, ok := annotations[m.key]
+			if !ok || !m.pattern.MatchString(value) {
+				return false
+			}
+		}
+		return true
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return match(cronjob.Annotations)
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return match(cronworkflow.Annotations)
+		},
+	}
+}
+
+// Keep only items owned by kind, and by name unless it is empty. The
+// controller in ownerReferences is matched when there is one, otherwise any of
+// the owners is. The kind is compared case-insensitively.
+func ownedByFilter(kind, name string) filter {
+	matchRef := func(ref metav1.OwnerReference) bool {
+		return strings.EqualFold(ref.Kind, kind) && (name == "" || ref.Name == name)
+	}
+	match := func(obj metav1.Object) bool {
+		if ref := metav1.GetControllerOfNoCopy(obj); ref != nil {
+			return matchRef(*ref)
+		}
+		for _, ref := range obj.GetOwnerReferences() {
+			if matchRef(ref) {
+				return true
+			}
+		}
+		return false
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return match(cronjob)
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return match(cronworkflow)
+		},
+	}
+}
+
+// Parse the '--owned-by' value in the 'KIND[/NAME]' form.
+func parseOwnedBy(value string) (kind, name string, err error) {
+	kind, name, _ = strings.Cut(value, "/")
+	if kind == "" {
+		return "", "", fmt.Errorf("failed to parse '--owned-by' value: '%s' is not in the 'KIND[/NAME]' form", value)
+	}
+	return kind, name, nil
+}
+
+// Keep only items without ownerReferences.
+var unownedFilter = filter{
+	cronJob: func(cronjob *batchv1.CronJob) bool {
+		return len(cronjob.OwnerReferences) == 0
+	},
+	cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+		return len(cronworkflow.OwnerReferences) == 0
+	},
+}
+
+// Keep only items which have never run and were created more than olderThan
+// before now: status.lastScheduleTime is unset and status.active is empty for
+// CronJobs, and status.lastScheduledTime is unset for CronWorkflows.
+func neverRunFilter(olderThan time.Duration, now time.Time) filter {
+	old := func(obj metav1.Object) bool {
+		return now.Sub(obj.GetCreationTimestamp().Time) > olderThan
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return cronjob.Status.LastScheduleTime == nil && len(cronjob.Status.Active) == 0 && old(cronjob)
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return cronworkflow.Status.LastScheduledTime == nil && old(cronworkflow)
+		},
+	}
+}
+
+// Keep only items whose template labels match the selector: the labels of
+// the pod template in the Job template for CronJobs, and of workflowMetadata
+// for CronWorkflows. The labels of the objects themselves are matched by
+// '--selector' on the server.
+func templateSelectorFilter(value string) (filter, error) {
+	selector, err := parseSelector("template-selector", value)
+	if err != nil {
+		return filter{}, err
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return selector.Matches(labels.Set(cronjob.Spec.JobTemplate.Spec.Template.Labels))
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			var set labels.Set
+			if cronworkflow.Spec.WorkflowMetadata != nil {
+				set = cronworkflow.Spec.WorkflowMetadata.Labels
+			}
+			return selector.Matches(set)
+		},
+	}, nil
+}
+
+// Keep only items whose ttlSecondsAfterFinished of the Job template (CronJobs)
+// or ttlStrategy.secondsAfterCompletion (CronWorkflows) matches the condition.
+func ttlFilter(cond secondsCondition) filter {
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return cond.match(int32PtrToInt64Ptr(cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished))
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			ttl := cronworkflow.Spec.WorkflowSpec.TTLStrategy
+			if ttl == nil {
+				return cond.match(nil)
+			}
+			return cond.match(int32PtrToInt64Ptr(ttl.SecondsAfterCompletion))
+		},
+	}
+}
+
+func int32PtrToInt64Ptr(v *int32) *int64 {
+	if v == nil {
+		return nil
+	}
+	n := int64(*v)
+	return &n
+}
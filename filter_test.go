@@ -0,0 +1,617 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Get the 'Kind/namespace/name' of the items for easy comparison.
+func getItemNames(items []item) []string {
+	names := []string{}
+	for _, item := range items {
+		names = append(names, item.kind()+"/"+item.object().GetNamespace()+"/"+item.object().GetName())
+	}
+	return names
+}
+
+func Test_missingHistoryLimitsFilter(t *testing.T) {
+	t.Parallel()
+	ptr := func(v int32) *int32 { return &v }
+
+	bothSet := getCronJob("ns-a", "both-set", "0 0 * * *", false)
+	bothSet.Spec.SuccessfulJobsHistoryLimit = ptr(3)
+	bothSet.Spec.FailedJobsHistoryLimit = ptr(1)
+	zero := getCronJob("ns-a", "zero", "0 0 * * *", false)
+	zero.Spec.SuccessfulJobsHistoryLimit = ptr(0)
+	zero.Spec.FailedJobsHistoryLimit = ptr(0)
+	failedUnset := getCronJob("ns-a", "failed-unset", "0 0 * * *", false)
+	failedUnset.Spec.SuccessfulJobsHistoryLimit = ptr(3)
+	defaultsUnset := getCronJob("ns-a", "defaults-unset", "0 0 * * *", false)
+
+	cwBothSet := getCronWorkflow("ns-b", "both-set", "0 0 * * *", false)
+	cwBothSet.Spec.SuccessfulJobsHistoryLimit = ptr(3)
+	cwBothSet.Spec.FailedJobsHistoryLimit = ptr(1)
+	cwSuccessfulUnset := getCronWorkflow("ns-b", "successful-unset", "0 0 * * *", false)
+	cwSuccessfulUnset.Spec.FailedJobsHistoryLimit = ptr(1)
+
+	got := applyFilters(
+		[]filter{missingHistoryLimitsFilter},
+		mergeItems(
+			[]batchv1.CronJob{bothSet, zero, failedUnset, defaultsUnset},
+			[]wfv1alpha1.CronWorkflow{cwBothSet, cwSuccessfulUnset},
+		),
+	)
+	want := []string{
+		"CronJob/ns-a/defaults-unset",
+		"CronJob/ns-a/failed-unset",
+		"CronWorkflow/ns-b/successful-unset",
+	}
+	if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+		t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_applyFilters_noFilters(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{getCronJob("ns-a", "n-1", "0 0 * * *", false)}
+	cronworkflows := []wfv1alpha1.CronWorkflow{getCronWorkflow("ns-a", "n-2", "0 0 * * *", false)}
+	got := applyFilters(nil, mergeItems(cronjobs, cronworkflows))
+	want := []string{"CronJob/ns-a/n-1", "CronWorkflow/ns-a/n-2"}
+	if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+		t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_priorityClassFilter(t *testing.T) {
+	t.Parallel()
+	high := getCronJob("ns-a", "high", "0 0 * * *", false)
+	high.Spec.JobTemplate.Spec.Template.Spec.PriorityClassName = "high"
+	low := getCronJob("ns-a", "low", "0 0 * * *", false)
+	low.Spec.JobTemplate.Spec.Template.Spec.PriorityClassName = "low"
+	unset := getCronJob("ns-a", "unset", "0 0 * * *", false)
+
+	cwHigh := getCronWorkflow("ns-b", "high", "0 0 * * *", false)
+	cwHigh.Spec.WorkflowSpec.PodPriorityClassName = "high"
+	cwCritical := getCronWorkflow("ns-b", "critical", "0 0 * * *", false)
+	cwCritical.Spec.WorkflowSpec.PodPriorityClassName = "system-cluster-critical"
+	cwUnset := getCronWorkflow("ns-b", "unset", "0 0 * * *", false)
+
+	tests := []struct {
+		name  string
+		names []string
+		want  []string
+	}{
+		{
+			name:  "set",
+			names: []string{"high"},
+			want:  []string{"CronJob/ns-a/high", "CronWorkflow/ns-b/high"},
+		},
+		{
+			name:  "unset",
+			names: []string{""},
+			want:  []string{"CronJob/ns-a/unset", "CronWorkflow/ns-b/unset"},
+		},
+		{
+			name:  "repeated",
+			names: []string{"system-cluster-critical", "low", ""},
+			want:  []string{"CronJob/ns-a/low", "CronJob/ns-a/unset", "CronWorkflow/ns-b/critical", "CronWorkflow/ns-b/unset"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters(
+				[]filter{priorityClassFilter(tt.names)},
+				mergeItems(
+					[]batchv1.CronJob{high, low, unset},
+					[]wfv1alpha1.CronWorkflow{cwHigh, cwCritical, cwUnset},
+				),
+			)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_minRequestFilter(t *testing.T) {
+	t.Parallel()
+	container := func(cpu, memory string) corev1.Container {
+		requests := corev1.ResourceList{}
+		if cpu != "" {
+			requests[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			requests[corev1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return corev1.Container{Resources: corev1.ResourceRequirements{Requests: requests}}
+	}
+
+	// 1500m + 0.5 = 2 cpu, 3Gi + 1024Mi = 4Gi memory
+	heavy := getCronJob("ns-a", "heavy", "0 0 * * *", false)
+	heavy.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{container("1500m", "3Gi"), container("0.5", "1024Mi")}
+	// the init container is not counted
+	light := getCronJob("ns-a", "light", "0 0 * * *", false)
+	light.Spec.JobTemplate.Spec.Template.Spec.InitContainers = []corev1.Container{container("4", "8Gi")}
+	light.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{container("500m", ""), container("", "")}
+	none := getCronJob("ns-a", "none", "0 0 * * *", false)
+
+	// the heaviest template counts, not the sum of all templates
+	cwHeavy := getCronWorkflow("ns-b", "heavy", "0 0 * * *", false)
+	cwHeavy.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "a", Container: ptrContainer(container("1", "1Gi"))},
+		{Name: "b", Script: &wfv1alpha1.ScriptTemplate{Container: container("2000m", "4096Mi")}},
+	}
+	cwSplit := getCronWorkflow("ns-b", "split", "0 0 * * *", false)
+	cwSplit.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "a", Container: ptrContainer(container("1", "2Gi"))},
+		{Name: "b", Container: ptrContainer(container("1", "2Gi"))},
+	}
+	cwRef := getCronWorkflow("ns-b", "ref", "0 0 * * *", false)
+	cwRef.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "tmpl"}
+
+	tests := []struct {
+		name     string
+		resource corev1.ResourceName
+		min      string
+		want     []string
+	}{
+		{
+			name:     "cpu",
+			resource: corev1.ResourceCPU,
+			min:      "2",
+			want:     []string{"CronJob/ns-a/heavy", "CronWorkflow/ns-b/heavy"},
+		},
+		{
+			name:     "cpu in millicores",
+			resource: corev1.ResourceCPU,
+			min:      "500m",
+			want:     []string{"CronJob/ns-a/heavy", "CronJob/ns-a/light", "CronWorkflow/ns-b/heavy", "CronWorkflow/ns-b/split"},
+		},
+		{
+			name:     "memory",
+			resource: corev1.ResourceMemory,
+			min:      "4Gi",
+			want:     []string{"CronJob/ns-a/heavy", "CronWorkflow/ns-b/heavy"},
+		},
+		{
+			name:     "zero keeps items without requests",
+			resource: corev1.ResourceMemory,
+			min:      "0",
+			want:     []string{"CronJob/ns-a/heavy", "CronJob/ns-a/light", "CronJob/ns-a/none", "CronWorkflow/ns-b/heavy", "CronWorkflow/ns-b/ref", "CronWorkflow/ns-b/split"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters(
+				[]filter{minRequestFilter(tt.resource, resource.MustParse(tt.min))},
+				mergeItems(
+					[]batchv1.CronJob{heavy, light, none},
+					[]wfv1alpha1.CronWorkflow{cwHeavy, cwSplit, cwRef},
+				),
+			)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func ptrContainer(c corev1.Container) *corev1.Container {
+	return &c
+}
+
+func Test_parseAnnotationRegexes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		values  []string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			values: []string{"runbook.example.com/url=.*payments.*", "owner=^team-(a|b)$", "empty="},
+		},
+		{
+			name:    "invalid pattern names the flag instance",
+			values:  []string{"owner=^team-a$", "runbook.example.com/url=.*(payments"},
+			wantErr: "failed to parse '--annotation-regex runbook.example.com/url=.*(payments'",
+		},
+		{
+			name:    "missing pattern",
+			values:  []string{"owner"},
+			wantErr: "failed to parse '--annotation-regex owner'",
+		},
+		{
+			name:    "missing key",
+			values:  []string{"=foo"},
+			wantErr: "failed to parse '--annotation-regex =foo'",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseAnnotationRegexes(tt.values)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("parseAnnotationRegexes() error = %v", err)
+				}
+				if len(got) != len(tt.values) {
+					t.Errorf("parseAnnotationRegexes() returned %d matchers, want %d", len(got), len(tt.values))
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("parseAnnotationRegexes() error = %v, want prefix %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_annotationRegexFilter(t *testing.T) {
+	t.Parallel()
+	payments := getCronJob("ns-a", "payments", "0 0 * * *", false)
+	payments.Annotations = map[string]string{"runbook.example.com/url": "https://wiki/payments/settle", "owner": "team-a"}
+	search := getCronJob("ns-a", "search", "0 0 * * *", false)
+	search.Annotations = map[string]string{"runbook.example.com/url": "https://wiki/search/reindex", "owner": "team-b"}
+	noAnnotations := getCronJob("ns-a", "no-annotations", "0 0 * * *", false)
+	cwPayments := getCronWorkflow("ns-b", "payments", "0 0 * * *", false)
+	cwPayments.Annotations = map[string]string{"runbook.example.com/url": "https://wiki/payments/report"}
+
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{
+			name:   "single",
+			values: []string{"runbook.example.com/url=.*payments.*"},
+			want:   []string{"CronJob/ns-a/payments", "CronWorkflow/ns-b/payments"},
+		},
+		{
+			name:   "AND across repetitions",
+			values: []string{"runbook.example.com/url=payments", "owner=^team-a$"},
+			want:   []string{"CronJob/ns-a/payments"},
+		},
+		{
+			name:   "missing key fails",
+			values: []string{"owner=.*"},
+			want:   []string{"CronJob/ns-a/payments", "CronJob/ns-a/search"},
+		},
+		{
+			name:   "anchored pattern",
+			values: []string{"owner=^team-$"},
+			want:   []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			matchers, err := parseAnnotationRegexes(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := applyFilters(
+				[]filter{annotationRegexFilter(matchers)},
+				mergeItems([]batchv1.CronJob{payments, search, noAnnotations}, []wfv1alpha1.CronWorkflow{cwPayments}),
+			)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_ownedByFilter(t *testing.T) {
+	t.Parallel()
+	ptr := func(v bool) *bool { return &v }
+
+	handMade := getCronJob("ns-a", "hand-made", "0 0 * * *", false)
+	operated := getCronJob("ns-a", "operated", "0 0 * * *", false)
+	operated.OwnerReferences = []metav1.OwnerReference{{Kind: "BackupSchedule", Name: "nightly", Controller: ptr(true)}}
+	// The controller wins over the other owners.
+	multiple := getCronJob("ns-a", "multiple", "0 0 * * *", false)
+	multiple.OwnerReferences = []metav1.OwnerReference{
+		{Kind: "ConfigMap", Name: "settings"},
+		{Kind: "BackupSchedule", Name: "weekly", Controller: ptr(true)},
+	}
+	// Without a controller, any of the owners counts.
+	noController := getCronJob("ns-a", "no-controller", "0 0 * * *", false)
+	noController.OwnerReferences = []metav1.OwnerReference{
+		{Kind: "ConfigMap", Name: "settings"},
+		{Kind: "Application", Name: "app", Controller: ptr(false)},
+	}
+	cwOperated := getCronWorkflow("ns-b", "operated", "0 0 * * *", false)
+	cwOperated.OwnerReferences = []metav1.OwnerReference{{Kind: "BackupSchedule", Name: "nightly", Controller: ptr(true)}}
+	cwHandMade := getCronWorkflow("ns-b", "hand-made", "0 0 * * *", false)
+
+	items := mergeItems(
+		[]batchv1.CronJob{handMade, operated, multiple, noController},
+		[]wfv1alpha1.CronWorkflow{cwOperated, cwHandMade},
+	)
+	tests := []struct {
+		name   string
+		filter filter
+		want   []string
+	}{
+		{
+			name:   "kind",
+			filter: ownedByFilter("backupschedule", ""),
+			want:   []string{"CronJob/ns-a/multiple", "CronJob/ns-a/operated", "CronWorkflow/ns-b/operated"},
+		},
+		{
+			name:   "kind and name",
+			filter: ownedByFilter("BackupSchedule", "nightly"),
+			want:   []string{"CronJob/ns-a/operated", "CronWorkflow/ns-b/operated"},
+		},
+		{
+			name:   "non-controller owner is ignored when there is a controller",
+			filter: ownedByFilter("ConfigMap", "settings"),
+			want:   []string{"CronJob/ns-a/no-controller"},
+		},
+		{
+			name:   "non-controller owner without a controller",
+			filter: ownedByFilter("Application", ""),
+			want:   []string{"CronJob/ns-a/no-controller"},
+		},
+		{
+			name:   "unowned",
+			filter: unownedFilter,
+			want:   []string{"CronJob/ns-a/hand-made", "CronWorkflow/ns-b/hand-made"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters([]filter{tt.filter}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseOwnedBy(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value    string
+		wantKind string
+		wantName string
+		wantErr  bool
+	}{
+		{value: "BackupSchedule", wantKind: "BackupSchedule"},
+		{value: "BackupSchedule/nightly", wantKind: "BackupSchedule", wantName: "nightly"},
+		{value: "/nightly", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			kind, name, err := parseOwnedBy(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseOwnedBy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if kind != tt.wantKind || name != tt.wantName {
+				t.Errorf("parseOwnedBy() = %v, %v, want %v, %v", kind, name, tt.wantKind, tt.wantName)
+			}
+		})
+	}
+}
+
+func Test_ttlFilter(t *testing.T) {
+	t.Parallel()
+	ptr := func(v int32) *int32 { return &v }
+
+	unset := getCronJob("ns-a", "unset", "0 0 * * *", false)
+	zero := getCronJob("ns-a", "zero", "0 0 * * *", false)
+	zero.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = ptr(0)
+	day := getCronJob("ns-a", "day", "0 0 * * *", false)
+	day.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = ptr(86400)
+	cwUnset := getCronWorkflow("ns-b", "unset", "0 0 * * *", false)
+	// secondsAfterSuccess alone doesn't clean up the failed workflows.
+	cwSuccessOnly := getCronWorkflow("ns-b", "success-only", "0 0 * * *", false)
+	cwSuccessOnly.Spec.WorkflowSpec.TTLStrategy = &wfv1alpha1.TTLStrategy{SecondsAfterSuccess: ptr(60)}
+	cwHour := getCronWorkflow("ns-b", "hour", "0 0 * * *", false)
+	cwHour.Spec.WorkflowSpec.TTLStrategy = &wfv1alpha1.TTLStrategy{SecondsAfterCompletion: ptr(3600)}
+
+	items := mergeItems(
+		[]batchv1.CronJob{unset, zero, day},
+		[]wfv1alpha1.CronWorkflow{cwUnset, cwSuccessOnly, cwHour},
+	)
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "set", want: []string{"CronJob/ns-a/day", "CronJob/ns-a/zero", "CronWorkflow/ns-b/hour"}},
+		{value: "unset", want: []string{"CronJob/ns-a/unset", "CronWorkflow/ns-b/success-only", "CronWorkflow/ns-b/unset"}},
+		{value: "lt=3600", want: []string{"CronJob/ns-a/zero"}},
+		{value: "gt=0", want: []string{"CronJob/ns-a/day", "CronWorkflow/ns-b/hour"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			cond, err := parseSecondsCondition("ttl", tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := applyFilters([]filter{ttlFilter(cond)}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_neverRunFilter(t *testing.T) {
+	t.Parallel()
+	now := getTime("2023-03-01T00:00:00Z")
+	created := func(obj metav1.Object, s string) {
+		obj.SetCreationTimestamp(metav1.NewTime(getTime(s)))
+	}
+	lastSchedule := metav1.NewTime(getTime("2023-02-28T00:00:00Z"))
+
+	stale := getCronJob("ns-a", "stale", "0 0 31 2 *", false)
+	created(&stale, "2023-01-01T00:00:00Z")
+	fresh := getCronJob("ns-a", "fresh", "0 0 * * *", false)
+	created(&fresh, "2023-02-28T12:00:00Z")
+	ran := getCronJob("ns-a", "ran", "0 0 * * *", false)
+	created(&ran, "2023-01-01T00:00:00Z")
+	ran.Status.LastScheduleTime = &lastSchedule
+	// The first run is still active before its lastScheduleTime is reported.
+	running := getCronJob("ns-a", "running", "0 0 * * *", false)
+	created(&running, "2023-01-01T00:00:00Z")
+	running.Status.Active = []corev1.ObjectReference{{Kind: "Job", Name: "running-1"}}
+	cwStale := getCronWorkflow("ns-b", "stale", "0 0 31 2 *", false)
+	created(&cwStale, "2023-01-01T00:00:00Z")
+	cwFresh := getCronWorkflow("ns-b", "fresh", "0 0 * * *", false)
+	created(&cwFresh, "2023-02-28T12:00:00Z")
+	cwRan := getCronWorkflow("ns-b", "ran", "0 0 * * *", false)
+	created(&cwRan, "2023-01-01T00:00:00Z")
+	cwRan.Status.LastScheduledTime = &lastSchedule
+
+	items := mergeItems(
+		[]batchv1.CronJob{stale, fresh, ran, running},
+		[]wfv1alpha1.CronWorkflow{cwStale, cwFresh, cwRan},
+	)
+	tests := []struct {
+		name      string
+		olderThan time.Duration
+		want      []string
+	}{
+		{
+			name: "any age",
+			want: []string{"CronJob/ns-a/fresh", "CronJob/ns-a/stale", "CronWorkflow/ns-b/fresh", "CronWorkflow/ns-b/stale"},
+		},
+		{
+			name:      "older than 30 days",
+			olderThan: 30 * 24 * time.Hour,
+			want:      []string{"CronJob/ns-a/stale", "CronWorkflow/ns-b/stale"},
+		},
+		{
+			name:      "older than the stale ones",
+			olderThan: 90 * 24 * time.Hour,
+			want:      []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters([]filter{neverRunFilter(tt.olderThan, now)}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_templateSelectorFilter(t *testing.T) {
+	t.Parallel()
+	// The object labels differ from the template labels.
+	billing := getCronJob("ns-a", "billing", "0 0 * * *", false)
+	billing.Labels = map[string]string{"app.kubernetes.io/part-of": "search"}
+	billing.Spec.JobTemplate.Spec.Template.Labels = map[string]string{"app.kubernetes.io/part-of": "billing", "tier": "batch"}
+	search := getCronJob("ns-a", "search", "0 0 * * *", false)
+	search.Labels = map[string]string{"app.kubernetes.io/part-of": "billing"}
+	search.Spec.JobTemplate.Spec.Template.Labels = map[string]string{"app.kubernetes.io/part-of": "search"}
+	cwBilling := getCronWorkflow("ns-b", "billing", "0 0 * * *", false)
+	cwBilling.Spec.WorkflowMetadata = &metav1.ObjectMeta{Labels: map[string]string{"app.kubernetes.io/part-of": "billing"}}
+	cwNoMetadata := getCronWorkflow("ns-b", "no-metadata", "0 0 * * *", false)
+	cwNoMetadata.Labels = map[string]string{"app.kubernetes.io/part-of": "billing"}
+
+	items := mergeItems(
+		[]batchv1.CronJob{billing, search},
+		[]wfv1alpha1.CronWorkflow{cwBilling, cwNoMetadata},
+	)
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "app.kubernetes.io/part-of=billing", want: []string{"CronJob/ns-a/billing", "CronWorkflow/ns-b/billing"}},
+		{value: "app.kubernetes.io/part-of=billing,tier=batch", want: []string{"CronJob/ns-a/billing"}},
+		{value: "app.kubernetes.io/part-of in (billing,search)", want: []string{"CronJob/ns-a/billing", "CronJob/ns-a/search", "CronWorkflow/ns-b/billing"}},
+		{value: "!app.kubernetes.io/part-of", want: []string{"CronWorkflow/ns-b/no-metadata"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			f, err := templateSelectorFilter(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := applyFilters([]filter{f}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := templateSelectorFilter("app in billing"); err == nil || !strings.HasPrefix(err.Error(), "failed to parse '--template-selector' value 'app in billing': ") {
+		t.Errorf("want a parse error, got %v", err)
+	}
+}
+
+func Test_templateSelector_run(t *testing.T) {
+	t.Parallel()
+	manifest := func(name, objectPartOf, templatePartOf string) string {
+		return "" +
+			"apiVersion: batch/v1\n" +
+			"kind: CronJob\n" +
+			"metadata:\n" +
+			"  namespace: default\n" +
+			"  name: " + name + "\n" +
+			"  labels: {team: " + objectPartOf + "}\n" +
+			"spec:\n" +
+			"  schedule: '0 1 * * *'\n" +
+			"  jobTemplate:\n" +
+			"    spec:\n" +
+			"      template:\n" +
+			"        metadata:\n" +
+			"          labels: {app.kubernetes.io/part-of: " + templatePartOf + "}\n" +
+			"        spec:\n" +
+			"          containers: [{name: main, image: busybox}]\n"
+	}
+	path := filepath.Join(t.TempDir(), "cronjobs.yaml")
+	content := strings.Join([]string{
+		manifest("a", "infra", "billing"),
+		manifest("b", "payments", "billing"),
+		manifest("c", "payments", "search"),
+	}, "---\n")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	args := []string{commandName, "--fixtures", path, "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z", "--no-headers",
+		"-l", "team=payments", "--template-selector", "app.kubernetes.io/part-of=billing"}
+	if err := run(&got, &bytes.Buffer{}, args); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(got.String()), "\n") {
+		names = append(names, strings.Fields(line)[1])
+	}
+	if diff := cmp.Diff([]string{"b"}, names); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
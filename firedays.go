@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// weekdaySet is a set of time.Weekday.
+type weekdaySet uint8
+
+func (s weekdaySet) has(day time.Weekday) bool {
+	return s&(1<<day) != 0
+}
+
+func (s *weekdaySet) add(day time.Weekday) {
+	*s |= 1 << day
+}
+
+const (
+	weekdays weekdaySet = 1<<time.Monday | 1<<time.Tuesday | 1<<time.Wednesday | 1<<time.Thursday | 1<<time.Friday
+	weekends weekdaySet = 1<<time.Saturday | 1<<time.Sunday
+	allDays             = weekdays | weekends
+)
+
+var dayNames = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// Parse the '--fires-on' value. One of 'weekdays', 'weekends', or a comma
+// separated list of day names such as 'MON,TUE'.
+func parseWeekdaySet(value string) (weekdaySet, error) {
+	switch strings.ToLower(value) {
+	case "weekdays":
+		return weekdays, nil
+	case "weekends":
+		return weekends, nil
+	}
+
+	var set weekdaySet
+	for _, name := range strings.Split(value, ",") {
+		day, ok := dayNames[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("failed to parse '--fires-on' value: unknown day '%s'", name)
+		}
+		set.add(day)
+	}
+	return set, nil
+}
+
+// firesOnHorizon is how far the fire times are sampled. A year covers the
+// day-of-month schedules such as '0 0 31 * *' on every weekday they can fall.
+const firesOnHorizon = 366 * 24 * time.Hour
+
+// Get the days of the week the schedule fires on in (start, start+horizon].
+// The weekdays are those of the zone of the schedule, so a schedule with
+// 'CRON_TZ=' is classified in its own zone. Only the first fire of each day is
+// looked at, so the cost is bounded by the days in the horizon.
+func getFireDays(sched cron.Schedule, start time.Time, horizon time.Duration) weekdaySet {
+	// Next returns the times in the location of start, not of the schedule.
+	loc := start.Location()
+	if spec, ok := sched.(*cron.SpecSchedule); ok {
+		loc = spec.Location
+	}
+
+	var set weekdaySet
+	end := start.Add(horizon)
+	for t := sched.Next(start); !t.IsZero() && !t.After(end); {
+		t = t.In(loc)
+		set.add(t.Weekday())
+		if set == allDays {
+			break
+		}
+		// Skip to the end of the day in the zone of the schedule.
+		next := time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location()).Add(-time.Second)
+		t = sched.Next(next)
+	}
+	return set
+}
+
+// Parse the schedule of the item in its time zone.
+func parseItemSchedule(item item) (cron.Schedule, error) {
+	spec := item.schedule()
+	if tz := item.timezone(); tz != "" {
+		spec = fmt.Sprintf("CRON_TZ=%s %s", tz, spec)
+	}
+	sched, err := cron.ParseStandard(spec)
+	if err != nil {
+		obj := item.object()
+		return nil, fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", spec, item.kind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return sched, nil
+}
+
+// Keep only items which fire on days within the horizon from start. With only,
+// the items must fire on no other day; otherwise firing on any of days is
+// enough. An item whose schedule or time zone can't be parsed is not kept.
+func firesOnFilter(days weekdaySet, only bool, start time.Time) filter {
+	match := func(item item) bool {
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return false
+		}
+		return matchFireDays(getFireDays(sched, start, firesOnHorizon), days, only)
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return match(item{cronJob: cronjob})
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return match(item{cronWorkflow: cronworkflow})
+		},
+	}
+}
+
+func matchFireDays(fires, days weekdaySet, only bool) bool {
+	if only {
+		return fires != 0 && fires&^days == 0
+	}
+	return fires&days != 0
+}
+
+// typicalIntervalSamples is the number of the gaps between the fires sampled
+// by getTypicalInterval.
+const typicalIntervalSamples = 100
+
+// Get the typical interval of the schedule from start, the median of the gaps
+// between its fires within firesOnHorizon, so that a weekday schedule is daily
+// and a monthly one is not skewed by the lengths of the months. It returns
+// false when the schedule fires less than twice in the horizon.
+func getTypicalInterval(sched cron.Schedule, start time.Time) (time.Duration, bool) {
+	var gaps []time.Duration
+	end := start.Add(firesOnHorizon)
+	prev := sched.Next(start)
+	for t := sched.Next(prev); !prev.IsZero() && !t.IsZero() && !t.After(end) && len(gaps) < typicalIntervalSamples; prev, t = t, sched.Next(t) {
+		gaps = append(gaps, t.Sub(prev))
+	}
+	if len(gaps) == 0 {
+		return 0, false
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return gaps[len(gaps)/2], true
+}
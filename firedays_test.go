@@ -0,0 +1,227 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	"github.com/robfig/cron/v3"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func getWeekdaySet(days ...time.Weekday) weekdaySet {
+	var set weekdaySet
+	for _, day := range days {
+		set.add(day)
+	}
+	return set
+}
+
+func Test_getFireDays(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		sched   cron.Schedule
+		start   time.Time
+		horizon time.Duration
+	}
+	// 2023-01-01 is a Sunday.
+	week := args{start: getTime("2022-12-31T12:00:00Z"), horizon: 7 * 24 * time.Hour}
+	year := args{start: getTime("2023-01-01T00:00:00Z"), horizon: firesOnHorizon}
+	with := func(a args, spec string) args {
+		a.sched = getSchedule(spec)
+		return a
+	}
+	tests := []struct {
+		name string
+		args args
+		want weekdaySet
+	}{
+		{
+			name: "every minute",
+			args: with(week, "* * * * *"),
+			want: allDays,
+		},
+		{
+			name: "day-of-week range",
+			args: with(week, "0 9 * * 1-5"),
+			want: weekdays,
+		},
+		{
+			name: "day-of-week list",
+			args: with(week, "0 9 * * 0,6"),
+			want: weekends,
+		},
+		{
+			name: "day-of-week name",
+			args: with(week, "30 3 * * WED"),
+			want: getWeekdaySet(time.Wednesday),
+		},
+		{
+			name: "day-of-week step",
+			args: with(week, "0 0 * * */3"),
+			want: getWeekdaySet(time.Sunday, time.Wednesday, time.Saturday),
+		},
+		{
+			name: "day-of-month within a week",
+			args: with(week, "0 0 1 * *"),
+			want: getWeekdaySet(time.Sunday),
+		},
+		{
+			name: "day-of-month over a year falls on every day",
+			args: with(year, "0 0 1 * *"),
+			want: allDays,
+		},
+		{
+			name: "day-of-month and month",
+			args: with(year, "0 0 25 12 *"),
+			want: getWeekdaySet(time.Monday), // 2023-12-25
+		},
+		{
+			name: "day-of-month and day-of-week are ORed",
+			args: with(week, "0 0 1 * 5"),
+			want: getWeekdaySet(time.Sunday, time.Friday),
+		},
+		{
+			name: "day-of-month not in the horizon, day-of-week ORed",
+			args: with(week, "0 0 13 * 5"),
+			want: getWeekdaySet(time.Friday),
+		},
+		{
+			name: "restricted day-of-month covering all days is still ORed",
+			args: with(week, "0 0 1-31 * 5"),
+			want: allDays,
+		},
+		{
+			name: "wildcard day-of-month is ANDed",
+			args: with(week, "0 0 * * 5"),
+			want: getWeekdaySet(time.Friday),
+		},
+		{
+			name: "wildcard day-of-week is ANDed",
+			args: with(year, "0 0 13 * *"),
+			want: allDays, // the 13th of 2023 falls on every day of the week
+		},
+		{
+			name: "never fires",
+			args: with(year, "0 0 30 2 *"),
+			want: 0,
+		},
+		{
+			name: "weekday in the schedule's zone",
+			// Monday 08:00 in Tokyo is Sunday 23:00 in UTC.
+			args: with(week, "CRON_TZ=Asia/Tokyo 0 8 * * 1"),
+			want: getWeekdaySet(time.Monday),
+		},
+		{
+			name: "midnight in the schedule's zone",
+			// 00:30 in New York is 05:30 in UTC on the same day, so each
+			// fire is counted once.
+			args: with(week, "CRON_TZ=America/New_York 30 0 * * 6"),
+			want: getWeekdaySet(time.Saturday),
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := getFireDays(tt.args.sched, tt.args.start, tt.args.horizon)
+			if got != tt.want {
+				t.Errorf("getFireDays() = %08b, want %08b", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseWeekdaySet(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    weekdaySet
+		wantErr bool
+	}{
+		{value: "weekdays", want: weekdays},
+		{value: "Weekends", want: weekends},
+		{value: "MON,tue, Fri", want: getWeekdaySet(time.Monday, time.Tuesday, time.Friday)},
+		{value: "SUN", want: getWeekdaySet(time.Sunday)},
+		{value: "MON,FUNDAY", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseWeekdaySet(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseWeekdaySet() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseWeekdaySet() = %08b, want %08b", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_firesOnFilter(t *testing.T) {
+	t.Parallel()
+	start := getTime("2023-01-01T00:00:00Z")
+	weekdayOnly := getCronJob("ns-a", "weekday-only", "0 9 * * 1-5", false)
+	weekendOnly := getCronJob("ns-a", "weekend-only", "0 9 * * 0,6", false)
+	daily := getCronJob("ns-a", "daily", "0 9 * * *", false)
+	never := getCronJob("ns-a", "never", "0 0 30 2 *", false)
+	// Saturday 08:00 in Tokyo is Friday 23:00 in UTC.
+	tokyo := "Asia/Tokyo"
+	saturdayInTokyo := getCronJob("ns-a", "saturday-in-tokyo", "0 8 * * 6", false)
+	saturdayInTokyo.Spec.TimeZone = &tokyo
+	cwMonday := getCronWorkflow("ns-b", "monday", "0 9 * * MON", false)
+	cwMonday.Spec.Timezone = "America/New_York"
+	cwBadZone := getCronWorkflow("ns-b", "bad-zone", "0 9 * * *", false)
+	cwBadZone.Spec.Timezone = "Mars/Olympus_Mons"
+
+	tests := []struct {
+		name string
+		days weekdaySet
+		only bool
+		want []string
+	}{
+		{
+			name: "ever on weekends",
+			days: weekends,
+			want: []string{"CronJob/ns-a/daily", "CronJob/ns-a/saturday-in-tokyo", "CronJob/ns-a/weekend-only"},
+		},
+		{
+			name: "only on weekends",
+			days: weekends,
+			only: true,
+			want: []string{"CronJob/ns-a/saturday-in-tokyo", "CronJob/ns-a/weekend-only"},
+		},
+		{
+			name: "ever on weekdays",
+			days: weekdays,
+			want: []string{"CronJob/ns-a/daily", "CronJob/ns-a/weekday-only", "CronWorkflow/ns-b/monday"},
+		},
+		{
+			name: "only on monday",
+			days: getWeekdaySet(time.Monday),
+			only: true,
+			want: []string{"CronWorkflow/ns-b/monday"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters(
+				[]filter{firesOnFilter(tt.days, tt.only, start)},
+				mergeItems(
+					[]batchv1.CronJob{weekdayOnly, weekendOnly, daily, never, saturdayInTokyo},
+					[]wfv1alpha1.CronWorkflow{cwMonday, cwBadZone},
+				),
+			)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
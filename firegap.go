@@ -0,0 +1,158 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// fireGap is the largest gap between the consecutive fires of an item, checked
+// by '--assert-fires-every'.
+type fireGap struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Schedule  string `json:"schedule"`
+	// From and To are the fires around the gap, or the edges of the checked
+	// period when there is no fire there.
+	From metav1.Time `json:"from"`
+	To   metav1.Time `json:"to"`
+	// Gap is the duration from From to To, e.g. "72h0m0s".
+	Gap string `json:"gap"`
+	// AtLeast is set when From or To is an edge of the checked period, so the
+	// actual gap is longer.
+	AtLeast bool `json:"atLeast,omitempty"`
+	gap     time.Duration
+}
+
+type fireGapReport struct {
+	ApiVersion string         `json:"apiVersion"`
+	Window     documentWindow `json:"window"`
+	Every      string         `json:"every"`
+	Violations []fireGap      `json:"violations"`
+}
+
+// Find the items whose largest gap between the consecutive fires around the
+// from-to period is longer than every, in the order of items.
+func findFireGaps(items []item, from, to time.Time, every time.Duration) ([]fireGap, error) {
+	gaps := []fireGap{}
+	for _, item := range items {
+		g, err := getLargestFireGap(item, from, to, every)
+		if err != nil {
+			return nil, err
+		}
+		if g.gap > every {
+			gaps = append(gaps, g)
+		}
+	}
+	return gaps, nil
+}
+
+// Get the largest gap between the consecutive fires of the item from
+// from-every to to+every, so that a gap overlapping the from-to period is
+// longer than every only when the gap found here is. The schedules are merged,
+// each in the time zone of the item, and the fires are not capped at
+// maxFireOccurrences as only the previous one is kept. A suspended item never
+// fires, so its gap is the whole period.
+func getLargestFireGap(item item, from, to time.Time, every time.Duration) (fireGap, error) {
+	start, end := from.Add(-every), to.Add(every)
+	obj := item.object()
+	ret := fireGap{
+		Kind:      item.kind(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Schedule:  item.schedule(),
+	}
+
+	// The next fire of each schedule, or zero when it is past end.
+	var scheds []cron.Schedule
+	var nexts []time.Time
+	if !item.suspended() {
+		for _, schedule := range item.schedules() {
+			spec := schedule
+			if tz := item.timezone(); tz != "" {
+				spec = fmt.Sprintf("CRON_TZ=%s %s", tz, spec)
+			}
+			sched, err := cron.ParseStandard(spec)
+			if err != nil {
+				return fireGap{}, fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", spec, item.kind(), obj.GetNamespace(), obj.GetName(), err)
+			}
+			scheds = append(scheds, sched)
+			// Next is after the given time, and start is inclusive.
+			nexts = append(nexts, sched.Next(start.Add(-time.Second)))
+		}
+	}
+
+	prev, prevIsEdge := start, true
+	observe := func(t time.Time, isEdge bool) {
+		if gap := t.Sub(prev); gap > ret.gap || ret.Gap == "" {
+			ret.gap = gap
+			ret.From, ret.To = metav1.NewTime(prev), metav1.NewTime(t)
+			ret.AtLeast = prevIsEdge || isEdge
+			ret.Gap = gap.String()
+		}
+		prev, prevIsEdge = t, isEdge
+	}
+	for {
+		var next time.Time
+		for _, t := range nexts {
+			if !t.IsZero() && !t.After(end) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+		if next.IsZero() {
+			break
+		}
+		observe(next, false)
+		// Advance every schedule firing at the same time.
+		for i, t := range nexts {
+			if t.Equal(next) {
+				nexts[i] = scheds[i].Next(t)
+			}
+		}
+	}
+	observe(end, true)
+	return ret, nil
+}
+
+// Print the items whose gaps are longer than every.
+func printFireGaps(stdout io.Writer, output string, noHeaders bool, tf timeFormat, from, to time.Time, every time.Duration, gaps []fireGap) error {
+	report := fireGapReport{ApiVersion: "v1", Window: documentWindow{From: from, To: to}, Every: every.String(), Violations: gaps}
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Schedule", "Kind", "Largest Gap", "Gap From", "Gap To"}, "\t"))
+	}
+	for _, g := range gaps {
+		gap := g.Gap
+		if g.AtLeast {
+			gap = ">" + gap
+		}
+		gapFrom, gapTo := g.From, g.To
+		fmt.Fprintln(tw, strings.Join([]string{g.Namespace, g.Name, g.Schedule, g.Kind, gap, tf.format(&gapFrom), tf.format(&gapTo)}, "\t"))
+	}
+	return tw.Flush()
+}
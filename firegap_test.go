@@ -0,0 +1,110 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_getLargestFireGap(t *testing.T) {
+	t.Parallel()
+	tokyo := "Asia/Tokyo"
+	weekdays := getCronJob("ns-a", "weekdays", "0 2 * * 1-5", false)
+	daily := getCronJob("ns-a", "daily", "0 2 * * *", false)
+	// 00:00 on Monday to Friday in Tokyo is 15:00 on Sunday to Thursday in UTC.
+	tokyoWeekdays := getCronJob("ns-a", "tokyo-weekdays", "0 0 * * 1-5", false)
+	tokyoWeekdays.Spec.TimeZone = &tokyo
+	utcWeekdays := getCronJob("ns-a", "utc-weekdays", "0 0 * * 1-5", false)
+	suspended := getCronJob("ns-a", "suspended", "0 2 * * *", true)
+
+	tests := []struct {
+		name     string
+		item     item
+		from, to string
+		gap      time.Duration
+		gapFrom  string
+		gapTo    string
+		atLeast  bool
+	}{
+		{
+			// From Friday noon to Monday noon, there is no fire on the weekend.
+			name:    "weekdays over a weekend",
+			item:    item{cronJob: &weekdays},
+			from:    "2023-01-27T12:00:00Z",
+			to:      "2023-01-30T12:00:00Z",
+			gap:     72 * time.Hour,
+			gapFrom: "2023-01-27T02:00:00Z",
+			gapTo:   "2023-01-30T02:00:00Z",
+		},
+		{
+			name:    "daily",
+			item:    item{cronJob: &daily},
+			from:    "2023-01-27T12:00:00Z",
+			to:      "2023-01-30T12:00:00Z",
+			gap:     24 * time.Hour,
+			gapFrom: "2023-01-27T02:00:00Z",
+			gapTo:   "2023-01-28T02:00:00Z",
+		},
+		{
+			name:    "time zone",
+			item:    item{cronJob: &tokyoWeekdays},
+			from:    "2023-01-29T16:00:00Z",
+			to:      "2023-01-29T20:00:00Z",
+			gap:     24 * time.Hour,
+			gapFrom: "2023-01-29T15:00:00Z",
+			gapTo:   "2023-01-30T15:00:00Z",
+		},
+		{
+			// The same schedule in UTC has not fired since Friday.
+			name:    "without time zone",
+			item:    item{cronJob: &utcWeekdays},
+			from:    "2023-01-29T16:00:00Z",
+			to:      "2023-01-29T20:00:00Z",
+			gap:     32 * time.Hour,
+			gapFrom: "2023-01-28T16:00:00Z",
+			gapTo:   "2023-01-30T00:00:00Z",
+			atLeast: true,
+		},
+		{
+			name:    "suspended",
+			item:    item{cronJob: &suspended},
+			from:    "2023-01-24T00:00:00Z",
+			to:      "2023-01-24T06:00:00Z",
+			gap:     54 * time.Hour,
+			gapFrom: "2023-01-23T00:00:00Z",
+			gapTo:   "2023-01-25T06:00:00Z",
+			atLeast: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := getLargestFireGap(tt.item, getTime(tt.from), getTime(tt.to), 24*time.Hour)
+			if err != nil {
+				t.Fatalf("getLargestFireGap() error = %v", err)
+			}
+			want := []string{tt.gap.String(), tt.gapFrom, tt.gapTo}
+			gotValues := []string{got.Gap, got.From.UTC().Format(time.RFC3339), got.To.UTC().Format(time.RFC3339)}
+			if diff := cmp.Diff(want, gotValues); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if got.AtLeast != tt.atLeast {
+				t.Errorf("AtLeast = %v, want %v", got.AtLeast, tt.atLeast)
+			}
+		})
+	}
+}
+
+func Test_run_assertFiresEvery(t *testing.T) {
+	t.Parallel()
+	stdout, _, err := runFake(newRunClients(), append(runPeriod, "--assert-fires-every", "24h", "--no-headers")...)
+	if err == nil || err.Error() != "1 items don't fire every 24h0m0s" {
+		t.Errorf("error = %v", err)
+	}
+	want := "ns-b   cleanup   0 2 * * *   CronJob   >54h0m0s   2023-01-23T00:00:00Z   2023-01-25T06:00:00Z\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
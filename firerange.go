@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxFireOccurrences caps the fires enumerated for the last fire of an item in
+// the period, so that e.g. '* * * * *' over a year stays fast.
+const maxFireOccurrences = 10000
+
+// fireRange is the first and the last fire of an item in the from-to period.
+type fireRange struct {
+	// first and last are nil without fires in the period.
+	first *time.Time
+	last  *time.Time
+	// truncated is set when the cap was reached before the end of the period,
+	// and last is nil.
+	truncated bool
+}
+
+// Get the first and last fires of the item in the period like the matching,
+// honoring the boundaries. The fires of all the schedules are merged.
+func getFireRange(item item, from, to time.Time, bounds boundaries) fireRange {
+	var r fireRange
+	for _, schedule := range item.schedules() {
+		sr := getScheduleFireRange(schedule, from, to, bounds)
+		if sr.first != nil && (r.first == nil || sr.first.Before(*r.first)) {
+			r.first = sr.first
+		}
+		if sr.last != nil && (r.last == nil || sr.last.After(*r.last)) {
+			r.last = sr.last
+		}
+		r.truncated = r.truncated || sr.truncated
+	}
+	if r.truncated {
+		r.last = nil
+	}
+	return r
+}
+
+func getScheduleFireRange(schedule string, from, to time.Time, bounds boundaries) fireRange {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return fireRange{}
+	}
+	var r fireRange
+	count := 0
+	for next := sched.Next(bounds.start(from)); !next.IsZero() && bounds.beforeEnd(next, to); next = sched.Next(next) {
+		if count == maxFireOccurrences {
+			r.last = nil
+			r.truncated = true
+			break
+		}
+		count++
+		next := next
+		if r.first == nil {
+			r.first = &next
+		}
+		r.last = &next
+	}
+	return r
+}
+
+// Format the first and last fires in the table. The last fire is ">cap" when
+// the enumeration was truncated.
+func (r fireRange) format(tf timeFormat) (first, last string) {
+	toMeta := func(t *time.Time) *metav1.Time {
+		if t == nil {
+			return nil
+		}
+		mt := metav1.NewTime(*t)
+		return &mt
+	}
+	first = tf.format(toMeta(r.first))
+	if r.truncated {
+		return first, ">" + strconv.Itoa(maxFireOccurrences)
+	}
+	return first, tf.format(toMeta(r.last))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_getFireRange(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T01:00:00Z")
+	tests := []struct {
+		name      string
+		schedule  string
+		bounds    boundaries
+		wantFirst string
+		wantLast  string
+	}{
+		{name: "fires at from and to", schedule: "0 * * * *", wantFirst: "2023-01-24T00:00:00Z", wantLast: "2023-01-24T01:00:00Z"},
+		{name: "exclusive from", schedule: "0 * * * *", bounds: boundaries{exclusiveFrom: true}, wantFirst: "2023-01-24T01:00:00Z", wantLast: "2023-01-24T01:00:00Z"},
+		{name: "exclusive to", schedule: "0 * * * *", bounds: boundaries{exclusiveTo: true}, wantFirst: "2023-01-24T00:00:00Z", wantLast: "2023-01-24T00:00:00Z"},
+		{name: "inside", schedule: "*/20 * * * *", bounds: boundaries{exclusiveFrom: true, exclusiveTo: true}, wantFirst: "2023-01-24T00:20:00Z", wantLast: "2023-01-24T00:40:00Z"},
+		{name: "no fire", schedule: "0 12 * * *", wantFirst: "<none>", wantLast: "<none>"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cronjob := getCronJob("ns", "cj", tt.schedule, false)
+			r := getFireRange(item{cronJob: &cronjob}, from, to, tt.bounds)
+			first, last := r.format(timeFormat{})
+			if diff := cmp.Diff([]string{tt.wantFirst, tt.wantLast}, []string{first, last}); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_getFireRange_truncated(t *testing.T) {
+	t.Parallel()
+	from := getTime("2023-01-24T00:00:00Z")
+	cronjob := getCronJob("ns", "cj", "* * * * *", false)
+	item := item{cronJob: &cronjob}
+
+	// Exactly the cap isn't truncated.
+	r := getFireRange(item, from, from.Add((maxFireOccurrences-1)*time.Minute), boundaries{})
+	if r.truncated || r.last == nil || !r.last.Equal(from.Add((maxFireOccurrences-1)*time.Minute)) {
+		t.Errorf("want the last fire at the cap, got %+v", r)
+	}
+
+	r = getFireRange(item, from, from.Add(maxFireOccurrences*time.Minute), boundaries{})
+	first, last := r.format(timeFormat{})
+	if diff := cmp.Diff([]string{"2023-01-24T00:00:00Z", ">10000"}, []string{first, last}); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_printList_fireRange(t *testing.T) {
+	t.Parallel()
+	window := &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T01:00:00Z")}
+	items := mergeItems([]batchv1.CronJob{getCronJob("ns-a", "cj", "*/5 0 * * *", false)}, nil)
+	var got bytes.Buffer
+	printList(&got, printListOptions{noHeaders: true, wide: true, window: window}, items)
+	fields := strings.Fields(got.String())
+	if diff := cmp.Diff([]string{"2023-01-24T00:00:00Z", "2023-01-24T00:55:00Z", "12"}, fields[len(fields)-3:]); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_buildPrintformat_fireRange(t *testing.T) {
+	t.Parallel()
+	window := &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T01:00:00Z")}
+	items := mergeItems([]batchv1.CronJob{getCronJob("ns-a", "cj", "0 * * * *", false)}, nil)
+	got := buildPrintformat(items, documentOptions{window: window, bounds: boundaries{exclusiveTo: true}}).Evaluations[0]
+	if got.FirstFire == nil || !got.FirstFire.Equal(window.From) {
+		t.Errorf("want firstFire at %s, got %v", window.From, got.FirstFire)
+	}
+	if got.LastFire == nil || !got.LastFire.Equal(window.From) || got.LastFireTruncated {
+		t.Errorf("want lastFire at %s, got %v", window.From, got.LastFire)
+	}
+}
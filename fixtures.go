@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/spf13/pflag"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clusterFlags are the flags which need a cluster, so they can't be used with
+// '--fixtures'.
+var clusterFlags = []string{
+	"namespace-selector",
+	"namespaces",
+	"resolve-templates",
+	"write-configmap",
+	"post-url",
+	"cache-ttl",
+	"include-crds",
+	"server-time",
+	"runtime-risk",
+}
+
+// Validate the flags used with '--fixtures'. The patch actions only work with
+// '--print-commands' or '--generate-patches', which don't patch anything.
+func validateFixturesFlags(fsets *pflag.FlagSet, actions int, noPatch bool) error {
+	for _, name := range clusterFlags {
+		if fsets.Changed(name) {
+			return fmt.Errorf("'--fixtures' can't be used with '--%s'", name)
+		}
+	}
+	if actions > 0 && !noPatch {
+		return errors.New("'--fixtures' can't patch the items, use '--print-commands' or '--generate-patches'")
+	}
+	return nil
+}
+
+// List the CronJobs and CronWorkflows of the fixtures in the namespace and
+// matching the selector, as the API would, and to be executed during the
+// from-to period unless all. An empty namespace means all namespaces, and the
+// fixtures without a namespace are put in defaultNamespace.
+func listFixtures(path, namespace, defaultNamespace, selector string, from, to time.Time, bounds boundaries, all bool) ([]item, error) {
+	sel, err := parseSelector("selector", selector)
+	if err != nil {
+		return nil, err
+	}
+	items, err := loadFixtures(path, defaultNamespace)
+	if err != nil {
+		return nil, err
+	}
+	items = filterManifests(items, namespace, sel)
+	if all {
+		return items, nil
+	}
+
+	included, err := getScheduleIncludedItems(items, from, to, bounds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the items in the from-to period: %w", err)
+	}
+	return included, nil
+}
+
+// Load the fixtures from a JSON output document of '-o json', or from the
+// manifest files as '--filename' of drift.
+func loadFixtures(path, namespace string) ([]item, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	if !info.IsDir() {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixtures: %w", err)
+		}
+		var doc struct {
+			SchemaVersion *int              `json:"schemaVersion"`
+			Items         []json.RawMessage `json:"items"`
+		}
+		// Not a document when it isn't even a JSON object, e.g. YAML.
+		if json.Unmarshal(b, &doc) == nil && doc.SchemaVersion != nil {
+			return decodeDocumentItems(path, *doc.SchemaVersion, doc.Items, namespace)
+		}
+	}
+	return loadManifests([]string{path}, true, namespace)
+}
+
+func decodeDocumentItems(path string, version int, raws []json.RawMessage, namespace string) ([]item, error) {
+	if version != schemaVersion {
+		return nil, fmt.Errorf("failed to decode fixtures in '%s': unsupported schemaVersion %d, want %d", path, version, schemaVersion)
+	}
+	cronjobs := []batchv1.CronJob{}
+	cronworkflows := []wfv1alpha1.CronWorkflow{}
+	for i, raw := range raws {
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, fmt.Errorf("failed to decode items[%d] in '%s': %w", i, path, err)
+		}
+		switch typeMeta.Kind {
+		case "CronJob":
+			var cronjob batchv1.CronJob
+			if err := json.Unmarshal(raw, &cronjob); err != nil {
+				return nil, fmt.Errorf("failed to decode items[%d] in '%s' as a CronJob: %w", i, path, err)
+			}
+			if cronjob.Namespace == "" {
+				cronjob.Namespace = namespace
+			}
+			cronjobs = append(cronjobs, cronjob)
+		case "CronWorkflow":
+			var cronworkflow wfv1alpha1.CronWorkflow
+			if err := json.Unmarshal(raw, &cronworkflow); err != nil {
+				return nil, fmt.Errorf("failed to decode items[%d] in '%s' as a CronWorkflow: %w", i, path, err)
+			}
+			if cronworkflow.Namespace == "" {
+				cronworkflow.Namespace = namespace
+			}
+			cronworkflows = append(cronworkflows, cronworkflow)
+		default:
+			return nil, fmt.Errorf("failed to decode items[%d] in '%s': unsupported kind '%s'", i, path, typeMeta.Kind)
+		}
+	}
+	return mergeItems(cronjobs, cronworkflows), nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// Capture the json output document from a fake cluster into a fixtures file.
+func captureFixtures(t *testing.T, from, to string) (string, []item) {
+	t.Helper()
+	cronjobs := []batchv1.CronJob{
+		getCronJob("ns-a", "cj1", "0 1 * * *", false),
+		getCronJob("ns-b", "cj2", "0 2 * * *", true),
+		getCronJob("ns-b", "cj3", "0 12 * * *", false),
+	}
+	cronjobs[0].Labels = map[string]string{"app": "a"}
+	cronworkflows := []wfv1alpha1.CronWorkflow{
+		getCronWorkflow("ns-a", "cwf1", "30 1 * * *", false),
+	}
+	c := newFakeClients(cronjobs, cronworkflows)
+	items, err := listScheduleIncluded(context.Background(), c, "", "", getTime(from), getTime(to), boundaries{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc bytes.Buffer
+	if err := printJSON(&doc, items, documentOptions{window: &documentWindow{From: getTime(from), To: getTime(to)}}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, doc.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path, items
+}
+
+func Test_fixtures_roundTrip(t *testing.T) {
+	t.Parallel()
+	from, to := "2023-01-24T00:00:00Z", "2023-01-24T06:00:00Z"
+	path, items := captureFixtures(t, from, to)
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+		var want bytes.Buffer
+		printList(&want, printListOptions{}, items)
+
+		var got bytes.Buffer
+		if err := run(&got, &bytes.Buffer{}, []string{commandName, "--fixtures", path, "--from", from, "--to", to}); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if diff := cmp.Diff(want.String(), got.String()); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got bytes.Buffer
+		if err := run(&got, &bytes.Buffer{}, []string{commandName, "--fixtures", path, "--from", from, "--to", to, "-o", "json"}); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		if diff := cmp.Diff(string(want), got.String()); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("namespace, selector, and period", func(t *testing.T) {
+		t.Parallel()
+		var got bytes.Buffer
+		args := []string{commandName, "--fixtures", path, "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T01:00:00Z", "-n", "ns-a", "-l", "app=a", "--no-headers"}
+		if err := run(&got, &bytes.Buffer{}, args); err != nil {
+			t.Fatalf("run() error = %v", err)
+		}
+		want := "ns-a   cj1   0 1 * * *   false   CronJob\n"
+		if diff := cmp.Diff(want, got.String()); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+}
+
+func Test_loadFixtures_manifests(t *testing.T) {
+	t.Parallel()
+	items, err := loadFixtures("testdata/drift", "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifests, err := loadManifests([]string{"testdata/drift"}, true, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(len(manifests), len(items)); diff != "" || len(items) == 0 {
+		t.Errorf("want the manifests in the directory (-want +got):\n%s", diff)
+	}
+}
+
+func Test_loadFixtures_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "unsupported schemaVersion",
+			content: `{"schemaVersion": 99, "items": []}`,
+			want:    "unsupported schemaVersion 99",
+		},
+		{
+			name:    "unsupported kind",
+			content: `{"schemaVersion": 1, "items": [{"apiVersion": "v1", "kind": "Pod"}]}`,
+			want:    "failed to decode items[0] in '%s': unsupported kind 'Pod'",
+		},
+		{
+			name:    "undecodable CronJob",
+			content: `{"schemaVersion": 1, "items": [{"apiVersion": "v1", "kind": "CronJob", "spec": "0 1 * * *"}]}`,
+			want:    "failed to decode items[0] in '%s' as a CronJob",
+		},
+		{
+			name:    "undecodable CronWorkflow",
+			content: `{"schemaVersion": 1, "items": [{"apiVersion": "argoproj.io/v1alpha1", "kind": "CronWorkflow", "spec": {"suspend": "yes"}}]}`,
+			want:    "failed to decode items[0] in '%s' as a CronWorkflow",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(t.TempDir(), "fixtures.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := loadFixtures(path, "default")
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if want := strings.ReplaceAll(tt.want, "%s", path); !strings.Contains(err.Error(), want) {
+				t.Errorf("want the error containing %q, got %q", want, err.Error())
+			}
+		})
+	}
+}
+
+func Test_fixtures_clusterFlags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "post url",
+			args: []string{"--post-url", "http://localhost"},
+			want: "'--fixtures' can't be used with '--post-url'",
+		},
+		{
+			name: "runtime risk",
+			args: []string{"--runtime-risk"},
+			want: "'--fixtures' can't be used with '--runtime-risk'",
+		},
+		{
+			name: "patch",
+			args: []string{"--suspend"},
+			want: "'--fixtures' can't patch the items, use '--print-commands' or '--generate-patches'",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			args := append([]string{commandName, "--fixtures", "testdata/drift", "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}, tt.args...)
+			err := run(&bytes.Buffer{}, &bytes.Buffer{}, args)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("run() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
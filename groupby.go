@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// itemGroup is a section of '--group-by-label': the items with the value of
+// the label, or unlabelledGroup for those without it.
+type itemGroup struct {
+	value string
+	items []item
+}
+
+// Partition the items by the value of the label on each item. The groups are
+// sorted by the value with unlabelledGroup last, and the items keep their
+// order in each group, e.g. that of '--sort-by'.
+func groupItemsByLabel(items []item, label string) []itemGroup {
+	indexes := map[string]int{}
+	groups := []itemGroup{}
+	for _, item := range items {
+		value := item.object().GetLabels()[label]
+		if value == "" {
+			value = unlabelledGroup
+		}
+		i, ok := indexes[value]
+		if !ok {
+			i = len(groups)
+			indexes[value] = i
+			groups = append(groups, itemGroup{value: value})
+		}
+		groups[i].items = append(groups[i].items, item)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i].value, groups[j].value
+		if (a == unlabelledGroup) != (b == unlabelledGroup) {
+			return b == unlabelledGroup
+		}
+		return a < b
+	})
+	return groups
+}
+
+// Print the table of each group under its title with its count, e.g.
+// 'team=payments (2)', separated by a blank line.
+func printGroupedList(stdout io.Writer, opts printListOptions, label string, groups []itemGroup) {
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(stdout)
+		}
+		title := label + "=" + group.value
+		if group.value == unlabelledGroup {
+			title = unlabelledGroup
+		}
+		fmt.Fprintf(stdout, "%s (%d)\n", title, len(group.items))
+		printList(stdout, opts, group.items)
+	}
+}
+
+// documentGroup is a group of '--group-by-label' in the json/yaml output
+// document, referring to its items.
+type documentGroup struct {
+	Label string `json:"label"`
+	// Value is the value of the label, or "<unlabelled>" for the items
+	// without it.
+	Value string            `json:"value"`
+	Count int               `json:"count"`
+	Items []objectReference `json:"items"`
+}
+
+// Get the groups of the output document, nil for no groups.
+func buildDocumentGroups(label string, groups []itemGroup) []documentGroup {
+	if groups == nil {
+		return nil
+	}
+	ret := make([]documentGroup, len(groups))
+	for i, group := range groups {
+		refs := make([]objectReference, len(group.items))
+		for j, item := range group.items {
+			obj := item.object()
+			refs[j] = objectReference{Kind: item.kind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+		}
+		ret[i] = documentGroup{Label: label, Value: group.value, Count: len(group.items), Items: refs}
+	}
+	return ret
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_run_groupByLabel(t *testing.T) {
+	t.Parallel()
+	fixtures := []string{
+		"--fixtures", "testdata/group-by-label.yaml",
+		"--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z",
+		"--group-by-label", "team", "--no-pager",
+	}
+	tests := []struct {
+		name   string
+		args   []string
+		golden string
+	}{
+		{name: "table", golden: "group-by-label.golden.txt"},
+		{name: "show labels", args: []string{"--show-labels"}, golden: "group-by-label.labels.golden.txt"},
+		// The items keep the order of '--sort-by' in each group.
+		{name: "sort by score", args: []string{"--search", "en", "--sort-by", "score"}, golden: "group-by-label.score.golden.txt"},
+		{name: "json", args: []string{"-o", "json"}, golden: "group-by-label.golden.json"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout, stderr bytes.Buffer
+			if err := run(&stdout, &stderr, append(append([]string{commandName}, fixtures...), tt.args...)); err != nil {
+				t.Fatalf("run() error = %v, stderr = %s", err, stderr.String())
+			}
+			assertGolden(t, tt.golden, stdout.Bytes())
+		})
+	}
+}
+
+func Test_groupItemsByLabel(t *testing.T) {
+	t.Parallel()
+	labelled := func(name string, team string) item {
+		cj := getCronJob("default", name, "0 1 * * *", false)
+		if team != "" {
+			cj.Labels = map[string]string{"team": team}
+		}
+		return item{cronJob: &cj}
+	}
+	items := []item{
+		labelled("a", "ops"),
+		labelled("b", ""),
+		labelled("c", "billing"),
+		labelled("d", "ops"),
+	}
+	type group struct {
+		Value string
+		Names []string
+	}
+	var got []group
+	for _, g := range groupItemsByLabel(items, "team") {
+		var names []string
+		for _, item := range g.items {
+			names = append(names, item.object().GetName())
+		}
+		got = append(got, group{Value: g.value, Names: names})
+	}
+	want := []group{
+		{Value: "billing", Names: []string{"c"}},
+		{Value: "ops", Names: []string{"a", "d"}},
+		{Value: unlabelledGroup, Names: []string{"b"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("groups (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The rows of the heatmap, from Monday.
+var heatmapDays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// The shades of '--heatmap-shade', from zero fires to the busiest hour.
+var heatmapShades = []rune{' ', '░', '▒', '▓', '█'}
+
+type heatmap struct {
+	ApiVersion string `json:"apiVersion"`
+	// WeekStart is the Monday 00:00 of the counted week in Timezone.
+	WeekStart time.Time `json:"weekStart"`
+	Timezone  string    `json:"timezone"`
+	Days      []string  `json:"days"`
+	// Matrix is the fire counts per day per hour, Matrix[0][0] is Mon 00:00-01:00.
+	Matrix [7][24]int `json:"matrix"`
+}
+
+// Get the Monday 00:00 of the week containing t in the location.
+func getWeekStart(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, loc)
+}
+
+// Count the fires of the items per day of the week and hour in the location,
+// over the week containing from. The schedules are enumerated for the actual
+// dates of that week, so day-of-month constraints only count when one of its
+// dates matches, e.g. '0 0 1 * *' is empty unless the week contains the 1st.
+func buildHeatmap(items []item, from time.Time, loc *time.Location) (heatmap, error) {
+	start := getWeekStart(from, loc)
+	end := start.AddDate(0, 0, 7)
+	h := heatmap{ApiVersion: "v1", WeekStart: start, Timezone: loc.String(), Days: heatmapDays}
+	for _, item := range items {
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return heatmap{}, err
+		}
+		// Enumerate in UTC like the period, as a schedule without a time zone
+		// is evaluated in the zone of the given time.
+		for t := sched.Next(start.UTC().Add(-time.Second)); !t.IsZero() && t.Before(end); t = sched.Next(t) {
+			local := t.In(loc)
+			h.Matrix[(int(local.Weekday())+6)%7][local.Hour()]++
+		}
+	}
+	return h, nil
+}
+
+func printHeatmap(stdout io.Writer, output string, noHeaders, shade bool, h heatmap) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(h, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(h)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	max := 0
+	for _, row := range h.Matrix {
+		for _, n := range row {
+			if n > max {
+				max = n
+			}
+		}
+	}
+
+	// The cells are narrow, so the columns are separated by a single space.
+	tw := tabwriter.NewWriter(stdout, 0, 1, 1, ' ', tabwriter.AlignRight)
+	if !noHeaders {
+		cells := []string{""}
+		for hour := 0; hour < 24; hour++ {
+			cells = append(cells, fmt.Sprintf("%02d", hour))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t")+"\t")
+	}
+	for day, row := range h.Matrix {
+		cells := []string{h.Days[day]}
+		for _, n := range row {
+			if shade {
+				cells = append(cells, strings.Repeat(string(getShade(n, max)), 2))
+			} else {
+				cells = append(cells, strconv.Itoa(n))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t")+"\t")
+	}
+	return tw.Flush()
+}
+
+// Get the shade of the count relative to the busiest cell. Only zero is blank.
+func getShade(n, max int) rune {
+	if n == 0 || max == 0 {
+		return heatmapShades[0]
+	}
+	levels := len(heatmapShades) - 1
+	return heatmapShades[1+(n-1)*levels/max]
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func getHeatmapFixtures() []item {
+	return mergeItems(
+		[]batchv1.CronJob{
+			getCronJob("default", "weekdays", "0 9 * * 1-5", false),
+			getCronJob("default", "every-6h", "30 */6 * * *", false),
+			// The week of 2023-01-24 has no 1st, and has the 24th on Tuesday.
+			getCronJob("default", "monthly-1st", "0 0 1 * *", false),
+			getCronJob("default", "monthly-24th", "0 12 24 * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("default", "weekend", "0 22 * * 0,6", false),
+		},
+	)
+}
+
+func Test_getWeekStart(t *testing.T) {
+	t.Parallel()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		t    time.Time
+		loc  *time.Location
+		want time.Time
+	}{
+		{t: getTime("2023-01-24T10:00:00Z"), loc: time.UTC, want: getTime("2023-01-23T00:00:00Z")},
+		{t: getTime("2023-01-23T00:00:00Z"), loc: time.UTC, want: getTime("2023-01-23T00:00:00Z")},
+		{t: getTime("2023-01-29T23:59:59Z"), loc: time.UTC, want: getTime("2023-01-23T00:00:00Z")},
+		// Sunday in UTC is already Monday in Tokyo.
+		{t: getTime("2023-01-29T16:00:00Z"), loc: tokyo, want: getTime("2023-01-29T15:00:00Z")},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.t.String(), func(t *testing.T) {
+			t.Parallel()
+			got := getWeekStart(tt.t, tt.loc)
+			if !got.Equal(tt.want) {
+				t.Errorf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_buildHeatmap(t *testing.T) {
+	t.Parallel()
+	h, err := buildHeatmap(getHeatmapFixtures(), getTime("2023-01-24T10:00:00Z"), time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		day, hour int
+		want      int
+	}{
+		// 'every-6h' at 00:30, 'monthly-1st' is not counted.
+		{day: 0, hour: 0, want: 1},
+		{day: 0, hour: 9, want: 1},
+		// 'monthly-24th' on Tuesday.
+		{day: 1, hour: 12, want: 2},
+		{day: 2, hour: 12, want: 1},
+		{day: 5, hour: 9, want: 0},
+		{day: 6, hour: 22, want: 1},
+	}
+	for _, tt := range tests {
+		if got := h.Matrix[tt.day][tt.hour]; got != tt.want {
+			t.Errorf("%s %02d: want %d, got %d", heatmapDays[tt.day], tt.hour, tt.want, got)
+		}
+	}
+}
+
+func Test_printHeatmap(t *testing.T) {
+	t.Parallel()
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		golden string
+		output string
+		shade  bool
+		loc    *time.Location
+	}{
+		{golden: "heatmap.golden.txt", loc: time.UTC},
+		{golden: "heatmap.shade.golden.txt", shade: true, loc: time.UTC},
+		{golden: "heatmap.tokyo.golden.txt", loc: tokyo},
+		{golden: "heatmap.golden.json", output: "json", loc: time.UTC},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.golden, func(t *testing.T) {
+			t.Parallel()
+			h, err := buildHeatmap(getHeatmapFixtures(), getTime("2023-01-24T10:00:00Z"), tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got bytes.Buffer
+			if err := printHeatmap(&got, tt.output, false, tt.shade, h); err != nil {
+				t.Fatal(err)
+			}
+			assertGolden(t, tt.golden, got.Bytes())
+		})
+	}
+}
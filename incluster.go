@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+)
+
+// serviceAccountDir is where the token, the CA certificate and the namespace
+// of the service account are mounted in a pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// errNoConfig is returned when neither a kubeconfig nor the service account
+// of a pod is found, instead of falling back to localhost:8080.
+var errNoConfig = errors.New("no kubeconfig is found and not running in a cluster: set KUBECONFIG or '--kubeconfig', or run in a pod with a service account")
+
+// inClusterEnv is the environment of a pod the in-cluster configuration is
+// read from, replaced in the tests.
+type inClusterEnv struct {
+	// dir is the directory of the service account, serviceAccountDir.
+	dir    string
+	getenv func(string) string
+}
+
+// podEnv is the environment of the running process.
+var podEnv = inClusterEnv{dir: serviceAccountDir, getenv: os.Getenv}
+
+// Whether the process runs in a pod with a service account, the same as
+// rest.InClusterConfig requires.
+func (e inClusterEnv) possible() bool {
+	if e.getenv("KUBERNETES_SERVICE_HOST") == "" || e.getenv("KUBERNETES_SERVICE_PORT") == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(e.dir, "token"))
+	return err == nil
+}
+
+// Get the REST config of the service account like rest.InClusterConfig. The
+// token is read from the file again when it's rotated.
+func (e inClusterEnv) restConfig() (*rest.Config, error) {
+	host, port := e.getenv("KUBERNETES_SERVICE_HOST"), e.getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, errors.New("KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT must be defined in a cluster")
+	}
+	tokenFile := filepath.Join(e.dir, "token")
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the token of the service account: %w", err)
+	}
+	cfg := &rest.Config{
+		Host:            "https://" + net.JoinHostPort(host, port),
+		BearerToken:     string(token),
+		BearerTokenFile: tokenFile,
+	}
+	caFile := filepath.Join(e.dir, "ca.crt")
+	if _, err := os.Stat(caFile); err == nil {
+		cfg.TLSClientConfig.CAFile = caFile
+	}
+	return cfg, nil
+}
+
+// Get the namespace of the pod: POD_NAMESPACE, the namespace of the service
+// account, or "default" like client-go.
+func (e inClusterEnv) namespace() string {
+	if ns := e.getenv("POD_NAMESPACE"); ns != "" {
+		return ns
+	}
+	if b, err := os.ReadFile(filepath.Join(e.dir, "namespace")); err == nil {
+		if ns := strings.TrimSpace(string(b)); ns != "" {
+			return ns
+		}
+	}
+	return "default"
+}
+
+// Ignore the kubeconfig for '--in-cluster', so that the service account of
+// the pod is used by getRESTConfig even when a kubeconfig is found.
+func useInCluster(cfgFlags *genericclioptions.ConfigFlags, inCluster bool) error {
+	if !inCluster {
+		return nil
+	}
+	if cfgFlags.KubeConfig != nil && *cfgFlags.KubeConfig != "" {
+		return errors.New("'--in-cluster' can't be used with '--kubeconfig'")
+	}
+	if cfgFlags.APIServer != nil && *cfgFlags.APIServer != "" {
+		return errors.New("'--in-cluster' can't be used with '--server'")
+	}
+	empty := os.DevNull
+	cfgFlags.KubeConfig = &empty
+	return nil
+}
+
+// Whether a cluster is configured by the kubeconfig or '--server'. Without
+// them the loader of the flags silently falls back to localhost:8080.
+func hasKubeconfig(cfgFlags *genericclioptions.ConfigFlags) (bool, error) {
+	if cfgFlags.APIServer != nil && *cfgFlags.APIServer != "" {
+		return true, nil
+	}
+	raw, err := cfgFlags.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return false, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+	return len(raw.Clusters) > 0, nil
+}
+
+// Get the REST config shared by the kubernetes, the argo and the dynamic
+// clients: the kubeconfig and the flags, or the service account of the pod
+// when no kubeconfig is found or with '--in-cluster'.
+func getRESTConfig(cfgFlags *genericclioptions.ConfigFlags, env inClusterEnv) (*rest.Config, error) {
+	ok, err := hasKubeconfig(cfgFlags)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if !env.possible() {
+			return nil, errNoConfig
+		}
+		cfg, err := env.restConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the in-cluster configuration: %w", err)
+		}
+		return cfg, nil
+	}
+	cfg, err := cfgFlags.ToRESTConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes REST client configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// Get the namespace the namespaced objects without a namespace belong to:
+// '-n', the namespace of the context, or that of the pod in a cluster.
+func getDefaultNamespace(cfgFlags *genericclioptions.ConfigFlags, env inClusterEnv) (string, error) {
+	ok, err := hasKubeconfig(cfgFlags)
+	if err != nil {
+		return "", err
+	}
+	if !ok && env.possible() {
+		if cfgFlags.Namespace != nil && *cfgFlags.Namespace != "" {
+			return *cfgFlags.Namespace, nil
+		}
+		return env.namespace(), nil
+	}
+	namespace, _, err := cfgFlags.ToRawKubeConfigLoader().Namespace()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the namespace of the context: %w", err)
+	}
+	return namespace, nil
+}
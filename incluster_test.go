@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// Fake the environment of a pod with the files of the service account in a
+// temporary directory.
+func newFakePodEnv(t *testing.T, env map[string]string) inClusterEnv {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{"token": "fake-token", "namespace": "batch\n", "ca.crt": "fake-ca"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %s", name, err)
+		}
+	}
+	return inClusterEnv{dir: dir, getenv: func(key string) string { return env[key] }}
+}
+
+var fakeServiceEnv = map[string]string{"KUBERNETES_SERVICE_HOST": "10.96.0.1", "KUBERNETES_SERVICE_PORT": "443"}
+
+// Get the flags with the kubeconfig, which is empty when content is empty.
+func newKubeconfigFlags(t *testing.T, content string) *genericclioptions.ConfigFlags {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write the kubeconfig: %s", err)
+	}
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.KubeConfig = &path
+	return cfgFlags
+}
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: alice
+    namespace: ns-a
+current-context: dev
+users:
+- name: alice
+  user:
+    token: alice-token
+`
+
+func Test_inClusterEnv(t *testing.T) {
+	t.Parallel()
+	env := newFakePodEnv(t, fakeServiceEnv)
+	if !env.possible() {
+		t.Fatalf("want possible in the fake pod")
+	}
+	cfg, err := env.restConfig()
+	if err != nil {
+		t.Fatalf("restConfig() error = %v", err)
+	}
+	if cfg.Host != "https://10.96.0.1:443" || cfg.BearerToken != "fake-token" || cfg.BearerTokenFile != filepath.Join(env.dir, "token") {
+		t.Errorf("unexpected config: host %q, token %q, token file %q", cfg.Host, cfg.BearerToken, cfg.BearerTokenFile)
+	}
+	if cfg.TLSClientConfig.CAFile != filepath.Join(env.dir, "ca.crt") {
+		t.Errorf("want the CA of the service account, got %q", cfg.TLSClientConfig.CAFile)
+	}
+	if got := env.namespace(); got != "batch" {
+		t.Errorf("want the namespace of the service account, got %q", got)
+	}
+
+	withPodNamespace := newFakePodEnv(t, map[string]string{"POD_NAMESPACE": "ops"})
+	if got := withPodNamespace.namespace(); got != "ops" {
+		t.Errorf("want POD_NAMESPACE, got %q", got)
+	}
+	if withPodNamespace.possible() {
+		t.Errorf("want not possible without KUBERNETES_SERVICE_HOST")
+	}
+	withoutToken := inClusterEnv{dir: t.TempDir(), getenv: func(key string) string { return fakeServiceEnv[key] }}
+	if withoutToken.possible() {
+		t.Errorf("want not possible without the token")
+	}
+	if got := withoutToken.namespace(); got != "default" {
+		t.Errorf("want default without the namespace, got %q", got)
+	}
+}
+
+func Test_getRESTConfig(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		kubeconfig string
+		inCluster  bool
+		inPod      bool
+		wantHost   string
+		wantErr    error
+	}{
+		{
+			name:       "kubeconfig",
+			kubeconfig: testKubeconfig,
+			inPod:      true,
+			wantHost:   "https://dev.example.com",
+		},
+		{
+			name:     "no kubeconfig in a pod",
+			inPod:    true,
+			wantHost: "https://10.96.0.1:443",
+		},
+		{
+			name:    "no kubeconfig and not in a pod",
+			wantErr: errNoConfig,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			env := newFakePodEnv(t, nil)
+			if tt.inPod {
+				env = newFakePodEnv(t, fakeServiceEnv)
+			}
+			cfg, err := getRESTConfig(newKubeconfigFlags(t, tt.kubeconfig), env)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("want %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("getRESTConfig() error = %v", err)
+			}
+			if cfg.Host != tt.wantHost {
+				t.Errorf("want %q, got %q", tt.wantHost, cfg.Host)
+			}
+		})
+	}
+}
+
+func Test_useInCluster(t *testing.T) {
+	t.Parallel()
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	if err := useInCluster(cfgFlags, true); err != nil {
+		t.Fatalf("useInCluster() error = %v", err)
+	}
+	// The kubeconfig of KUBECONFIG or the home directory is ignored.
+	cfg, err := getRESTConfig(cfgFlags, newFakePodEnv(t, fakeServiceEnv))
+	if err != nil {
+		t.Fatalf("getRESTConfig() error = %v", err)
+	}
+	if cfg.Host != "https://10.96.0.1:443" {
+		t.Errorf("want the in-cluster host, got %q", cfg.Host)
+	}
+	if _, err := getRESTConfig(cfgFlags, newFakePodEnv(t, nil)); !errors.Is(err, errNoConfig) {
+		t.Errorf("want %v out of a pod, got %v", errNoConfig, err)
+	}
+
+	withKubeconfig := newKubeconfigFlags(t, testKubeconfig)
+	want := "'--in-cluster' can't be used with '--kubeconfig'"
+	if err := useInCluster(withKubeconfig, true); err == nil || err.Error() != want {
+		t.Errorf("want %q, got %v", want, err)
+	}
+}
+
+func Test_getDefaultNamespace(t *testing.T) {
+	t.Parallel()
+	namespace := "ns-b"
+	tests := []struct {
+		name       string
+		kubeconfig string
+		namespace  *string
+		inPod      bool
+		want       string
+	}{
+		{name: "context", kubeconfig: testKubeconfig, inPod: true, want: "ns-a"},
+		{name: "service account", inPod: true, want: "batch"},
+		{name: "flag in a pod", namespace: &namespace, inPod: true, want: "ns-b"},
+		{name: "neither", want: "default"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cfgFlags := newKubeconfigFlags(t, tt.kubeconfig)
+			if tt.namespace != nil {
+				cfgFlags.Namespace = tt.namespace
+			}
+			env := newFakePodEnv(t, nil)
+			if tt.inPod {
+				env = newFakePodEnv(t, fakeServiceEnv)
+			}
+			got, err := getDefaultNamespace(cfgFlags, env)
+			if err != nil {
+				t.Fatalf("getDefaultNamespace() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"sort"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/robfig/cron/v3"
+	"github.com/unblee/kubectl-cls/pkg/cls"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// item is either a CronJob, a CronWorkflow, or an object listed by a
+// '--provider', so all the kinds can be handled in a single sorted listing.
+type item struct {
+	cronJob      *batchv1.CronJob
+	cronWorkflow *wfv1alpha1.CronWorkflow
+	provided     *providedObject
+}
+
+func (i item) kind() string {
+	switch {
+	case i.cronJob != nil:
+		return "CronJob"
+	case i.provided != nil:
+		return i.provided.kind
+	}
+	return "CronWorkflow"
+}
+
+func (i item) object() metav1.Object {
+	switch {
+	case i.cronJob != nil:
+		return i.cronJob
+	case i.provided != nil:
+		return i.provided
+	}
+	return i.cronWorkflow
+}
+
+func (i item) schedule() string {
+	switch {
+	case i.cronJob != nil:
+		return i.cronJob.Spec.Schedule
+	case i.provided != nil:
+		return i.provided.schedules[0]
+	}
+	return i.cronWorkflow.Spec.Schedule
+}
+
+// Get all the schedules of the item. Only the items of the providers and the
+// custom resources may have multiple schedules.
+func (i item) schedules() []string {
+	if i.provided != nil {
+		return i.provided.schedules
+	}
+	return []string{i.schedule()}
+}
+
+// Get the names of the schedules in the order of schedules, e.g. the sessions
+// of a KubeStash BackupConfiguration, or nil when they are unnamed.
+func (i item) scheduleNames() []string {
+	if i.provided != nil {
+		return i.provided.scheduleNames
+	}
+	return nil
+}
+
+// Get the index of the first schedule firing in the from-to period, or 0 when
+// none of them does. The matched schedule is shown first, and the others keep
+// their order, see moveToFront.
+func (i item) matchedScheduleIndex(from, to time.Time, bounds boundaries) int {
+	window := bounds.window(from, to)
+	for j, schedule := range i.schedules() {
+		sched, err := cron.ParseStandard(schedule)
+		if err == nil && window.Includes(sched) {
+			return j
+		}
+	}
+	return 0
+}
+
+// Get a copy of the values with the j-th one moved to the front. A nil values
+// stays nil.
+func moveToFront(values []string, j int) []string {
+	if values == nil {
+		return nil
+	}
+	ret := make([]string, 0, len(values))
+	ret = append(ret, values[j])
+	ret = append(ret, values[:j]...)
+	return append(ret, values[j+1:]...)
+}
+
+func (i item) suspended() bool {
+	switch {
+	case i.cronJob != nil:
+		return i.cronJob.Spec.Suspend != nil && *i.cronJob.Spec.Suspend
+	case i.provided != nil:
+		return i.provided.suspend
+	}
+	return i.cronWorkflow.Spec.Suspend
+}
+
+// Get the item as a Scheduled of the library.
+func (i item) scheduled() cls.Scheduled {
+	switch {
+	case i.cronJob != nil:
+		return cls.CronJob{CronJob: i.cronJob}
+	case i.provided != nil:
+		return i.provided
+	}
+	return cls.CronWorkflow{CronWorkflow: i.cronWorkflow}
+}
+
+// Get the items of the Scheduled of the built-in kinds and of the providers.
+// The other kinds are never listed by the CLI.
+func scheduledItems(scheduled []cls.Scheduled) []item {
+	items := make([]item, 0, len(scheduled))
+	for _, s := range scheduled {
+		switch s := s.(type) {
+		case cls.CronJob:
+			items = append(items, item{cronJob: s.CronJob})
+		case cls.CronWorkflow:
+			items = append(items, item{cronWorkflow: s.CronWorkflow})
+		case *providedObject:
+			items = append(items, item{provided: s})
+		}
+	}
+	return items
+}
+
+// Merge CronJobs and CronWorkflows into a single slice sorted by namespace,
+// then name, then kind.
+func mergeItems(cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) []item {
+	items := make([]item, 0, len(cronjobs)+len(cronworkflows))
+	for i := range cronjobs {
+		items = append(items, item{cronJob: &cronjobs[i]})
+	}
+	for i := range cronworkflows {
+		items = append(items, item{cronWorkflow: &cronworkflows[i]})
+	}
+	sortItems(items)
+	return items
+}
+
+func sortItems(items []item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].object(), items[j].object()
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		if a.GetName() != b.GetName() {
+			return a.GetName() < b.GetName()
+		}
+		return items[i].kind() < items[j].kind()
+	})
+}
+
+// Get the time zone the schedule is evaluated in by the controller, or "" when
+// unset.
+func (i item) timezone() string {
+	switch {
+	case i.cronJob != nil:
+		if i.cronJob.Spec.TimeZone != nil {
+			return *i.cronJob.Spec.TimeZone
+		}
+		return ""
+	case i.provided != nil:
+		return i.provided.timezone
+	}
+	return i.cronWorkflow.Spec.Timezone
+}
+
+// Get the last time the item was scheduled from the status, or nil when it
+// has never been scheduled or the kind has no status.
+func (i item) lastScheduleTime() *metav1.Time {
+	switch {
+	case i.cronJob != nil:
+		return i.cronJob.Status.LastScheduleTime
+	case i.provided != nil:
+		return nil
+	}
+	return i.cronWorkflow.Status.LastScheduledTime
+}
+
+// Get the concurrencyPolicy, where an empty policy is "Allow".
+func (i item) concurrencyPolicy() string {
+	switch {
+	case i.cronJob != nil:
+		return formatConcurrencyPolicy(string(i.cronJob.Spec.ConcurrencyPolicy))
+	case i.provided != nil:
+		return formatConcurrencyPolicy("")
+	}
+	return formatConcurrencyPolicy(string(i.cronWorkflow.Spec.ConcurrencyPolicy))
+}
+
+// Get the startingDeadlineSeconds, or nil when it is unset or the kind has
+// none.
+func (i item) startingDeadlineSeconds() *int64 {
+	switch {
+	case i.cronJob != nil:
+		return i.cronJob.Spec.StartingDeadlineSeconds
+	case i.provided != nil:
+		return nil
+	}
+	return i.cronWorkflow.Spec.StartingDeadlineSeconds
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_mergeItems(t *testing.T) {
+	t.Parallel()
+	got := mergeItems(
+		[]batchv1.CronJob{
+			getCronJob("ns-b", "backup", "0 1 * * *", false),
+			getCronJob("ns-a", "report", "0 1 * * *", false),
+			getCronJob("ns-a", "cleanup", "0 1 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("ns-a", "etl", "0 1 * * *", false),
+			getCronWorkflow("ns-b", "backup", "0 1 * * *", false),
+			getCronWorkflow("ns-a", "aggregate", "0 1 * * *", false),
+		},
+	)
+	want := []string{
+		"CronWorkflow/ns-a/aggregate",
+		"CronJob/ns-a/cleanup",
+		"CronWorkflow/ns-a/etl",
+		"CronJob/ns-a/report",
+		"CronJob/ns-b/backup", // kind is the final tiebreaker
+		"CronWorkflow/ns-b/backup",
+	}
+	if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+		t.Errorf("mergeItems() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_printList_interleave(t *testing.T) {
+	t.Parallel()
+	items := mergeItems(
+		[]batchv1.CronJob{
+			getCronJob("ns-a", "report", "0 1 * * *", false),
+			getCronJob("ns-b", "backup", "0 2 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("ns-a", "etl", "30 0 * * *", false),
+			getCronWorkflow("ns-b", "backup", "0 3 * * *", true),
+		},
+	)
+
+	var table bytes.Buffer
+	printList(&table, printListOptions{}, items)
+	assertGolden(t, "interleave.golden.txt", table.Bytes())
+
+	var document bytes.Buffer
+	if err := printJSON(&document, items, documentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "interleave.golden.json", document.Bytes())
+}
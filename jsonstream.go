@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+const jsonIndent = "    "
+
+// documentEncoder writes a JSON object field by field, encoding the elements
+// of the arrays one at a time. The output is byte-compatible with
+// json.MarshalIndent(v, "", "    "), or with json.Marshal when compact.
+//
+// The first error is kept in err and the later writes are no-ops, the same
+// as bufio.Writer.
+type documentEncoder struct {
+	w       io.Writer
+	compact bool
+	buf     bytes.Buffer
+	fields  int
+	err     error
+}
+
+func newDocumentEncoder(w io.Writer, compact bool) *documentEncoder {
+	return &documentEncoder{w: w, compact: compact}
+}
+
+func (e *documentEncoder) open() {
+	e.write("{")
+}
+
+func (e *documentEncoder) close() {
+	if !e.compact && e.fields > 0 {
+		e.write("\n")
+	}
+	e.write("}")
+}
+
+// field writes a field whose value is encoded at once.
+func (e *documentEncoder) field(name string, v any) {
+	e.key(name)
+	e.value(v, jsonIndent)
+}
+
+// array writes a field whose value is an array of n elements, and element
+// returns the i-th element right before it is encoded.
+func (e *documentEncoder) array(name string, n int, element func(i int) any) {
+	e.key(name)
+	if n == 0 {
+		e.write("[]")
+		return
+	}
+	e.write("[")
+	for i := 0; i < n && e.err == nil; i++ {
+		if i > 0 {
+			e.write(",")
+		}
+		if !e.compact {
+			e.write("\n" + jsonIndent + jsonIndent)
+		}
+		e.value(element(i), jsonIndent+jsonIndent)
+	}
+	if !e.compact {
+		e.write("\n" + jsonIndent)
+	}
+	e.write("]")
+}
+
+func (e *documentEncoder) key(name string) {
+	if e.fields > 0 {
+		e.write(",")
+	}
+	e.fields++
+	if !e.compact {
+		e.write("\n" + jsonIndent)
+	}
+	e.value(name, "")
+	if e.compact {
+		e.write(":")
+	} else {
+		e.write(": ")
+	}
+}
+
+// value encodes v whose first line is already indented by prefix.
+func (e *documentEncoder) value(v any, prefix string) {
+	if e.err != nil {
+		return
+	}
+	e.buf.Reset()
+	enc := json.NewEncoder(&e.buf)
+	if !e.compact {
+		enc.SetIndent(prefix, jsonIndent)
+	}
+	if err := enc.Encode(v); err != nil {
+		e.err = err
+		return
+	}
+	// Drop the newline json.Encoder terminates each value with.
+	_, e.err = e.w.Write(bytes.TrimSuffix(e.buf.Bytes(), []byte("\n")))
+}
+
+func (e *documentEncoder) write(s string) {
+	if e.err != nil {
+		return
+	}
+	_, e.err = io.WriteString(e.w, s)
+}
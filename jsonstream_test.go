@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_printJSON_streaming(t *testing.T) {
+	t.Parallel()
+	window := &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-25T00:00:00Z"), Round: "1h"}
+	tests := []struct {
+		name  string
+		items []item
+		opts  documentOptions
+	}{
+		{
+			name:  "no items",
+			items: nil,
+			opts:  documentOptions{},
+		},
+		{
+			name:  "no items with window",
+			items: nil,
+			opts:  documentOptions{window: window},
+		},
+		{
+			name:  "items with window",
+			items: getNoisyItems(),
+			opts:  documentOptions{window: window},
+		},
+		{
+			name:  "raw items",
+			items: getNoisyItems(),
+			opts:  documentOptions{raw: true},
+		},
+		{
+			name:  "compact",
+			items: getNoisyItems(),
+			opts:  documentOptions{window: window, compact: true},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			pf := buildPrintformat(tt.items, tt.opts)
+			var want []byte
+			var err error
+			if tt.opts.compact {
+				want, err = json.Marshal(pf)
+			} else {
+				want, err = json.MarshalIndent(pf, "", "    ")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got bytes.Buffer
+			if err := printJSON(&got, tt.items, tt.opts); err != nil {
+				t.Fatalf("printJSON() error = %v", err)
+			}
+			if diff := cmp.Diff(string(want), got.String()); diff != "" {
+				t.Errorf("printJSON() is not byte-compatible (-want +got):\n%s", diff)
+			}
+
+			var wantDoc, gotDoc map[string]any
+			if err := json.Unmarshal(want, &wantDoc); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(got.Bytes(), &gotDoc); err != nil {
+				t.Fatalf("failed to unmarshal the streamed document: %s", err)
+			}
+			if diff := cmp.Diff(wantDoc, gotDoc); diff != "" {
+				t.Errorf("printJSON() structure mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func getManyItems(n int) []item {
+	cronjobs := make([]batchv1.CronJob, n/2)
+	for i := range cronjobs {
+		cronjobs[i] = getCronJob("ns-a", fmt.Sprintf("cj-%05d", i), "*/5 * * * *", false)
+	}
+	cronworkflows := make([]wfv1alpha1.CronWorkflow, n-n/2)
+	for i := range cronworkflows {
+		cronworkflows[i] = getCronWorkflow("ns-b", fmt.Sprintf("cwf-%05d", i), "0 * * * *", false)
+	}
+	return mergeItems(cronjobs, cronworkflows)
+}
+
+func Benchmark_printJSON(b *testing.B) {
+	items := getManyItems(30000)
+	window := &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T01:00:00Z")}
+	for _, compact := range []bool{false, true} {
+		compact := compact
+		b.Run(fmt.Sprintf("compact=%t", compact), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := printJSON(io.Discard, items, documentOptions{window: window, compact: compact}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
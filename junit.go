@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+// junitTestSuites is the document of '-o junit'. The matched items are failed
+// test cases, so that a CI gate shows what fires in the window, and the items
+// checked by '--names-from' but not matched are passed test cases.
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// itemKey identifies an item by its kind, namespace and name.
+type itemKey struct {
+	kind, namespace, name string
+}
+
+func getItemKey(item item) itemKey {
+	obj := item.object()
+	return itemKey{kind: item.kind(), namespace: obj.GetNamespace(), name: obj.GetName()}
+}
+
+// Build the JUnit document of the matched items, and of the checked items
+// which are not matched. A nil checked means only the matched items.
+func buildJUnit(items, checked []item, from, to time.Time, bounds boundaries) junitTestSuites {
+	suite := junitTestSuite{
+		Name: commandName,
+		Properties: []junitProperty{
+			{Name: "from", Value: from.Format(time.RFC3339)},
+			{Name: "to", Value: to.Format(time.RFC3339)},
+		},
+		Cases: []junitTestCase{},
+	}
+	matched := map[itemKey]bool{}
+	for _, item := range items {
+		matched[getItemKey(item)] = true
+		obj := item.object()
+		message := fmt.Sprintf("%s '%s/%s' fires in the window", item.kind(), obj.GetNamespace(), obj.GetName())
+		if first := getFireRange(item, from, to, bounds).first; first != nil {
+			message = fmt.Sprintf("%s '%s/%s' fires at %s in the window", item.kind(), obj.GetNamespace(), obj.GetName(), first.UTC().Format(time.RFC3339))
+		}
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      obj.GetNamespace() + "/" + obj.GetName(),
+			ClassName: item.kind(),
+			Failure: &junitFailure{
+				Message: message,
+				Type:    "scheduled",
+				Text:    fmt.Sprintf("schedule: %s, suspend: %t", item.schedule(), item.suspended()),
+			},
+		})
+		suite.Failures++
+	}
+	for _, item := range checked {
+		if matched[getItemKey(item)] {
+			continue
+		}
+		obj := item.object()
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      obj.GetNamespace() + "/" + obj.GetName(),
+			ClassName: item.kind(),
+		})
+	}
+	suite.Tests = len(suite.Cases)
+	return junitTestSuites{Suites: []junitTestSuite{suite}}
+}
+
+func printJUnit(stdout io.Writer, items, checked []item, from, to time.Time, bounds boundaries) error {
+	b, err := xml.MarshalIndent(buildJUnit(items, checked, from, to, bounds), "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal to junit: %w", err)
+	}
+	fmt.Fprint(stdout, xml.Header)
+	fmt.Fprintln(stdout, string(b))
+	return nil
+}
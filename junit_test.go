@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_printJUnit(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	matched := mergeItems(
+		[]batchv1.CronJob{getCronJob("ns-a", "backup", "0 1 * * *", false)},
+		[]wfv1alpha1.CronWorkflow{getCronWorkflow("ns-b", "report<&>", "30 2 * * *", true)},
+	)
+	checked := mergeItems(
+		[]batchv1.CronJob{
+			getCronJob("ns-a", "backup", "0 1 * * *", false),
+			getCronJob("ns-a", "nightly", "0 12 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{getCronWorkflow("ns-b", "report<&>", "30 2 * * *", true)},
+	)
+
+	var got bytes.Buffer
+	if err := printJUnit(&got, matched, checked, from, to, boundaries{}); err != nil {
+		t.Fatalf("printJUnit() error = %v", err)
+	}
+	assertGolden(t, "junit.golden.xml", got.Bytes())
+
+	// The shape read by the CI systems.
+	var doc struct {
+		XMLName xml.Name `xml:"testsuites"`
+		Suites  []struct {
+			Tests      int `xml:"tests,attr"`
+			Failures   int `xml:"failures,attr"`
+			Properties []struct {
+				Name  string `xml:"name,attr"`
+				Value string `xml:"value,attr"`
+			} `xml:"properties>property"`
+			Cases []struct {
+				Name    string `xml:"name,attr"`
+				Failure *struct {
+					Message string `xml:"message,attr"`
+				} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	if err := xml.Unmarshal(got.Bytes(), &doc); err != nil {
+		t.Fatalf("failed to decode the document: %v", err)
+	}
+	if len(doc.Suites) != 1 {
+		t.Fatalf("want a testsuite, got %d", len(doc.Suites))
+	}
+	suite := doc.Suites[0]
+	if suite.Tests != 3 || suite.Failures != 2 || len(suite.Cases) != 3 {
+		t.Errorf("want 3 tests with 2 failures, got tests=%d failures=%d cases=%d", suite.Tests, suite.Failures, len(suite.Cases))
+	}
+	properties := map[string]string{}
+	for _, p := range suite.Properties {
+		properties[p.Name] = p.Value
+	}
+	if diff := cmp.Diff(map[string]string{"from": "2023-01-24T00:00:00Z", "to": "2023-01-24T06:00:00Z"}, properties); diff != "" {
+		t.Errorf("properties mismatch (-want +got):\n%s", diff)
+	}
+	if c := suite.Cases[0]; c.Name != "ns-a/backup" || c.Failure == nil || !strings.Contains(c.Failure.Message, "2023-01-24T01:00:00Z") {
+		t.Errorf("want the failure of ns-a/backup with the first fire, got %+v", c)
+	}
+	if c := suite.Cases[2]; c.Name != "ns-a/nightly" || c.Failure != nil {
+		t.Errorf("want the passed case of ns-a/nightly, got %+v", c)
+	}
+}
+
+func Test_run_junit_namesFrom(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "names")
+	if err := os.WriteFile(path, []byte("ns-a/backup\nns-b/nightly\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := runFake(newRunClients(), append([]string{"-o", "junit", "--names-from", path}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	for _, want := range []string{
+		`<testsuite name="kubectl-cls" tests="2" failures="1" errors="0" skipped="0">`,
+		`<testcase name="ns-a/backup" classname="CronJob">`,
+		`<testcase name="ns-b/nightly" classname="CronJob"></testcase>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %s in the output:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+)
+
+// The clusters before v1.21 only serve the CronJobs in batch/v1beta1. They are
+// listed with the batch/v1beta1 client and converted into the batch/v1 types,
+// so that the rest of the code only handles batch/v1.
+
+// Detect whether the CronJobs have to be listed in batch/v1beta1, which is only
+// the case when batch/v1 doesn't serve them but batch/v1beta1 does. When
+// neither does, batch/v1 is used so that the listing reports the error.
+func detectLegacyCronJobs(d discovery.DiscoveryInterface) (bool, error) {
+	served, err := servesCronJobs(d, batchv1.SchemeGroupVersion.String())
+	if err != nil || served {
+		return false, err
+	}
+	return servesCronJobs(d, batchv1beta1.SchemeGroupVersion.String())
+}
+
+func servesCronJobs(d discovery.DiscoveryInterface, groupVersion string) (bool, error) {
+	return servesResource(d, groupVersion, "cronjobs")
+}
+
+// Whether the group version serves the resource. A group version which is not
+// served at all serves nothing.
+func servesResource(d discovery.DiscoveryInterface, groupVersion, resource string) (bool, error) {
+	resources, err := d.ServerResourcesForGroupVersion(groupVersion)
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to discover the resources of %s: %w", groupVersion, err)
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Get the API version the CronJobs are listed in.
+func (c *clients) cronJobVersion() string {
+	if c.legacyCronJobs {
+		return batchv1beta1.SchemeGroupVersion.String()
+	}
+	return batchv1.SchemeGroupVersion.String()
+}
+
+func listLegacyCronJobs(ctx context.Context, c *clients, namespace, selector string) ([]batchv1.CronJob, error) {
+	list, err := c.k8s.BatchV1beta1().CronJobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	cronjobs := make([]batchv1.CronJob, len(list.Items))
+	for i := range list.Items {
+		cronjobs[i] = convertLegacyCronJob(&list.Items[i])
+	}
+	return cronjobs, nil
+}
+
+// Get the CronJob of the name in the API version of c.
+func getCronJobByName(ctx context.Context, c *clients, namespace, name string) (*batchv1.CronJob, error) {
+	if !c.legacyCronJobs {
+		return c.k8s.BatchV1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	}
+	legacy, err := c.k8s.BatchV1beta1().CronJobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	cronjob := convertLegacyCronJob(legacy)
+	return &cronjob, nil
+}
+
+// Patch the CronJob in the API version of c. The patched fields are the same
+// in both versions.
+func patchCronJob(ctx context.Context, c *clients, namespace, name string, data []byte, opts metav1.PatchOptions) error {
+	var err error
+	if c.legacyCronJobs {
+		_, err = c.k8s.BatchV1beta1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, data, opts)
+	} else {
+		_, err = c.k8s.BatchV1().CronJobs(namespace).Patch(ctx, name, types.MergePatchType, data, opts)
+	}
+	return err
+}
+
+// Convert the batch/v1beta1 CronJob into batch/v1. The TypeMeta keeps
+// batch/v1beta1, so that the output documents show the served version.
+func convertLegacyCronJob(in *batchv1beta1.CronJob) batchv1.CronJob {
+	c := in.DeepCopy()
+	return batchv1.CronJob{
+		TypeMeta:   metav1.TypeMeta{APIVersion: batchv1beta1.SchemeGroupVersion.String(), Kind: "CronJob"},
+		ObjectMeta: c.ObjectMeta,
+		Spec: batchv1.CronJobSpec{
+			Schedule:                c.Spec.Schedule,
+			TimeZone:                c.Spec.TimeZone,
+			StartingDeadlineSeconds: c.Spec.StartingDeadlineSeconds,
+			ConcurrencyPolicy:       batchv1.ConcurrencyPolicy(c.Spec.ConcurrencyPolicy),
+			Suspend:                 c.Spec.Suspend,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: c.Spec.JobTemplate.ObjectMeta,
+				Spec:       c.Spec.JobTemplate.Spec,
+			},
+			SuccessfulJobsHistoryLimit: c.Spec.SuccessfulJobsHistoryLimit,
+			FailedJobsHistoryLimit:     c.Spec.FailedJobsHistoryLimit,
+		},
+		Status: batchv1.CronJobStatus{
+			Active:             c.Status.Active,
+			LastScheduleTime:   c.Status.LastScheduleTime,
+			LastSuccessfulTime: c.Status.LastSuccessfulTime,
+		},
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_detectLegacyCronJobs(t *testing.T) {
+	t.Parallel()
+	jobs := metav1.APIResource{Name: "jobs", Kind: "Job"}
+	cronjobs := metav1.APIResource{Name: "cronjobs", Kind: "CronJob"}
+	tests := []struct {
+		name      string
+		resources []*metav1.APIResourceList
+		want      bool
+	}{
+		{
+			name: "batch/v1",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{jobs, cronjobs}},
+				{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{cronjobs}},
+			},
+			want: false,
+		},
+		{
+			name: "only batch/v1beta1",
+			resources: []*metav1.APIResourceList{
+				{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{jobs}},
+				{GroupVersion: "batch/v1beta1", APIResources: []metav1.APIResource{cronjobs}},
+			},
+			want: true,
+		},
+		{
+			name:      "neither",
+			resources: []*metav1.APIResourceList{{GroupVersion: "batch/v1", APIResources: []metav1.APIResource{jobs}}},
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d := k8sfake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+			d.Resources = tt.resources
+			got, err := detectLegacyCronJobs(d)
+			if err != nil {
+				t.Fatalf("detectLegacyCronJobs() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("detectLegacyCronJobs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_listCronJobs_legacy(t *testing.T) {
+	t.Parallel()
+	suspend := true
+	legacy := &batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "legacy"},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule:          "0 1 * * *",
+			ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+			Suspend:           &suspend,
+		},
+	}
+	current := getCronJob("ns-a", "current", "0 2 * * *", false)
+	newLegacyClients := func(legacyCronJobs bool) *clients {
+		return &clients{
+			k8s:            k8sfake.NewSimpleClientset(legacy, &current),
+			argo:           argofake.NewSimpleClientset().ArgoprojV1alpha1(),
+			legacyCronJobs: legacyCronJobs,
+		}
+	}
+
+	t.Run("batch/v1beta1", func(t *testing.T) {
+		t.Parallel()
+		c := newLegacyClients(true)
+		got, err := listCronJobs(context.Background(), c, "", "")
+		if err != nil {
+			t.Fatalf("listCronJobs() error = %v", err)
+		}
+		want := []batchv1.CronJob{{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "batch/v1beta1", Kind: "CronJob"},
+			ObjectMeta: legacy.ObjectMeta,
+			Spec: batchv1.CronJobSpec{
+				Schedule:          "0 1 * * *",
+				ConcurrencyPolicy: batchv1.ForbidConcurrent,
+				Suspend:           &suspend,
+			},
+		}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+
+		named, err := getCronJobByName(context.Background(), c, "ns-a", "legacy")
+		if err != nil {
+			t.Fatalf("getCronJobByName() error = %v", err)
+		}
+		if diff := cmp.Diff(want[0], *named); diff != "" {
+			t.Errorf("getCronJobByName() (-want +got):\n%s", diff)
+		}
+
+		// The document shows the served version.
+		var doc bytes.Buffer
+		items := mergeItems(got, nil)
+		if err := printJSON(&doc, items, documentOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		var parsed struct {
+			Items []metav1.TypeMeta `json:"items"`
+		}
+		if err := json.Unmarshal(doc.Bytes(), &parsed); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]metav1.TypeMeta{{APIVersion: "batch/v1beta1", Kind: "CronJob"}}, parsed.Items); diff != "" {
+			t.Errorf("TypeMeta (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("batch/v1", func(t *testing.T) {
+		t.Parallel()
+		got, err := listCronJobs(context.Background(), newLegacyClients(false), "", "")
+		if err != nil {
+			t.Fatalf("listCronJobs() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"CronJob/ns-a/current"}, getItemNames(mergeItems(got, nil))); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Keep only items with any container lacking the cpu or memory limit, see
+// getCronJobMissingLimits and getCronWorkflowMissingLimits. The init
+// containers are checked with includeInit.
+func missingLimitsFilter(includeInit bool) filter {
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return len(getCronJobMissingLimits(cronjob, includeInit)) > 0
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return len(getCronWorkflowMissingLimits(cronworkflow, includeInit)) > 0
+		},
+	}
+}
+
+// Get the names of the containers in the Job template lacking the cpu or
+// memory limit.
+func getCronJobMissingLimits(cronjob *batchv1.CronJob, includeInit bool) []string {
+	spec := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	names := []string{}
+	if includeInit {
+		names = append(names, missingLimits("", spec.InitContainers)...)
+	}
+	return append(names, missingLimits("", spec.Containers)...)
+}
+
+// Get the names of the containers of the templates of the inline workflow
+// spec lacking the cpu or memory limit, as 'template/container'. The sidecars
+// run alongside the containers, so they are checked too. A
+// workflowTemplateRef is not resolved and has none.
+func getCronWorkflowMissingLimits(cronworkflow *wfv1alpha1.CronWorkflow, includeInit bool) []string {
+	names := []string{}
+	for _, tmpl := range cronworkflow.Spec.WorkflowSpec.Templates {
+		containers := []corev1.Container{}
+		if includeInit {
+			for _, c := range tmpl.InitContainers {
+				containers = append(containers, c.Container)
+			}
+		}
+		if tmpl.Container != nil {
+			containers = append(containers, *tmpl.Container)
+		}
+		if tmpl.Script != nil {
+			containers = append(containers, tmpl.Script.Container)
+		}
+		if tmpl.ContainerSet != nil {
+			for _, c := range tmpl.ContainerSet.Containers {
+				containers = append(containers, c.Container)
+			}
+		}
+		for _, c := range tmpl.Sidecars {
+			containers = append(containers, c.Container)
+		}
+		names = append(names, missingLimits(tmpl.Name+"/", containers)...)
+	}
+	return names
+}
+
+func missingLimits(prefix string, containers []corev1.Container) []string {
+	names := []string{}
+	for _, c := range containers {
+		_, cpu := c.Resources.Limits[corev1.ResourceCPU]
+		_, memory := c.Resources.Limits[corev1.ResourceMemory]
+		if !cpu || !memory {
+			names = append(names, prefix+c.Name)
+		}
+	}
+	return names
+}
+
+// Format the containers lacking the limits in the wide output, "<none>" when
+// the item has none or is not a CronJob or a CronWorkflow.
+func formatMissingLimits(item item, includeInit bool) string {
+	var names []string
+	switch {
+	case item.cronJob != nil:
+		names = getCronJobMissingLimits(item.cronJob, includeInit)
+	case item.cronWorkflow != nil:
+		names = getCronWorkflowMissingLimits(item.cronWorkflow, includeInit)
+	}
+	if len(names) == 0 {
+		return "<none>"
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func Test_missingLimitsFilter(t *testing.T) {
+	t.Parallel()
+	container := func(name, cpu, memory string) corev1.Container {
+		limits := corev1.ResourceList{}
+		if cpu != "" {
+			limits[corev1.ResourceCPU] = resource.MustParse(cpu)
+		}
+		if memory != "" {
+			limits[corev1.ResourceMemory] = resource.MustParse(memory)
+		}
+		return corev1.Container{Name: name, Resources: corev1.ResourceRequirements{Limits: limits}}
+	}
+
+	compliant := getCronJob("ns-a", "compliant", "0 0 * * *", false)
+	compliant.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{container("app", "1", "1Gi")}
+	// Only the init container lacks the limits.
+	initOnly := getCronJob("ns-a", "init-only", "0 0 * * *", false)
+	initOnly.Spec.JobTemplate.Spec.Template.Spec.InitContainers = []corev1.Container{container("setup", "", "")}
+	initOnly.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{container("app", "1", "1Gi")}
+	partial := getCronJob("ns-a", "partial", "0 0 * * *", false)
+	partial.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{container("app", "1", "1Gi"), container("sidecar", "500m", "")}
+	missing := getCronJob("ns-a", "missing", "0 0 * * *", false)
+	missing.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{container("app", "", ""), container("sidecar", "", "")}
+
+	cwCompliant := getCronWorkflow("ns-b", "compliant", "0 0 * * *", false)
+	cwCompliant.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "main", Container: ptrContainer(container("main", "1", "1Gi"))},
+	}
+	cwPartial := getCronWorkflow("ns-b", "partial", "0 0 * * *", false)
+	cwPartial.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "build", Container: ptrContainer(container("main", "1", "1Gi"))},
+		{Name: "test", Script: &wfv1alpha1.ScriptTemplate{Container: container("main", "", "1Gi")}},
+	}
+	cwInit := getCronWorkflow("ns-b", "init", "0 0 * * *", false)
+	cwInit.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{
+			Name:           "main",
+			Container:      ptrContainer(container("main", "1", "1Gi")),
+			InitContainers: []wfv1alpha1.UserContainer{{Container: container("fetch", "", "")}},
+		},
+	}
+	items := mergeItems(
+		[]batchv1.CronJob{compliant, initOnly, partial, missing},
+		[]wfv1alpha1.CronWorkflow{cwCompliant, cwPartial, cwInit},
+	)
+
+	tests := []struct {
+		name        string
+		includeInit bool
+		want        []string
+		wantColumns []string
+	}{
+		{
+			name:        "containers",
+			want:        []string{"CronJob/ns-a/missing", "CronJob/ns-a/partial", "CronWorkflow/ns-b/partial"},
+			wantColumns: []string{"app,sidecar", "sidecar", "test/main"},
+		},
+		{
+			name:        "init containers",
+			includeInit: true,
+			want:        []string{"CronJob/ns-a/init-only", "CronJob/ns-a/missing", "CronJob/ns-a/partial", "CronWorkflow/ns-b/init", "CronWorkflow/ns-b/partial"},
+			wantColumns: []string{"setup", "app,sidecar", "sidecar", "main/fetch", "test/main"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters([]filter{missingLimitsFilter(tt.includeInit)}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			columns := []string{}
+			for _, item := range got {
+				columns = append(columns, formatMissingLimits(item, tt.includeInit))
+			}
+			if diff := cmp.Diff(tt.wantColumns, columns); diff != "" {
+				t.Errorf("columns (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_missingLimits(t *testing.T) {
+	t.Parallel()
+	_, _, err := runFake(newRunClients(), append(runPeriod, "--missing-limits-include-init")...)
+	if err == nil || err.Error() != "'--missing-limits-include-init' requires '--missing-limits'" {
+		t.Errorf("error = %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/exp/slog"
+)
+
+// The formats of '--log-format'.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+func validateLogFormat(format string) error {
+	switch format {
+	case logFormatText, logFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("'--log-format' must be '%s' or '%s', got '%s'", logFormatText, logFormatJSON, format)
+}
+
+// Get the logger of the diagnostics written into stderr, such as the warnings
+// and the retries, which never go into stdout. The debug records are written
+// only with verbose. The text format writes the message alone, prefixed with
+// "warning: " for the warnings, as the attributes are in the message already.
+func newLogger(stderr io.Writer, format string, verbose bool) *slog.Logger {
+	return newLeveledLogger(stderr, format, logLevel(verbose, false))
+}
+
+// Get the level of the logger: the debug records with '--verbose', and only
+// the errors with '--quiet'.
+func logLevel(verbose, quiet bool) slog.Level {
+	switch {
+	case verbose:
+		return slog.LevelDebug
+	case quiet:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// Get the logger of newLogger writing the records of the level and above.
+func newLeveledLogger(stderr io.Writer, format string, level slog.Level) *slog.Logger {
+	if format == logFormatJSON {
+		return slog.New(slog.HandlerOptions{Level: level}.NewJSONHandler(stderr))
+	}
+	return slog.New(&textHandler{mu: &sync.Mutex{}, w: stderr, level: level})
+}
+
+// textHandler writes the records as the plain lines, see newLogger.
+type textHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	level slog.Level
+}
+
+func (h *textHandler) Enabled(level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *textHandler) Handle(r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r.Level == slog.LevelWarn {
+		_, err := fmt.Fprintf(h.w, "warning: %s\n", r.Message)
+		return err
+	}
+	_, err := fmt.Fprintln(h.w, r.Message)
+	return err
+}
+
+func (h *textHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *textHandler) WithGroup(string) slog.Handler { return h }
+
+// loggedError is the error of a command already logged with '--log-format
+// json', which main doesn't print again.
+type loggedError struct {
+	error
+}
+
+func (e loggedError) Unwrap() error { return e.error }
+
+// Log the error of the command as an error record with '--log-format json',
+// so that stderr is only JSON lines. Otherwise the error is returned as is and
+// main prints it.
+func logCommandError(logger *slog.Logger, format string, err error) error {
+	if err == nil || format != logFormatJSON {
+		return err
+	}
+	logger.Error(err.Error(), nil)
+	return loggedError{err}
+}
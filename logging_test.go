@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_newLogger_text(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		verbose bool
+		want    string
+	}{
+		{
+			name: "default",
+			want: "listening on :8080\nwarning: skipped 1 namespaces\n",
+		},
+		{
+			name:    "verbose",
+			verbose: true,
+			want:    "using the cache\nlistening on :8080\nwarning: skipped 1 namespaces\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stderr bytes.Buffer
+			logger := newLogger(&stderr, logFormatText, tt.verbose)
+			logger.Debug("using the cache", "resource", "cronjobs")
+			logger.Info("listening on :8080", "address", ":8080")
+			logger.Warn("skipped 1 namespaces", "namespaces", []string{"ns-a"})
+			if diff := cmp.Diff(tt.want, stderr.String()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// Decode the JSON lines of '--log-format json', failing on any other line.
+func decodeLogRecords(t *testing.T, stderr string) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSuffix(stderr, "\n"), "\n") {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("stderr has a line which is not JSON %q: %s", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func Test_run_logFormatJSON(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-b", "cleanup", "0 2 * * *", false)
+	cronworkflow := getCronWorkflow("ns-b", "report", "0 3 * * *", false)
+	k8sClient := k8sfake.NewSimpleClientset(&cronjob)
+	argoClient := argofake.NewSimpleClientset(&cronworkflow)
+	failList(&argoClient.Fake, "cronworkflows", errors.New("connection refused"))
+	c := &clients{k8s: k8sClient, argo: argoClient.ArgoprojV1alpha1(), noServerTable: true}
+
+	stdout, stderr, err := runFake(c, append([]string{"-n", "ns-b", "--no-headers", "--log-format", "json", "--verbose"}, runPeriod...)...)
+	if !errors.Is(err, errPartialFailure) || !errors.As(err, &loggedError{}) {
+		t.Fatalf("want the logged partial failure, got %v", err)
+	}
+	// The results are printed as well as with the text format.
+	if diff := cmp.Diff("ns-b   cleanup   0 2 * * *   false   CronJob\n", stdout); diff != "" {
+		t.Errorf("stdout (-want +got):\n%s", diff)
+	}
+
+	type record struct {
+		level, msg, kind, namespace string
+	}
+	var got []record
+	for _, r := range decodeLogRecords(t, stderr) {
+		if r["level"] == "DEBUG" && r["stage"] != nil {
+			// The progress events, see Test_run_logFormatJSON_progress.
+			continue
+		}
+		kind, _ := r["kind"].(string)
+		namespace, _ := r["namespace"].(string)
+		got = append(got, record{level: r["level"].(string), msg: r["msg"].(string), kind: kind, namespace: namespace})
+	}
+	want := []record{
+		{level: "DEBUG", msg: "listing the CronJobs in batch/v1", kind: "CronJob"},
+		{level: "WARN", msg: "failed to get CronWorkflow in 'ns-b' namespace: connection refused", kind: "CronWorkflow", namespace: "ns-b"},
+		{level: "ERROR", msg: errPartialFailure.Error()},
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(record{})); diff != "" {
+		t.Errorf("records (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_logFormatJSON_progress(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{getCronJob("ns-a", "backup", "0 1 * * *", false)},
+		[]wfv1alpha1.CronWorkflow{},
+	)
+	_, stderr, err := runFake(c, append([]string{"-n", "ns-a", "--log-format", "json", "--verbose"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var got []string
+	for _, r := range decodeLogRecords(t, stderr) {
+		if stage, ok := r["stage"].(string); ok {
+			got = append(got, stage+" "+r["msg"].(string))
+		}
+	}
+	want := []string{
+		"listing listing cronjobs…",
+		"received listing cronjobs… 1 received",
+		"evaluating evaluating 1 items…",
+		"listing listing cronworkflows…",
+		"received listing cronworkflows… 0 received",
+		"evaluating evaluating 0 items…",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("progress (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_logFormatJSON_withoutVerbose(t *testing.T) {
+	t.Parallel()
+	_, stderr, err := runFake(newRunClients(), append([]string{"-n", "ns-a", "--log-format", "json"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if stderr != "" {
+		t.Errorf("want no debug records without '--verbose', got %q", stderr)
+	}
+}
+
+func Test_run_logFormat_invalid(t *testing.T) {
+	t.Parallel()
+	_, _, err := runFake(newRunClients(), append([]string{"--log-format", "logfmt"}, runPeriod...)...)
+	want := "'--log-format' must be 'text' or 'json', got 'logfmt'"
+	if err == nil || err.Error() != want {
+		t.Errorf("want %q, got %v", want, err)
+	}
+}
+
+func Test_run_quiet(t *testing.T) {
+	t.Parallel()
+	newPartialClients := func() *clients {
+		cronjob := getCronJob("ns-b", "cleanup", "0 2 * * ?", false)
+		k8sClient := k8sfake.NewSimpleClientset(&cronjob)
+		argoClient := argofake.NewSimpleClientset()
+		failList(&argoClient.Fake, "cronworkflows", errors.New("connection refused"))
+		return &clients{k8s: k8sClient, argo: argoClient.ArgoprojV1alpha1(), noServerTable: true}
+	}
+	tests := []struct {
+		name       string
+		clients    func() *clients
+		args       []string
+		wantStdout string
+		wantStderr string
+		wantErr    error
+	}{
+		{
+			name:    "partial failure",
+			clients: newPartialClients,
+			args:    []string{"-q", "--no-headers"},
+			// The warnings of the failed kind and the schedule are silent, but
+			// the exit code is kept by the error, which main prints.
+			wantStdout: "ns-b   cleanup   0 2 * * ?   false   CronJob\n",
+			wantErr:    errPartialFailure,
+		},
+		{
+			name:       "partial failure in json logs",
+			clients:    newPartialClients,
+			args:       []string{"--quiet", "--no-headers", "--log-format", "json"},
+			wantStdout: "ns-b   cleanup   0 2 * * ?   false   CronJob\n",
+			wantStderr: `"level":"ERROR","msg":"` + errPartialFailure.Error() + `"}` + "\n",
+			wantErr:    errPartialFailure,
+		},
+		{
+			name:       "success",
+			clients:    newRunClients,
+			args:       []string{"-q", "--no-headers"},
+			wantStdout: "ns-b   cleanup   0 2 * * *   true    CronJob\nns-b   report    0 3 * * *   false   CronWorkflow\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, stderr, err := runFake(tt.clients(), append(append([]string{"-n", "ns-b"}, tt.args...), runPeriod...)...)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Fatalf("want %v, got %v", tt.wantErr, err)
+			}
+			if diff := cmp.Diff(tt.wantStdout, stdout); diff != "" {
+				t.Errorf("stdout (-want +got):\n%s", diff)
+			}
+			if tt.wantStderr == "" {
+				if stderr != "" {
+					t.Errorf("want nothing on stderr, got %q", stderr)
+				}
+			} else if !strings.HasSuffix(stderr, tt.wantStderr) || strings.Count(stderr, "\n") != 1 {
+				t.Errorf("want only the error record on stderr, got %q", stderr)
+			}
+		})
+	}
+}
+
+func Test_run_quiet_invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"-q", "--verbose"}, want: "'--quiet' can't be used with '--verbose'"},
+		{args: []string{"--quiet", "--timing"}, want: "'--quiet' can't be used with '--timing'"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			t.Parallel()
+			stdout, stderr, err := runFake(newRunClients(), append(tt.args, runPeriod...)...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("want %q, got %v", tt.want, err)
+			}
+			if stdout != "" || stderr != "" {
+				t.Errorf("want nothing written, got stdout %q and stderr %q", stdout, stderr)
+			}
+		})
+	}
+}
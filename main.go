@@ -1,60 +1,318 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
 	"time"
+	_ "time/tzdata" // for '--display-timezone' on machines without the zone database
 
 	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
 	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/typed/workflow/v1alpha1"
 	"github.com/robfig/cron/v3"
 	"github.com/spf13/pflag"
+	"github.com/unblee/kubectl-cls/pkg/cls"
 	"golang.org/x/exp/maps"
 	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 )
 
 const commandName = "kubectl-cls"
 
 func main() {
 	if err := run(os.Stdout, os.Stderr, os.Args); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		if !errors.As(err, &loggedError{}) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if errors.Is(err, errPartialFailure) {
+			os.Exit(exitPartialFailure)
+		}
 		os.Exit(1)
 	}
 }
 
 func run(stdout, stderr io.Writer, args []string) error {
+	return runWithClients(stdout, stderr, args, newClients)
+}
+
+// clientsFactory builds the clients from the flags. The tests replace
+// newClients with the fake clientsets.
+type clientsFactory func(cfgFlags *genericclioptions.ConfigFlags) (*clients, error)
+
+// runWithClients is run with the clients built by buildClients.
+func runWithClients(stdout, stderr io.Writer, args []string, buildClients clientsFactory) (retErr error) {
+	// Subcommands
+	// -----------------
+	if len(args) > 1 && args[1] == "serve" {
+		return runServe(stdout, stderr, args[1:])
+	}
+	if len(args) > 1 && args[1] == "drift" {
+		return runDrift(stdout, stderr, args[1:])
+	}
+	if len(args) > 1 && args[1] == "doctor" {
+		return runDoctor(stdout, stderr, args[1:])
+	}
+	if len(args) > 1 && args[1] == "next" {
+		return runNext(stdout, stderr, args[1:], buildClients, time.Now)
+	}
+	if len(args) > 1 && args[1] == "prev" {
+		return runPrev(stdout, stderr, args[1:], buildClients, time.Now)
+	}
+	if len(args) > 1 && args[1] == "audit" {
+		return runAudit(stdout, stderr, args[1:], buildClients, time.Now)
+	}
+
 	// Parse flags
 	// -----------------
 	var (
-		fromFlag       string
-		toFlag         string
-		noHeadersFlag  bool
-		outputFlag     string
-		selectorFlag   string
-		showLabelsFlag bool
-		versionFlag    bool
+		fromFlag              string
+		toFlag                string
+		windowFlag            string
+		todayFlag             bool
+		tomorrowFlag          bool
+		thisWeekFlag          bool
+		weekStartFlag         string
+		rangeFlag             string
+		noHeadersFlag         bool
+		outputFlag            string
+		delimiterFlag         string
+		outputSchemaFlag      bool
+		selectorFlag          string
+		namespaceSelectorFlag string
+		namespacesFlag        []string
+		showLabelsFlag        bool
+		groupByLabelFlag      string
+		rawFlag               bool
+		keepStatusFlag        bool
+		compactFlag           bool
+		redactFlag            bool
+		redactPatternFlag     string
+		maxColumnWidthFlag    int
+		maxWidthFlag          int
+		noTruncateFlag        bool
+		noPagerFlag           bool
+		displayTimezoneFlag   string
+		relativeTimesFlag     bool
+
+		missingHistoryLimitsFlag bool
+		missingLimitsFlag        bool
+		missingLimitsInitFlag    bool
+		registryAllowlistFlag    string
+		mountsSecretFlag         string
+		mountsConfigMapFlag      string
+		mountsPVCFlag            string
+		priorityClassFlag        []string
+		minCPURequestFlag        string
+		minMemoryRequestFlag     string
+		firesOnFlag              string
+		firesOnModeFlag          string
+		searchFlag               string
+		sortByFlag               string
+		dailyBetweenFlag         string
+		timezoneFlag             string
+		annotationRegexFlag      []string
+		templateSelectorFlag     string
+		ownedByFlag              string
+		unownedFlag              bool
+		ttlFlag                  string
+		neverRunFlag             bool
+		olderThanFlag            time.Duration
+		entrypointFlag           string
+		resolveTemplatesFlag     bool
+		roundFlag                string
+		exclusiveFromFlag        bool
+		exclusiveToFlag          bool
+		concurrencyConflictsFlag bool
+		expectedDurationFlag     time.Duration
+		duplicatesFlag           bool
+		duplicateKeyFlag         string
+		demandFlag               string
+		heatmapFlag              bool
+		heatmapShadeFlag         bool
+		policyFlag               string
+		failOnViolationFlag      bool
+		assertFiresEveryFlag     time.Duration
+		deadlineRiskFlag         bool
+		runtimeRiskFlag          int
+		rollupLabelFlag          string
+		saveFlag                 string
+		compareFlag              string
+		statsFlag                bool
+		tzReportFlag             bool
+		shiftFlag                string
+		setTimezoneFlag          string
+		suspendFlag              bool
+		resumeFlag               bool
+		resumeAllFlag            bool
+		onlyMissingFlag          bool
+		dryRunFlag               bool
+		printCommandsFlag        bool
+		generatePatchesFlag      string
+		auditLogFlag             string
+		yesFlag                  bool
+		versionFlag              bool
+		cacheTTLFlag             time.Duration
+		noCacheFlag              bool
+		verboseFlag              bool
+		quietFlag                bool
+		logFormatFlag            string
+		timingFlag               bool
+		serverTimeFlag           bool
+		strictFlag               bool
+		traceEvalFlag            []string
+		namesFromFlag            string
+		fixturesFlag             string
+		providerFlag             []string
+		includeCRDsFlag          []string
+		providerTimeoutFlag      time.Duration
+
+		writeConfigMapFlag string
+
+		postURLFlag                   string
+		postHeaderFlag                []string
+		postBestEffortFlag            bool
+		postCAFileFlag                string
+		postInsecureSkipTLSVerifyFlag bool
+
+		exporterFlag         bool
+		exporterListenFlag   string
+		exporterIntervalFlag time.Duration
+		exporterWindowFlag   time.Duration
+
+		inClusterFlag bool
 	)
 	fsets := pflag.NewFlagSet(commandName, pflag.ContinueOnError)
 	fsets.SetOutput(stderr)
-	fsets.StringVarP(&fromFlag, "from", "", "", "The start time of the period. e.g. '2023-01-24T00:00:00+09:00'.")
-	fsets.StringVarP(&toFlag, "to", "", "", "The end time of the period. e.g. '2023-01-24T00:00:00+09:00'.")
+	fsets.StringVarP(&fromFlag, "from", "", "", "The start time of the period. e.g. '2023-01-24T00:00:00+09:00', '2023-01-24' (its midnight), '1674518400' (Unix seconds or milliseconds), 'tomorrow 02:00', or an offset from now such as '-2h'.")
+	fsets.StringVarP(&toFlag, "to", "", "", "The end time of the period. e.g. '2023-01-24T00:00:00+09:00', '2023-01-24' (its 23:59:59), '1674540000' (Unix seconds or milliseconds), 'tomorrow 02:00', or an offset from now such as 'now+1h'.")
+	fsets.StringVarP(&windowFlag, "window", "", "", "The length of the period when '--from' is omitted, e.g. '6h' or 'P1D'. (default 1h)")
+	fsets.BoolVarP(&todayFlag, presetToday, "", false, "If present, set the period to today in '--timezone'. Can't be used with '--from' and '--to'.")
+	fsets.BoolVarP(&tomorrowFlag, presetTomorrow, "", false, "If present, set the period to tomorrow in '--timezone'. Can't be used with '--from' and '--to'.")
+	fsets.BoolVarP(&thisWeekFlag, presetThisWeek, "", false, "If present, set the period to this week in '--timezone', starting on '--week-start'. Can't be used with '--from' and '--to'.")
+	fsets.StringVarP(&weekStartFlag, "week-start", "", "monday", "The first day of the week of '--this-week'. One of: monday|sunday.")
+	fsets.StringVarP(&rangeFlag, "range", "", "", "The period as an ISO 8601 interval, 'START/END' or 'START/DURATION'. e.g. '2023-01-24T00:00:00Z/PT6H'. Can't be used with '--from' and '--to'.")
+	fsets.BoolVarP(&exclusiveFromFlag, "exclusive-from", "", false, "If present, exclude the schedules at exactly '--from'. By default both '--from' and '--to' are included.")
+	fsets.BoolVarP(&exclusiveToFlag, "exclusive-to", "", false, "If present, exclude the schedules at exactly '--to'. e.g. back-to-back periods 00:00-06:00 and 06:00-12:00.")
+	fsets.StringVarP(&roundFlag, "round", "", "", "If present, floor '--from' and ceil '--to' to the granularity. e.g. '1m', '5m', '1h'.")
 	fsets.BoolVarP(&noHeadersFlag, "no-headers", "", false, "If present, don't print headers.")
-	fsets.StringVarP(&outputFlag, "output", "o", "", "Output format. One of: ''|json.")
+	fsets.StringVarP(&outputFlag, "output", "o", "", "Output format. One of: ''|wide|json|yaml|mermaid|junit|raw.")
+	fsets.StringVarP(&delimiterFlag, "delimiter", "", defaultRawDelimiter, "The delimiter joining the fields of '-o raw'.")
+	fsets.BoolVarP(&outputSchemaFlag, "output-schema", "", false, "Prints the JSON Schema of the json/yaml output document.")
 	fsets.StringVarP(&selectorFlag, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2).")
+	fsets.StringVarP(&namespaceSelectorFlag, "namespace-selector", "", "", "Selector (label query) on the Namespace objects. If present, only the matching namespaces are queried. Can't be used with '--namespace'.")
+	fsets.StringSliceVarP(&namespacesFlag, "namespaces", "", nil, "If present, query only these namespaces one by one, e.g. when listing across all namespaces is forbidden. The forbidden ones are skipped. A glob pattern such as 'team-*' is expanded against the Namespaces, as well as that of '--namespace'. Can't be used with '--namespace'.")
 	fsets.BoolVarP(&showLabelsFlag, "show-labels", "", false, "When printing, show all labels as the last column (default hide labels column)")
+	fsets.StringVarP(&groupByLabelFlag, "group-by-label", "", "", "If present, print the items in a section for each value of the label key with its count, e.g. team, and those without the label last. The json/yaml output has the groups too.")
+	fsets.BoolVarP(&rawFlag, "raw", "", false, "If present, write the objects untouched into the json/yaml output document. By default, managedFields, other server-populated metadata, and status are stripped.")
+	fsets.BoolVarP(&keepStatusFlag, "keep-status", "", false, "If present, keep the status of the objects in the json/yaml output document.")
+	fsets.BoolVarP(&compactFlag, "compact", "", false, "If present, write the json output document without the indentation. Useful for very large result sets.")
+	fsets.BoolVarP(&redactFlag, "redact", "", false, "If present, replace the literal values of the env vars whose names match '--redact-pattern' with 'REDACTED' in the json/yaml output document.")
+	fsets.StringVarP(&redactPatternFlag, "redact-pattern", "", defaultRedactPattern, "The regular expression matched against the env var names by '--redact'.")
+	fsets.IntVarP(&maxColumnWidthFlag, "max-column-width", "", 0, "If greater than zero, truncate the table cells longer than the width with '...'. Table output only.")
+	fsets.IntVarP(&maxWidthFlag, "max-width", "", 0, "If greater than zero, fit the table into the width by truncating the widest columns with '...'. Defaults to the terminal width when stdout is a terminal. Table output only.")
+	fsets.BoolVarP(&noTruncateFlag, "no-truncate", "", false, "If present, never truncate the table cells. Overrides '--max-width' and '--max-column-width'.")
+	fsets.BoolVarP(&noPagerFlag, "no-pager", "", false, "If present, never pipe the table through $KUBECTL_CLS_PAGER or $PAGER (default: less -FRX) when it's taller than the terminal.")
+	fsets.StringVarP(&displayTimezoneFlag, "display-timezone", "", "UTC", "The IANA time zone the timestamps in the table are printed in, or 'local'. Defaults to '--timezone' when it's given. Matching is not affected.")
+	fsets.BoolVarP(&relativeTimesFlag, "relative-times", "", false, "If present, print the timestamps in the table as offsets from now, e.g. 'in 42m' or '3h ago'.")
+	fsets.BoolVarP(&missingHistoryLimitsFlag, "missing-history-limits", "", false, "If present, keep only items where successfulJobsHistoryLimit or failedJobsHistoryLimit is unset.")
+	fsets.BoolVarP(&missingLimitsFlag, "missing-limits", "", false, "If present, keep only items with any container in the Job template (the templates of the workflow spec for CronWorkflows) lacking resources.limits.cpu or resources.limits.memory. '-o wide' lists the containers.")
+	fsets.StringVarP(&registryAllowlistFlag, "registry-allowlist", "", "", "If present, keep only items with any image whose registry is not in the comma separated list of registries, optionally with a repository prefix, and print the images. e.g. 'registry.example.com,ghcr.io/ourorg'.")
+	fsets.BoolVarP(&missingLimitsInitFlag, "missing-limits-include-init", "", false, "If present, '--missing-limits' checks the init containers too.")
+	fsets.StringVarP(&mountsSecretFlag, "mounts-secret", "", "", "If present, keep only items referencing the Secret of the name by a volume, envFrom, or env.valueFrom.")
+	fsets.StringVarP(&mountsConfigMapFlag, "mounts-configmap", "", "", "If present, keep only items referencing the ConfigMap of the name by a volume, envFrom, or env.valueFrom.")
+	fsets.StringVarP(&mountsPVCFlag, "mounts-pvc", "", "", "If present, keep only items mounting the PersistentVolumeClaim of the name.")
+	fsets.StringArrayVarP(&priorityClassFlag, "priority-class", "", nil, "If present, keep only items whose pod priorityClassName is the value. Can be repeated. An empty value matches items without one.")
+	fsets.StringVarP(&minCPURequestFlag, "min-cpu-request", "", "", "If present, keep only items whose pod cpu request is the quantity or above. e.g. '2', '500m'.")
+	fsets.StringVarP(&minMemoryRequestFlag, "min-memory-request", "", "", "If present, keep only items whose pod memory request is the quantity or above. e.g. '4Gi'.")
+	fsets.StringVarP(&firesOnFlag, "fires-on", "", "", "If present, keep only items which fire on the days in the schedule's time zone. One of: weekdays|weekends, or days such as 'MON,TUE'.")
+	fsets.StringVarP(&firesOnModeFlag, "fires-on-mode", "", "ever", "How '--fires-on' matches. One of: ever (fires on any of the days)|only (fires on no other day).")
+	fsets.StringVarP(&dailyBetweenFlag, "daily-between", "", "", "If present, keep only items which fire in the from-to period at a time of day in the range 'HH:MM-HH:MM' in '--timezone'. The range can cross midnight, e.g. '22:00-02:00'.")
+	fsets.StringVarP(&timezoneFlag, "timezone", "", "UTC", "The IANA time zone of '--daily-between' and of the '--from' and '--to' without the offset, or 'local'. The timestamps are printed in it too, unless '--display-timezone' is given.")
+	fsets.StringVarP(&searchFlag, "search", "", "", "If present, keep only items whose 'NAMESPACE/NAME' fuzzy matches the term, like fzf does, e.g. 'setl' matches 'nightly-settlement'. Case-insensitive, applied after the other filters.")
+	fsets.StringVarP(&sortByFlag, "sort-by", "", sortByName, "How the items are sorted. One of: name (by namespace, name, and kind)|score (by the match score of '--search', the best first).")
+	fsets.StringArrayVarP(&annotationRegexFlag, "annotation-regex", "", nil, "If present, keep only items whose annotation KEY matches the regular expression in the 'KEY=PATTERN' form. Can be repeated, all of them must match.")
+	fsets.StringVarP(&templateSelectorFlag, "template-selector", "", "", "If present, keep only items whose pod template labels (workflowMetadata labels for CronWorkflows) match the selector. The same syntax as '--selector', e.g. 'app.kubernetes.io/part-of=billing'.")
+	fsets.StringVarP(&ownedByFlag, "owned-by", "", "", "If present, keep only items owned by the 'KIND[/NAME]' in ownerReferences. The controller is preferred when there is one.")
+	fsets.BoolVarP(&unownedFlag, "unowned", "", false, "If present, keep only items without ownerReferences.")
+	fsets.StringVarP(&ttlFlag, "ttl", "", "", "If present, keep only items whose ttlSecondsAfterFinished (ttlStrategy.secondsAfterCompletion for CronWorkflows) matches. One of: set|unset|lt=SECONDS|gt=SECONDS.")
+	fsets.BoolVarP(&neverRunFlag, "never-run", "", false, "If present, keep only items which have never run: without status.lastScheduleTime and status.active (status.lastScheduledTime for CronWorkflows).")
+	durationVarP(fsets, &olderThanFlag, "older-than", "", 0, "The minimum age from creationTimestamp of the items kept by '--never-run'. e.g. '30d'.")
+	fsets.StringVarP(&entrypointFlag, "entrypoint", "", "", "If present, keep only CronWorkflows whose workflow spec entrypoint is the value. CronJobs are excluded.")
+	fsets.BoolVarP(&resolveTemplatesFlag, "resolve-templates", "", false, "If present, '--entrypoint' gets the template of workflowTemplateRef to look up its entrypoint when the workflow spec has none.")
+	fsets.BoolVarP(&concurrencyConflictsFlag, "concurrency-conflicts", "", false, "If present, print the fire times of the Forbid and Replace items at which the previous run is predicted to be still running, instead of the items.")
+	durationVarP(fsets, &expectedDurationFlag, "expected-duration", "", 0, "The expected run duration for '--concurrency-conflicts' and the tasks of '-o mermaid' (default 5m), overridden by the '"+expectedDurationAnnotation+"' annotation of each item.")
+	fsets.BoolVarP(&duplicatesFlag, "duplicates", "", false, "If present, print the groups of the items which look like the same job deployed more than once, instead of the items.")
+	fsets.StringVarP(&duplicateKeyFlag, "duplicate-key", "", duplicateKeyScheduleImage, "How '--duplicates' groups the items. One of: "+duplicateKeyScheduleImage+"|"+duplicateKeyScheduleName+".")
+	fsets.StringVarP(&demandFlag, "demand", "", "", "If present, print the sum of the cpu and memory requests of the fires per bucket of the duration, instead of the items. The bucket defaults to "+defaultDemandBucket+".")
+	fsets.Lookup("demand").NoOptDefVal = defaultDemandBucket
+	fsets.BoolVarP(&heatmapFlag, "heatmap", "", false, "If present, print the fire counts per day of the week and hour in '--display-timezone' over the week containing '--from', instead of the items.")
+	fsets.BoolVarP(&heatmapShadeFlag, "heatmap-shade", "", false, "If present, print the '--heatmap' cells as shade characters instead of the counts.")
+	fsets.StringVarP(&policyFlag, "policy", "", "", "If present, print the matched items violating the rules of the policy file, instead of the items.")
+	fsets.BoolVarP(&failOnViolationFlag, "fail-on-violation", "", false, "If present, exit with an error when '--policy' finds any violation.")
+	fsets.BoolVarP(&deadlineRiskFlag, "deadline-risk", "", false, "If present, print the startingDeadlineSeconds of the matched items classified against their typical intervals, instead of the items.")
+	fsets.IntVarP(&runtimeRiskFlag, "runtime-risk", "", 0, "If present, print the p95 duration of the last N completed Jobs of the matched CronJobs compared with their typical intervals, instead of the items. N defaults to "+defaultRuntimeRiskRuns+".")
+	fsets.Lookup("runtime-risk").NoOptDefVal = defaultRuntimeRiskRuns
+	durationVarP(fsets, &assertFiresEveryFlag, "assert-fires-every", "", 0, "If greater than zero, print the matched items with a gap between two fires around the window longer than the duration, instead of the items, and exit with an error when there is any. e.g. 24h.")
+	fsets.StringVarP(&saveFlag, "save", "", "", "If present, save all the items in scope regardless of the period, with their evaluations, into the file as the json output document for '--compare'.")
+	fsets.StringVarP(&compareFlag, "compare", "", "", "If present, print the items added, removed, or changed since the snapshot file of '--save', regardless of the period, instead of the items.")
+	fsets.StringVarP(&rollupLabelFlag, "rollup-label", "", "", "If present, print the item and fire counts of the matched items grouped by the value of the label key, taken from the namespace when the item has none, instead of the items. e.g. team.")
+	fsets.BoolVarP(&statsFlag, "stats", "", false, "If present, print the aggregates of the items over the period, instead of the items.")
+	fsets.BoolVarP(&tzReportFlag, "tz-report", "", false, "If present, print the first fires of the items evaluated both in UTC and in their time zones, flagging the items only one of them fires in the period, instead of the items.")
+	fsets.StringVarP(&shiftFlag, "shift", "", "", "If present, patch the schedules of the matched items to fire the duration later, or earlier when negative, e.g. 2h.")
+	fsets.StringVarP(&setTimezoneFlag, "set-timezone", "", "", "If present, patch the time zone of the matched items to the zone, e.g. Asia/Tokyo.")
+	fsets.BoolVarP(&onlyMissingFlag, "only-missing", "", false, "If present, '--set-timezone' only patches the items without a time zone.")
+	fsets.BoolVarP(&suspendFlag, "suspend", "", false, "If present, suspend the matched items and mark them with the '"+suspendedAtAnnotation+"' annotation.")
+	fsets.BoolVarP(&resumeFlag, "resume", "", false, "If present, resume the matched items suspended by '--suspend' and remove the annotation.")
+	fsets.BoolVarP(&resumeAllFlag, "resume-all", "", false, "If present, resume all the suspended matched items, with or without the '--suspend' annotation.")
+	fsets.BoolVarP(&dryRunFlag, "dry-run", "", false, "If present, print what '--shift', '--set-timezone', '--suspend', or '--resume' would patch without patching.")
+	fsets.BoolVarP(&printCommandsFlag, "print-commands", "", false, "If present, print the kubectl commands equivalent to '--shift', '--set-timezone', '--suspend', or '--resume' as a shell script instead of patching.")
+	fsets.StringVarP(&generatePatchesFlag, "generate-patches", "", "", "If present, write the patches of '--shift', '--set-timezone', '--suspend', or '--resume' into the directory as JSON merge patch files with an index instead of patching.")
+	fsets.StringVarP(&auditLogFlag, "audit-log", "", "", "If present, append a JSON line for each patch of '--shift', '--set-timezone', '--suspend', or '--resume', including the dry-run ones, to the file.")
+	fsets.BoolVarP(&yesFlag, "yes", "y", false, "If present, patch without the confirmation.")
 	fsets.BoolVarP(&versionFlag, "version", "V", false, "Prints version information.")
+	durationVarP(fsets, &cacheTTLFlag, "cache-ttl", "", 0, "If greater than zero, cache the listed objects in the user cache directory and reuse them within the duration, e.g. 60s.")
+	fsets.BoolVarP(&noCacheFlag, "no-cache", "", false, "If present, neither read nor write the cache of '--cache-ttl'.")
+	fsets.BoolVarP(&verboseFlag, "verbose", "", false, "If present, write the debug logs such as the cache hits into stderr.")
+	fsets.BoolVarP(&quietFlag, "quiet", "q", false, "If present, write nothing but the results and the errors: no warnings, no progress, and no logs. The exit codes are not affected.")
+	fsets.StringVarP(&logFormatFlag, "log-format", "", logFormatText, "The format of the logs written into stderr. One of: text|json. The results on stdout are not affected.")
+	fsets.BoolVarP(&timingFlag, "timing", "", false, "If present, write the wall time of each phase, such as the listing of each resource and namespace, into stderr after the results, and into the json/yaml output.")
+	fsets.BoolVarP(&serverTimeFlag, "server-time", "", false, "If present, take now from the clock of the API server instead of the local one, e.g. for '--relative-times' and '--older-than'.")
+	fsets.BoolVarP(&strictFlag, "strict", "", false, "If present, fail when any of CronJobs and CronWorkflows fails to be listed, instead of printing the others.")
+	fsets.StringVarP(&namesFromFlag, "names-from", "", "", "If present, evaluate only the items named in the file ('-' for stdin), one NAME or NAMESPACE/NAME per line. They are got one by one instead of listed.")
+	fsets.StringSliceVarP(&traceEvalFlag, "trace-eval", "", nil, "If present, write the evaluation of the schedules of the item into stderr, in the NAMESPACE/NAME form. '*' matches any namespace or name, e.g. '*/*'. Can be repeated.")
+	fsets.StringSliceVarP(&includeCRDsFlag, "include-crds", "", nil, "If present, also list the custom resources with cron schedules of the operators, e.g. 'kubestash'. The CRDs not installed are ignored. See the README for the supported values.")
+	fsets.StringArrayVarP(&providerFlag, "provider", "", nil, "If present, also list the objects printed by the executable as a JSON array. Can be repeated. See the README for the contract.")
+	durationVarP(fsets, &providerTimeoutFlag, "provider-timeout", "", defaultProviderTimeout, "The maximum duration to run each '--provider'.")
+	fsets.StringVarP(&fixturesFlag, "fixtures", "", "", "If present, read the items from the JSON output document of '-o json', or from the manifest files of the file or directory, instead of the cluster.")
+	fsets.StringVarP(&writeConfigMapFlag, "write-configmap", "", "", "If present, write the JSON output document into the ConfigMap 'NAMESPACE/NAME' by server-side apply.")
+	fsets.StringVarP(&postURLFlag, "post-url", "", "", "If present, POST the JSON output document to the URL after evaluation.")
+	fsets.StringArrayVarP(&postHeaderFlag, "post-header", "", nil, "Header added to the '--post-url' request in the 'Name: value' form. Can be repeated.")
+	fsets.BoolVarP(&postBestEffortFlag, "post-best-effort", "", false, "If present, a failed '--post-url' delivery only prints a warning instead of failing the command.")
+	fsets.StringVarP(&postCAFileFlag, "post-ca-file", "", "", "Path to a PEM encoded CA certificate trusted in addition to the system roots for '--post-url'.")
+	fsets.BoolVarP(&postInsecureSkipTLSVerifyFlag, "post-insecure-skip-tls-verify", "", false, "If present, the '--post-url' server certificate will not be checked for validity.")
+	fsets.BoolVarP(&exporterFlag, "exporter", "", false, "If present, run as a Prometheus exporter instead of printing. '--from' and '--to' are not used.")
+	fsets.StringVarP(&exporterListenFlag, "exporter-listen", "", ":9090", "The address to serve /metrics on in exporter mode.")
+	durationVarP(fsets, &exporterIntervalFlag, "exporter-interval", "", time.Minute, "The interval between evaluations in exporter mode.")
+	durationVarP(fsets, &exporterWindowFlag, "exporter-window", "", time.Hour, "The length of the sliding window (now, now+window) evaluated in exporter mode.")
 	cfgFlags := genericclioptions.NewConfigFlags(true)
 	cfgFlags.AddFlags(fsets)
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
 
 	fsets.Usage = func() {
 		fmt.Fprintf(stderr, "Usage of %s:\n", commandName)
@@ -62,6 +320,14 @@ func run(stdout, stderr io.Writer, args []string) error {
 		fmt.Fprintln(stderr, "Example")
 		fmt.Fprintln(stderr, "  $ kubectl-cls --from 2023-01-24T00:00:00+09:00 --to 2023-01-24T06:00:00+09:00")
 		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Subcommands")
+		fmt.Fprintln(stderr, "  serve    Expose the query over HTTP. See 'kubectl-cls serve --help'.")
+		fmt.Fprintln(stderr, "  drift    Compare the cluster with the manifest files. See 'kubectl-cls drift --help'.")
+		fmt.Fprintln(stderr, "  next     Print the next fire of each item from now. See 'kubectl-cls next --help'.")
+		fmt.Fprintln(stderr, "  prev     Print the fires expected in a past window. See 'kubectl-cls prev --help'.")
+		fmt.Fprintln(stderr, "  audit    Compare the fires expected in a past window with the runs. See 'kubectl-cls audit --help'.")
+		fmt.Fprintln(stderr, "  doctor   Check the setup step by step. See 'kubectl-cls doctor --help'.")
+		fmt.Fprintln(stderr, "")
 		fmt.Fprintln(stderr, "Flags")
 		fmt.Fprint(stderr, fsets.FlagUsages())
 	}
@@ -69,10 +335,64 @@ func run(stdout, stderr io.Writer, args []string) error {
 	if err := fsets.Parse(args[1:]); err != nil {
 		return err
 	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+
+	if err := validateLogFormat(logFormatFlag); err != nil {
+		return err
+	}
+	if quietFlag && verboseFlag {
+		return errors.New("'--quiet' can't be used with '--verbose'")
+	}
+	if quietFlag && timingFlag {
+		return errors.New("'--quiet' can't be used with '--timing'")
+	}
+	logger := newLeveledLogger(stderr, logFormatFlag, logLevel(verboseFlag, quietFlag))
+	defer func() { retErr = logCommandError(logger, logFormatFlag, retErr) }()
+	var timing *timings
+	if timingFlag {
+		timing = &timings{}
+	}
 
 	if versionFlag {
-		fmt.Fprintf(stdout, "%s %s (rev:%s)\n", commandName, Version, Revision)
-		return nil
+		return printVersion(stdout, outputFlag)
+	}
+
+	if outputSchemaFlag {
+		return printOutputSchema(stdout)
+	}
+
+	if exporterFlag {
+		if fixturesFlag != "" {
+			return errors.New("'--fixtures' can't be used with '--exporter'")
+		}
+		if len(providerFlag) > 0 {
+			return errors.New("'--provider' can't be used with '--exporter'")
+		}
+		if namesFromFlag != "" {
+			return errors.New("'--names-from' can't be used with '--exporter'")
+		}
+		if len(namespacesFlag) > 0 {
+			return errors.New("'--namespaces' can't be used with '--exporter'")
+		}
+		if len(includeCRDsFlag) > 0 {
+			return errors.New("'--include-crds' can't be used with '--exporter'")
+		}
+		if timingFlag {
+			return errors.New("'--timing' can't be used with '--exporter'")
+		}
+		c, err := buildClients(cfgFlags)
+		if err != nil {
+			return err
+		}
+		return runExporter(logger, c, exporterOptions{
+			listen:    exporterListenFlag,
+			interval:  exporterIntervalFlag,
+			window:    exporterWindowFlag,
+			namespace: *cfgFlags.Namespace,
+			selector:  selectorFlag,
+		})
 	}
 
 	var (
@@ -82,255 +402,1515 @@ func run(stdout, stderr io.Writer, args []string) error {
 		to         time.Time
 	)
 
-	// Set the start time of the period.
+	location, err := parseLocation("timezone", timezoneFlag)
+	if err != nil {
+		return err
+	}
+
+	var round time.Duration
+	if roundFlag != "" {
+		round, err = parseRound(roundFlag)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Validate the preset windows.
 	// -----------------
-	if fromFlag == "" {
-		return errors.New("please set --from flag")
+	preset := ""
+	presetFlags := map[string]bool{presetToday: todayFlag, presetTomorrow: tomorrowFlag, presetThisWeek: thisWeekFlag}
+	for _, name := range windowPresets {
+		if !presetFlags[name] {
+			continue
+		}
+		if preset != "" {
+			return fmt.Errorf("'--%s' can't be used with '--%s'", preset, name)
+		}
+		for _, period := range []string{"from", "to", "window", "range"} {
+			if fsets.Changed(period) {
+				return fmt.Errorf("'--%s' can't be used with '--%s'", name, period)
+			}
+		}
+		preset = name
 	}
-	from, err = time.Parse(timeLayout, fromFlag)
+	weekStart, err := parseWeekStart(weekStartFlag)
 	if err != nil {
-		return fmt.Errorf("failed to parse '--from' value: %w", err)
+		return err
+	}
+	if fsets.Changed("week-start") && preset != presetThisWeek {
+		return errors.New("'--week-start' requires '--this-week'")
 	}
-	from = from.UTC() // Convert to UTC for easy comparison with the schedule.
 
-	// Set the end time of the period.
-	// -----------------
-	if toFlag == "" {
-		return errors.New("please set --to flag")
+	// Resolve the period against now, which is resolved again against the
+	// clock of the API server with '--server-time' once the clients are built.
+	resolvePeriod := func(now time.Time) (from, to time.Time, err error) {
+		if preset != "" {
+			// Set the period to the calendar period of the preset.
+			// -----------------
+			from, to = presetWindow(preset, weekStart, now, location)
+		} else if rangeFlag != "" {
+			// Set the period from the ISO 8601 interval.
+			// -----------------
+			if fsets.Changed("from") || fsets.Changed("to") {
+				return from, to, errors.New("'--range' can't be used with '--from' and '--to'")
+			}
+			if windowFlag != "" {
+				return from, to, errors.New("'--range' can't be used with '--window'")
+			}
+			from, to, err = parseRange(rangeFlag, timeLayout)
+			if err != nil {
+				return from, to, err
+			}
+		} else {
+			// Set the period, now and an hour before it by default.
+			// -----------------
+			from, to, err = resolveWindow(fromFlag, toFlag, windowFlag, now, location)
+			if err != nil {
+				return from, to, err
+			}
+		}
+		// Round in the zone of the value, so that '1h' is an hour of the wall clock.
+		from = floorTime(from, round)
+		from = from.UTC() // Convert to UTC for easy comparison with the schedule.
+		to = ceilTime(to, round)
+		to = to.UTC() // Convert to UTC for easy comparison with the schedule.
+		if from.After(to) {
+			return from, to, errors.New("'--from' '--to' times are reversed")
+		}
+		return from, to, nil
+	}
+	from, to, err = resolvePeriod(time.Now())
+	if err != nil {
+		return err
 	}
-	to, err = time.Parse(timeLayout, toFlag)
+
+	bounds := boundaries{exclusiveFrom: exclusiveFromFlag, exclusiveTo: exclusiveToFlag}
+	traceTargets, err := parseTraceTargets(traceEvalFlag)
 	if err != nil {
-		return fmt.Errorf("failed to parse '--to' value: %w", err)
+		return err
 	}
-	to = to.UTC() // Convert to UTC for easy comparison with the schedule.
+	bounds.trace = newEvaluationTracer(stderr, traceTargets)
+	bounds.lints = &scheduleLints{}
+	// Also explains the failed parse of a schedule, before its error.
+	defer bounds.lints.warn(logger)
 
 	// Validation
 	// -----------------
-	if from.After(to) {
-		return errors.New("'--from' '--to' times are reversed")
-	}
-	if outputFlag != "" && outputFlag != "json" {
+	if outputFlag != "" && outputFlag != "wide" && outputFlag != "json" && outputFlag != "yaml" && outputFlag != "mermaid" && outputFlag != "junit" && outputFlag != "raw" {
 		return fmt.Errorf("%s is unsupported output format", outputFlag)
 	}
-
-	// List CronJobs
-	// -----------------
-	cfg, err := cfgFlags.ToRESTConfig()
+	if outputFlag == "mermaid" || outputFlag == "junit" || outputFlag == "raw" {
+		for _, name := range itemOutputFlags {
+			if fsets.Changed(name) {
+				return fmt.Errorf("'-o %s' can't be used with '--%s'", outputFlag, name)
+			}
+		}
+	}
+	if fsets.Changed("delimiter") {
+		if outputFlag != "raw" {
+			return errors.New("'--delimiter' requires '-o raw'")
+		}
+		if err := validateRawDelimiter(delimiterFlag); err != nil {
+			return err
+		}
+	}
+	var configMapNamespace, configMapName string
+	if writeConfigMapFlag != "" {
+		configMapNamespace, configMapName, err = parseConfigMapRef(writeConfigMapFlag)
+		if err != nil {
+			return err
+		}
+	}
+	postHeaders, err := parsePostHeaders(postHeaderFlag)
 	if err != nil {
-		return fmt.Errorf("failed to get kubernetes REST client configuration: %w", err)
+		return err
 	}
-
-	k8sClient, err := kubernetes.NewForConfig(cfg)
+	if err := validateDeliveryFlags(fsets); err != nil {
+		return err
+	}
+	displayLocation, err := parseLocation("display-timezone", displayTimezoneFlag)
+	if err != nil {
+		return err
+	}
+	if fsets.Changed("timezone") && !fsets.Changed("display-timezone") {
+		// The times are printed in the zone they are given in.
+		displayLocation = location
+	}
+	var minCPURequest, minMemoryRequest resource.Quantity
+	if minCPURequestFlag != "" {
+		minCPURequest, err = resource.ParseQuantity(minCPURequestFlag)
+		if err != nil {
+			return fmt.Errorf("failed to parse '--min-cpu-request' value: %w", err)
+		}
+	}
+	if minMemoryRequestFlag != "" {
+		minMemoryRequest, err = resource.ParseQuantity(minMemoryRequestFlag)
+		if err != nil {
+			return fmt.Errorf("failed to parse '--min-memory-request' value: %w", err)
+		}
+	}
+	if len(namespacesFlag) > 0 {
+		for _, name := range []string{"namespace", "namespace-selector", "stats", "tz-report", "save", "compare"} {
+			if fsets.Changed(name) {
+				return fmt.Errorf("'--namespaces' can't be used with '--%s'", name)
+			}
+		}
+	}
+	for _, name := range []string{"selector", "namespace-selector"} {
+		if _, err := parseSelector(name, fsets.Lookup(name).Value.String()); err != nil {
+			return err
+		}
+	}
+	if namespaceSelectorFlag != "" && *cfgFlags.Namespace != "" {
+		return errors.New("'--namespace-selector' and '--namespace' can't be used together")
+	}
+	if err := validateNamespacePatterns(append([]string{*cfgFlags.Namespace}, namespacesFlag...)); err != nil {
+		return err
+	}
+	if isNamespacePattern(*cfgFlags.Namespace) {
+		// The pattern is expanded against the Namespaces of the cluster.
+		for _, name := range []string{"fixtures", "provider", "names-from", "exporter"} {
+			if fsets.Changed(name) {
+				return fmt.Errorf("the pattern of '--namespace' can't be used with '--%s'", name)
+			}
+		}
+	}
+	var firesOnDays weekdaySet
+	if firesOnFlag != "" {
+		firesOnDays, err = parseWeekdaySet(firesOnFlag)
+		if err != nil {
+			return err
+		}
+	}
+	if sortByFlag != sortByName && sortByFlag != sortByScore {
+		return fmt.Errorf("%s is unsupported '--sort-by'", sortByFlag)
+	}
+	if sortByFlag == sortByScore && searchFlag == "" {
+		return errors.New("'--sort-by score' requires '--search'")
+	}
+	if firesOnModeFlag != "ever" && firesOnModeFlag != "only" {
+		return fmt.Errorf("%s is unsupported '--fires-on-mode'", firesOnModeFlag)
+	}
+	var dailyBetween timeOfDayRange
+	if dailyBetweenFlag != "" {
+		dailyBetween, err = parseTimeOfDayRange(dailyBetweenFlag)
+		if err != nil {
+			return err
+		}
+	}
+	annotationRegexes, err := parseAnnotationRegexes(annotationRegexFlag)
 	if err != nil {
-		return fmt.Errorf("failed to get kubernetes client: %w", err)
+		return err
+	}
+	var templateSelector filter
+	if templateSelectorFlag != "" {
+		templateSelector, err = templateSelectorFilter(templateSelectorFlag)
+		if err != nil {
+			return err
+		}
+	}
+	var ownedByKind, ownedByName string
+	if ownedByFlag != "" {
+		if unownedFlag {
+			return errors.New("'--owned-by' and '--unowned' can't be used together")
+		}
+		ownedByKind, ownedByName, err = parseOwnedBy(ownedByFlag)
+		if err != nil {
+			return err
+		}
+	}
+	var ttlCondition secondsCondition
+	if ttlFlag != "" {
+		ttlCondition, err = parseSecondsCondition("ttl", ttlFlag)
+		if err != nil {
+			return err
+		}
+	}
+	var registryAllowlist []string
+	if registryAllowlistFlag != "" {
+		registryAllowlist, err = parseRegistryAllowlist(registryAllowlistFlag)
+		if err != nil {
+			return err
+		}
+	}
+	if missingLimitsInitFlag && !missingLimitsFlag {
+		return errors.New("'--missing-limits-include-init' requires '--missing-limits'")
+	}
+	if fsets.Changed("older-than") && !neverRunFlag {
+		return errors.New("'--older-than' requires '--never-run'")
+	}
+	if olderThanFlag < 0 {
+		return errors.New("'--older-than' must not be negative")
+	}
+	if concurrencyConflictsFlag && expectedDurationFlag <= 0 {
+		return errors.New("'--concurrency-conflicts' requires '--expected-duration' greater than zero")
+	}
+	if duplicateKeyFlag != duplicateKeyScheduleImage && duplicateKeyFlag != duplicateKeyScheduleName {
+		return fmt.Errorf("%s is unsupported '--duplicate-key'", duplicateKeyFlag)
+	}
+	var demandBucketDuration time.Duration
+	if demandFlag != "" {
+		demandBucketDuration, err = parseDuration(demandFlag)
+		if err != nil {
+			return fmt.Errorf("failed to parse '--demand' value: %w", err)
+		}
+		if demandBucketDuration <= 0 {
+			return errors.New("'--demand' must be greater than zero")
+		}
+	}
+	if cacheTTLFlag < 0 {
+		return errors.New("'--cache-ttl' must not be negative")
+	}
+	var shift time.Duration
+	if shiftFlag != "" {
+		shift, err = parseShift(shiftFlag)
+		if err != nil {
+			return err
+		}
+	}
+	actions := 0
+	for _, set := range []bool{shiftFlag != "", setTimezoneFlag != "", suspendFlag, resumeFlag, resumeAllFlag} {
+		if set {
+			actions++
+		}
+	}
+	if actions > 1 {
+		return errors.New("only one of '--shift', '--set-timezone', '--suspend', '--resume', and '--resume-all' can be used")
+	}
+	if generatePatchesFlag != "" {
+		if actions == 0 {
+			return errors.New("'--generate-patches' requires '--shift', '--set-timezone', '--suspend', '--resume', or '--resume-all'")
+		}
+		if printCommandsFlag {
+			return errors.New("only one of '--print-commands' and '--generate-patches' can be used")
+		}
+	}
+	if auditLogFlag != "" && actions == 0 {
+		return errors.New("'--audit-log' requires '--shift', '--set-timezone', '--suspend', '--resume', or '--resume-all'")
+	}
+	if len(providerFlag) > 0 {
+		if err := validateProviderFlags(fsets, outputFlag); err != nil {
+			return err
+		}
+		if providerTimeoutFlag <= 0 {
+			return errors.New("'--provider-timeout' must be greater than zero")
+		}
+	}
+	var includedCRDs []crdKind
+	if len(includeCRDsFlag) > 0 {
+		includedCRDs, err = parseCRDKinds(includeCRDsFlag)
+		if err != nil {
+			return err
+		}
+		if err := validateCRDFlags(fsets); err != nil {
+			return err
+		}
+	}
+	if namesFromFlag != "" {
+		if err := validateNamesFromFlags(fsets); err != nil {
+			return err
+		}
+	}
+	if fixturesFlag != "" {
+		if err := validateFixturesFlags(fsets, actions, printCommandsFlag || generatePatchesFlag != ""); err != nil {
+			return err
+		}
+	}
+	if setTimezoneFlag != "" {
+		setTimezoneFlag, err = parseSetTimezone(setTimezoneFlag)
+		if err != nil {
+			return err
+		}
+	}
+	if fsets.Changed("runtime-risk") && runtimeRiskFlag <= 0 {
+		return errors.New("'--runtime-risk' must be positive")
+	}
+	var policyRules []policyRule
+	if policyFlag != "" {
+		policyRules, err = loadPolicy(policyFlag)
+		if err != nil {
+			return err
+		}
+	}
+	var redactPattern *regexp.Regexp
+	if redactFlag {
+		redactPattern, err = regexp.Compile(redactPatternFlag)
+		if err != nil {
+			return fmt.Errorf("failed to parse '--redact-pattern' value: %w", err)
+		}
 	}
 
+	// List CronJobs and CronWorkflows
+	// -----------------
 	targetNamespace := ""
 	if *cfgFlags.Namespace != "" {
 		targetNamespace = *cfgFlags.Namespace
 	}
 
-	cronjobList, err := k8sClient.BatchV1().CronJobs(targetNamespace).List(context.Background(), metav1.ListOptions{LabelSelector: selectorFlag})
-	if err != nil {
-		if targetNamespace == "" {
-			targetNamespace = "all"
+	// now is the local clock, or the one of the API server with '--server-time'.
+	now := time.Now
+	// The reports of all the items regardless of the period.
+	listAll := statsFlag || tzReportFlag || saveFlag != "" || compareFlag != ""
+	var c *clients
+	var items []item
+	// checked are the items named by '--names-from', matched or not.
+	var checked []item
+	// listWarnings are the warnings of the listing in the output document.
+	var listWarnings []documentWarning
+	if fixturesFlag != "" {
+		defaultNamespace, err := getDefaultNamespace(cfgFlags, podEnv)
+		if err != nil {
+			return err
+		}
+		start := time.Now()
+		items, err = listFixtures(fixturesFlag, targetNamespace, defaultNamespace, selectorFlag, from, to, bounds, listAll)
+		if err != nil {
+			return err
+		}
+		timing.record(timingList, "fixtures", "", time.Since(start))
+	} else {
+		start := time.Now()
+		c, err = buildClients(cfgFlags)
+		if err != nil {
+			return err
+		}
+		if serverTimeFlag {
+			now = getServerClock(context.Background(), c, time.Now, logger)
+			from, to, err = resolvePeriod(now())
+			if err != nil {
+				return err
+			}
+		}
+		timing.record(timingConfig, "", "", time.Since(start))
+		c.timings = timing
+
+		logger.Debug(fmt.Sprintf("listing the CronJobs in %s", c.cronJobVersion()), "kind", "CronJob", "version", c.cronJobVersion())
+		c.serverTable = !c.noServerTable && useServerTable(fsets, outputFlag)
+		if !strictFlag {
+			c.failures = &kindFailures{}
+		}
+		c.crdKinds = includedCRDs
+		var progress *progressLine
+		if !verboseFlag && !quietFlag && logFormatFlag == logFormatText {
+			progress = newProgressLine(stderr)
+		}
+		if logFormatFlag == logFormatJSON {
+			// The progress line is for terminals, so log the events instead.
+			c.progress = func(e progressEvent) { logProgress(logger, e) }
+		}
+		if progress != nil {
+			c.progress = progress.update
+			// Cleared before the errors as well.
+			defer progress.clear()
+		}
+		c.cache, err = newListCache(cfgFlags, cacheTTLFlag, noCacheFlag, logger)
+		if err != nil {
+			return err
+		}
+
+		if namesFromFlag != "" {
+			defaultNamespace := targetNamespace
+			if defaultNamespace == "" {
+				defaultNamespace, err = getDefaultNamespace(cfgFlags, podEnv)
+				if err != nil {
+					return err
+				}
+			}
+			names, err := readNamesFrom(namesFromFlag, os.Stdin, defaultNamespace)
+			if err != nil {
+				return err
+			}
+			named, err := getNamedItems(context.Background(), c, names, logger)
+			if err != nil {
+				return err
+			}
+			checked = named
+			items, err = getScheduleIncludedItems(named, from, to, bounds)
+			if err != nil {
+				return fmt.Errorf("failed to get the items in the from-to period: %w", err)
+			}
+		} else if listAll {
+			if isNamespacePattern(targetNamespace) {
+				namespaces, err := expandNamespacePatterns(context.Background(), c.k8s, []string{targetNamespace})
+				if err != nil {
+					return err
+				}
+				items, err = listItemsInNamespaces(context.Background(), c, namespaces, selectorFlag)
+			} else {
+				items, err = listAllItems(context.Background(), c, targetNamespace, namespaceSelectorFlag, selectorFlag)
+			}
+			if err != nil {
+				return err
+			}
+		} else if namespaceSelectorFlag != "" || len(namespacesFlag) > 0 || isNamespacePattern(targetNamespace) {
+			namespaces := namespacesFlag
+			if isNamespacePattern(targetNamespace) {
+				namespaces = []string{targetNamespace}
+			}
+			namespaces, err = expandNamespacePatterns(context.Background(), c.k8s, namespaces)
+			if err != nil {
+				return err
+			}
+			if namespaceSelectorFlag != "" {
+				namespaces, err = resolveNamespaces(context.Background(), c.k8s, namespaceSelectorFlag)
+				if err != nil {
+					return err
+				}
+			}
+			var skipped []string
+			items, skipped, err = listScheduleIncludedInNamespaces(context.Background(), c, namespaces, selectorFlag, from, to, bounds)
+			if err != nil {
+				return err
+			}
+			progress.clear()
+			warnSkippedNamespaces(logger, skipped)
+			listWarnings = append(listWarnings, skippedNamespaceWarnings(skipped)...)
+		} else if targetNamespace == "" {
+			var skipped []string
+			items, skipped, err = listScheduleIncludedInAllNamespaces(context.Background(), c, selectorFlag, from, to, bounds)
+			if err != nil {
+				return err
+			}
+			progress.clear()
+			warnSkippedNamespaces(logger, skipped)
+			listWarnings = append(listWarnings, skippedNamespaceWarnings(skipped)...)
+		} else {
+			items, err = listScheduleIncluded(context.Background(), c, targetNamespace, selectorFlag, from, to, bounds)
+			if err != nil {
+				return err
+			}
+		}
+
+		progress.clear()
+		if c.failures.failed() {
+			c.failures.warn(logger)
+			listWarnings = append(listWarnings, c.failures.documentWarnings()...)
+			defer func() {
+				if retErr == nil {
+					retErr = errPartialFailure
+				}
+			}()
 		}
-		return fmt.Errorf("failed to get CronJobs in '%s' namespace: %w", targetNamespace, err)
 	}
-	includedCronJobs, err := getScheduleIncludedCronJobs(cronjobList.Items, from, to)
-	if err != nil {
-		return fmt.Errorf("failed to get CronJobs in the from-to period: %w", err)
+
+	// List the objects of the providers
+	// -----------------
+	if len(providerFlag) > 0 {
+		start := time.Now()
+		provided, err := listProviders(context.Background(), providerFlag, providerTimeoutFlag, providerQuery{Namespace: targetNamespace, Selector: selectorFlag})
+		if err != nil {
+			return err
+		}
+		timing.record(timingList, "providers", "", time.Since(start))
+		start = time.Now()
+		provided, err = getScheduleIncludedItems(provided, from, to, bounds)
+		if err != nil {
+			return fmt.Errorf("failed to get the items in the from-to period: %w", err)
+		}
+		timing.record(timingEvaluation, "providers", "", time.Since(start))
+		items = append(items, provided...)
+		sortItems(items)
 	}
+	// The items listed without the evaluation, such as those of '--stats'.
+	bounds.lints.addItems(items)
 
-	// List CronWorkflows
+	if timing != nil {
+		// Everything after the listing and the evaluation is printing.
+		start := time.Now()
+		defer func() {
+			timing.record(timingPrint, "", "", time.Since(start))
+			if err := printTimings(stderr, logger, logFormatFlag, timing.phases()); err != nil && retErr == nil {
+				retErr = err
+			}
+		}()
+	}
+
+	// Filter
 	// -----------------
-	argoClient, err := argov1alpha1.NewForConfig(cfg)
-	if err != nil {
-		return fmt.Errorf("failed to get argo workflows client: %w", err)
+	filters := []filter{}
+	if missingHistoryLimitsFlag {
+		filters = append(filters, missingHistoryLimitsFilter)
 	}
-	cronworkflowList, err := argoClient.CronWorkflows(targetNamespace).List(context.Background(), metav1.ListOptions{LabelSelector: selectorFlag})
-	if err != nil {
-		if targetNamespace == "" {
-			targetNamespace = "all"
+	if missingLimitsFlag {
+		filters = append(filters, missingLimitsFilter(missingLimitsInitFlag))
+	}
+	if registryAllowlistFlag != "" {
+		filters = append(filters, registryAllowlistFilter(registryAllowlist))
+	}
+	if mountsSecretFlag != "" {
+		filters = append(filters, mountsFilter(mountSecret, mountsSecretFlag))
+	}
+	if mountsConfigMapFlag != "" {
+		filters = append(filters, mountsFilter(mountConfigMap, mountsConfigMapFlag))
+	}
+	if mountsPVCFlag != "" {
+		filters = append(filters, mountsFilter(mountPVC, mountsPVCFlag))
+	}
+	if fsets.Changed("priority-class") {
+		filters = append(filters, priorityClassFilter(priorityClassFlag))
+	}
+	if minCPURequestFlag != "" {
+		filters = append(filters, minRequestFilter(corev1.ResourceCPU, minCPURequest))
+	}
+	if minMemoryRequestFlag != "" {
+		filters = append(filters, minRequestFilter(corev1.ResourceMemory, minMemoryRequest))
+	}
+	if firesOnFlag != "" {
+		filters = append(filters, firesOnFilter(firesOnDays, firesOnModeFlag == "only", from))
+	}
+	if dailyBetweenFlag != "" {
+		filters = append(filters, dailyBetweenFilter(dailyBetween, location, from, to, bounds))
+	}
+	if len(annotationRegexes) > 0 {
+		filters = append(filters, annotationRegexFilter(annotationRegexes))
+	}
+	if templateSelectorFlag != "" {
+		filters = append(filters, templateSelector)
+	}
+	if ownedByFlag != "" {
+		filters = append(filters, ownedByFilter(ownedByKind, ownedByName))
+	}
+	if unownedFlag {
+		filters = append(filters, unownedFilter)
+	}
+	if ttlFlag != "" {
+		filters = append(filters, ttlFilter(ttlCondition))
+	}
+	if neverRunFlag {
+		filters = append(filters, neverRunFilter(olderThanFlag, now()))
+	}
+	if entrypointFlag != "" {
+		resolved := map[templateKey]string{}
+		if resolveTemplatesFlag {
+			resolved, err = resolveTemplateEntrypoints(context.Background(), c, items)
+			if err != nil {
+				return err
+			}
 		}
-		return fmt.Errorf("failed to get CronWorkflow in '%s' namespace: %w", targetNamespace, err)
+		filters = append(filters, entrypointFilter(entrypointFlag, resolved))
 	}
-	includedCronWorkflows, err := getScheduleIncludedCronWorkflows(cronworkflowList.Items, from, to)
-	if err != nil {
-		return fmt.Errorf("failed to get CronWorkflows in the from-to period: %w", err)
+	items = applyFilters(filters, items)
+	if searchFlag != "" {
+		items = searchItems(items, searchFlag, sortByFlag == sortByScore)
+	}
+
+	// Snapshot
+	// -----------------
+	if saveFlag != "" || compareFlag != "" {
+		// Load it before saving, which may overwrite the same file.
+		var snapshot []item
+		if compareFlag != "" {
+			snapshot, err = loadSnapshot(compareFlag)
+			if err != nil {
+				return err
+			}
+		}
+		if saveFlag != "" {
+			if err := saveSnapshot(saveFlag, items, documentOptions{window: &documentWindow{From: from, To: to, Round: roundFlag}, bounds: bounds, redact: redactPattern}); err != nil {
+				return err
+			}
+		}
+		if compareFlag != "" {
+			return printSnapshotChanges(stdout, outputFlag, noHeadersFlag, compareFlag, compareSnapshot(snapshot, items))
+		}
+		if !statsFlag && !tzReportFlag {
+			items, err = getScheduleIncludedItems(items, from, to, bounds)
+			if err != nil {
+				return fmt.Errorf("failed to get the items in the from-to period: %w", err)
+			}
+		}
+	}
+
+	// Patch
+	// -----------------
+	if actions > 0 {
+		var plans []patchPlan
+		var prompt, verb string
+		switch {
+		case shiftFlag != "":
+			plans = planShift(items, shift)
+			verb = "shift"
+			prompt = "Shift the schedules of %d items by " + shift.String() + "?"
+		case setTimezoneFlag != "":
+			plans = planSetTimezone(items, setTimezoneFlag, onlyMissingFlag)
+			verb = "set-timezone"
+			prompt = "Set the time zone of %d items to " + setTimezoneFlag + "?"
+		case suspendFlag:
+			runID, err := newRunID()
+			if err != nil {
+				return err
+			}
+			plans, err = planSuspend(items, suspendMarker{From: from, To: to, RunID: runID})
+			if err != nil {
+				return err
+			}
+			prompt = "Suspend %d items (run ID " + runID + ")?"
+			verb = "suspend"
+		default:
+			plans = planResume(items, resumeAllFlag)
+			prompt = "Resume %d items?"
+			verb = "resume"
+		}
+		if printCommandsFlag {
+			return printPatchCommands(stdout, plans)
+		}
+		if generatePatchesFlag != "" {
+			results, err := writePatchFiles(c, generatePatchesFlag, plans)
+			if err != nil {
+				return err
+			}
+			return printPatchResults(stdout, outputFlag, noHeadersFlag, results)
+		}
+		var log *auditLog
+		if auditLogFlag != "" {
+			_, user, err := getKubeconfigUser(cfgFlags)
+			if err != nil {
+				return err
+			}
+			log, err = openAuditLog(auditLogFlag, user)
+			if err != nil {
+				return err
+			}
+			log.now = now
+			defer log.Close()
+		}
+		results, err := applyPatchPlans(context.Background(), c, plans, patchOptions{
+			dryRun:   dryRunFlag,
+			yes:      yesFlag,
+			stdin:    os.Stdin,
+			stderr:   stderr,
+			prompt:   prompt,
+			auditLog: log,
+			verb:     verb,
+		})
+		if results != nil {
+			if err := printPatchResults(stdout, outputFlag, noHeadersFlag, results); err != nil {
+				return err
+			}
+		}
+		return err
+	}
+
+	// Timezone report
+	// -----------------
+	if tzReportFlag {
+		entries, err := buildTZReport(items, from, to, bounds)
+		if err != nil {
+			return err
+		}
+		tf := timeFormat{location: displayLocation, relative: relativeTimesFlag, now: now()}
+		return printTZReport(stdout, outputFlag, noHeadersFlag, tf, entries)
+	}
+
+	// Stats
+	// -----------------
+	if statsFlag {
+		tf := timeFormat{location: displayLocation, relative: relativeTimesFlag, now: now()}
+		return printStats(stdout, outputFlag, tf, computeStats(items, from, to, bounds, displayLocation))
+	}
+
+	// Rollup
+	// -----------------
+	if rollupLabelFlag != "" {
+		var k8s kubernetes.Interface
+		if c != nil {
+			k8s = c.k8s
+		}
+		groups, err := rollupItems(context.Background(), k8s, items, rollupLabelFlag, from, to, bounds)
+		if err != nil {
+			return err
+		}
+		return printRollup(stdout, outputFlag, noHeadersFlag, rollupLabelFlag, from, to, groups)
+	}
+
+	// Duplicates
+	// -----------------
+	if duplicatesFlag {
+		return printDuplicates(stdout, outputFlag, noHeadersFlag, duplicateKeyFlag, findDuplicates(items, duplicateKeyFlag))
+	}
+
+	// Policy
+	// -----------------
+	if policyFlag != "" {
+		violations, err := findViolations(items, policyRules, from)
+		if err != nil {
+			return err
+		}
+		if err := printViolations(stdout, outputFlag, noHeadersFlag, violations); err != nil {
+			return err
+		}
+		if failOnViolationFlag && len(violations) > 0 {
+			return fmt.Errorf("%d policy violations found", len(violations))
+		}
+		return nil
+	}
+
+	// Deadline risks
+	// -----------------
+	if deadlineRiskFlag {
+		risks, err := findDeadlineRisks(items, from)
+		if err != nil {
+			return err
+		}
+		return printDeadlineRisks(stdout, outputFlag, noHeadersFlag, risks)
+	}
+
+	// Runtime risks
+	// -----------------
+	if fsets.Changed("runtime-risk") {
+		risks, err := findRuntimeRisks(context.Background(), c, items, from, runtimeRiskFlag)
+		if err != nil {
+			return err
+		}
+		return printRuntimeRisks(stdout, outputFlag, noHeadersFlag, risks)
+	}
+
+	// Fire gaps
+	// -----------------
+	if assertFiresEveryFlag > 0 {
+		gaps, err := findFireGaps(items, from, to, assertFiresEveryFlag)
+		if err != nil {
+			return err
+		}
+		tf := timeFormat{location: displayLocation, relative: relativeTimesFlag, now: now()}
+		if err := printFireGaps(stdout, outputFlag, noHeadersFlag, tf, from, to, assertFiresEveryFlag, gaps); err != nil {
+			return err
+		}
+		if len(gaps) > 0 {
+			return fmt.Errorf("%d items don't fire every %s", len(gaps), assertFiresEveryFlag)
+		}
+		return nil
+	}
+
+	// Heatmap
+	// -----------------
+	if heatmapFlag {
+		h, err := buildHeatmap(items, from, displayLocation)
+		if err != nil {
+			return err
+		}
+		return printHeatmap(stdout, outputFlag, noHeadersFlag, heatmapShadeFlag, h)
+	}
+
+	// Demand
+	// -----------------
+	if demandFlag != "" {
+		report, err := projectDemand(items, from, to, bounds, demandBucketDuration)
+		if err != nil {
+			return err
+		}
+		tf := timeFormat{location: displayLocation, relative: relativeTimesFlag, now: now()}
+		return printDemand(stdout, logger, outputFlag, noHeadersFlag, tf, report)
+	}
+
+	// Concurrency conflicts
+	// -----------------
+	if concurrencyConflictsFlag {
+		conflicts, err := findConflicts(items, from, to, bounds, expectedDurationFlag)
+		if err != nil {
+			return err
+		}
+		tf := timeFormat{location: displayLocation, relative: relativeTimesFlag, now: now()}
+		return printConflicts(stdout, outputFlag, noHeadersFlag, tf, conflicts)
 	}
 
 	// PrintResults
 	// -----------------
+	docOpts := documentOptions{
+		raw:        rawFlag,
+		keepStatus: keepStatusFlag,
+		compact:    compactFlag,
+		redact:     redactPattern,
+		window:     &documentWindow{From: from, To: to, Round: roundFlag},
+		bounds:     bounds,
+		timing:     timing.phases(),
+		warnings:   append(listWarnings, bounds.lints.list()...),
+	}
+	var groups []itemGroup
+	if groupByLabelFlag != "" {
+		groups = groupItemsByLabel(items, groupByLabelFlag)
+		docOpts.groups = buildDocumentGroups(groupByLabelFlag, groups)
+	}
 	switch outputFlag {
 	case "json":
-		printJSON(stdout, includedCronJobs, includedCronWorkflows)
-	case "":
-		printList(stdout, noHeadersFlag, showLabelsFlag, includedCronJobs, includedCronWorkflows)
+		if err := printJSON(stdout, items, docOpts); err != nil {
+			return err
+		}
+	case "yaml":
+		if err := printYAML(stdout, items, docOpts); err != nil {
+			return err
+		}
+	case "mermaid":
+		if err := printMermaid(stdout, items, from, to, bounds, displayLocation, expectedDurationFlag); err != nil {
+			return err
+		}
+	case "junit":
+		if err := printJUnit(stdout, items, checked, from, to, bounds); err != nil {
+			return err
+		}
+	case "raw":
+		if err := printRaw(stdout, items, delimiterFlag, now(), displayLocation); err != nil {
+			return err
+		}
+	case "", "wide":
+		listOpts := printListOptions{
+			noHeaders:      noHeadersFlag,
+			showLabels:     showLabelsFlag,
+			wide:           outputFlag == "wide",
+			maxColumnWidth: maxColumnWidthFlag,
+			maxWidth:       maxWidthFlag,
+			timeFormat: timeFormat{
+				location: displayLocation,
+				relative: relativeTimesFlag,
+				now:      now(),
+			},
+			window:            docOpts.window,
+			bounds:            bounds,
+			missingLimits:     missingLimitsFlag,
+			missingLimitsInit: missingLimitsInitFlag,
+			registryAllowlist: registryAllowlist,
+		}
+		if !fsets.Changed("max-width") {
+			listOpts.maxWidth = terminalWidth(stdout)
+		}
+		if noTruncateFlag {
+			listOpts.maxColumnWidth = 0
+			listOpts.maxWidth = 0
+		}
+		printTable := func(w io.Writer) {
+			if groupByLabelFlag != "" {
+				printGroupedList(w, listOpts, groupByLabelFlag, groups)
+				return
+			}
+			printList(w, listOpts, items)
+		}
+		if noPagerFlag {
+			printTable(stdout)
+			break
+		}
+		var table bytes.Buffer
+		printTable(&table)
+		if err := terminalPager.write(stdout, stderr, table.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	// Write results into a ConfigMap
+	// -----------------
+	if writeConfigMapFlag != "" {
+		var document bytes.Buffer
+		if err := printJSON(&document, items, docOpts); err != nil {
+			return err
+		}
+		if err := writeConfigMap(context.Background(), c.k8s, configMapNamespace, configMapName, document.Bytes(), from, to); err != nil {
+			return err
+		}
+	}
+
+	// Post results
+	// -----------------
+	if postURLFlag != "" {
+		var body bytes.Buffer
+		if err := printJSON(&body, items, docOpts); err != nil {
+			return err
+		}
+		opts := postOptions{
+			url:                   postURLFlag,
+			headers:               postHeaders,
+			caFile:                postCAFileFlag,
+			insecureSkipTLSVerify: postInsecureSkipTLSVerifyFlag,
+			retries:               2,
+			retryWait:             time.Second,
+		}
+		if err := postResults(context.Background(), logger, opts, body.Bytes()); err != nil {
+			if !postBestEffortFlag {
+				return err
+			}
+			logger.Warn(err.Error(), "url", postURLFlag)
+		}
 	}
 
 	return nil
 }
 
-// Extract CronJobs to be executed during the from-to period.
-func getScheduleIncludedCronJobs(cronjobs []batchv1.CronJob, from, to time.Time) ([]batchv1.CronJob, error) {
-	// If there is no CronJob in the specified Namespace, return early.
-	if len(cronjobs) == 0 {
-		return []batchv1.CronJob{}, nil
+// clients holds the API clients used to list CronJobs and CronWorkflows.
+type clients struct {
+	k8s  kubernetes.Interface
+	argo argov1alpha1.ArgoprojV1alpha1Interface
+	// serverTable lists the CronJobs as server-side Tables, see listCronJobs.
+	serverTable bool
+	// cache reuses the listed objects when not nil, see listCache.
+	cache *listCache
+	// legacyCronJobs lists the CronJobs in batch/v1beta1, see
+	// detectLegacyCronJobs.
+	legacyCronJobs bool
+	// noServerTable disables serverTable for the clients which can't request
+	// the server-side Tables, e.g. the fake clientsets.
+	noServerTable bool
+	// dynamic lists the custom resources of crdKinds.
+	dynamic  dynamic.Interface
+	crdKinds []crdKind
+	// failures records the kinds failed to be listed instead of failing when
+	// not nil, see kindFailures.
+	failures *kindFailures
+	// progress receives the progress of the listing when not nil. It is called
+	// concurrently when the namespaces are listed in parallel.
+	progress func(progressEvent)
+	// serverTime gets the time of the API server for '--server-time', nil for
+	// the fake clientsets.
+	serverTime serverTime
+	// timings records the wall time of the listing and the evaluation when
+	// not nil, see '--timing'.
+	timings *timings
+}
+
+func newClients(cfgFlags *genericclioptions.ConfigFlags) (*clients, error) {
+	cfg, err := getRESTConfig(cfgFlags, podEnv)
+	if err != nil {
+		return nil, err
 	}
 
-	// Extract CronJobs to be executed during the from-to period.
-	scheduleIncludedCronJob := map[string]batchv1.CronJob{}
-	for _, cronjob := range cronjobs {
-		sched, err := cron.ParseStandard(cronjob.Spec.Schedule)
+	k8sClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubernetes client: %w", err)
+	}
+
+	argoClient, err := argov1alpha1.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get argo workflows client: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dynamic client: %w", err)
+	}
+
+	legacyCronJobs, err := detectLegacyCronJobs(k8sClient.Discovery())
+	if err != nil {
+		return nil, err
+	}
+
+	return &clients{k8s: k8sClient, argo: argoClient, dynamic: dynamicClient, legacyCronJobs: legacyCronJobs, serverTime: newServerTime(cfg)}, nil
+}
+
+// List CronJobs, as a server-side Table when c.serverTable, in batch/v1beta1
+// when c.legacyCronJobs, or from the cache.
+func listCronJobs(ctx context.Context, c *clients, namespace, selector string) ([]batchv1.CronJob, error) {
+	key := cacheKey{Resource: "cronjobs", Namespace: namespace, Selector: selector, Table: c.serverTable}
+	return cachedList(c.cache, key, func() ([]batchv1.CronJob, error) {
+		if c.legacyCronJobs {
+			return listLegacyCronJobs(ctx, c, namespace, selector)
+		}
+		if c.serverTable {
+			return listCronJobsAsTable(ctx, c, namespace, selector)
+		}
+		list, err := c.k8s.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse schedule spec '%s' of CronJob '%s/%s': %w", cronjob.Spec.Schedule, cronjob.Namespace, cronjob.Name, err)
+			return nil, err
 		}
-		if isInclude(sched, from, to) {
-			scheduleIncludedCronJob[cronjob.Namespace+cronjob.Name] = cronjob
+		return list.Items, nil
+	})
+}
+
+// List CronWorkflows, or from the cache.
+func listCronWorkflows(ctx context.Context, c *clients, namespace, selector string) ([]wfv1alpha1.CronWorkflow, error) {
+	key := cacheKey{Resource: "cronworkflows", Namespace: namespace, Selector: selector}
+	return cachedList(c.cache, key, func() ([]wfv1alpha1.CronWorkflow, error) {
+		list, err := c.argo.CronWorkflows(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return nil, err
 		}
+		return list.Items, nil
+	})
+}
+
+// cronJobSource lists the CronJobs with listCronJobs.
+type cronJobSource struct {
+	c *clients
+}
+
+func (s cronJobSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	cronjobs, err := listCronJobs(ctx, s.c, opts.Namespace, opts.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CronJobs in '%s' namespace: %w", displayNamespace(opts.Namespace), err)
 	}
+	return cls.CronJobs(cronjobs), nil
+}
+
+// cronWorkflowSource lists the CronWorkflows with listCronWorkflows.
+type cronWorkflowSource struct {
+	c *clients
+}
 
-	// sort
-	sortedKeys := maps.Keys(scheduleIncludedCronJob)
-	sort.Strings(sortedKeys)
-	ret := make([]batchv1.CronJob, len(sortedKeys))
-	for i, sortedKey := range sortedKeys {
-		ret[i] = scheduleIncludedCronJob[sortedKey]
+func (s cronWorkflowSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	cronworkflows, err := listCronWorkflows(ctx, s.c, opts.Namespace, opts.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CronWorkflow in '%s' namespace: %w", displayNamespace(opts.Namespace), err)
 	}
+	return cls.CronWorkflows(cronworkflows), nil
+}
 
-	return ret, nil
+// listedSource is a Source of the objects already listed, regardless of the
+// namespace and the selector.
+type listedSource []cls.Scheduled
+
+func (s listedSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	return s, nil
 }
 
-// Extract CronWorkflows list to be executed during the from-to period.
-func getScheduleIncludedCronWorkflows(cronworkflows []wfv1alpha1.CronWorkflow, from, to time.Time) ([]wfv1alpha1.CronWorkflow, error) {
-	// If there is no CronJob in the specified Namespace, return early.
-	if len(cronworkflows) == 0 {
-		return []wfv1alpha1.CronWorkflow{}, nil
+func displayNamespace(namespace string) string {
+	if namespace == "" {
+		return "all"
 	}
+	return namespace
+}
 
-	// Extract CronWorkflows to be executed during the from-to period.
-	scheduleIncludedCronWorkflow := map[string]wfv1alpha1.CronWorkflow{}
-	for _, cronworkflow := range cronworkflows {
-		sched, err := cron.ParseStandard(cronworkflow.Spec.Schedule)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse schedule spec '%s' of CronJob '%s/%s': %w", cronworkflow.Spec.Schedule, cronworkflow.Namespace, cronworkflow.Name, err)
+// List CronJobs and CronWorkflows to be executed during the from-to period,
+// sorted by namespace, name, and kind. An empty namespace means all namespaces.
+// When c.failures is set, a kind failed to be listed is recorded there and the
+// other kind is returned, unless both of them failed.
+func listScheduleIncluded(ctx context.Context, c *clients, namespace, selector string, from, to time.Time, bounds boundaries) ([]item, error) {
+	sources := []cls.Source{cronJobSource{c}, cronWorkflowSource{c}}
+	resources := []string{"cronjobs", "cronworkflows"}
+	kinds := []string{"CronJob", "CronWorkflow"}
+	for _, kind := range c.crdKinds {
+		sources = append(sources, crdSource{c: c, kind: kind})
+		resources = append(resources, kind.resource.Resource)
+		kinds = append(kinds, kind.kind)
+	}
+	if c.progress != nil {
+		for i, source := range sources {
+			sources[i] = progressSource{Source: source, resource: resources[i], report: c.progress}
+		}
+	}
+	var listed time.Duration
+	if c.timings != nil {
+		for i, source := range sources {
+			sources[i] = timingSource{Source: source, resource: resources[i], timings: c.timings, listed: &listed}
 		}
-		if isInclude(sched, from, to) {
-			scheduleIncludedCronWorkflow[cronworkflow.Namespace+cronworkflow.Name] = cronworkflow
+	}
+	if bounds.lints != nil {
+		for i, source := range sources {
+			sources[i] = lintSource{Source: source, lints: bounds.lints}
+		}
+	}
+	var failed []error
+	if c.failures != nil {
+		for i, source := range sources {
+			sources[i] = tolerantSource{Source: source, kind: kinds[i], failed: &failed}
 		}
 	}
+	start := time.Now()
+	matched, err := cls.Match(ctx, sources, bounds.options(namespace, selector, from, to))
+	c.timings.record(timingEvaluation, "", namespace, time.Since(start)-listed)
+	if err == nil && len(failed) == len(sources) {
+		// Nothing is listed, fail as well as '--strict'.
+		err = failed[0]
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the items in the from-to period: %w", err)
+	}
+	c.failures.add(failed...)
+	return scheduledItems(matched), nil
+}
 
-	// sort
-	sortedKeys := maps.Keys(scheduleIncludedCronWorkflow)
-	sort.Strings(sortedKeys)
-	ret := make([]wfv1alpha1.CronWorkflow, len(sortedKeys))
-	for i, sortedKey := range sortedKeys {
-		ret[i] = scheduleIncludedCronWorkflow[sortedKey]
+// Extract the items to be executed during the from-to period.
+func getScheduleIncludedItems(items []item, from, to time.Time, bounds boundaries) ([]item, error) {
+	scheduled := make(listedSource, len(items))
+	for i, item := range items {
+		scheduled[i] = item.scheduled()
+	}
+	var source cls.Source = scheduled
+	if bounds.lints != nil {
+		source = lintSource{Source: source, lints: bounds.lints}
+	}
+	matched, err := cls.Match(context.Background(), []cls.Source{source}, bounds.options("", "", from, to))
+	if err != nil {
+		return nil, err
+	}
+	return scheduledItems(matched), nil
+}
+
+// Extract CronJobs to be executed during the from-to period.
+func getScheduleIncludedCronJobs(cronjobs []batchv1.CronJob, from, to time.Time, bounds boundaries) ([]batchv1.CronJob, error) {
+	matched, err := cls.Match(context.Background(), []cls.Source{listedSource(cls.CronJobs(cronjobs))}, bounds.options("", "", from, to))
+	if err != nil {
+		return nil, err
 	}
+	ret := make([]batchv1.CronJob, len(matched))
+	for i, m := range matched {
+		ret[i] = *m.(cls.CronJob).CronJob
+	}
+	return ret, nil
+}
 
+// Extract CronWorkflows list to be executed during the from-to period.
+func getScheduleIncludedCronWorkflows(cronworkflows []wfv1alpha1.CronWorkflow, from, to time.Time, bounds boundaries) ([]wfv1alpha1.CronWorkflow, error) {
+	matched, err := cls.Match(context.Background(), []cls.Source{listedSource(cls.CronWorkflows(cronworkflows))}, bounds.options("", "", from, to))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]wfv1alpha1.CronWorkflow, len(matched))
+	for i, m := range matched {
+		ret[i] = *m.(cls.CronWorkflow).CronWorkflow
+	}
 	return ret, nil
 }
 
-// Whether the schedule is included in the from-to period.
-func isInclude(sched cron.Schedule, from, to time.Time) bool {
-	// To include the 'from' time in the from-to period.
-	from = from.Add(-1 * time.Second)
+// boundaries is the inclusivity of the from-to period. The zero value
+// includes both 'from' and 'to'.
+type boundaries struct {
+	exclusiveFrom bool
+	exclusiveTo   bool
+	// trace, when set, receives the evaluations of the schedules in the
+	// period. See '--trace-eval'.
+	trace func(cls.Evaluation)
+	// lints, when set, collects the warnings of the schedules before they are
+	// parsed, see lintSchedule.
+	lints *scheduleLints
+}
+
+func (b boundaries) window(from, to time.Time) cls.Window {
+	return cls.Window{From: from, To: to, ExclusiveFrom: b.exclusiveFrom, ExclusiveTo: b.exclusiveTo}
+}
 
-	next := sched.Next(from)
+func (b boundaries) options(namespace, selector string, from, to time.Time) cls.Options {
+	return cls.Options{Namespace: namespace, Selector: selector, Window: b.window(from, to), Trace: b.trace}
+}
 
-	// To include the 'to' time in the from-to period.
-	if next.Equal(to) {
-		return true
+// Get the time to pass to Next to get the first schedule in the period.
+func (b boundaries) start(from time.Time) time.Time {
+	return b.window(from, time.Time{}).Start()
+}
+
+// Whether the time of a schedule is not after the end of the period.
+func (b boundaries) beforeEnd(t, to time.Time) bool {
+	return b.window(time.Time{}, to).BeforeEnd(t)
+}
+
+// Whether the schedule is included in the from-to period.
+func isInclude(sched cron.Schedule, from, to time.Time, bounds boundaries) bool {
+	return bounds.window(from, to).Includes(sched)
+}
+
+// Count the schedules included in the from-to period.
+func countInclude(sched cron.Schedule, from, to time.Time, bounds boundaries) int {
+	count := 0
+	for next := sched.Next(bounds.start(from)); !next.IsZero() && bounds.beforeEnd(next, to); next = sched.Next(next) {
+		count++
 	}
+	return count
+}
 
-	if next.After(to) {
-		return false
+// Parse the time zone value of the flag. 'local' is the local zone of the
+// machine, any other value is an IANA time zone name.
+func parseLocation(flag, value string) (*time.Location, error) {
+	if strings.EqualFold(value, "local") {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse '--%s' value: %w", flag, err)
 	}
+	return loc, nil
+}
 
-	return true
+type printListOptions struct {
+	noHeaders      bool
+	showLabels     bool
+	wide           bool
+	maxColumnWidth int
+	// maxWidth is the width the whole table is fitted into. Zero disables it.
+	maxWidth   int
+	timeFormat timeFormat
+	// window is the period of the first and last fires of '-o wide'.
+	window *documentWindow
+	bounds boundaries
+	// missingLimits adds the column of the containers lacking the limits to
+	// '-o wide', with the init containers with missingLimitsInit.
+	missingLimits     bool
+	missingLimitsInit bool
+	// registryAllowlist adds the column of the images not in it when set.
+	registryAllowlist []string
 }
 
-func printList(stdout io.Writer, noHeaders, showLabels bool, cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) {
-	tw := tabwriter.NewWriter(stdout, 0, 1, 3, ' ', 0)
-	if !noHeaders {
-		if showLabels {
-			fmt.Fprintln(tw, "Namespace\tName\tSchedule\tSuspend\tKind\tLabels")
-		} else {
-			fmt.Fprintln(tw, "Namespace\tName\tSchedule\tSuspend\tKind")
+func printList(stdout io.Writer, opts printListOptions, items []item) {
+	headers := []string{"Namespace", "Name", "Schedule", "Suspend", "Kind"}
+	if opts.wide {
+		headers = append(headers, wideHeaders...)
+		if opts.missingLimits {
+			headers = append(headers, "Missing Limits")
 		}
 	}
+	if opts.registryAllowlist != nil {
+		headers = append(headers, "Disallowed Images")
+	}
+	if opts.showLabels {
+		headers = append(headers, "Labels")
+	}
 
-	if len(cronjobs) != 0 {
-		for _, cronjob := range cronjobs {
-			if showLabels {
-				labels := make([]string, len(cronjob.GetLabels()))
-				i := 0
-				for k, v := range cronjob.GetLabels() {
-					labels[i] = fmt.Sprintf("%s=%s", k, v)
-					i++
-				}
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\tCronJob\t%s\n", cronjob.Namespace, cronjob.Name, cronjob.Spec.Schedule, *cronjob.Spec.Suspend, strings.Join(labels, ","))
-			} else {
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\tCronJob\n", cronjob.Namespace, cronjob.Name, cronjob.Spec.Schedule, *cronjob.Spec.Suspend)
+	rows := make([][]string, len(items))
+	for i, item := range items {
+		obj := item.object()
+		row := []string{obj.GetNamespace(), obj.GetName(), formatSchedules(item, opts), strconv.FormatBool(item.suspended()), item.kind()}
+		if opts.wide {
+			row = append(row, wideColumns(item, opts.timeFormat, opts.window, opts.bounds)...)
+			if opts.missingLimits {
+				row = append(row, formatMissingLimits(item, opts.missingLimitsInit))
 			}
 		}
+		if opts.registryAllowlist != nil {
+			row = append(row, strings.Join(getDisallowedImages(item, opts.registryAllowlist), ","))
+		}
+		if opts.showLabels {
+			row = append(row, formatLabels(obj.GetLabels()))
+		}
+		for j := range row {
+			row[j] = truncate(row[j], opts.maxColumnWidth)
+		}
+		rows[i] = row
 	}
 
-	if len(cronworkflows) != 0 {
-		for _, cronworkflow := range cronworkflows {
-			if showLabels {
-				labels := make([]string, len(cronworkflow.GetLabels()))
-				i := 0
-				for k, v := range cronworkflow.GetLabels() {
-					labels[i] = fmt.Sprintf("%s=%s", k, v)
-					i++
-				}
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\tCronWorkflow\t%s\n", cronworkflow.Namespace, cronworkflow.Name, cronworkflow.Spec.Schedule, cronworkflow.Spec.Suspend, strings.Join(labels, ","))
-			} else {
-				fmt.Fprintf(tw, "%s\t%s\t%s\t%t\tCronWorkflow\n", cronworkflow.Namespace, cronworkflow.Name, cronworkflow.Spec.Schedule, cronworkflow.Spec.Suspend)
+	if opts.maxWidth > 0 {
+		var visibleHeaders []string
+		if !opts.noHeaders {
+			visibleHeaders = headers
+		}
+		widths := fitColumnWidths(visibleHeaders, rows, tablePadding, opts.maxWidth)
+		for _, row := range rows {
+			for j := range row {
+				row[j] = truncate(row[j], widths[j])
 			}
 		}
 	}
 
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !opts.noHeaders {
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
 	tw.Flush()
 }
 
+// Format labels as 'k1=v1,k2=v2' sorted by key.
+func formatLabels(labels map[string]string) string {
+	keys := maps.Keys(labels)
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%s", k, labels[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Format the schedules of the item in the schedule column, the one which
+// matched the window first. The table shows 'expr1 (+2 more)' for multiple
+// schedules, and '-o wide' lists all of them separated by '; ', as the
+// expressions may contain commas. The named schedules are 'name: expr'.
+func formatSchedules(item item, opts printListOptions) string {
+	matched := 0
+	if opts.window != nil {
+		matched = item.matchedScheduleIndex(opts.window.From, opts.window.To, opts.bounds)
+	}
+	schedules := moveToFront(item.schedules(), matched)
+	for j, name := range moveToFront(item.scheduleNames(), matched) {
+		if name != "" {
+			schedules[j] = name + ": " + schedules[j]
+		}
+	}
+	if opts.wide || len(schedules) == 1 {
+		return strings.Join(schedules, "; ")
+	}
+	return fmt.Sprintf("%s (+%d more)", schedules[0], len(schedules)-1)
+}
+
+// schemaVersion is the version of the json/yaml output document.
+// Bump it when an incompatible change is made to printformat.
+const schemaVersion = 1
+
 type printformat struct {
-	ApiVersion string `json:"apiVersion"`
-	Items      []any  `json:"items"`
+	ApiVersion    string          `json:"apiVersion"`
+	SchemaVersion int             `json:"schemaVersion"`
+	Window        *documentWindow `json:"window,omitempty"`
+	// Timing is the wall time of the phases before printing, with '--timing'.
+	Timing []timingPhase `json:"timing,omitempty"`
+	// Warnings are those written into stderr, such as the kinds which failed
+	// to be listed and the nonstandard syntax of the schedules.
+	Warnings []documentWarning `json:"warnings,omitempty"`
+	// Groups are the groups of the items by '--group-by-label'.
+	Groups      []documentGroup `json:"groups,omitempty"`
+	Items       []any           `json:"items"`
+	Evaluations []evaluation    `json:"evaluations"`
+}
+
+// documentWindow is the from-to period the items were evaluated in, after the
+// rounding by '--round'.
+type documentWindow struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Round string    `json:"round,omitempty"`
+}
+
+// evaluation is the evaluation result of an item, in the same order as items.
+type evaluation struct {
+	Kind                    string `json:"kind"`
+	Namespace               string `json:"namespace"`
+	Name                    string `json:"name"`
+	StartingDeadlineSeconds *int64 `json:"startingDeadlineSeconds"`
+	// FirstFire and LastFire are the first and last fires in the window, null
+	// without a window. LastFire is also null when LastFireTruncated.
+	FirstFire         *time.Time `json:"firstFire"`
+	LastFire          *time.Time `json:"lastFire"`
+	LastFireTruncated bool       `json:"lastFireTruncated,omitempty"`
+	// Pods is the estimate of the pods started in the window, null without a
+	// window. It is a lower bound when PodsTruncated.
+	Pods          *int `json:"pods"`
+	PodsTruncated bool `json:"podsTruncated,omitempty"`
 }
 
-func buildPrintformat(cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) printformat {
-	items := make([]any, len(cronjobs)+len(cronworkflows))
+func buildPrintformat(items []item, opts documentOptions) printformat {
+	objects := make([]any, len(items))
+	evaluations := make([]evaluation, len(items))
+	for i, item := range items {
+		objects[i], evaluations[i] = buildDocument(item, opts)
+	}
 
-	for i, item := range cronjobs {
+	return printformat{
+		ApiVersion:    "v1",
+		SchemaVersion: schemaVersion,
+		Window:        opts.window,
+		Timing:        opts.timing,
+		Warnings:      opts.warnings,
+		Groups:        opts.groups,
+		Items:         objects,
+		Evaluations:   evaluations,
+	}
+}
+
+// buildDocument builds the object and the evaluation of an item in the
+// json/yaml output document.
+func buildDocument(item item, opts documentOptions) (any, evaluation) {
+	var fires fireRange
+	var pods *int
+	var podsTruncated bool
+	if opts.window != nil {
+		fires = getFireRange(item, opts.window.From, opts.window.To, opts.bounds)
+		if estimate, err := estimatePods(item, opts.window.From, opts.window.To, opts.bounds); err == nil {
+			pods, podsTruncated = &estimate.pods, estimate.truncated
+		}
+	}
+	switch {
+	case item.cronJob != nil:
+		cronjob := newCronJobDocument(item.cronJob, opts)
 		// manualy set TypeMeta manually because of this bug:
 		// https://github.com/kubernetes/client-go/issues/308
-		item.TypeMeta.APIVersion = "v1"
-		item.TypeMeta.Kind = "CronJob"
-		items[i] = item
-	}
-	for i, item := range cronworkflows {
+		// The batch/v1beta1 CronJobs keep their version, see
+		// convertLegacyCronJob.
+		if cronjob.TypeMeta.APIVersion != batchv1beta1.SchemeGroupVersion.String() {
+			cronjob.TypeMeta.APIVersion = "v1"
+		}
+		cronjob.TypeMeta.Kind = "CronJob"
+		return cronjob, evaluation{
+			Kind:                    "CronJob",
+			Namespace:               cronjob.Namespace,
+			Name:                    cronjob.Name,
+			StartingDeadlineSeconds: cronjob.Spec.StartingDeadlineSeconds,
+			FirstFire:               fires.first,
+			LastFire:                fires.last,
+			LastFireTruncated:       fires.truncated,
+			Pods:                    pods,
+			PodsTruncated:           podsTruncated,
+		}
+	case item.cronWorkflow != nil:
+		cronworkflow := newCronWorkflowDocument(item.cronWorkflow, opts)
 		// manualy set TypeMeta manually because of this bug:
 		// https://github.com/kubernetes/client-go/issues/308
-		item.TypeMeta.APIVersion = "argoproj.io/v1alpha1"
-		item.TypeMeta.Kind = "CronWorkflow"
-		items[i+len(cronjobs)] = item
+		cronworkflow.TypeMeta.APIVersion = "argoproj.io/v1alpha1"
+		cronworkflow.TypeMeta.Kind = "CronWorkflow"
+		return cronworkflow, evaluation{
+			Kind:                    "CronWorkflow",
+			Namespace:               cronworkflow.Namespace,
+			Name:                    cronworkflow.Name,
+			StartingDeadlineSeconds: cronworkflow.Spec.StartingDeadlineSeconds,
+			FirstFire:               fires.first,
+			LastFire:                fires.last,
+			LastFireTruncated:       fires.truncated,
+			Pods:                    pods,
+			PodsTruncated:           podsTruncated,
+		}
+	case item.provided != nil:
+		matched := 0
+		if opts.window != nil {
+			matched = item.matchedScheduleIndex(opts.window.From, opts.window.To, opts.bounds)
+		}
+		return item.provided.document(matched), evaluation{
+			Kind:              item.provided.kind,
+			Namespace:         item.provided.Namespace,
+			Name:              item.provided.Name,
+			FirstFire:         fires.first,
+			LastFire:          fires.last,
+			LastFireTruncated: fires.truncated,
+			Pods:              pods,
+			PodsTruncated:     podsTruncated,
+		}
 	}
+	return nil, evaluation{}
+}
 
-	return printformat{
-		ApiVersion: "v1",
-		Items:      items,
+// printJSON streams the document: it writes the envelope, then encodes the
+// items one at a time, so that the memory stays proportional to a single item.
+// The output is the same as json.MarshalIndent(buildPrintformat(...)), or as
+// json.Marshal with opts.compact.
+func printJSON(stdout io.Writer, items []item, opts documentOptions) error {
+	w := bufio.NewWriter(stdout)
+	enc := newDocumentEncoder(w, opts.compact)
+
+	enc.open()
+	enc.field("apiVersion", "v1")
+	enc.field("schemaVersion", schemaVersion)
+	if opts.window != nil {
+		enc.field("window", opts.window)
+	}
+	if opts.timing != nil {
+		enc.field("timing", opts.timing)
 	}
+	if opts.warnings != nil {
+		enc.field("warnings", opts.warnings)
+	}
+	if opts.groups != nil {
+		enc.field("groups", opts.groups)
+	}
+	// The evaluations are small, keep them until the items are written.
+	evaluations := make([]evaluation, len(items))
+	enc.array("items", len(items), func(i int) any {
+		var object any
+		object, evaluations[i] = buildDocument(items[i], opts)
+		return object
+	})
+	enc.array("evaluations", len(evaluations), func(i int) any {
+		return evaluations[i]
+	})
+	enc.close()
+
+	if enc.err != nil {
+		return fmt.Errorf("failed to marshal to json: %w", enc.err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write json: %w", err)
+	}
+	return nil
 }
 
-func printJSON(stdout io.Writer, cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) error {
-	pf := buildPrintformat(cronjobs, cronworkflows)
-	b, err := json.MarshalIndent(pf, "", "    ")
+func printYAML(stdout io.Writer, items []item, opts documentOptions) error {
+	pf := buildPrintformat(items, opts)
+	b, err := yaml.Marshal(pf)
 	if err != nil {
-		return fmt.Errorf("failed to marshal to json: %w", err)
+		return fmt.Errorf("failed to marshal to yaml: %w", err)
+	}
+	if _, err := stdout.Write(b); err != nil {
+		return fmt.Errorf("failed to write yaml: %w", err)
 	}
-	fmt.Fprint(stdout, string(b))
 	return nil
 }
@@ -1,17 +1,47 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
 	"github.com/google/go-cmp/cmp"
 	"github.com/robfig/cron/v3"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 )
 
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// Compare got with the golden file in testdata, or update it with -update.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file (run 'go test -update' to create it): %s", err)
+	}
+	if diff := cmp.Diff(string(want), string(got)); diff != "" {
+		t.Errorf("%s mismatch (-want +got):\n%s", name, diff)
+	}
+}
+
 func getTime(value string) time.Time {
 	t, err := time.Parse("2006-01-02T15:04:05Z07:00", value)
 	if err != nil {
@@ -56,6 +86,23 @@ func getCronWorkflow(namespace, name, schedule string, suspend bool) wfv1alpha1.
 	}
 }
 
+func newFakeClients(cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) *clients {
+	k8sObjects := make([]runtime.Object, len(cronjobs))
+	for i := range cronjobs {
+		k8sObjects[i] = &cronjobs[i]
+	}
+	argoObjects := make([]runtime.Object, len(cronworkflows))
+	for i := range cronworkflows {
+		argoObjects[i] = &cronworkflows[i]
+	}
+	return &clients{
+		k8s:  k8sfake.NewSimpleClientset(k8sObjects...),
+		argo: argofake.NewSimpleClientset(argoObjects...).ArgoprojV1alpha1(),
+		// The fake clientset can't respond with the server-side Tables.
+		noServerTable: true,
+	}
+}
+
 func Test_isInclude(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -154,13 +201,86 @@ func Test_isInclude(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			if got := isInclude(tt.args.sched, tt.args.from, tt.args.to); got != tt.want {
+			if got := isInclude(tt.args.sched, tt.args.from, tt.args.to, boundaries{}); got != tt.want {
 				t.Errorf("isInclude() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func Test_isInclude_boundaries(t *testing.T) {
+	t.Parallel()
+	from := getTime("2023-01-24T00:00:00Z")
+	to := getTime("2023-01-24T06:00:00Z")
+	atFrom := getSchedule("0 0 * * *")
+	atTo := getSchedule("0 6 * * *")
+	inside := getSchedule("0 3 * * *")
+
+	tests := []struct {
+		name       string
+		bounds     boundaries
+		wantAtFrom bool
+		wantAtTo   bool
+	}{
+		{
+			name:       "both inclusive (default)",
+			bounds:     boundaries{},
+			wantAtFrom: true,
+			wantAtTo:   true,
+		},
+		{
+			name:       "exclusive from",
+			bounds:     boundaries{exclusiveFrom: true},
+			wantAtFrom: false,
+			wantAtTo:   true,
+		},
+		{
+			name:       "exclusive to",
+			bounds:     boundaries{exclusiveTo: true},
+			wantAtFrom: true,
+			wantAtTo:   false,
+		},
+		{
+			name:       "both exclusive",
+			bounds:     boundaries{exclusiveFrom: true, exclusiveTo: true},
+			wantAtFrom: false,
+			wantAtTo:   false,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := isInclude(atFrom, from, to, tt.bounds); got != tt.wantAtFrom {
+				t.Errorf("isInclude() at from = %v, want %v", got, tt.wantAtFrom)
+			}
+			if got := isInclude(atTo, from, to, tt.bounds); got != tt.wantAtTo {
+				t.Errorf("isInclude() at to = %v, want %v", got, tt.wantAtTo)
+			}
+			if got := isInclude(inside, from, to, tt.bounds); !got {
+				t.Errorf("isInclude() inside = %v, want true", got)
+			}
+		})
+	}
+}
+
+func Test_countInclude_backToBack(t *testing.T) {
+	t.Parallel()
+	sched := getSchedule("0 */3 * * *")
+	first := []time.Time{getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")}
+	second := []time.Time{getTime("2023-01-24T06:00:00Z"), getTime("2023-01-24T12:00:00Z")}
+
+	// 06:00 is counted in both periods by default.
+	if got := countInclude(sched, first[0], first[1], boundaries{}) + countInclude(sched, second[0], second[1], boundaries{}); got != 6 {
+		t.Errorf("countInclude() inclusive total = %v, want 6", got)
+	}
+	// Half-open periods count each of 00:00, 03:00, 06:00, 09:00 once.
+	bounds := boundaries{exclusiveTo: true}
+	if got := countInclude(sched, first[0], first[1], bounds) + countInclude(sched, second[0], second[1], bounds); got != 4 {
+		t.Errorf("countInclude() half-open total = %v, want 4", got)
+	}
+}
+
 func Test_getScheduleIncludedCronJobs(t *testing.T) {
 	t.Parallel()
 	type args struct {
@@ -198,7 +318,7 @@ func Test_getScheduleIncludedCronJobs(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := getScheduleIncludedCronJobs(tt.args.cronjobs, tt.args.from, tt.args.to)
+			got, err := getScheduleIncludedCronJobs(tt.args.cronjobs, tt.args.from, tt.args.to, boundaries{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getScheduleIncludedCronJobs() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -247,7 +367,7 @@ func Test_getScheduleIncludedCronWorkflows(t *testing.T) {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			got, err := getScheduleIncludedCronWorkflows(tt.args.cronworkflows, tt.args.from, tt.args.to)
+			got, err := getScheduleIncludedCronWorkflows(tt.args.cronworkflows, tt.args.from, tt.args.to, boundaries{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("getScheduleIncludedCronWorkflows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -258,3 +378,263 @@ func Test_getScheduleIncludedCronWorkflows(t *testing.T) {
 		})
 	}
 }
+
+func Test_countInclude(t *testing.T) {
+	t.Parallel()
+	type args struct {
+		sched cron.Schedule
+		from  time.Time
+		to    time.Time
+	}
+	tests := []struct {
+		name string
+		args args
+		want int
+	}{
+		{
+			name: "every minute",
+			args: args{
+				sched: getSchedule("* * * * *"),
+				from:  getTime("2023-01-24T00:00:00Z"),
+				to:    getTime("2023-01-24T01:00:00Z"),
+			},
+			want: 61,
+		},
+		{
+			name: "exclude test",
+			args: args{
+				sched: getSchedule("0 3 * * *"),
+				from:  getTime("2023-01-24T00:00:00Z"),
+				to:    getTime("2023-01-24T01:00:00Z"),
+			},
+			want: 0,
+		},
+		{
+			name: "boundary test",
+			args: args{
+				sched: getSchedule("0 * * * *"),
+				from:  getTime("2023-01-24T00:00:00Z"),
+				to:    getTime("2023-01-24T01:00:00Z"),
+			},
+			want: 2,
+		},
+		{
+			name: "never fires",
+			args: args{
+				sched: getSchedule("0 0 30 2 *"),
+				from:  getTime("2023-01-24T00:00:00Z"),
+				to:    getTime("2023-01-24T01:00:00Z"),
+			},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := countInclude(tt.args.sched, tt.args.from, tt.args.to, boundaries{}); got != tt.want {
+				t.Errorf("countInclude() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_printJSON(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{
+		getCronJob("ns-a", "n-1", "*/5 0 * * *", false),
+	}
+	deadline := int64(0)
+	cronjobs[0].Spec.StartingDeadlineSeconds = &deadline
+	cronworkflows := []wfv1alpha1.CronWorkflow{
+		getCronWorkflow("ns-b", "n-2", "0 1 * * *", true),
+	}
+
+	var got bytes.Buffer
+	if err := printJSON(&got, mergeItems(cronjobs, cronworkflows), documentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "output.golden.json", got.Bytes())
+}
+
+func Test_printYAML(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{
+		getCronJob("ns-a", "n-1", "*/5 0 * * *", false),
+	}
+	cronworkflows := []wfv1alpha1.CronWorkflow{
+		getCronWorkflow("ns-b", "n-2", "0 1 * * *", true),
+	}
+
+	var got bytes.Buffer
+	if err := printYAML(&got, mergeItems(cronjobs, cronworkflows), documentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "output.golden.yaml", got.Bytes())
+}
+
+func Test_printList(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "n-1", "*/5 0 * * *", false)
+	cronjob.Labels = map[string]string{"helm.sh/chart": "chart-1.0.0", "app": "foo"}
+	cronworkflow := getCronWorkflow("ns-b", "n-2", "0 1 * * *", true)
+	deadline := int64(300)
+	cronworkflow.Spec.StartingDeadlineSeconds = &deadline
+
+	tests := []struct {
+		name string
+		opts printListOptions
+		want string
+	}{
+		{
+			name: "default",
+			opts: printListOptions{},
+			want: "" +
+				"Namespace   Name   Schedule      Suspend   Kind\n" +
+				"ns-a        n-1    */5 0 * * *   false     CronJob\n" +
+				"ns-b        n-2    0 1 * * *     true      CronWorkflow\n",
+		},
+		{
+			name: "no headers",
+			opts: printListOptions{noHeaders: true},
+			want: "" +
+				"ns-a   n-1   */5 0 * * *   false   CronJob\n" +
+				"ns-b   n-2   0 1 * * *     true    CronWorkflow\n",
+		},
+		{
+			name: "wide with labels",
+			opts: printListOptions{wide: true, showLabels: true},
+			want: "" +
+				"Namespace   Name   Schedule      Suspend   Kind           Schedules   Owner         Images   Deadline   Hist   Backoff   Concurrency   Workflow   Last Schedule   First Fire   Last Fire   Pods     Labels\n" +
+				"ns-a        n-1    */5 0 * * *   false     CronJob        1           chart-1.0.0   <none>   <unset>    -/-    <unset>   Allow                    <none>          <none>       <none>      <none>   app=foo,helm.sh/chart=chart-1.0.0\n" +
+				"ns-b        n-2    0 1 * * *     true      CronWorkflow   1           <none>        <none>   300s       -/-    <unset>   Allow         <none>     <none>          <none>       <none>      <none>   \n",
+		},
+		{
+			name: "max column width",
+			opts: printListOptions{showLabels: true, maxColumnWidth: 10},
+			want: "" +
+				"Namespace   Name   Schedule     Suspend   Kind         Labels\n" +
+				"ns-a        n-1    */5 0 *...   false     CronJob      app=foo...\n" +
+				"ns-b        n-2    0 1 * * *    true      CronWor...   \n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got bytes.Buffer
+			printList(&got, tt.opts, mergeItems([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{cronworkflow}))
+			if diff := cmp.Diff(tt.want, got.String()); diff != "" {
+				t.Errorf("printList() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_printList_wide(t *testing.T) {
+	t.Parallel()
+	deadline := int64(120)
+	backoffLimit := int32(2)
+	successful, failed := int32(3), int32(1)
+
+	cronjob := getCronJob("ns-a", "cj", "0 1 * * *", false)
+	cronjob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronjob.Spec.StartingDeadlineSeconds = &deadline
+	cronjob.Spec.SuccessfulJobsHistoryLimit = &successful
+	cronjob.Spec.FailedJobsHistoryLimit = &failed
+	cronjob.Spec.JobTemplate.Spec.BackoffLimit = &backoffLimit
+	cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main", Image: "ghcr.io/example/app:v1"}}
+
+	inline := getCronWorkflow("ns-a", "cw-inline", "0 2 * * *", false)
+	inline.Spec.ConcurrencyPolicy = wfv1alpha1.ReplaceConcurrent
+	inline.Spec.StartingDeadlineSeconds = &deadline
+	inline.Spec.WorkflowSpec.Entrypoint = "main"
+	inline.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "main", Container: &corev1.Container{Image: "alpine:3.17"}},
+	}
+
+	templateRef := getCronWorkflow("ns-b", "cw-ref", "0 3 * * *", true)
+	templateRef.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "backup"}
+
+	clusterTemplateRef := getCronWorkflow("ns-b", "cw-cluster-ref", "0 4 * * *", false)
+	clusterTemplateRef.Spec.ConcurrencyPolicy = wfv1alpha1.ForbidConcurrent
+	clusterTemplateRef.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "reindex", ClusterScope: true}
+
+	var got bytes.Buffer
+	printList(&got, printListOptions{wide: true}, mergeItems([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{inline, templateRef, clusterTemplateRef}))
+	assertGolden(t, "wide.golden.txt", got.Bytes())
+}
+
+func Test_printList_schedules(t *testing.T) {
+	t.Parallel()
+	getProvided := func(name string, schedules ...string) item {
+		return item{provided: &providedObject{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: name},
+			kind:       "CronHPA",
+			schedules:  schedules,
+		}}
+	}
+	items := []item{
+		getProvided("one", "0 1 * * *"),
+		getProvided("two", "0 12 * * *", "0 3 * * *"),
+		getProvided("five", "0 12 * * *", "0 13 * * *", "0 14 * * *", "0 2 * * *", "0 15 * * *"),
+	}
+	window := &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+		var got bytes.Buffer
+		printList(&got, printListOptions{window: window}, items)
+		want := "" +
+			"Namespace   Name   Schedule              Suspend   Kind\n" +
+			"ns-a        one    0 1 * * *             false     CronHPA\n" +
+			"ns-a        two    0 3 * * * (+1 more)   false     CronHPA\n" +
+			"ns-a        five   0 2 * * * (+4 more)   false     CronHPA\n"
+		if diff := cmp.Diff(want, got.String()); diff != "" {
+			t.Errorf("printList() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("wide", func(t *testing.T) {
+		t.Parallel()
+		var got bytes.Buffer
+		printList(&got, printListOptions{wide: true, window: window}, items)
+		// The blank columns of the providers are trimmed from the comparison.
+		var rows []string
+		for _, line := range strings.Split(strings.TrimSpace(got.String()), "\n")[1:] {
+			fields := strings.Split(line, "   ")
+			row := []string{}
+			for _, f := range fields {
+				if f = strings.TrimSpace(f); f != "" {
+					row = append(row, f)
+				}
+			}
+			rows = append(rows, strings.Join(row, " | "))
+		}
+		want := []string{
+			"ns-a | one | 0 1 * * * | false | CronHPA | 1 | 2023-01-24T01:00:00Z | 2023-01-24T01:00:00Z | 1",
+			"ns-a | two | 0 3 * * *; 0 12 * * * | false | CronHPA | 2 | 2023-01-24T03:00:00Z | 2023-01-24T03:00:00Z | 1",
+			"ns-a | five | 0 2 * * *; 0 12 * * *; 0 13 * * *; 0 14 * * *; 0 15 * * * | false | CronHPA | 5 | 2023-01-24T02:00:00Z | 2023-01-24T02:00:00Z | 1",
+		}
+		if diff := cmp.Diff(want, rows); diff != "" {
+			t.Errorf("printList() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		var got []providerItem
+		for _, item := range items {
+			doc, _ := buildDocument(item, documentOptions{window: window})
+			got = append(got, doc.(providerItem))
+		}
+		want := []providerItem{
+			{Name: "one", Namespace: "ns-a", Kind: "CronHPA", Schedule: "0 1 * * *"},
+			{Name: "two", Namespace: "ns-a", Kind: "CronHPA", Schedule: "0 3 * * *", Schedules: []string{"0 3 * * *", "0 12 * * *"}},
+			{Name: "five", Namespace: "ns-a", Kind: "CronHPA", Schedule: "0 2 * * *", Schedules: []string{"0 2 * * *", "0 12 * * *", "0 13 * * *", "0 14 * * *", "0 15 * * *"}},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("buildDocument() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
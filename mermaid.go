@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// defaultMermaidDuration is the nominal duration of the tasks of '-o mermaid'
+// without '--expected-duration' nor the annotation.
+const defaultMermaidDuration = 5 * time.Minute
+
+// itemOutputFlags are the flags which can't be used with '-o mermaid',
+// '-o junit', and '-o raw', as they print reports or results instead of the
+// matched items.
+var itemOutputFlags = []string{
+	"concurrency-conflicts",
+	"duplicates",
+	"demand",
+	"heatmap",
+	"policy",
+	"assert-fires-every",
+	"deadline-risk",
+	"runtime-risk",
+	"rollup-label",
+	"group-by-label",
+	"compare",
+	"stats",
+	"tz-report",
+	"shift",
+	"set-timezone",
+	"suspend",
+	"resume",
+	"resume-all",
+}
+
+// Get the fires of all the schedules of the item in the from-to period in
+// order, at most maxFireOccurrences of them. truncated is set when there are
+// more fires than that.
+func getItemFires(item item, from, to time.Time, bounds boundaries) (fires []time.Time, truncated bool, err error) {
+	for _, schedule := range item.schedules() {
+		spec := schedule
+		if tz := item.timezone(); tz != "" {
+			spec = fmt.Sprintf("CRON_TZ=%s %s", tz, spec)
+		}
+		sched, err := cron.ParseStandard(spec)
+		if err != nil {
+			obj := item.object()
+			return nil, false, fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", spec, item.kind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		count := 0
+		for t := sched.Next(bounds.start(from)); !t.IsZero() && bounds.beforeEnd(t, to); t = sched.Next(t) {
+			if count == maxFireOccurrences {
+				truncated = true
+				break
+			}
+			count++
+			fires = append(fires, t)
+		}
+	}
+	sort.Slice(fires, func(i, j int) bool { return fires[i].Before(fires[j]) })
+	if len(fires) > maxFireOccurrences {
+		fires = fires[:maxFireOccurrences]
+		truncated = true
+	}
+	return fires, truncated, nil
+}
+
+// Print the items as a Mermaid gantt diagram in a Markdown code block, with a
+// section for each namespace and a task for each fire, lasting the expected
+// duration of the item. The fires of an item are capped at
+// maxFireOccurrences, and a comment notes the truncation.
+func printMermaid(stdout io.Writer, items []item, from, to time.Time, bounds boundaries, loc *time.Location, defaultDuration time.Duration) error {
+	if defaultDuration <= 0 {
+		defaultDuration = defaultMermaidDuration
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	w := bufio.NewWriter(stdout)
+	fmt.Fprintln(w, "```mermaid")
+	fmt.Fprintln(w, "gantt")
+	fmt.Fprintf(w, "    title Scheduled from %s to %s\n", from.In(loc).Format(time.RFC3339), to.In(loc).Format(time.RFC3339))
+	fmt.Fprintln(w, "    dateFormat YYYY-MM-DDTHH:mm:ssZ")
+	fmt.Fprintln(w, "    axisFormat %H:%M")
+	namespace := ""
+	for i, item := range items {
+		obj := item.object()
+		fires, truncated, err := getItemFires(item, from, to, bounds)
+		if err != nil {
+			return err
+		}
+		duration, err := getExpectedDuration(item, defaultDuration)
+		if err != nil {
+			return err
+		}
+		if i == 0 || obj.GetNamespace() != namespace {
+			namespace = obj.GetNamespace()
+			fmt.Fprintf(w, "    section %s\n", sanitizeMermaid(namespace))
+		}
+		name := sanitizeMermaid(fmt.Sprintf("%s (%s)", obj.GetName(), item.kind()))
+		for _, t := range fires {
+			fmt.Fprintf(w, "    %s :%s, %s\n", name, t.In(loc).Format(time.RFC3339), formatMermaidDuration(duration))
+		}
+		if truncated {
+			fmt.Fprintf(w, "    %%%% %s is truncated at %d fires\n", name, maxFireOccurrences)
+		}
+	}
+	fmt.Fprintln(w, "```")
+	return w.Flush()
+}
+
+// Replace the characters of the Mermaid syntax in the task and section names,
+// e.g. ':' separating the name from the metadata, and '#' and ';' parsed as
+// entities and statement ends.
+func sanitizeMermaid(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ':', ';', '#', '\n', '\r', '\t':
+			return ' '
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// Format the duration in the largest Mermaid unit dividing it, e.g. '5m'.
+func formatMermaidDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return fmt.Sprintf("%dms", d/time.Millisecond)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_printMermaid(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "backup: db", "0 */2 * * *", false)
+	cronjob.Annotations = map[string]string{expectedDurationAnnotation: "90m"}
+	items := mergeItems(
+		[]batchv1.CronJob{cronjob, getCronJob("ns-b", "cleanup", "30 1 * * *", false)},
+		[]wfv1alpha1.CronWorkflow{getCronWorkflow("ns-a", "report", "15 3 * * *", false)},
+	)
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+
+	var got bytes.Buffer
+	if err := printMermaid(&got, items, from, to, boundaries{}, nil, 0); err != nil {
+		t.Fatalf("printMermaid() error = %v", err)
+	}
+	assertGolden(t, "mermaid.golden.md", got.Bytes())
+}
+
+func Test_printMermaid_truncated(t *testing.T) {
+	t.Parallel()
+	items := mergeItems([]batchv1.CronJob{getCronJob("ns-a", "every-minute", "* * * * *", false)}, nil)
+	from, to := getTime("2023-01-01T00:00:00Z"), getTime("2023-01-08T00:00:00Z")
+
+	var got bytes.Buffer
+	if err := printMermaid(&got, items, from, to, boundaries{}, nil, time.Minute); err != nil {
+		t.Fatalf("printMermaid() error = %v", err)
+	}
+	if n := strings.Count(got.String(), ", 1m\n"); n != maxFireOccurrences {
+		t.Errorf("want %d tasks, got %d", maxFireOccurrences, n)
+	}
+	if !strings.Contains(got.String(), "    %% every-minute (CronJob) is truncated at 10000 fires\n") {
+		t.Errorf("want the truncation comment")
+	}
+}
+
+func Test_sanitizeMermaid(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"backup (CronJob)":   "backup (CronJob)",
+		"a:b":                "a b",
+		"a;b#c":              "a b c",
+		"multi\nline\tname ": "multi line name",
+	}
+	for in, want := range tests {
+		if got := sanitizeMermaid(in); got != want {
+			t.Errorf("sanitizeMermaid(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func Test_formatMermaidDuration(t *testing.T) {
+	t.Parallel()
+	tests := map[time.Duration]string{
+		2 * time.Hour:           "2h",
+		90 * time.Minute:        "90m",
+		45 * time.Second:        "45s",
+		1500 * time.Millisecond: "1500ms",
+	}
+	for in, want := range tests {
+		if got := formatMermaidDuration(in); got != want {
+			t.Errorf("formatMermaidDuration(%s) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// The kinds of the objects referenced by '--mounts-secret',
+// '--mounts-configmap', and '--mounts-pvc'.
+const (
+	mountSecret    = "Secret"
+	mountConfigMap = "ConfigMap"
+	mountPVC       = "PersistentVolumeClaim"
+)
+
+// Keep only items referencing the object of the kind and the name, by a
+// volume, or by envFrom or env.valueFrom of a container. The init containers
+// count, as they mount the volumes too.
+func mountsFilter(kind, name string) filter {
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return volumesReference(cronjob.Spec.JobTemplate.Spec.Template.Spec.Volumes, kind, name) ||
+				containersReference(getCronJobContainers(cronjob), kind, name)
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return cronWorkflowReferences(cronworkflow, kind, name)
+		},
+	}
+}
+
+// Whether the inline workflow spec references the object: the volumes of the
+// workflow and of the templates, and the containers and the sidecars of the
+// templates. A workflowTemplateRef is not resolved.
+func cronWorkflowReferences(cronworkflow *wfv1alpha1.CronWorkflow, kind, name string) bool {
+	spec := cronworkflow.Spec.WorkflowSpec
+	if volumesReference(spec.Volumes, kind, name) || containersReference(getCronWorkflowContainers(cronworkflow), kind, name) {
+		return true
+	}
+	for _, tmpl := range spec.Templates {
+		if volumesReference(tmpl.Volumes, kind, name) {
+			return true
+		}
+		for _, c := range tmpl.Sidecars {
+			if containersReference([]corev1.Container{c.Container}, kind, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Whether any of the volumes, including the sources of the projected ones,
+// references the object. The PVCs are only referenced by the volumes.
+func volumesReference(volumes []corev1.Volume, kind, name string) bool {
+	for _, v := range volumes {
+		switch kind {
+		case mountSecret:
+			if v.Secret != nil && v.Secret.SecretName == name {
+				return true
+			}
+		case mountConfigMap:
+			if v.ConfigMap != nil && v.ConfigMap.Name == name {
+				return true
+			}
+		case mountPVC:
+			if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == name {
+				return true
+			}
+		}
+		if v.Projected == nil {
+			continue
+		}
+		for _, source := range v.Projected.Sources {
+			if kind == mountSecret && source.Secret != nil && source.Secret.Name == name {
+				return true
+			}
+			if kind == mountConfigMap && source.ConfigMap != nil && source.ConfigMap.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Whether envFrom or env.valueFrom of any of the containers references the
+// Secret or the ConfigMap.
+func containersReference(containers []corev1.Container, kind, name string) bool {
+	for _, c := range containers {
+		for _, from := range c.EnvFrom {
+			if kind == mountSecret && from.SecretRef != nil && from.SecretRef.Name == name {
+				return true
+			}
+			if kind == mountConfigMap && from.ConfigMapRef != nil && from.ConfigMapRef.Name == name {
+				return true
+			}
+		}
+		for _, env := range c.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if kind == mountSecret && env.ValueFrom.SecretKeyRef != nil && env.ValueFrom.SecretKeyRef.Name == name {
+				return true
+			}
+			if kind == mountConfigMap && env.ValueFrom.ConfigMapKeyRef != nil && env.ValueFrom.ConfigMapKeyRef.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
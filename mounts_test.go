@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_mountsFilter(t *testing.T) {
+	t.Parallel()
+	withPodSpec := func(name string, spec corev1.PodSpec) batchv1.CronJob {
+		cronjob := getCronJob("ns-a", name, "0 0 * * *", false)
+		cronjob.Spec.JobTemplate.Spec.Template.Spec = spec
+		return cronjob
+	}
+	ref := corev1.LocalObjectReference{Name: "creds"}
+	cronjobs := []batchv1.CronJob{
+		withPodSpec("secret-volume", corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "v", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "creds"}}},
+		}}),
+		withPodSpec("projected", corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "v", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{Secret: &corev1.SecretProjection{LocalObjectReference: ref}},
+				{ConfigMap: &corev1.ConfigMapProjection{LocalObjectReference: ref}},
+			}}}},
+		}}),
+		withPodSpec("secret-env-from", corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: ref}}}},
+		}}),
+		// The init containers count.
+		withPodSpec("secret-env", corev1.PodSpec{InitContainers: []corev1.Container{
+			{Name: "setup", Env: []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: ref, Key: "token"}}}}},
+		}}),
+		withPodSpec("configmap-volume", corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "v", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: ref}}},
+		}}),
+		withPodSpec("configmap-env-from", corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: ref}}}},
+		}}),
+		withPodSpec("configmap-env", corev1.PodSpec{Containers: []corev1.Container{
+			{Name: "app", Env: []corev1.EnvVar{{Name: "MODE", ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: ref, Key: "mode"}}}}},
+		}}),
+		withPodSpec("pvc", corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "v", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "creds"}}},
+		}}),
+		withPodSpec("other", corev1.PodSpec{Volumes: []corev1.Volume{
+			{Name: "v", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "other"}}},
+		}}),
+	}
+
+	workflowVolume := getCronWorkflow("ns-b", "workflow-volume", "0 0 * * *", false)
+	workflowVolume.Spec.WorkflowSpec.Volumes = []corev1.Volume{
+		{Name: "v", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "creds"}}},
+	}
+	templateVolume := getCronWorkflow("ns-b", "template-volume", "0 0 * * *", false)
+	templateVolume.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{{Name: "main", Volumes: []corev1.Volume{
+		{Name: "v", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "creds"}}},
+	}}}
+	script := getCronWorkflow("ns-b", "script", "0 0 * * *", false)
+	script.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{{Name: "main", Script: &wfv1alpha1.ScriptTemplate{Container: corev1.Container{
+		EnvFrom: []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: ref}}},
+	}}}}
+	sidecar := getCronWorkflow("ns-b", "sidecar", "0 0 * * *", false)
+	sidecar.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{{Name: "main", Sidecars: []wfv1alpha1.UserContainer{{Container: corev1.Container{
+		Env: []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: ref}}}},
+	}}}}}
+	items := mergeItems(cronjobs, []wfv1alpha1.CronWorkflow{workflowVolume, templateVolume, script, sidecar})
+
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{
+			kind: mountSecret,
+			want: []string{
+				"CronJob/ns-a/projected",
+				"CronJob/ns-a/secret-env",
+				"CronJob/ns-a/secret-env-from",
+				"CronJob/ns-a/secret-volume",
+				"CronWorkflow/ns-b/sidecar",
+				"CronWorkflow/ns-b/template-volume",
+			},
+		},
+		{
+			kind: mountConfigMap,
+			want: []string{
+				"CronJob/ns-a/configmap-env",
+				"CronJob/ns-a/configmap-env-from",
+				"CronJob/ns-a/configmap-volume",
+				"CronJob/ns-a/projected",
+				"CronWorkflow/ns-b/script",
+			},
+		},
+		{
+			kind: mountPVC,
+			want: []string{"CronJob/ns-a/pvc", "CronWorkflow/ns-b/workflow-volume"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.kind, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters([]filter{mountsFilter(tt.kind, "creds")}, items)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
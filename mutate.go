@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// The results of a planned patch.
+const (
+	patchResultPatched = "patched"
+	patchResultDryRun  = "dry-run"
+	patchResultSkipped = "skipped"
+)
+
+// patchPlan is a merge patch of an item, with the values before and after for
+// the output. A plan with a non-empty refusal or a nil patch isn't applied.
+type patchPlan struct {
+	item    item
+	patch   map[string]any
+	before  string
+	after   string
+	refusal string
+}
+
+type patchResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Before    string `json:"before"`
+	After     string `json:"after,omitempty"`
+	// Result is "patched", "dry-run", "skipped", or the reason of the refusal
+	// or failure.
+	Result string `json:"result"`
+}
+
+type patchReport struct {
+	ApiVersion string        `json:"apiVersion"`
+	Results    []patchResult `json:"results"`
+}
+
+type patchOptions struct {
+	// dryRun prints the results without patching.
+	dryRun bool
+	// yes skips the confirmation read from stdin.
+	yes    bool
+	stdin  io.Reader
+	stderr io.Writer
+	// prompt is the confirmation question, e.g. "Shift 3 items?".
+	prompt string
+	// auditLog records the patches, and the dry-run ones, as verb when not
+	// nil, e.g. "suspend".
+	auditLog *auditLog
+	verb     string
+}
+
+// Ask the question on out, and report whether the answer read from in is yes.
+func confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// Apply the plans after the confirmation, and return the results in the order
+// of the plans. A failed patch doesn't stop the others, and is reported in the
+// results and by the error. A failure to write the audit log stops the
+// patches, so that none is unrecorded.
+func applyPatchPlans(ctx context.Context, c *clients, plans []patchPlan, opts patchOptions) ([]patchResult, error) {
+	results := make([]patchResult, len(plans))
+	pending := 0
+	for i, plan := range plans {
+		obj := plan.item.object()
+		results[i] = patchResult{Kind: plan.item.kind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Before: plan.before, After: plan.after}
+		switch {
+		case plan.refusal != "":
+			results[i].Result = plan.refusal
+		case plan.patch == nil:
+			results[i].Result = patchResultSkipped
+		case opts.dryRun:
+			results[i].Result = patchResultDryRun
+		default:
+			pending++
+		}
+	}
+	if opts.dryRun {
+		if opts.auditLog == nil {
+			return results, nil
+		}
+		for _, plan := range plans {
+			if plan.refusal != "" || plan.patch == nil {
+				continue
+			}
+			if err := opts.auditLog.record(c, opts.verb, plan.item, true, nil); err != nil {
+				return results, err
+			}
+		}
+		return results, opts.auditLog.sync()
+	}
+	if pending == 0 {
+		return results, nil
+	}
+	if !opts.yes && !confirm(opts.stdin, opts.stderr, fmt.Sprintf(opts.prompt, pending)) {
+		return nil, errors.New("aborted, nothing was patched")
+	}
+
+	failed := 0
+	var logErr error
+	for i, plan := range plans {
+		if plan.refusal != "" || plan.patch == nil {
+			continue
+		}
+		if logErr != nil {
+			results[i].Result = patchResultSkipped
+			continue
+		}
+		err := patchItem(ctx, c, plan.item, plan.patch)
+		if opts.auditLog != nil {
+			logErr = opts.auditLog.record(c, opts.verb, plan.item, false, err)
+		}
+		if err != nil {
+			results[i].Result = err.Error()
+			failed++
+			continue
+		}
+		results[i].Result = patchResultPatched
+	}
+	if opts.auditLog != nil {
+		if err := opts.auditLog.sync(); err != nil && logErr == nil {
+			logErr = err
+		}
+	}
+	if logErr != nil {
+		return results, logErr
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("failed to patch %d items", failed)
+	}
+	return results, nil
+}
+
+// Patch the item by a merge patch.
+func patchItem(ctx context.Context, c *clients, item item, patch map[string]any) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the patch: %w", err)
+	}
+	obj := item.object()
+	opts := metav1.PatchOptions{FieldManager: fieldManager}
+	if item.cronJob != nil {
+		err = patchCronJob(ctx, c, obj.GetNamespace(), obj.GetName(), data, opts)
+	} else {
+		_, err = c.argo.CronWorkflows(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.MergePatchType, data, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to patch %s '%s/%s': %w", item.kind(), obj.GetNamespace(), obj.GetName(), err)
+	}
+	return nil
+}
+
+func printPatchResults(stdout io.Writer, output string, noHeaders bool, results []patchResult) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(patchReport{ApiVersion: "v1", Results: results}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(patchReport{ApiVersion: "v1", Results: results})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	orNone := func(s string) string {
+		if s == "" {
+			return "<none>"
+		}
+		return s
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Kind", "Before", "After", "Result"}, "\t"))
+	}
+	for _, r := range results {
+		fmt.Fprintln(tw, strings.Join([]string{r.Namespace, r.Name, r.Kind, orNone(r.Before), orNone(r.After), r.Result}, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+type fakeMutateClients struct {
+	*clients
+	k8s  *k8sfake.Clientset
+	argo *argofake.Clientset
+}
+
+func newFakeMutateClients(cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) fakeMutateClients {
+	k8sClient := k8sfake.NewSimpleClientset()
+	for i := range cronjobs {
+		k8sClient.Tracker().Add(&cronjobs[i])
+	}
+	argoClient := argofake.NewSimpleClientset()
+	for i := range cronworkflows {
+		argoClient.Tracker().Add(&cronworkflows[i])
+	}
+	return fakeMutateClients{
+		clients: &clients{k8s: k8sClient, argo: argoClient.ArgoprojV1alpha1()},
+		k8s:     k8sClient,
+		argo:    argoClient,
+	}
+}
+
+// Get the merge patches sent to the clientsets as "Kind namespace/name: patch".
+func (c fakeMutateClients) patches() []string {
+	patches := []string{}
+	for _, action := range append(c.k8s.Actions(), c.argo.Actions()...) {
+		if patch, ok := action.(k8stesting.PatchAction); ok {
+			patches = append(patches, patch.GetResource().Resource+" "+patch.GetNamespace()+"/"+patch.GetName()+": "+string(patch.GetPatch()))
+		}
+	}
+	return patches
+}
+
+func getShiftFixtures() ([]batchv1.CronJob, []wfv1alpha1.CronWorkflow) {
+	return []batchv1.CronJob{
+		getCronJob("ns-a", "nightly", "0 23 * * 1-5", false),
+		getCronJob("ns-a", "every-2h", "0 */2 * * *", false),
+	}, []wfv1alpha1.CronWorkflow{
+		getCronWorkflow("ns-b", "report", "30 9 * * *", false),
+	}
+}
+
+func Test_applyPatchPlans_shift(t *testing.T) {
+	t.Parallel()
+	cronjobs, cronworkflows := getShiftFixtures()
+	c := newFakeMutateClients(cronjobs, cronworkflows)
+	items := mergeItems(cronjobs, cronworkflows)
+
+	var stderr bytes.Buffer
+	results, err := applyPatchPlans(context.Background(), c.clients, planShift(items, 2*time.Hour), patchOptions{stdin: strings.NewReader("y\n"), stderr: &stderr, prompt: "Shift %d items?"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("Shift 2 items? [y/N]: ", stderr.String()); diff != "" {
+		t.Errorf("prompt (-want +got):\n%s", diff)
+	}
+	want := []patchResult{
+		{Kind: "CronJob", Namespace: "ns-a", Name: "every-2h", Before: "0 */2 * * *", Result: "refused: steps on the hour field '*/2' can't be shifted"},
+		{Kind: "CronJob", Namespace: "ns-a", Name: "nightly", Before: "0 23 * * 1-5", After: "0 1 * * 2-6", Result: patchResultPatched},
+		{Kind: "CronWorkflow", Namespace: "ns-b", Name: "report", Before: "30 9 * * *", After: "30 11 * * *", Result: patchResultPatched},
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Errorf("results (-want +got):\n%s", diff)
+	}
+	wantPatches := []string{
+		`cronjobs ns-a/nightly: {"spec":{"schedule":"0 1 * * 2-6"}}`,
+		`cronworkflows ns-b/report: {"spec":{"schedule":"30 11 * * *"}}`,
+	}
+	if diff := cmp.Diff(wantPatches, c.patches()); diff != "" {
+		t.Errorf("patches (-want +got):\n%s", diff)
+	}
+
+	// The objects are patched.
+	got, err := c.k8s.BatchV1().CronJobs("ns-a").Get(context.Background(), "nightly", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Spec.Schedule != "0 1 * * 2-6" {
+		t.Errorf("want the patched schedule, got %s", got.Spec.Schedule)
+	}
+}
+
+func Test_applyPatchPlans_dryRun(t *testing.T) {
+	t.Parallel()
+	cronjobs, cronworkflows := getShiftFixtures()
+	c := newFakeMutateClients(cronjobs, cronworkflows)
+
+	results, err := applyPatchPlans(context.Background(), c.clients, planShift(mergeItems(cronjobs, cronworkflows), 2*time.Hour), patchOptions{dryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotResults := []string{}
+	for _, r := range results {
+		gotResults = append(gotResults, r.Result)
+	}
+	if diff := cmp.Diff([]string{"refused: steps on the hour field '*/2' can't be shifted", patchResultDryRun, patchResultDryRun}, gotResults); diff != "" {
+		t.Errorf("results (-want +got):\n%s", diff)
+	}
+	if patches := c.patches(); len(patches) != 0 {
+		t.Errorf("want no patches, got %v", patches)
+	}
+}
+
+func Test_applyPatchPlans_confirmation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		stdin       string
+		yes         bool
+		wantPatches int
+		wantErr     bool
+	}{
+		{name: "yes", stdin: "yes\n", wantPatches: 2},
+		{name: "no", stdin: "n\n", wantErr: true},
+		{name: "empty", stdin: "\n", wantErr: true},
+		{name: "EOF", stdin: "", wantErr: true},
+		{name: "--yes", yes: true, wantPatches: 2},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cronjobs, cronworkflows := getShiftFixtures()
+			c := newFakeMutateClients(cronjobs, cronworkflows)
+			_, err := applyPatchPlans(context.Background(), c.clients, planShift(mergeItems(cronjobs, cronworkflows), time.Hour), patchOptions{yes: tt.yes, stdin: strings.NewReader(tt.stdin), stderr: &bytes.Buffer{}, prompt: "%d?"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyPatchPlans() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := len(c.patches()); got != tt.wantPatches {
+				t.Errorf("want %d patches, got %d", tt.wantPatches, got)
+			}
+		})
+	}
+}
+
+func Test_applyPatchPlans_failure(t *testing.T) {
+	t.Parallel()
+	cronjobs, cronworkflows := getShiftFixtures()
+	// The CronWorkflow doesn't exist in the cluster.
+	c := newFakeMutateClients(cronjobs, nil)
+	results, err := applyPatchPlans(context.Background(), c.clients, planShift(mergeItems(cronjobs, cronworkflows), time.Hour), patchOptions{yes: true})
+	if err == nil || err.Error() != "failed to patch 1 items" {
+		t.Errorf("want the failure count, got %v", err)
+	}
+	if got := results[1].Result; got != patchResultPatched {
+		t.Errorf("want the other item patched, got %s", got)
+	}
+	if got := results[2].Result; !strings.HasPrefix(got, "failed to patch CronWorkflow 'ns-b/report'") {
+		t.Errorf("want the failure in the result, got %s", got)
+	}
+}
+
+func Test_printPatchResults(t *testing.T) {
+	t.Parallel()
+	results := []patchResult{
+		{Kind: "CronJob", Namespace: "ns-a", Name: "nightly", Before: "0 23 * * 1-5", After: "0 1 * * 2-6", Result: patchResultPatched},
+		{Kind: "CronJob", Namespace: "ns-a", Name: "every-2h", Before: "0 */2 * * *", Result: "refused: steps on the hour field '*/2' can't be shifted"},
+	}
+	var got bytes.Buffer
+	if err := printPatchResults(&got, "", false, results); err != nil {
+		t.Fatal(err)
+	}
+	want := `Namespace   Name       Kind      Before         After         Result
+ns-a        nightly    CronJob   0 23 * * 1-5   0 1 * * 2-6   patched
+ns-a        every-2h   CronJob   0 */2 * * *    <none>        refused: steps on the hour field '*/2' can't be shifted
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
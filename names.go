@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/slog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// namesFromFlags are the flags which can't be used with '--names-from', as the
+// named items are got one by one instead of listed.
+var namesFromFlags = []string{
+	"selector",
+	"namespace-selector",
+	"namespaces",
+	"fixtures",
+	"provider",
+	"include-crds",
+	"stats",
+	"tz-report",
+	"save",
+	"compare",
+}
+
+func validateNamesFromFlags(fsets *pflag.FlagSet) error {
+	for _, name := range namesFromFlags {
+		if fsets.Changed(name) {
+			return fmt.Errorf("'--names-from' can't be used with '--%s'", name)
+		}
+	}
+	return nil
+}
+
+// targetName is a name read by '--names-from'.
+type targetName struct {
+	namespace string
+	name      string
+}
+
+func (n targetName) String() string {
+	return n.namespace + "/" + n.name
+}
+
+// Read the names of the '--names-from' file, or of stdin for '-'.
+func readNamesFrom(path string, stdin io.Reader, defaultNamespace string) ([]targetName, error) {
+	if path == "-" {
+		return readNames(stdin, defaultNamespace)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '--names-from' file: %w", err)
+	}
+	defer f.Close()
+	return readNames(f, defaultNamespace)
+}
+
+// Read a name in the NAME or NAMESPACE/NAME form per line, where NAME is in
+// defaultNamespace. Blank lines and the text after '#' are ignored, and the
+// duplicated names are read once.
+func readNames(r io.Reader, defaultNamespace string) ([]targetName, error) {
+	names := []targetName{}
+	seen := map[targetName]bool{}
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		n := targetName{namespace: defaultNamespace, name: line}
+		if ns, name, ok := strings.Cut(line, "/"); ok {
+			n = targetName{namespace: ns, name: name}
+		}
+		if n.namespace == "" || n.name == "" || strings.Contains(n.name, "/") {
+			return nil, fmt.Errorf("failed to parse '--names-from' line %d: must be NAME or NAMESPACE/NAME: %s", lineNum, line)
+		}
+		if !seen[n] {
+			seen[n] = true
+			names = append(names, n)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read '--names-from' names: %w", err)
+	}
+	return names, nil
+}
+
+// Get the CronJob and the CronWorkflow of each name with Get calls instead of
+// listing all of them. A name found in neither kind is warned about, and
+// doesn't fail.
+func getNamedItems(ctx context.Context, c *clients, names []targetName, logger *slog.Logger) ([]item, error) {
+	items := []item{}
+	for _, n := range names {
+		found := false
+		cronjob, err := getCronJobByName(ctx, c, n.namespace, n.name)
+		switch {
+		case err == nil:
+			items = append(items, item{cronJob: cronjob})
+			found = true
+		case !apierrors.IsNotFound(err):
+			return nil, fmt.Errorf("failed to get CronJob '%s': %w", n, err)
+		}
+		cronworkflow, err := c.argo.CronWorkflows(n.namespace).Get(ctx, n.name, metav1.GetOptions{})
+		switch {
+		case err == nil:
+			items = append(items, item{cronWorkflow: cronworkflow})
+			found = true
+		case !apierrors.IsNotFound(err):
+			return nil, fmt.Errorf("failed to get CronWorkflow '%s': %w", n, err)
+		}
+		if !found {
+			logger.Warn(fmt.Sprintf("'%s' is not found as a CronJob nor a CronWorkflow", n), "namespace", n.namespace, "name", n.name)
+		}
+	}
+	sortItems(items)
+	return items, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func Test_readNames(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    []targetName
+		wantErr string
+	}{
+		{
+			name: "names and comments",
+			input: "" +
+				"# CHG-1234\n" +
+				"backup\n" +
+				"\n" +
+				"  ns-b/cleanup  # moved to ns-b\n" +
+				"backup\n",
+			want: []targetName{{namespace: "default", name: "backup"}, {namespace: "ns-b", name: "cleanup"}},
+		},
+		{
+			name:  "empty",
+			input: "# nothing\n\n",
+			want:  []targetName{},
+		},
+		{
+			name:    "empty namespace",
+			input:   "backup\n/cleanup\n",
+			wantErr: "failed to parse '--names-from' line 2: must be NAME or NAMESPACE/NAME: /cleanup",
+		},
+		{
+			name:    "too many slashes",
+			input:   "ns-a/cronjobs/backup\n",
+			wantErr: "failed to parse '--names-from' line 1: must be NAME or NAMESPACE/NAME: ns-a/cronjobs/backup",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := readNames(strings.NewReader(tt.input), "default")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("readNames() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("readNames() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(targetName{})); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_getNamedItems(t *testing.T) {
+	t.Parallel()
+	cronjob1 := getCronJob("default", "backup", "0 1 * * *", false)
+	cronjob2 := getCronJob("ns-b", "cleanup", "0 2 * * *", false)
+	cronworkflow := getCronWorkflow("ns-b", "cleanup", "0 3 * * *", false)
+	k8sClient := k8sfake.NewSimpleClientset(&cronjob1, &cronjob2)
+	argoClient := argofake.NewSimpleClientset(&cronworkflow)
+	c := &clients{k8s: k8sClient, argo: argoClient.ArgoprojV1alpha1()}
+
+	names := []targetName{
+		{namespace: "ns-b", name: "cleanup"},
+		{namespace: "default", name: "backup"},
+		{namespace: "default", name: "cleanup"},
+		{namespace: "ns-c", name: "missing"},
+	}
+	var stderr bytes.Buffer
+	got, err := getNamedItems(context.Background(), c, names, newLogger(&stderr, logFormatText, false))
+	if err != nil {
+		t.Fatalf("getNamedItems() error = %v", err)
+	}
+	want := mergeItems([]batchv1.CronJob{cronjob1, cronjob2}, []wfv1alpha1.CronWorkflow{cronworkflow})
+	if diff := cmp.Diff(getItemNames(want), getItemNames(got)); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	wantWarnings := "" +
+		"warning: 'default/cleanup' is not found as a CronJob nor a CronWorkflow\n" +
+		"warning: 'ns-c/missing' is not found as a CronJob nor a CronWorkflow\n"
+	if diff := cmp.Diff(wantWarnings, stderr.String()); diff != "" {
+		t.Errorf("warnings (-want +got):\n%s", diff)
+	}
+
+	// Only the named objects are got, nothing is listed.
+	for _, action := range append(k8sClient.Actions(), argoClient.Actions()...) {
+		if action.GetVerb() != "get" {
+			t.Errorf("want only get calls, got %s %s", action.GetVerb(), action.GetResource().Resource)
+		}
+	}
+}
+
+func Test_validateNamesFromFlags(t *testing.T) {
+	t.Parallel()
+	args := []string{commandName, "--names-from", "-", "--selector", "app=a", "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}
+	err := run(&bytes.Buffer{}, &bytes.Buffer{}, args)
+	if want := "'--names-from' can't be used with '--selector'"; err == nil || err.Error() != want {
+		t.Errorf("run() error = %v, want %s", err, want)
+	}
+}
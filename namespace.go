@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// namespaceConcurrency bounds the namespaces listed at the same time.
+const namespaceConcurrency = 8
+
+// Get the names of the Namespaces matching the label selector, sorted by name.
+func resolveNamespaces(ctx context.Context, client kubernetes.Interface, selector string) ([]string, error) {
+	namespaces, err := listNamespaceNames(ctx, client, selector)
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return nil, fmt.Errorf("failed to list Namespaces for '--namespace-selector', use '-n NAMESPACE' instead if you are not allowed to list Namespaces: %w", err)
+		}
+		return nil, fmt.Errorf("failed to list Namespaces for '--namespace-selector': %w", err)
+	}
+	return namespaces, nil
+}
+
+// Whether the namespace of '-n' or '--namespaces' is a glob pattern of
+// path.Match, e.g. 'team-payments-*'.
+func isNamespacePattern(namespace string) bool {
+	return strings.ContainsAny(namespace, "*?[")
+}
+
+// Check the glob patterns of the namespaces are well-formed.
+func validateNamespacePatterns(namespaces []string) error {
+	for _, ns := range namespaces {
+		if !isNamespacePattern(ns) {
+			continue
+		}
+		if _, err := path.Match(ns, ""); err != nil {
+			return fmt.Errorf("invalid namespace pattern '%s': %w", ns, err)
+		}
+	}
+	return nil
+}
+
+// Expand the glob patterns in the namespaces into the names of the matching
+// Namespaces, listing the Namespaces once. The plain names are kept as is,
+// and the Namespaces are not listed at all without a pattern. It fails when
+// a pattern matches no Namespace.
+func expandNamespacePatterns(ctx context.Context, client kubernetes.Interface, namespaces []string) ([]string, error) {
+	hasPattern := false
+	for _, ns := range namespaces {
+		hasPattern = hasPattern || isNamespacePattern(ns)
+	}
+	if !hasPattern {
+		return namespaces, nil
+	}
+	all, err := listNamespaceNames(ctx, client, "")
+	if err != nil {
+		if apierrors.IsForbidden(err) {
+			return nil, fmt.Errorf("failed to list Namespaces for the namespace pattern, use the names of the namespaces instead if you are not allowed to list Namespaces: %w", err)
+		}
+		return nil, fmt.Errorf("failed to list Namespaces for the namespace pattern: %w", err)
+	}
+	seen := map[string]bool{}
+	ret := []string{}
+	add := func(ns string) {
+		if !seen[ns] {
+			seen[ns] = true
+			ret = append(ret, ns)
+		}
+	}
+	for _, ns := range namespaces {
+		if !isNamespacePattern(ns) {
+			add(ns)
+			continue
+		}
+		matched := false
+		for _, name := range all {
+			if ok, _ := path.Match(ns, name); ok {
+				add(name)
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no namespaces match the pattern '%s'", ns)
+		}
+	}
+	return ret, nil
+}
+
+func listNamespaceNames(ctx context.Context, client kubernetes.Interface, selector string) ([]string, error) {
+	namespaceList, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, len(namespaceList.Items))
+	for i, ns := range namespaceList.Items {
+		namespaces[i] = ns.Name
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// List CronJobs and CronWorkflows to be executed during the from-to period in
+// all namespaces. When the cluster-wide listing is forbidden, e.g. by
+// namespace-scoped RBAC, they are listed in each of the Namespaces instead,
+// and the forbidden namespaces are skipped and returned.
+func listScheduleIncludedInAllNamespaces(ctx context.Context, c *clients, selector string, from, to time.Time, bounds boundaries) ([]item, []string, error) {
+	items, err := listScheduleIncluded(ctx, c, "", selector, from, to, bounds)
+	if !apierrors.IsForbidden(err) {
+		return items, nil, err
+	}
+	namespaces, nsErr := listNamespaceNames(ctx, c.k8s, "")
+	if nsErr != nil {
+		return nil, nil, fmt.Errorf("%w, and failed to list Namespaces to list them in each namespace, use '--namespaces' to set your namespaces: %v", err, nsErr)
+	}
+	return listScheduleIncludedInNamespaces(ctx, c, namespaces, selector, from, to, bounds)
+}
+
+// List CronJobs and CronWorkflows to be executed during the from-to period in
+// each of the namespaces, sorted like listScheduleIncluded. The namespaces are
+// listed concurrently, at most namespaceConcurrency at a time. The namespaces
+// where the listing is forbidden are skipped and returned sorted, and any other
+// error fails.
+func listScheduleIncludedInNamespaces(ctx context.Context, c *clients, namespaces []string, selector string, from, to time.Time, bounds boundaries) ([]item, []string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]item, len(namespaces))
+	errs := make([]error, len(namespaces))
+	sem := make(chan struct{}, namespaceConcurrency)
+	var wg sync.WaitGroup
+	for i, ns := range namespaces {
+		// An empty name would list all namespaces.
+		if ns == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(i int, ns string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i], errs[i] = listScheduleIncluded(ctx, c, ns, selector, from, to, bounds)
+			if errs[i] != nil && !apierrors.IsForbidden(errs[i]) {
+				cancel()
+			}
+		}(i, ns)
+	}
+	wg.Wait()
+
+	items := []item{}
+	skipped := []string{}
+	var firstErr error
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			items = append(items, results[i]...)
+		case apierrors.IsForbidden(err):
+			skipped = append(skipped, namespaces[i])
+		case firstErr == nil || errors.Is(firstErr, context.Canceled):
+			// Prefer the error which canceled the others.
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return nil, nil, firstErr
+	}
+	sortItems(items)
+	sort.Strings(skipped)
+	return items, skipped, nil
+}
+
+// Log the namespaces skipped by listScheduleIncludedInNamespaces as a
+// warning.
+func warnSkippedNamespaces(logger *slog.Logger, skipped []string) {
+	if len(skipped) == 0 {
+		return
+	}
+	logger.Warn(fmt.Sprintf("skipped %d namespaces where listing CronJobs or CronWorkflows is forbidden: %s", len(skipped), strings.Join(skipped, ", ")), "namespaces", skipped)
+}
+
+// List all the CronJobs and CronWorkflows in the namespace, or in the
+// namespaces matching the namespace selector when set, regardless of the
+// period, for the reports which evaluate the items not executed during the
+// period too, e.g. '--stats' counting the items never firing.
+func listAllItems(ctx context.Context, c *clients, namespace, namespaceSelector, selector string) ([]item, error) {
+	if namespaceSelector == "" {
+		return listItems(ctx, c, namespace, selector)
+	}
+	namespaces, err := resolveNamespaces(ctx, c.k8s, namespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	return listItemsInNamespaces(ctx, c, namespaces, selector)
+}
+
+// List all the CronJobs and CronWorkflows in each of the namespaces.
+func listItemsInNamespaces(ctx context.Context, c *clients, namespaces []string, selector string) ([]item, error) {
+	items := []item{}
+	for _, ns := range namespaces {
+		// An empty name would list all namespaces.
+		if ns == "" {
+			continue
+		}
+		nsItems, err := listItems(ctx, c, ns, selector)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, nsItems...)
+	}
+	sortItems(items)
+	return items, nil
+}
+
+// Get the labels of the Namespaces, getting each one once. Getting the
+// Namespaces one by one needs only 'get', unlike listing them.
+func getNamespaceLabels(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string]map[string]string, error) {
+	ret := map[string]map[string]string{}
+	for _, name := range namespaces {
+		if _, ok := ret[name]; ok {
+			continue
+		}
+		ns, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Namespace '%s': %w", name, err)
+		}
+		ret[name] = ns.Labels
+	}
+	return ret, nil
+}
@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func getNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func Test_resolveNamespaces(t *testing.T) {
+	t.Parallel()
+	client := k8sfake.NewSimpleClientset(
+		getNamespace("payments-prod", map[string]string{"team": "payments"}),
+		getNamespace("payments-dev", map[string]string{"team": "payments", "env": "dev"}),
+		getNamespace("search", map[string]string{"team": "search"}),
+		getNamespace("kube-system", nil),
+	)
+
+	tests := []struct {
+		name     string
+		selector string
+		want     []string
+	}{
+		{
+			name:     "equality",
+			selector: "team=payments",
+			want:     []string{"payments-dev", "payments-prod"},
+		},
+		{
+			name:     "multiple requirements",
+			selector: "team=payments,env!=dev",
+			want:     []string{"payments-prod"},
+		},
+		{
+			name:     "no match",
+			selector: "team=unknown",
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := resolveNamespaces(context.Background(), client, tt.selector)
+			if err != nil {
+				t.Fatalf("resolveNamespaces() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("resolveNamespaces() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_resolveNamespaces_forbidden(t *testing.T) {
+	t.Parallel()
+	client := k8sfake.NewSimpleClientset()
+	client.PrependReactor("list", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "namespaces"}, "", nil)
+	})
+
+	_, err := resolveNamespaces(context.Background(), client, "team=payments")
+	if err == nil {
+		t.Fatal("resolveNamespaces() error = nil, want error")
+	}
+	if !strings.Contains(err.Error(), "-n NAMESPACE") {
+		t.Errorf("resolveNamespaces() error = %v, want a suggestion to use -n", err)
+	}
+	if !apierrors.IsForbidden(err) {
+		t.Errorf("resolveNamespaces() error = %v, want it to wrap the forbidden error", err)
+	}
+}
+
+func Test_listScheduleIncludedInNamespaces(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{
+			getCronJob("payments-prod", "settle", "0 1 * * *", false),
+			getCronJob("search", "reindex", "0 1 * * *", false),
+			getCronJob("payments-dev", "settle", "0 1 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("payments-prod", "report", "0 1 * * *", false),
+			getCronWorkflow("search", "crawl", "0 1 * * *", false),
+		},
+	)
+	fake := c.k8s.(*k8sfake.Clientset)
+	for _, ns := range []*corev1.Namespace{
+		getNamespace("payments-prod", map[string]string{"team": "payments"}),
+		getNamespace("payments-dev", map[string]string{"team": "payments"}),
+		getNamespace("search", map[string]string{"team": "search"}),
+	} {
+		if err := fake.Tracker().Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	namespaces, err := resolveNamespaces(context.Background(), c.k8s, "team=payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.ClearActions()
+	got, _, err := listScheduleIncludedInNamespaces(context.Background(), c, namespaces, "", getTime("2023-01-24T01:00:00Z"), getTime("2023-01-24T01:00:00Z"), boundaries{})
+	if err != nil {
+		t.Fatalf("listScheduleIncludedInNamespaces() error = %v", err)
+	}
+
+	want := []string{
+		"CronJob/payments-dev/settle",
+		"CronWorkflow/payments-prod/report",
+		"CronJob/payments-prod/settle",
+	}
+	if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+		t.Errorf("listScheduleIncludedInNamespaces() mismatch (-want +got):\n%s", diff)
+	}
+
+	// The CronJobs are listed per namespace, never across all namespaces.
+	listed := []string{}
+	for _, action := range fake.Actions() {
+		if action.Matches("list", "cronjobs") {
+			listed = append(listed, action.GetNamespace())
+		}
+	}
+	sort.Strings(listed) // The namespaces are listed concurrently.
+	if diff := cmp.Diff([]string{"payments-dev", "payments-prod"}, listed); diff != "" {
+		t.Errorf("listed namespaces mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// Forbid listing the resource across all namespaces and in the namespaces.
+func forbidList(fake *k8stesting.Fake, resource string, namespaces ...string) {
+	fake.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		ns := action.GetNamespace()
+		if ns == "" || contains(namespaces, ns) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: action.GetResource().Group, Resource: resource}, "", errors.New("RBAC"))
+		}
+		return false, nil, nil
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func Test_listScheduleIncludedInAllNamespaces(t *testing.T) {
+	t.Parallel()
+	newForbiddenClients := func() (*clients, *k8sfake.Clientset) {
+		c := newFakeClients(
+			[]batchv1.CronJob{
+				getCronJob("team-a", "backup", "0 1 * * *", false),
+				getCronJob("team-b", "backup", "0 1 * * *", false),
+				getCronJob("team-c", "backup", "0 1 * * *", false),
+			},
+			[]wfv1alpha1.CronWorkflow{
+				getCronWorkflow("team-b", "report", "0 1 * * *", false),
+			},
+		)
+		fake := c.k8s.(*k8sfake.Clientset)
+		for _, ns := range []string{"team-a", "team-b", "team-c"} {
+			if err := fake.Tracker().Add(getNamespace(ns, nil)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// The user can only list in team-a and team-b.
+		forbidList(&fake.Fake, "cronjobs", "team-c")
+		return c, fake
+	}
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+
+	t.Run("fall back to each namespace", func(t *testing.T) {
+		t.Parallel()
+		c, _ := newForbiddenClients()
+		got, skipped, err := listScheduleIncludedInAllNamespaces(context.Background(), c, "", from, to, boundaries{})
+		if err != nil {
+			t.Fatalf("listScheduleIncludedInAllNamespaces() error = %v", err)
+		}
+		want := []string{"CronJob/team-a/backup", "CronJob/team-b/backup", "CronWorkflow/team-b/report"}
+		if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"team-c"}, skipped); diff != "" {
+			t.Errorf("skipped mismatch (-want +got):\n%s", diff)
+		}
+
+		var stderr bytes.Buffer
+		warnSkippedNamespaces(newLogger(&stderr, logFormatText, false), skipped)
+		wantWarning := "warning: skipped 1 namespaces where listing CronJobs or CronWorkflows is forbidden: team-c\n"
+		if diff := cmp.Diff(wantWarning, stderr.String()); diff != "" {
+			t.Errorf("warning mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("allowed cluster-wide", func(t *testing.T) {
+		t.Parallel()
+		c := newFakeClients([]batchv1.CronJob{getCronJob("team-a", "backup", "0 1 * * *", false)}, nil)
+		got, skipped, err := listScheduleIncludedInAllNamespaces(context.Background(), c, "", from, to, boundaries{})
+		if err != nil {
+			t.Fatalf("listScheduleIncludedInAllNamespaces() error = %v", err)
+		}
+		if diff := cmp.Diff([]string{"CronJob/team-a/backup"}, getItemNames(got)); diff != "" {
+			t.Errorf("items mismatch (-want +got):\n%s", diff)
+		}
+		if len(skipped) != 0 {
+			t.Errorf("want no skipped namespaces, got %v", skipped)
+		}
+	})
+
+	t.Run("namespaces forbidden too", func(t *testing.T) {
+		t.Parallel()
+		c, fake := newForbiddenClients()
+		forbidList(&fake.Fake, "namespaces")
+		_, _, err := listScheduleIncludedInAllNamespaces(context.Background(), c, "", from, to, boundaries{})
+		if err == nil || !strings.Contains(err.Error(), "use '--namespaces'") {
+			t.Errorf("want an error suggesting '--namespaces', got %v", err)
+		}
+	})
+
+	t.Run("other errors fail", func(t *testing.T) {
+		t.Parallel()
+		c, fake := newForbiddenClients()
+		fake.PrependReactor("list", "cronjobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			if action.GetNamespace() == "team-b" {
+				return true, nil, errors.New("connection refused")
+			}
+			return false, nil, nil
+		})
+		_, _, err := listScheduleIncludedInAllNamespaces(context.Background(), c, "", from, to, boundaries{})
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("want the error of team-b, got %v", err)
+		}
+	})
+}
+
+func Test_listScheduleIncludedInNamespaces_bounded(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{}
+	namespaces := []string{}
+	for i := 0; i < 3*namespaceConcurrency; i++ {
+		ns := "ns-" + strings.Repeat("x", i+1)
+		namespaces = append(namespaces, ns)
+		cronjobs = append(cronjobs, getCronJob(ns, "backup", "0 1 * * *", false))
+	}
+	c := newFakeClients(cronjobs, nil)
+	fake := c.k8s.(*k8sfake.Clientset)
+
+	var mu sync.Mutex
+	running, max := 0, 0
+	fake.PrependReactor("list", "cronjobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return false, nil, nil
+	})
+
+	got, _, err := listScheduleIncludedInNamespaces(context.Background(), c, namespaces, "", getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z"), boundaries{})
+	if err != nil {
+		t.Fatalf("listScheduleIncludedInNamespaces() error = %v", err)
+	}
+	if len(got) != len(namespaces) {
+		t.Errorf("want %d items, got %d", len(namespaces), len(got))
+	}
+	if max > namespaceConcurrency {
+		t.Errorf("want at most %d namespaces listed at the same time, got %d", namespaceConcurrency, max)
+	}
+}
+
+func Test_expandNamespacePatterns(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		namespaces []string
+		want       []string
+		wantErr    string
+		wantListed bool
+	}{
+		{
+			name:       "pattern",
+			namespaces: []string{"team-payments-*"},
+			want:       []string{"team-payments-prod", "team-payments-stag", "team-payments-staging"},
+			wantListed: true,
+		},
+		{
+			name:       "literal and pattern",
+			namespaces: []string{"ops", "team-payments-????", "team-payments-prod"},
+			want:       []string{"ops", "team-payments-prod", "team-payments-stag"},
+			wantListed: true,
+		},
+		{
+			name:       "no match",
+			namespaces: []string{"team-payments-*", "team-billing-*"},
+			wantErr:    "no namespaces match the pattern 'team-billing-*'",
+			wantListed: true,
+		},
+		{
+			name:       "literals",
+			namespaces: []string{"team-billing", "ops"},
+			want:       []string{"team-billing", "ops"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := k8sfake.NewSimpleClientset(
+				getNamespace("ops", nil),
+				getNamespace("team-payments-prod", nil),
+				getNamespace("team-payments-stag", nil),
+				getNamespace("team-payments-staging", nil),
+			)
+			got, err := expandNamespacePatterns(context.Background(), client, tt.namespaces)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("want %q, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("expandNamespacePatterns() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			listed := false
+			for _, action := range client.Actions() {
+				listed = listed || (action.GetVerb() == "list" && action.GetResource().Resource == "namespaces")
+			}
+			if listed != tt.wantListed {
+				t.Errorf("want the Namespaces listed %v, got %v", tt.wantListed, listed)
+			}
+		})
+	}
+}
+
+func Test_run_namespacePattern(t *testing.T) {
+	t.Parallel()
+	newPatternClients := func() *clients {
+		c := newFakeClients(
+			[]batchv1.CronJob{
+				getCronJob("team-payments-prod", "settlement", "0 1 * * *", false),
+				getCronJob("team-payments-staging", "settlement", "0 1 * * *", false),
+				getCronJob("team-billing", "invoice", "0 1 * * *", false),
+			},
+			[]wfv1alpha1.CronWorkflow{},
+		)
+		fake := c.k8s.(*k8sfake.Clientset)
+		for _, ns := range []string{"team-payments-prod", "team-payments-staging", "team-billing"} {
+			if err := fake.Tracker().Add(getNamespace(ns, nil)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return c
+	}
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "namespace",
+			args: []string{"-n", "team-payments-*"},
+			want: "team-payments-prod      settlement   0 1 * * *   false   CronJob\n" +
+				"team-payments-staging   settlement   0 1 * * *   false   CronJob\n",
+		},
+		{
+			name: "namespaces",
+			args: []string{"--namespaces", "team-billing,team-payments-p*"},
+			want: "team-billing         invoice      0 1 * * *   false   CronJob\n" +
+				"team-payments-prod   settlement   0 1 * * *   false   CronJob\n",
+		},
+		{
+			name:    "no match",
+			args:    []string{"-n", "team-ops-*"},
+			wantErr: "no namespaces match the pattern 'team-ops-*'",
+		},
+		{
+			name:    "invalid",
+			args:    []string{"-n", "team-["},
+			wantErr: "invalid namespace pattern 'team-[': syntax error in pattern",
+		},
+		{
+			name:    "fixtures",
+			args:    []string{"-n", "team-*", "--fixtures", "testdata/drift"},
+			wantErr: "the pattern of '--namespace' can't be used with '--fixtures'",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, _, err := runFake(newPatternClients(), append(append([]string{"--no-headers"}, tt.args...), runPeriod...)...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, stdout); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errNotNaturalTime is returned by parseNaturalTime for the values which are
+// not natural-language times at all, so that the error of the structured
+// format is reported instead.
+var errNotNaturalTime = errors.New("not a natural-language time")
+
+var (
+	clockPattern    = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	weekdayPrefixes = map[string]time.Weekday{
+		"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+		"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	}
+	relativeUnits = map[string]time.Duration{
+		"minute": time.Minute, "min": time.Minute,
+		"hour": time.Hour, "hr": time.Hour,
+	}
+)
+
+var (
+	// dateOnlyPattern is a value of '--from' or '--to' with a date alone.
+	dateOnlyPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	// naiveTimePattern is a value of '--from' or '--to' without the offset.
+	naiveTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}$`)
+	// epochPattern is a value of '--from' or '--to' in the Unix time, which
+	// has more digits than an hour of the natural-language time such as '08'.
+	epochPattern = regexp.MustCompile(`^-?\d{3,}$`)
+)
+
+// supportedTimeFormats names the formats of '--from' and '--to' in the errors.
+const supportedTimeFormats = "RFC3339 such as '2023-01-24T00:00:00Z', a time without the offset such as '2023-01-24T00:00:00', the Unix time in seconds or milliseconds such as '1674518400', a date such as '2023-01-24', or a natural-language time such as 'tomorrow 02:00'"
+
+// Parse the '--from' or '--to' value by its format: a date alone such as
+// '2023-01-24' is its midnight in the location, or its end 23:59:59 with
+// endOfDay, a time without the offset such as '2023-01-24T02:00:00' is in the
+// location, a number of 3 or more digits is the Unix time in seconds, or in
+// milliseconds with more than 10 digits, and any other value is in the
+// layout, whose offset wins over the location, or a natural-language time
+// resolved against now in the location, see parseNaturalTime.
+func parseTimeFlag(value, layout string, endOfDay bool, now time.Time, loc *time.Location) (time.Time, error) {
+	if epochPattern.MatchString(value) {
+		return parseEpochTime(value)
+	}
+	if naiveTimePattern.MatchString(value) {
+		t, err := time.ParseInLocation("2006-01-02T15:04:05", value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w, the supported formats are %s", err, supportedTimeFormats)
+		}
+		return t, nil
+	}
+	if dateOnlyPattern.MatchString(value) {
+		t, err := time.ParseInLocation("2006-01-02", value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w, the supported formats are %s", err, supportedTimeFormats)
+		}
+		if endOfDay {
+			y, m, d := t.Date()
+			t = time.Date(y, m, d, 23, 59, 59, 0, loc)
+		}
+		return t, nil
+	}
+	t, err := time.Parse(layout, value)
+	if err == nil {
+		return t, nil
+	}
+	natural, naturalErr := parseNaturalTime(value, now, loc)
+	if errors.Is(naturalErr, errNotNaturalTime) {
+		return time.Time{}, fmt.Errorf("%w, the supported formats are %s", err, supportedTimeFormats)
+	}
+	return natural, naturalErr
+}
+
+// Parse the Unix time in seconds, or in milliseconds with more than 10
+// digits, which are after 2286 in seconds.
+func parseEpochTime(value string) (time.Time, error) {
+	if strings.HasPrefix(value, "-") {
+		return time.Time{}, fmt.Errorf("the Unix time '%s' is negative, the supported formats are %s", value, supportedTimeFormats)
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w, the supported formats are %s", err, supportedTimeFormats)
+	}
+	if len(value) > 10 {
+		return time.UnixMilli(n).UTC(), nil
+	}
+	return time.Unix(n, 0).UTC(), nil
+}
+
+// wallClock is a time of day on the wall clock.
+type wallClock struct{ hour, minute int }
+
+// Parse the natural-language time resolved against now in the location:
+//
+//   - "now", "in 3 hours", "in 2 days", and "30 minutes ago".
+//   - An offset from now such as "-2h", "+30m", and "now+1h".
+//   - A day, "today", "tomorrow", "yesterday", or a weekday such as "saturday"
+//     or "sat", optionally with "next", "this", or "last", followed by an
+//     optional time such as "02:00", "2am", "2:30 pm", "noon", or "midnight",
+//     optionally after "at". The day alone is its midnight.
+//   - A time alone, which is today.
+//
+// A bare weekday is the next one on or after today, "next" is the first one
+// after today, "this" is the one on or after today, and "last" is the last one
+// before today. The ambiguous values are refused with the interpretations
+// instead of guessing: a bare weekday which is today, a bare hour from 1 to
+// 12, and a wall time repeated by a daylight saving time change.
+func parseNaturalTime(value string, now time.Time, loc *time.Location) (time.Time, error) {
+	now = now.In(loc)
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) == 0 {
+		return time.Time{}, errNotNaturalTime
+	}
+	if len(fields) == 1 && fields[0] == "now" {
+		return now, nil
+	}
+	if t, ok, err := parseOffsetTime(value, now); ok || err != nil {
+		return t, err
+	}
+	if t, ok, err := parseRelativeTime(fields, now); ok || err != nil {
+		return t, err
+	}
+
+	days, rest := parseNaturalDay(fields, now)
+	if days == nil {
+		days = []time.Time{now}
+	}
+	if len(rest) > 0 && rest[0] == "at" && len(rest) > 1 {
+		rest = rest[1:]
+	}
+	clocks := []wallClock{{}}
+	if len(rest) > 0 {
+		clocks = parseWallClock(strings.Join(rest, ""))
+		if clocks == nil {
+			return time.Time{}, errNotNaturalTime
+		}
+	}
+
+	var candidates []time.Time
+	for _, day := range days {
+		for _, clock := range clocks {
+			ts, err := resolveWallClock(value, day, clock, loc)
+			if err != nil {
+				return time.Time{}, err
+			}
+			candidates = append(candidates, ts...)
+		}
+	}
+	if len(candidates) > 1 {
+		values := make([]string, len(candidates))
+		for i, t := range candidates {
+			values[i] = t.Format(time.RFC3339)
+		}
+		last := len(values) - 1
+		return time.Time{}, fmt.Errorf("'%s' is ambiguous, it could be %s or %s", value, strings.Join(values[:last], ", "), values[last])
+	}
+	return candidates[0], nil
+}
+
+// Parse "in N units" and "N units ago", where units are minutes, hours, days,
+// or weeks, and N may be "a" or "an". The days and the weeks are on the
+// calendar, so they keep the wall clock over a daylight saving time change.
+func parseRelativeTime(fields []string, now time.Time) (time.Time, bool, error) {
+	var amount, unit string
+	sign := 1
+	switch {
+	case len(fields) == 3 && fields[0] == "in":
+		amount, unit = fields[1], fields[2]
+	case len(fields) == 3 && fields[2] == "ago":
+		amount, unit, sign = fields[0], fields[1], -1
+	default:
+		return time.Time{}, false, nil
+	}
+	n := 1
+	if amount != "a" && amount != "an" {
+		var err error
+		n, err = strconv.Atoi(amount)
+		if err != nil || n < 0 {
+			return time.Time{}, false, nil
+		}
+	}
+	n *= sign
+	unit = strings.TrimSuffix(unit, "s")
+	switch unit {
+	case "day":
+		return now.AddDate(0, 0, n), true, nil
+	case "week":
+		return now.AddDate(0, 0, 7*n), true, nil
+	}
+	d, ok := relativeUnits[unit]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	return now.Add(time.Duration(n) * d), true, nil
+}
+
+// Parse the signed offset from now, optionally after "now", such as "-2h",
+// "+30m", "now+1h", and "now - 1d12h", in the syntaxes of parseDuration. The
+// days are 24h, unlike those of "in 2 days".
+func parseOffsetTime(value string, now time.Time) (time.Time, bool, error) {
+	offset := strings.Join(strings.Fields(value), "")
+	if len(offset) >= 3 && strings.EqualFold(offset[:3], "now") {
+		offset = offset[3:]
+	}
+	if !strings.HasPrefix(offset, "+") && !strings.HasPrefix(offset, "-") {
+		return time.Time{}, false, nil
+	}
+	d, err := parseDuration(offset)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid offset '%s': %w", value, err)
+	}
+	return now.Add(d), true, nil
+}
+
+// Parse the leading day of the fields into the candidate days, and return the
+// rest of the fields. The days are nil without a day.
+func parseNaturalDay(fields []string, now time.Time) ([]time.Time, []string) {
+	switch fields[0] {
+	case "today":
+		return []time.Time{now}, fields[1:]
+	case "tomorrow":
+		return []time.Time{now.AddDate(0, 0, 1)}, fields[1:]
+	case "yesterday":
+		return []time.Time{now.AddDate(0, 0, -1)}, fields[1:]
+	}
+	modifier, rest := "", fields
+	if fields[0] == "next" || fields[0] == "this" || fields[0] == "last" {
+		if len(fields) == 1 {
+			return nil, fields
+		}
+		modifier, rest = fields[0], fields[1:]
+	}
+	weekday, ok := parseWeekday(rest[0])
+	if !ok {
+		return nil, fields
+	}
+	ahead := (int(weekday) - int(now.Weekday()) + 7) % 7
+	switch modifier {
+	case "next":
+		if ahead == 0 {
+			ahead = 7
+		}
+	case "last":
+		ahead -= 7
+	case "":
+		if ahead == 0 {
+			return []time.Time{now, now.AddDate(0, 0, 7)}, rest[1:]
+		}
+	}
+	return []time.Time{now.AddDate(0, 0, ahead)}, rest[1:]
+}
+
+// Parse the weekday by its full name or its first three letters.
+func parseWeekday(value string) (time.Weekday, bool) {
+	if len(value) < 3 {
+		return 0, false
+	}
+	weekday, ok := weekdayPrefixes[value[:3]]
+	if !ok || (len(value) > 3 && value != strings.ToLower(weekday.String())) {
+		return 0, false
+	}
+	return weekday, true
+}
+
+// Parse the time of day into the candidate wall clocks, nil when it is not a
+// time. The bare hours from 1 to 12 are either in the morning or in the
+// afternoon, while the hours with a leading zero or the minutes are on the 24
+// hour clock.
+func parseWallClock(value string) []wallClock {
+	switch value {
+	case "noon":
+		return []wallClock{{hour: 12}}
+	case "midnight":
+		return []wallClock{{}}
+	}
+	m := clockPattern.FindStringSubmatch(value)
+	if m == nil {
+		return nil
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute := 0
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	if minute > 59 {
+		return nil
+	}
+	switch {
+	case m[3] != "":
+		if hour < 1 || hour > 12 {
+			return nil
+		}
+		hour %= 12
+		if m[3] == "pm" {
+			hour += 12
+		}
+	case hour > 23:
+		return nil
+	case m[2] == "" && m[1][0] != '0' && hour <= 12:
+		return []wallClock{{hour: hour % 12}, {hour: hour%12 + 12}}
+	}
+	return []wallClock{{hour: hour, minute: minute}}
+}
+
+// Resolve the wall clock on the day in the location. A wall clock repeated by
+// a daylight saving time change has both of the instants, and one skipped by
+// it is an error.
+func resolveWallClock(value string, day time.Time, clock wallClock, loc *time.Location) ([]time.Time, error) {
+	y, m, d := day.Date()
+	t := time.Date(y, m, d, clock.hour, clock.minute, 0, 0, loc)
+	if t.Hour() != clock.hour || t.Minute() != clock.minute {
+		return nil, fmt.Errorf("'%s' doesn't exist in %s, the clocks skip %02d:%02d on %s", value, loc, clock.hour, clock.minute, t.Format("2006-01-02"))
+	}
+	ts := []time.Time{t}
+	for _, shift := range []time.Duration{time.Hour, 30 * time.Minute, 2 * time.Hour} {
+		for _, u := range []time.Time{t.Add(-shift), t.Add(shift)} {
+			uy, um, ud := u.Date()
+			if uy == y && um == m && ud == d && u.Hour() == clock.hour && u.Minute() == clock.minute {
+				ts = append(ts, u)
+			}
+		}
+	}
+	if len(ts) > 1 && ts[1].Before(ts[0]) {
+		ts[0], ts[1] = ts[1], ts[0]
+	}
+	return ts, nil
+}
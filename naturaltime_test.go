@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseNaturalTime(t *testing.T) {
+	t.Parallel()
+	// Wednesday.
+	now := getTime("2023-01-25T10:30:00Z")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value   string
+		now     time.Time
+		loc     *time.Location
+		want    string
+		wantErr string
+	}{
+		// Relative
+		{value: "now", want: "2023-01-25T10:30:00Z"},
+		{value: "NOW", want: "2023-01-25T10:30:00Z"},
+		{value: "in 3 hours", want: "2023-01-25T13:30:00Z"},
+		{value: "in 1 hour", want: "2023-01-25T11:30:00Z"},
+		{value: "in an hour", want: "2023-01-25T11:30:00Z"},
+		{value: "in 45 minutes", want: "2023-01-25T11:15:00Z"},
+		{value: "in 10 mins", want: "2023-01-25T10:40:00Z"},
+		{value: "in 2 days", want: "2023-01-27T10:30:00Z"},
+		{value: "in a week", want: "2023-02-01T10:30:00Z"},
+		{value: "30 minutes ago", want: "2023-01-25T10:00:00Z"},
+		{value: "2 hrs ago", want: "2023-01-25T08:30:00Z"},
+		{value: "in  3   hours", want: "2023-01-25T13:30:00Z"},
+
+		// Offsets
+		{value: "-2h", want: "2023-01-25T08:30:00Z"},
+		{value: "+30m", want: "2023-01-25T11:00:00Z"},
+		{value: "now+1h", want: "2023-01-25T11:30:00Z"},
+		{value: "NOW-90s", want: "2023-01-25T10:28:30Z"},
+		{value: "now - 1d12h", want: "2023-01-23T22:30:00Z"},
+		{value: "+PT1H30M", want: "2023-01-25T12:00:00Z"},
+		{value: "+1d", loc: tokyo, want: "2023-01-26T19:30:00+09:00"},
+		// The days are 24h over the daylight saving time.
+		{value: "+1d", now: getTime("2023-03-11T12:00:00-05:00"), loc: newYork, want: "2023-03-12T13:00:00-04:00"},
+
+		// Days
+		{value: "today", want: "2023-01-25T00:00:00Z"},
+		{value: "tomorrow", want: "2023-01-26T00:00:00Z"},
+		{value: "yesterday", want: "2023-01-24T00:00:00Z"},
+		{value: "Tomorrow 02:00", want: "2023-01-26T02:00:00Z"},
+		{value: "tomorrow at 2am", want: "2023-01-26T02:00:00Z"},
+		{value: "today 14:15", want: "2023-01-25T14:15:00Z"},
+		{value: "yesterday 11:30 pm", want: "2023-01-24T23:30:00Z"},
+		{value: "tomorrow noon", want: "2023-01-26T12:00:00Z"},
+		{value: "today midnight", want: "2023-01-25T00:00:00Z"},
+		{value: "tomorrow 12am", want: "2023-01-26T00:00:00Z"},
+		{value: "tomorrow 12pm", want: "2023-01-26T12:00:00Z"},
+
+		// Weekdays
+		{value: "saturday", want: "2023-01-28T00:00:00Z"},
+		{value: "sat", want: "2023-01-28T00:00:00Z"},
+		{value: "next saturday 02:00", want: "2023-01-28T02:00:00Z"},
+		{value: "this saturday 02:00", want: "2023-01-28T02:00:00Z"},
+		{value: "last saturday 02:00", want: "2023-01-21T02:00:00Z"},
+		{value: "monday 9am", want: "2023-01-30T09:00:00Z"},
+		{value: "next wednesday", want: "2023-02-01T00:00:00Z"},
+		{value: "this wednesday", want: "2023-01-25T00:00:00Z"},
+		{value: "last wednesday", want: "2023-01-18T00:00:00Z"},
+		{value: "last tue", want: "2023-01-24T00:00:00Z"},
+		{value: "friday at 18:00", want: "2023-01-27T18:00:00Z"},
+
+		// Times alone are today.
+		{value: "02:00", want: "2023-01-25T02:00:00Z"},
+		{value: "9:05pm", want: "2023-01-25T21:05:00Z"},
+		{value: "at 14", want: "2023-01-25T14:00:00Z"},
+		{value: "08", want: "2023-01-25T08:00:00Z"},
+		{value: "0", want: "2023-01-25T00:00:00Z"},
+
+		// The location
+		{value: "tomorrow 02:00", loc: tokyo, want: "2023-01-26T02:00:00+09:00"},
+		// It is already Thursday in Tokyo.
+		{value: "today", loc: tokyo, want: "2023-01-25T00:00:00+09:00"},
+		{value: "today", now: getTime("2023-01-25T20:00:00Z"), loc: tokyo, want: "2023-01-26T00:00:00+09:00"},
+		{value: "saturday", now: getTime("2023-01-25T20:00:00Z"), loc: tokyo, want: "2023-01-28T00:00:00+09:00"},
+		// The calendar days keep the wall clock over the daylight saving time.
+		{value: "in 1 day", now: getTime("2023-03-11T12:00:00-05:00"), loc: newYork, want: "2023-03-12T12:00:00-04:00"},
+		{value: "in 24 hours", now: getTime("2023-03-11T12:00:00-05:00"), loc: newYork, want: "2023-03-12T13:00:00-04:00"},
+		{value: "tomorrow 03:00", now: getTime("2023-03-11T12:00:00-05:00"), loc: newYork, want: "2023-03-12T03:00:00-04:00"},
+
+		// Ambiguous
+		{value: "wednesday", wantErr: "'wednesday' is ambiguous, it could be 2023-01-25T00:00:00Z or 2023-02-01T00:00:00Z"},
+		{value: "tomorrow 2", wantErr: "'tomorrow 2' is ambiguous, it could be 2023-01-26T02:00:00Z or 2023-01-26T14:00:00Z"},
+		{value: "today 12", wantErr: "'today 12' is ambiguous, it could be 2023-01-25T00:00:00Z or 2023-01-25T12:00:00Z"},
+		{value: "wed 10", wantErr: "'wed 10' is ambiguous, it could be 2023-01-25T10:00:00Z, 2023-01-25T22:00:00Z, 2023-02-01T10:00:00Z or 2023-02-01T22:00:00Z"},
+		{value: "sunday 01:30", now: getTime("2023-11-01T12:00:00-04:00"), loc: newYork, wantErr: "'sunday 01:30' is ambiguous, it could be 2023-11-05T01:30:00-04:00 or 2023-11-05T01:30:00-05:00"},
+		{value: "sunday 02:30", now: getTime("2023-03-08T12:00:00-05:00"), loc: newYork, wantErr: "'sunday 02:30' doesn't exist in America/New_York, the clocks skip 02:30 on 2023-03-12"},
+
+		// Not natural-language times
+		{value: "", wantErr: errNotNaturalTime.Error()},
+		{value: "2023-01-24", wantErr: errNotNaturalTime.Error()},
+		{value: "next", wantErr: errNotNaturalTime.Error()},
+		{value: "next today", wantErr: errNotNaturalTime.Error()},
+		{value: "someday", wantErr: errNotNaturalTime.Error()},
+		{value: "saturn", wantErr: errNotNaturalTime.Error()},
+		{value: "tomorrow 25:00", wantErr: errNotNaturalTime.Error()},
+		{value: "tomorrow 02:60", wantErr: errNotNaturalTime.Error()},
+		{value: "tomorrow 13pm", wantErr: errNotNaturalTime.Error()},
+		{value: "tomorrow 2 o'clock", wantErr: errNotNaturalTime.Error()},
+		{value: "in 3 fortnights", wantErr: errNotNaturalTime.Error()},
+		{value: "in -3 hours", wantErr: errNotNaturalTime.Error()},
+		{value: "in three hours", wantErr: errNotNaturalTime.Error()},
+		{value: "now+", wantErr: `invalid offset 'now+': time: invalid duration "+"`},
+		{value: "-2 hours", wantErr: `invalid offset '-2 hours': time: unknown unit "hours" in duration "-2hours"`},
+		{value: "now+P1M", wantErr: "invalid offset 'now+P1M': years and months have no fixed length: +P1M"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			ref, loc := now, time.UTC
+			if !tt.now.IsZero() {
+				ref = tt.now
+			}
+			if tt.loc != nil {
+				loc = tt.loc
+			}
+			got, err := parseNaturalTime(tt.value, ref, loc)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want %q, got %v, %v", tt.wantErr, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got.Format(time.RFC3339)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseTimeFlag(t *testing.T) {
+	t.Parallel()
+	now := getTime("2023-01-25T10:30:00Z")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value    string
+		endOfDay bool
+		loc      *time.Location
+		want     string
+	}{
+		{value: "2023-01-24T00:00:00+09:00", want: "2023-01-24T00:00:00+09:00"},
+		{value: "2023-01-24T00:00:00+09:00", endOfDay: true, want: "2023-01-24T00:00:00+09:00"},
+		{value: "2023-05-01", want: "2023-05-01T00:00:00Z"},
+		{value: "2023-05-01", endOfDay: true, want: "2023-05-01T23:59:59Z"},
+		{value: "2023-05-01", loc: tokyo, want: "2023-05-01T00:00:00+09:00"},
+		{value: "2023-05-01", endOfDay: true, loc: tokyo, want: "2023-05-01T23:59:59+09:00"},
+		{value: "2023-02-28", endOfDay: true, want: "2023-02-28T23:59:59Z"},
+		{value: "tomorrow", endOfDay: true, want: "2023-01-26T00:00:00Z"},
+		// The time without the offset is in the location, and the offset wins.
+		{value: "2023-01-24T02:00:00", want: "2023-01-24T02:00:00Z"},
+		{value: "2023-01-24T02:00:00", loc: tokyo, want: "2023-01-24T02:00:00+09:00"},
+		{value: "2023-01-24T02:00:00", endOfDay: true, loc: tokyo, want: "2023-01-24T02:00:00+09:00"},
+		{value: "2023-01-24T02:00:00Z", loc: tokyo, want: "2023-01-24T02:00:00Z"},
+		{value: "2023-01-24T02:00:00-05:00", loc: tokyo, want: "2023-01-24T02:00:00-05:00"},
+		// The Unix time is in seconds, or in milliseconds with more than 10
+		// digits, regardless of the location.
+		{value: "1674518400", want: "2023-01-24T00:00:00Z"},
+		{value: "1674518400", endOfDay: true, loc: tokyo, want: "2023-01-24T00:00:00Z"},
+		{value: "1674518400123", want: "2023-01-24T00:00:00Z"},
+		{value: "1674540000000", want: "2023-01-24T06:00:00Z"},
+		{value: "100", want: "1970-01-01T00:01:40Z"},
+		// A number of 1 or 2 digits is an hour of today.
+		{value: "08", want: "2023-01-25T08:00:00Z"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(fmt.Sprintf("%s/%v", tt.value, tt.endOfDay), func(t *testing.T) {
+			t.Parallel()
+			loc := time.UTC
+			if tt.loc != nil {
+				loc = tt.loc
+			}
+			got, err := parseTimeFlag(tt.value, time.RFC3339, tt.endOfDay, now, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got.Format(time.RFC3339)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// The errors of the layouts name the supported formats.
+	for _, value := range []string{"2023-13-45", "2023-01-24T25:00:00", "2023-01-24 00:00", "someday"} {
+		_, err := parseTimeFlag(value, time.RFC3339, false, now, time.UTC)
+		var parseErr *time.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%s: want the time.ParseError, got %v", value, err)
+		}
+		if err != nil && !strings.HasSuffix(err.Error(), ", the supported formats are "+supportedTimeFormats) {
+			t.Errorf("%s: want the supported formats, got %v", value, err)
+		}
+	}
+	for _, value := range []string{"-100", "-1674518400", "99999999999999999999"} {
+		_, err := parseTimeFlag(value, time.RFC3339, false, now, time.UTC)
+		if err == nil || !strings.HasSuffix(err.Error(), ", the supported formats are "+supportedTimeFormats) {
+			t.Errorf("%s: want the error with the supported formats, got %v", value, err)
+		}
+	}
+}
+
+func Test_run_naturalTime(t *testing.T) {
+	t.Parallel()
+	if _, _, err := runFake(newRunClients(), "--from", "tomorrow", "--to", "in 2 days"); err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	// A date alone of '--to' is the end of the day.
+	stdout, _, err := runFake(newRunClients(), "--from", "2023-01-24", "--to", "2023-01-24", "-o", "json")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var doc printformat
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("failed to decode the output: %s", err)
+	}
+	wantWindow := &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T23:59:59Z")}
+	if diff := cmp.Diff(wantWindow, doc.Window); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+	_, _, err = runFake(newRunClients(), "--from", "2023-13-45", "--to", "2023-01-24")
+	if want := `failed to parse '--from' value: parsing time "2023-13-45": month out of range, the supported formats are `; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want %q..., got %v", want, err)
+	}
+
+	// The Unix times of both flags.
+	stdout, _, err = runFake(newRunClients(), "--from", "1674518400", "--to", "1674540000000", "-o", "json")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	doc = printformat{}
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("failed to decode the output: %s", err)
+	}
+	wantWindow = &documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+	if diff := cmp.Diff(wantWindow, doc.Window); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+	_, _, err = runFake(newRunClients(), "--from", "1674518400", "--to", "-1674540000")
+	if want := "failed to parse '--to' value: the Unix time '-1674540000' is negative, the supported formats are "; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want %q..., got %v", want, err)
+	}
+
+	// The times without the offset are in '--timezone', which the times are
+	// printed in too.
+	stdout, _, err = runFake(newRunClients(), "--from", "2023-01-24T00:00:00", "--to", "2023-01-24T06:00:00", "--timezone", "Asia/Tokyo", "-o", "json")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	doc = printformat{}
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("failed to decode the output: %s", err)
+	}
+	wantWindow = &documentWindow{From: getTime("2023-01-23T15:00:00Z"), To: getTime("2023-01-23T21:00:00Z")}
+	if diff := cmp.Diff(wantWindow, doc.Window); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+	stdout, _, err = runFake(newRunClients(), "--from", "2023-01-24", "--to", "2023-01-24", "--timezone", "Asia/Tokyo", "-o", "raw")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(stdout, "+09:00\n") {
+		t.Errorf("want the next fires in Asia/Tokyo, got %q", stdout)
+	}
+	stdout, _, err = runFake(newRunClients(), "--from", "2023-01-24", "--to", "2023-01-24", "--timezone", "Asia/Tokyo", "--display-timezone", "UTC", "-o", "raw")
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if !strings.Contains(stdout, "Z\n") {
+		t.Errorf("want the next fires in '--display-timezone', got %q", stdout)
+	}
+	_, _, err = runFake(newRunClients(), "--timezone", "Mars/Olympus")
+	if want := "failed to parse '--timezone' value: unknown time zone Mars/Olympus"; err == nil || err.Error() != want {
+		t.Errorf("want %q, got %v", want, err)
+	}
+
+	// The absolute and the relative times can be mixed.
+	if _, _, err := runFake(newRunClients(), "--from", "2023-01-24T00:00:00Z", "--to", "+1h"); err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	if _, _, err := runFake(newRunClients(), "--from", "-2h", "--to", "now+30m"); err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	if _, _, err := runFake(newRunClients(), "--from", "+1h", "--to", "-1h"); err == nil || err.Error() != "'--from' '--to' times are reversed" {
+		t.Errorf("want the reversed times, got %v", err)
+	}
+	_, _, err = runFake(newRunClients(), "--from", "next saturday 02:00", "--to", "next sunday 10")
+	want := "failed to parse '--to' value: 'next sunday 10' is ambiguous, it could be "
+	if err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want %q..., got %v", want, err)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+)
+
+// upcoming is the next fire of an item from now.
+type upcoming struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Schedule  string    `json:"schedule"`
+	Suspend   bool      `json:"suspend"`
+	NextFire  time.Time `json:"nextFire"`
+}
+
+type upcomingReport struct {
+	ApiVersion string     `json:"apiVersion"`
+	Upcoming   []upcoming `json:"upcoming"`
+}
+
+// runNext prints the next fires from now of the items, the soonest first. now
+// is the clock, replaced in the tests.
+func runNext(stdout, stderr io.Writer, args []string, buildClients clientsFactory, now func() time.Time) (retErr error) {
+	// Parse flags
+	// -----------------
+	var (
+		withinFlag           time.Duration
+		limitFlag            int
+		selectorFlag         string
+		includeSuspendedFlag bool
+		outputFlag           string
+		noHeadersFlag        bool
+		displayTimezoneFlag  string
+		serverTimeFlag       bool
+		logFormatFlag        string
+		inClusterFlag        bool
+	)
+	fsets := pflag.NewFlagSet(commandName+" next", pflag.ContinueOnError)
+	fsets.SetOutput(stderr)
+	durationVarP(fsets, &withinFlag, "within", "", 0, "If greater than zero, print only the fires within the duration from now, e.g. 6h.")
+	fsets.IntVarP(&limitFlag, "limit", "", 10, "The maximum number of items to print, the soonest first. Zero means no limit.")
+	fsets.StringVarP(&selectorFlag, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2).")
+	fsets.BoolVarP(&includeSuspendedFlag, "include-suspended", "", false, "If present, also print the suspended items, marked in the Suspend column.")
+	fsets.StringVarP(&outputFlag, "output", "o", "", "Output format. One of: json|yaml.")
+	fsets.BoolVarP(&noHeadersFlag, "no-headers", "", false, "If present, print output without headers.")
+	fsets.StringVarP(&displayTimezoneFlag, "display-timezone", "", "UTC", "The IANA time zone the timestamps in the table are printed in, or 'local'.")
+	fsets.BoolVarP(&serverTimeFlag, "server-time", "", false, "If present, take now from the clock of the API server instead of the local one.")
+	fsets.StringVarP(&logFormatFlag, "log-format", "", logFormatText, "The format of the logs written into stderr. One of: text|json.")
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.AddFlags(fsets)
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
+
+	fsets.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of %s next:\n", commandName)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example")
+		fmt.Fprintln(stderr, "  $ kubectl-cls next --within 6h -n default")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Flags")
+		fmt.Fprint(stderr, fsets.FlagUsages())
+	}
+
+	if err := fsets.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+	if err := validateLogFormat(logFormatFlag); err != nil {
+		return err
+	}
+	logger := newLogger(stderr, logFormatFlag, false)
+	defer func() { retErr = logCommandError(logger, logFormatFlag, retErr) }()
+
+	// Validation
+	// -----------------
+	if withinFlag < 0 {
+		return errors.New("'--within' must not be negative")
+	}
+	if limitFlag < 0 {
+		return errors.New("'--limit' must not be negative")
+	}
+	if outputFlag != "" && outputFlag != "json" && outputFlag != "yaml" {
+		return fmt.Errorf("%s is unsupported output format", outputFlag)
+	}
+	if _, err := parseSelector("selector", selectorFlag); err != nil {
+		return err
+	}
+	displayLocation, err := parseLocation("display-timezone", displayTimezoneFlag)
+	if err != nil {
+		return err
+	}
+
+	// List the next fires
+	// -----------------
+	c, err := buildClients(cfgFlags)
+	if err != nil {
+		return err
+	}
+	namespace := ""
+	if cfgFlags.Namespace != nil {
+		namespace = *cfgFlags.Namespace
+	}
+	items, err := listItems(context.Background(), c, namespace, selectorFlag)
+	if err != nil {
+		return err
+	}
+
+	if serverTimeFlag {
+		now = getServerClock(context.Background(), c, now, logger)
+	}
+	start := now()
+	var until time.Time
+	if withinFlag > 0 {
+		until = start.Add(withinFlag)
+	}
+	fires, err := findUpcoming(items, start, until, includeSuspendedFlag)
+	if err != nil {
+		return err
+	}
+	if limitFlag > 0 && len(fires) > limitFlag {
+		fires = fires[:limitFlag]
+	}
+	tf := timeFormat{location: displayLocation, now: start}
+	return printUpcoming(stdout, outputFlag, noHeadersFlag, tf, fires)
+}
+
+// Get the next fire of each item after now, sorted ascending with the ties in
+// the order of the items. The items never firing, the ones firing after
+// until unless it is zero, and the suspended ones unless includeSuspended are
+// excluded.
+func findUpcoming(items []item, now, until time.Time, includeSuspended bool) ([]upcoming, error) {
+	fires := []upcoming{}
+	for _, item := range items {
+		if item.suspended() && !includeSuspended {
+			continue
+		}
+		next, schedule, err := getNextFire(item, now)
+		if err != nil {
+			return nil, err
+		}
+		if next.IsZero() || (!until.IsZero() && next.After(until)) {
+			continue
+		}
+		obj := item.object()
+		fires = append(fires, upcoming{
+			Kind:      item.kind(),
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Schedule:  schedule,
+			Suspend:   item.suspended(),
+			NextFire:  next.UTC(),
+		})
+	}
+	sort.SliceStable(fires, func(i, j int) bool { return fires[i].NextFire.Before(fires[j].NextFire) })
+	return fires, nil
+}
+
+// Get the soonest fire of the schedules of the item after now, and the
+// schedule firing then, or the zero time when none of them fires.
+func getNextFire(item item, now time.Time) (time.Time, string, error) {
+	var next time.Time
+	var matched string
+	for _, schedule := range item.schedules() {
+		spec := schedule
+		if tz := item.timezone(); tz != "" {
+			spec = fmt.Sprintf("CRON_TZ=%s %s", tz, spec)
+		}
+		sched, err := cron.ParseStandard(spec)
+		if err != nil {
+			obj := item.object()
+			return time.Time{}, "", fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", spec, item.kind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		t := sched.Next(now)
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next, matched = t, schedule
+		}
+	}
+	return next, matched, nil
+}
+
+func printUpcoming(stdout io.Writer, output string, noHeaders bool, tf timeFormat, fires []upcoming) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(upcomingReport{ApiVersion: "v1", Upcoming: fires}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(upcomingReport{ApiVersion: "v1", Upcoming: fires})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Schedule", "Suspend", "Kind", "Next Fire", "In"}, "\t"))
+	}
+	for _, f := range fires {
+		next := metav1.NewTime(f.NextFire)
+		relative := timeFormat{relative: true, now: tf.now}
+		fmt.Fprintln(tw, strings.Join([]string{f.Namespace, f.Name, f.Schedule, strconv.FormatBool(f.Suspend), f.Kind, tf.format(&next), relative.format(&next)}, "\t"))
+	}
+	return tw.Flush()
+}
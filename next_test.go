@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// Run the next subcommand with the clients and the clock at now.
+func runFakeNext(c *clients, now string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+		return c, nil
+	}
+	clock := func() time.Time { return getTime(now) }
+	err := runNext(&stdout, &stderr, append([]string{"next"}, args...), buildClients, clock)
+	return stdout.String(), err
+}
+
+func Test_runNext(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "ordered by next fire",
+			args: []string{"--no-headers"},
+			want: "" +
+				"ns-b   report    0 3 * * *    false   CronWorkflow   2023-01-24T03:00:00Z   in 30m\n" +
+				"ns-b   nightly   0 12 * * *   false   CronJob        2023-01-24T12:00:00Z   in 9h\n" +
+				"ns-a   backup    0 1 * * *    false   CronJob        2023-01-25T01:00:00Z   in 22h\n",
+		},
+		{
+			name: "headers",
+			args: []string{"--within", "1h"},
+			want: "" +
+				"Namespace   Name     Schedule    Suspend   Kind           Next Fire              In\n" +
+				"ns-b        report   0 3 * * *   false     CronWorkflow   2023-01-24T03:00:00Z   in 30m\n",
+		},
+		{
+			name: "within",
+			args: []string{"--no-headers", "--within", "9h30m"},
+			want: "" +
+				"ns-b   report    0 3 * * *    false   CronWorkflow   2023-01-24T03:00:00Z   in 30m\n" +
+				"ns-b   nightly   0 12 * * *   false   CronJob        2023-01-24T12:00:00Z   in 9h\n",
+		},
+		{
+			name: "within nothing",
+			args: []string{"--no-headers", "--within", "10m"},
+			want: "",
+		},
+		{
+			name: "limit",
+			args: []string{"--no-headers", "--limit", "1"},
+			want: "ns-b   report   0 3 * * *   false   CronWorkflow   2023-01-24T03:00:00Z   in 30m\n",
+		},
+		{
+			name: "include suspended",
+			args: []string{"--no-headers", "--include-suspended", "-n", "ns-b"},
+			want: "" +
+				"ns-b   report    0 3 * * *    false   CronWorkflow   2023-01-24T03:00:00Z   in 30m\n" +
+				"ns-b   nightly   0 12 * * *   false   CronJob        2023-01-24T12:00:00Z   in 9h\n" +
+				"ns-b   cleanup   0 2 * * *    true    CronJob        2023-01-25T02:00:00Z   in 23h\n",
+		},
+		{
+			name: "selector and display timezone",
+			args: []string{"--no-headers", "-l", "app=a", "--display-timezone", "Asia/Tokyo"},
+			want: "" +
+				"ns-b   report   0 3 * * *   false   CronWorkflow   2023-01-24T12:00:00+09:00   in 30m\n" +
+				"ns-a   backup   0 1 * * *   false   CronJob        2023-01-25T10:00:00+09:00   in 22h\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := runFakeNext(newRunClients(), "2023-01-24T02:30:00Z", tt.args...)
+			if err != nil {
+				t.Fatalf("runNext() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_runNext_json(t *testing.T) {
+	t.Parallel()
+	got, err := runFakeNext(newRunClients(), "2023-01-24T02:30:00Z", "-o", "json", "--within", "1h")
+	if err != nil {
+		t.Fatalf("runNext() error = %v", err)
+	}
+	var report upcomingReport
+	if err := json.Unmarshal([]byte(got), &report); err != nil {
+		t.Fatal(err)
+	}
+	want := upcomingReport{
+		ApiVersion: "v1",
+		Upcoming: []upcoming{
+			{Kind: "CronWorkflow", Namespace: "ns-b", Name: "report", Schedule: "0 3 * * *", NextFire: getTime("2023-01-24T03:00:00Z")},
+		},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_runNext_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "negative within", args: []string{"--within", "-1h"}, want: "'--within' must not be negative"},
+		{name: "negative limit", args: []string{"--limit", "-1"}, want: "'--limit' must not be negative"},
+		{name: "output", args: []string{"-o", "wide"}, want: "wide is unsupported output format"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := runFakeNext(newRunClients(), "2023-01-24T02:30:00Z", tt.args...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("want error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// defaultPager is the pager without $KUBECTL_CLS_PAGER nor $PAGER, the same as
+// git: it quits when the table fits into a screen, keeps the colors, and
+// doesn't clear the screen on exit.
+const defaultPager = "less -FRX"
+
+// pagerRunner runs the command of the pager with the table as its input. It
+// returns an error only when the pager can't be started, as it exits on its
+// own terms, e.g. when the user quits before the end.
+type pagerRunner func(command string, input io.Reader, stdout, stderr io.Writer) error
+
+// pager pipes the tables taller than the terminal through the pager of the
+// user, like git. The environment and the runner are replaced in the tests.
+type pager struct {
+	lookupEnv func(string) (string, bool)
+	// size gets the size of the terminal of stdout, false when it isn't a
+	// terminal.
+	size func(io.Writer) (width, height int, ok bool)
+	run  pagerRunner
+}
+
+// terminalPager is the pager of the running process.
+var terminalPager = pager{lookupEnv: os.LookupEnv, size: terminalSize, run: runPagerCommand}
+
+// Get the command of the pager for a table of the lines, or empty when it
+// isn't paged: stdout isn't a terminal, the table fits into its height, or
+// the pager is disabled by an empty value or 'cat'. $KUBECTL_CLS_PAGER takes
+// precedence over $PAGER.
+func (p pager) command(stdout io.Writer, lines int) string {
+	_, height, ok := p.size(stdout)
+	if !ok || height <= 0 || lines <= height {
+		return ""
+	}
+	command := defaultPager
+	for _, key := range []string{"KUBECTL_CLS_PAGER", "PAGER"} {
+		if value, ok := p.lookupEnv(key); ok {
+			command = value
+			break
+		}
+	}
+	if command = strings.TrimSpace(command); command == "cat" {
+		return ""
+	}
+	return command
+}
+
+// Write the table into stdout, through the pager when it's taller than the
+// terminal. The table is written directly when the pager can't be started.
+func (p pager) write(stdout, stderr io.Writer, table []byte) error {
+	if command := p.command(stdout, bytes.Count(table, []byte("\n"))); command != "" {
+		if err := p.run(command, bytes.NewReader(table), stdout, stderr); err == nil {
+			return nil
+		}
+	}
+	if _, err := stdout.Write(table); err != nil {
+		return fmt.Errorf("failed to write the table: %w", err)
+	}
+	return nil
+}
+
+// Run the pager by the shell, as $PAGER may have the arguments. The program
+// is looked up first, as the shell itself starts without it.
+func runPagerCommand(command string, input io.Reader, stdout, stderr io.Writer) error {
+	if _, err := exec.LookPath(strings.Fields(command)[0]); err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = input
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// The exit code of the pager is not of the command.
+	_ = cmd.Wait()
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Get the pager of a fake terminal of the height, or of a pipe when height is
+// negative.
+func newFakePager(height int, env map[string]string, run pagerRunner) pager {
+	return pager{
+		lookupEnv: func(key string) (string, bool) {
+			value, ok := env[key]
+			return value, ok
+		},
+		size: func(io.Writer) (int, int, bool) {
+			return 80, height, height >= 0
+		},
+		run: run,
+	}
+}
+
+func Test_pager_command(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		height int
+		lines  int
+		env    map[string]string
+		want   string
+	}{
+		{name: "taller than the terminal", height: 24, lines: 25, want: defaultPager},
+		{name: "fits into the terminal", height: 24, lines: 24, want: ""},
+		{name: "not a terminal", height: -1, lines: 100, want: ""},
+		{name: "unknown height", height: 0, lines: 100, want: ""},
+		{name: "PAGER", height: 24, lines: 25, env: map[string]string{"PAGER": "more"}, want: "more"},
+		{
+			name:   "KUBECTL_CLS_PAGER over PAGER",
+			height: 24,
+			lines:  25,
+			env:    map[string]string{"KUBECTL_CLS_PAGER": "less -S", "PAGER": "more"},
+			want:   "less -S",
+		},
+		{name: "disabled by empty", height: 24, lines: 25, env: map[string]string{"PAGER": ""}, want: ""},
+		{name: "disabled by cat", height: 24, lines: 25, env: map[string]string{"KUBECTL_CLS_PAGER": "cat", "PAGER": "more"}, want: ""},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := newFakePager(tt.height, tt.env, nil)
+			if got := p.command(&bytes.Buffer{}, tt.lines); got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_pager_write(t *testing.T) {
+	t.Parallel()
+	table := []byte("ns-a   backup   0 1 * * *\nns-b   report   0 2 * * *\n")
+	tests := []struct {
+		name       string
+		height     int
+		runErr     error
+		wantRun    string
+		wantStdout string
+	}{
+		{name: "paged", height: 1, wantRun: defaultPager, wantStdout: "paged: " + string(table)},
+		{name: "fits", height: 2, wantStdout: string(table)},
+		{name: "piped", height: -1, wantStdout: string(table)},
+		{name: "failed to start", height: 1, runErr: errors.New("not found"), wantRun: defaultPager, wantStdout: string(table)},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var ran string
+			run := func(command string, input io.Reader, stdout, stderr io.Writer) error {
+				ran = command
+				if tt.runErr != nil {
+					return tt.runErr
+				}
+				b, err := io.ReadAll(input)
+				if err != nil {
+					return err
+				}
+				_, err = stdout.Write(append([]byte("paged: "), b...))
+				return err
+			}
+			var stdout, stderr bytes.Buffer
+			if err := newFakePager(tt.height, nil, run).write(&stdout, &stderr, table); err != nil {
+				t.Fatalf("write() error = %v", err)
+			}
+			if ran != tt.wantRun {
+				t.Errorf("want the pager %q, ran %q", tt.wantRun, ran)
+			}
+			if diff := cmp.Diff(tt.wantStdout, stdout.String()); diff != "" {
+				t.Errorf("stdout (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_terminalSize(t *testing.T) {
+	t.Parallel()
+	if _, _, ok := terminalSize(&bytes.Buffer{}); ok {
+		t.Errorf("want a buffer not to be a terminal")
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatalf("failed to create a file: %s", err)
+	}
+	defer f.Close()
+	if _, _, ok := terminalSize(f); ok {
+		t.Errorf("want a regular file not to be a terminal")
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/unblee/kubectl-cls/pkg/cls"
+	"golang.org/x/exp/slog"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// exitPartialFailure is the exit code when some kinds failed to be listed and
+// the others were printed.
+const exitPartialFailure = 2
+
+// errPartialFailure is returned by run after printing the results of the kinds
+// listed successfully, see kindFailures.
+var errPartialFailure = errors.New("some kinds failed to be listed, the results are partial")
+
+// kindFailures records the failures of the kinds which failed to be listed
+// while the others were listed, so that the successful kinds are still
+// printed. It is safe for concurrent use, and a nil kindFailures fails the
+// listing instead, see '--strict'.
+type kindFailures struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (f *kindFailures) add(errs ...error) {
+	if f == nil || len(errs) == 0 {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs = append(f.errs, errs...)
+}
+
+func (f *kindFailures) failed() bool {
+	if f == nil {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.errs) > 0
+}
+
+// Log the failures with their causes as warnings, with the kind and the
+// namespace of each.
+func (f *kindFailures) warn(logger *slog.Logger) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, err := range f.errs {
+		var attrs []any
+		var kerr kindError
+		if errors.As(err, &kerr) {
+			attrs = append(attrs, "kind", kerr.kind)
+			if kerr.namespace != "" {
+				attrs = append(attrs, "namespace", kerr.namespace)
+			}
+		}
+		logger.Warn(err.Error(), attrs...)
+	}
+}
+
+// kindError is the error of the kind failed to be listed in the namespace, or
+// in all namespaces when empty.
+type kindError struct {
+	kind      string
+	namespace string
+	err       error
+}
+
+func (e kindError) Error() string { return e.err.Error() }
+
+func (e kindError) Unwrap() error { return e.err }
+
+// tolerantSource is a Source which records its error into failed and lists
+// nothing, so that cls.Match goes on with the other sources.
+type tolerantSource struct {
+	cls.Source
+	kind   string
+	failed *[]error
+}
+
+func (s tolerantSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	objects, err := s.Source.List(ctx, opts)
+	if err == nil || ctx.Err() != nil {
+		return objects, err
+	}
+	// Keep failing when the cluster-wide listing is forbidden, so that
+	// listScheduleIncludedInAllNamespaces falls back to each namespace.
+	if opts.Namespace == "" && apierrors.IsForbidden(err) {
+		return nil, err
+	}
+	*s.failed = append(*s.failed, kindError{kind: s.kind, namespace: opts.Namespace, err: err})
+	return nil, nil
+}
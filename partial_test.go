@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func failList(fake *k8stesting.Fake, resource string, err error) {
+	fake.PrependReactor("list", resource, func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, err
+	})
+}
+
+func Test_listScheduleIncluded_partial(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	tests := []struct {
+		name          string
+		strict        bool
+		failCronJobs  bool
+		failWorkflows bool
+		want          []string
+		wantErr       string
+		wantWarnings  string
+	}{
+		{
+			name: "both listed",
+			want: []string{"CronJob/default/backup", "CronWorkflow/default/report"},
+		},
+		{
+			name:         "CronJobs failed",
+			failCronJobs: true,
+			want:         []string{"CronWorkflow/default/report"},
+			wantWarnings: "warning: failed to get CronJobs in 'default' namespace: connection refused\n",
+		},
+		{
+			name:          "CronWorkflows failed",
+			failWorkflows: true,
+			want:          []string{"CronJob/default/backup"},
+			wantWarnings:  "warning: failed to get CronWorkflow in 'default' namespace: connection refused\n",
+		},
+		{
+			name:          "both failed",
+			failCronJobs:  true,
+			failWorkflows: true,
+			wantErr:       "failed to get CronJobs in 'default' namespace: connection refused",
+		},
+		{
+			name:         "CronJobs failed with strict",
+			strict:       true,
+			failCronJobs: true,
+			wantErr:      "failed to get CronJobs in 'default' namespace: connection refused",
+		},
+		{
+			name:          "CronWorkflows failed with strict",
+			strict:        true,
+			failWorkflows: true,
+			wantErr:       "failed to get CronWorkflow in 'default' namespace: connection refused",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cronjob := getCronJob("default", "backup", "0 1 * * *", false)
+			cronworkflow := getCronWorkflow("default", "report", "0 2 * * *", false)
+			k8sClient := k8sfake.NewSimpleClientset(&cronjob)
+			argoClient := argofake.NewSimpleClientset(&cronworkflow)
+			if tt.failCronJobs {
+				failList(&k8sClient.Fake, "cronjobs", errors.New("connection refused"))
+			}
+			if tt.failWorkflows {
+				failList(&argoClient.Fake, "cronworkflows", errors.New("connection refused"))
+			}
+			c := &clients{k8s: k8sClient, argo: argoClient.ArgoprojV1alpha1()}
+			if !tt.strict {
+				c.failures = &kindFailures{}
+			}
+
+			got, err := listScheduleIncluded(context.Background(), c, "default", "", from, to, boundaries{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("want an error containing %q, got %v", tt.wantErr, err)
+				}
+				if c.failures.failed() {
+					t.Errorf("want no failures recorded on an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("listScheduleIncluded() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("items mismatch (-want +got):\n%s", diff)
+			}
+			var stderr bytes.Buffer
+			c.failures.warn(newLogger(&stderr, logFormatText, false))
+			if diff := cmp.Diff(tt.wantWarnings, stderr.String()); diff != "" {
+				t.Errorf("warnings mismatch (-want +got):\n%s", diff)
+			}
+			if got := c.failures.failed(); got != (tt.wantWarnings != "") {
+				t.Errorf("failed() = %v", got)
+			}
+		})
+	}
+}
+
+func Test_listScheduleIncludedInAllNamespaces_partial(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	c := newFakeClients(
+		[]batchv1.CronJob{getCronJob("team-a", "backup", "0 1 * * *", false)},
+		[]wfv1alpha1.CronWorkflow{getCronWorkflow("team-a", "report", "0 1 * * *", false)},
+	)
+	fake := c.k8s.(*k8sfake.Clientset)
+	fake.Tracker().Add(getNamespace("team-a", nil))
+	// The cluster-wide listing of CronJobs is forbidden, but not in team-a.
+	forbidList(&fake.Fake, "cronjobs")
+	c.failures = &kindFailures{}
+
+	got, skipped, err := listScheduleIncludedInAllNamespaces(context.Background(), c, "", from, to, boundaries{})
+	if err != nil {
+		t.Fatalf("listScheduleIncludedInAllNamespaces() error = %v", err)
+	}
+	want := []string{"CronJob/team-a/backup", "CronWorkflow/team-a/report"}
+	if diff := cmp.Diff(want, getItemNames(got)); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+	if len(skipped) != 0 || c.failures.failed() {
+		t.Errorf("want the fallback without failures, got skipped %v", skipped)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// The names in the directory of '--generate-patches'.
+const (
+	patchIndexName  = "index.json"
+	patchFileSuffix = ".patch.json"
+)
+
+// patchResultGenerated is the result of the plans written by
+// '--generate-patches'.
+const patchResultGenerated = "generated"
+
+// patchIndex maps the patch files to their targets.
+type patchIndex struct {
+	ApiVersion string           `json:"apiVersion"`
+	Patches    []patchIndexFile `json:"patches"`
+	// Skipped are the refused and skipped items, without a file.
+	Skipped []patchIndexSkip `json:"skipped"`
+}
+
+type patchIndexFile struct {
+	File string `json:"file"`
+	// Type is the patch type of 'kubectl patch --type'.
+	Type       string `json:"type"`
+	ApiVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace"`
+	Name       string `json:"name"`
+}
+
+type patchIndexSkip struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason"`
+}
+
+// Get the name of the patch file of the item, e.g.
+// 'ns-a__nightly__cronjob.patch.json'. The namespaces and the names can't
+// contain '_', so the names don't collide.
+func getPatchFileName(item item) string {
+	obj := item.object()
+	return strings.Join([]string{obj.GetNamespace(), obj.GetName(), strings.ToLower(item.kind())}, "__") + patchFileSuffix
+}
+
+// Write each plan as a JSON merge patch file into the directory, for
+// 'kubectl patch --type=merge --patch-file' or the GitOps tools, with the
+// index of the files, and return the results as applyPatchPlans does. The
+// directory is created when missing, and nothing is patched. c is nil with
+// '--fixtures'.
+func writePatchFiles(c *clients, dir string, plans []patchPlan) ([]patchResult, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create the patch directory: %w", err)
+	}
+	index := patchIndex{ApiVersion: "v1", Patches: []patchIndexFile{}, Skipped: []patchIndexSkip{}}
+	results := make([]patchResult, len(plans))
+	for i, plan := range plans {
+		obj := plan.item.object()
+		results[i] = patchResult{Kind: plan.item.kind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Before: plan.before, After: plan.after}
+		switch {
+		case plan.refusal != "":
+			results[i].Result = plan.refusal
+		case plan.patch == nil:
+			results[i].Result = patchResultSkipped
+		}
+		if results[i].Result != "" {
+			index.Skipped = append(index.Skipped, patchIndexSkip{Kind: plan.item.kind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Reason: results[i].Result})
+			continue
+		}
+
+		name := getPatchFileName(plan.item)
+		if err := writeJSONFile(filepath.Join(dir, name), plan.patch); err != nil {
+			return nil, err
+		}
+		gvk := getItemGroupVersionKind(c, plan.item)
+		index.Patches = append(index.Patches, patchIndexFile{
+			File:       name,
+			Type:       "merge",
+			ApiVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		})
+		results[i].Result = patchResultGenerated
+	}
+	if err := writeJSONFile(filepath.Join(dir, patchIndexName), index); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func writeJSONFile(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal '%s': %w", path, err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write the patch file: %w", err)
+	}
+	return nil
+}
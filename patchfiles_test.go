@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Read the files of the directory by the names.
+func readPatchFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[entry.Name()] = string(b)
+	}
+	return files
+}
+
+func Test_writePatchFiles(t *testing.T) {
+	t.Parallel()
+	suspendItems := mergeItems([]batchv1.CronJob{
+		getCronJob("ns-a", "active", "0 1 * * *", false),
+		getCronJob("ns-a", "paused", "0 2 * * *", true),
+	}, nil)
+	resumeItems := []item{suspendItems[1]}
+	shiftCronJobs, shiftCronWorkflows := getShiftFixtures()
+	tzCronJobs, tzCronWorkflows := getSetTimezoneFixtures()
+	suspendPlans, err := planSuspend(suspendItems, suspendMarker{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z"), RunID: "0123456789abcdef"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		plans []patchPlan
+		want  map[string]string
+	}{
+		{
+			name:  "suspend",
+			plans: suspendPlans,
+			want: map[string]string{
+				"ns-a__active__cronjob.patch.json": `{
+    "metadata": {
+        "annotations": {
+            "cls.unblee.io/suspended-at": "{\"from\":\"2023-01-24T00:00:00Z\",\"to\":\"2023-01-24T06:00:00Z\",\"runID\":\"0123456789abcdef\"}"
+        }
+    },
+    "spec": {
+        "suspend": true
+    }
+}
+`,
+				"index.json": `{
+    "apiVersion": "v1",
+    "patches": [
+        {
+            "file": "ns-a__active__cronjob.patch.json",
+            "type": "merge",
+            "apiVersion": "batch/v1",
+            "kind": "CronJob",
+            "namespace": "ns-a",
+            "name": "active"
+        }
+    ],
+    "skipped": [
+        {
+            "kind": "CronJob",
+            "namespace": "ns-a",
+            "name": "paused",
+            "reason": "skipped"
+        }
+    ]
+}
+`,
+			},
+		},
+		{
+			name:  "resume",
+			plans: planResume(resumeItems, true),
+			want: map[string]string{
+				"ns-a__paused__cronjob.patch.json": `{
+    "spec": {
+        "suspend": false
+    }
+}
+`,
+				"index.json": `{
+    "apiVersion": "v1",
+    "patches": [
+        {
+            "file": "ns-a__paused__cronjob.patch.json",
+            "type": "merge",
+            "apiVersion": "batch/v1",
+            "kind": "CronJob",
+            "namespace": "ns-a",
+            "name": "paused"
+        }
+    ],
+    "skipped": []
+}
+`,
+			},
+		},
+		{
+			name:  "set-timezone",
+			plans: planSetTimezone(mergeItems(tzCronJobs[1:], tzCronWorkflows[:1]), "Asia/Tokyo", true),
+			want: map[string]string{
+				"ns-a__missing__cronjob.patch.json": `{
+    "spec": {
+        "timeZone": "Asia/Tokyo"
+    }
+}
+`,
+				"ns-b__missing__cronworkflow.patch.json": `{
+    "spec": {
+        "timezone": "Asia/Tokyo"
+    }
+}
+`,
+				"index.json": `{
+    "apiVersion": "v1",
+    "patches": [
+        {
+            "file": "ns-a__missing__cronjob.patch.json",
+            "type": "merge",
+            "apiVersion": "batch/v1",
+            "kind": "CronJob",
+            "namespace": "ns-a",
+            "name": "missing"
+        },
+        {
+            "file": "ns-b__missing__cronworkflow.patch.json",
+            "type": "merge",
+            "apiVersion": "argoproj.io/v1alpha1",
+            "kind": "CronWorkflow",
+            "namespace": "ns-b",
+            "name": "missing"
+        }
+    ],
+    "skipped": [
+        {
+            "kind": "CronJob",
+            "namespace": "ns-a",
+            "name": "other",
+            "reason": "skipped"
+        }
+    ]
+}
+`,
+			},
+		},
+		{
+			name:  "shift",
+			plans: planShift(mergeItems(shiftCronJobs[:1], shiftCronWorkflows), 2*time.Hour),
+			want: map[string]string{
+				"ns-a__nightly__cronjob.patch.json": `{
+    "spec": {
+        "schedule": "0 1 * * 2-6"
+    }
+}
+`,
+				"ns-b__report__cronworkflow.patch.json": `{
+    "spec": {
+        "schedule": "30 11 * * *"
+    }
+}
+`,
+				"index.json": `{
+    "apiVersion": "v1",
+    "patches": [
+        {
+            "file": "ns-a__nightly__cronjob.patch.json",
+            "type": "merge",
+            "apiVersion": "batch/v1",
+            "kind": "CronJob",
+            "namespace": "ns-a",
+            "name": "nightly"
+        },
+        {
+            "file": "ns-b__report__cronworkflow.patch.json",
+            "type": "merge",
+            "apiVersion": "argoproj.io/v1alpha1",
+            "kind": "CronWorkflow",
+            "namespace": "ns-b",
+            "name": "report"
+        }
+    ],
+    "skipped": []
+}
+`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dir := filepath.Join(t.TempDir(), "patches")
+			if _, err := writePatchFiles(nil, dir, tt.plans); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, readPatchFiles(t, dir)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_generatePatches(t *testing.T) {
+	t.Parallel()
+	c := newRunClients()
+	dir := t.TempDir()
+	stdout, _, err := runFake(c, append([]string{"--suspend", "--generate-patches", dir, "-n", "ns-a"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "" +
+		"Namespace   Name     Kind      Before   After   Result\n" +
+		"ns-a        backup   CronJob   false    true    generated\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ns-a__backup__cronjob.patch.json")); err != nil {
+		t.Errorf("want the patch file, got %v", err)
+	}
+	cronjob, err := c.k8s.BatchV1().CronJobs("ns-a").Get(context.Background(), "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the CronJob: %v", err)
+	}
+	if cronjob.Spec.Suspend != nil && *cronjob.Spec.Suspend {
+		t.Error("want the CronJob not patched")
+	}
+}
+
+func Test_run_generatePatches_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--generate-patches", "patches"}, want: "'--generate-patches' requires '--shift', '--set-timezone', '--suspend', '--resume', or '--resume-all'"},
+		{args: []string{"--suspend", "--generate-patches", "patches", "--print-commands"}, want: "only one of '--print-commands' and '--generate-patches' can be used"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := runFake(newRunClients(), append(tt.args, runPeriod...)...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("want %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package cls
+
+import (
+	"context"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argov1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/typed/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CronJob is a batch/v1 CronJob.
+type CronJob struct {
+	*batchv1.CronJob
+}
+
+func (c CronJob) Schedules() []string { return []string{c.Spec.Schedule} }
+
+func (c CronJob) TimeZone() string {
+	if c.Spec.TimeZone != nil {
+		return *c.Spec.TimeZone
+	}
+	return ""
+}
+
+func (c CronJob) Meta() metav1.Object { return c.CronJob }
+
+func (c CronJob) Kind() string { return "CronJob" }
+
+// CronWorkflow is an Argo Workflows CronWorkflow.
+type CronWorkflow struct {
+	*wfv1alpha1.CronWorkflow
+}
+
+// Schedules returns the schedule. The CronWorkflows of this version of Argo
+// Workflows have a single schedule.
+func (c CronWorkflow) Schedules() []string { return []string{c.Spec.Schedule} }
+
+func (c CronWorkflow) TimeZone() string { return c.Spec.Timezone }
+
+func (c CronWorkflow) Meta() metav1.Object { return c.CronWorkflow }
+
+func (c CronWorkflow) Kind() string { return "CronWorkflow" }
+
+// CronJobSource lists the CronJobs with the client.
+type CronJobSource struct {
+	Client kubernetes.Interface
+}
+
+func (s CronJobSource) List(ctx context.Context, opts Options) ([]Scheduled, error) {
+	list, err := s.Client.BatchV1().CronJobs(opts.Namespace).List(ctx, metav1.ListOptions{LabelSelector: opts.Selector})
+	if err != nil {
+		return nil, err
+	}
+	return CronJobs(list.Items), nil
+}
+
+// CronWorkflowSource lists the CronWorkflows with the client.
+type CronWorkflowSource struct {
+	Client argov1alpha1.ArgoprojV1alpha1Interface
+}
+
+func (s CronWorkflowSource) List(ctx context.Context, opts Options) ([]Scheduled, error) {
+	list, err := s.Client.CronWorkflows(opts.Namespace).List(ctx, metav1.ListOptions{LabelSelector: opts.Selector})
+	if err != nil {
+		return nil, err
+	}
+	return CronWorkflows(list.Items), nil
+}
+
+// CronJobs wraps the CronJobs, pointing the elements of the slice.
+func CronJobs(cronjobs []batchv1.CronJob) []Scheduled {
+	ret := make([]Scheduled, len(cronjobs))
+	for i := range cronjobs {
+		ret[i] = CronJob{&cronjobs[i]}
+	}
+	return ret
+}
+
+// CronWorkflows wraps the CronWorkflows, pointing the elements of the slice.
+func CronWorkflows(cronworkflows []wfv1alpha1.CronWorkflow) []Scheduled {
+	ret := make([]Scheduled, len(cronworkflows))
+	for i := range cronworkflows {
+		ret[i] = CronWorkflow{&cronworkflows[i]}
+	}
+	return ret
+}
@@ -0,0 +1,186 @@
+// Package cls finds the scheduled objects, such as CronJobs and CronWorkflows,
+// to be executed during a period.
+//
+// The kinds are implementations of Scheduled listed by a Source, so that the
+// programs embedding the package can add their own kinds.
+package cls
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Scheduled is an object executed on cron schedules.
+type Scheduled interface {
+	// Schedules returns the cron schedules in the standard format, which may
+	// have a CRON_TZ= or TZ= prefix.
+	Schedules() []string
+	// TimeZone returns the time zone the controller evaluates the schedules
+	// in, or "" when unset. It is informational, Match evaluates the
+	// schedules as they are.
+	TimeZone() string
+	Meta() metav1.Object
+	Kind() string
+}
+
+// Source lists the objects of a kind.
+type Source interface {
+	// List returns the objects in opts.Namespace matching opts.Selector. The
+	// period is evaluated by Match.
+	List(ctx context.Context, opts Options) ([]Scheduled, error)
+}
+
+// Options are the options of Match.
+type Options struct {
+	// Namespace is the namespace to list the objects in. An empty namespace
+	// means all namespaces.
+	Namespace string
+	// Selector is the label selector of the objects.
+	Selector string
+	Window
+	// Trace, when set, receives the evaluation of each schedule by Match.
+	Trace func(Evaluation)
+}
+
+// Window is the from-to period. Both ends are included by default.
+type Window struct {
+	From          time.Time
+	To            time.Time
+	ExclusiveFrom bool
+	ExclusiveTo   bool
+}
+
+// Start returns the time to pass to Next to get the first schedule in the
+// period.
+func (w Window) Start() time.Time {
+	if w.ExclusiveFrom {
+		// Next returns the time strictly after it.
+		return w.From
+	}
+	// To include the 'from' time in the from-to period.
+	return w.From.Add(-1 * time.Second)
+}
+
+// BeforeEnd returns whether the time of a schedule is not after the end of the
+// period.
+func (w Window) BeforeEnd(t time.Time) bool {
+	if w.ExclusiveTo {
+		return t.Before(w.To)
+	}
+	// To include the 'to' time in the from-to period.
+	return !t.After(w.To)
+}
+
+// Includes returns whether the schedule fires in the period.
+func (w Window) Includes(sched cron.Schedule) bool {
+	_, _, ok := w.evaluate(sched)
+	return ok
+}
+
+func (w Window) evaluate(sched cron.Schedule) (reference, next time.Time, ok bool) {
+	reference = w.Start()
+	next = sched.Next(reference)
+	return reference, next, w.BeforeEnd(next)
+}
+
+// Evaluation is the evaluation of a schedule of an object in the period.
+type Evaluation struct {
+	Object Scheduled
+	// Schedule is the schedule as returned by Schedules.
+	Schedule string
+	// Expression is the schedule without the time zone prefix, with the
+	// fields separated by a single space.
+	Expression string
+	// Location is the location the schedule is evaluated in.
+	Location *time.Location
+	// Reference is the time passed to Next.
+	Reference time.Time
+	// Next is the time returned by Next, which is zero when the schedule
+	// never fires.
+	Next     time.Time
+	Window   Window
+	Included bool
+}
+
+// Match lists the objects of the sources and returns the ones any of whose
+// schedules fires in the period, sorted by namespace, then name, then kind.
+func Match(ctx context.Context, sources []Source, opts Options) ([]Scheduled, error) {
+	matched := []Scheduled{}
+	for _, source := range sources {
+		objects, err := source.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			ok, err := includes(obj, opts.Window, opts.Trace)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				matched = append(matched, obj)
+			}
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := matched[i].Meta(), matched[j].Meta()
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		if a.GetName() != b.GetName() {
+			return a.GetName() < b.GetName()
+		}
+		return matched[i].Kind() < matched[j].Kind()
+	})
+	return matched, nil
+}
+
+func includes(obj Scheduled, w Window, trace func(Evaluation)) (bool, error) {
+	for _, schedule := range obj.Schedules() {
+		sched, err := cron.ParseStandard(schedule)
+		if err != nil {
+			meta := obj.Meta()
+			return false, fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", schedule, obj.Kind(), meta.GetNamespace(), meta.GetName(), err)
+		}
+		reference, next, ok := w.evaluate(sched)
+		if trace != nil {
+			trace(Evaluation{
+				Object:     obj,
+				Schedule:   schedule,
+				Expression: expression(schedule),
+				Location:   location(sched, reference),
+				Reference:  reference,
+				Next:       next,
+				Window:     w,
+				Included:   ok,
+			})
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Get the schedule without the 'CRON_TZ=' or 'TZ=' prefix.
+func expression(schedule string) string {
+	fields := strings.Fields(schedule)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		fields = fields[1:]
+	}
+	return strings.Join(fields, " ")
+}
+
+// Get the location Next evaluates the schedule in. A schedule without a time
+// zone prefix is evaluated in the location of the reference time.
+func location(sched cron.Schedule, reference time.Time) *time.Location {
+	if spec, ok := sched.(*cron.SpecSchedule); ok && spec.Location != time.Local {
+		return spec.Location
+	}
+	return reference.Location()
+}
@@ -0,0 +1,224 @@
+package cls
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// toyJob is a third-party kind with any number of schedules.
+type toyJob struct {
+	metav1.ObjectMeta
+	schedules []string
+}
+
+func (j *toyJob) Schedules() []string { return j.schedules }
+func (j *toyJob) TimeZone() string    { return "" }
+func (j *toyJob) Meta() metav1.Object { return j }
+func (j *toyJob) Kind() string        { return "ToyJob" }
+
+// toySource lists the toyJobs, and records the options.
+type toySource struct {
+	jobs []*toyJob
+	opts []Options
+	err  error
+}
+
+func (s *toySource) List(ctx context.Context, opts Options) ([]Scheduled, error) {
+	s.opts = append(s.opts, opts)
+	if s.err != nil {
+		return nil, s.err
+	}
+	selector, err := labels.Parse(opts.Selector)
+	if err != nil {
+		return nil, err
+	}
+	ret := []Scheduled{}
+	for _, job := range s.jobs {
+		if opts.Namespace != "" && job.Namespace != opts.Namespace {
+			continue
+		}
+		if !selector.Matches(labels.Set(job.Labels)) {
+			continue
+		}
+		ret = append(ret, job)
+	}
+	return ret, nil
+}
+
+func getTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func getToyJob(namespace, name string, labels map[string]string, schedules ...string) *toyJob {
+	return &toyJob{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels}, schedules: schedules}
+}
+
+func getNames(scheduled []Scheduled) []string {
+	names := make([]string, len(scheduled))
+	for i, s := range scheduled {
+		names[i] = s.Kind() + " " + s.Meta().GetNamespace() + "/" + s.Meta().GetName()
+	}
+	return names
+}
+
+func Test_Match(t *testing.T) {
+	t.Parallel()
+	window := Window{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{
+			name: "any of the schedules",
+			opts: Options{Window: window},
+			want: []string{"CronJob ns-a/b", "ToyJob ns-a/b", "ToyJob ns-b/a"},
+		},
+		{
+			name: "namespace and selector",
+			opts: Options{Namespace: "ns-b", Selector: "app=a", Window: window},
+			want: []string{"ToyJob ns-b/a"},
+		},
+		{
+			name: "exclusive to",
+			opts: Options{Window: Window{From: window.From, To: getTime("2023-01-24T01:00:00Z"), ExclusiveTo: true}},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			toys := &toySource{jobs: []*toyJob{
+				getToyJob("ns-b", "a", map[string]string{"app": "a"}, "0 12 * * *", "0 3 * * *"),
+				getToyJob("ns-a", "b", nil, "0 1 * * *"),
+				getToyJob("ns-a", "c", nil, "0 12 * * *", "0 18 * * *"),
+			}}
+			cronjob := batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "b"}, Spec: batchv1.CronJobSpec{Schedule: "0 2 * * *"}}
+			cronjobs := CronJobSource{Client: k8sfake.NewSimpleClientset(&cronjob)}
+
+			got, err := Match(context.Background(), []Source{toys, cronjobs}, tt.opts)
+			if err != nil {
+				t.Fatalf("Match() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, getNames(got)); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff([]Options{tt.opts}, toys.opts); diff != "" {
+				t.Errorf("the options are not passed to the source (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_Match_errors(t *testing.T) {
+	t.Parallel()
+	window := Window{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+
+	listErr := errors.New("forbidden")
+	if _, err := Match(context.Background(), []Source{&toySource{err: listErr}}, Options{Window: window}); !errors.Is(err, listErr) {
+		t.Errorf("Match() error = %v, want %v", err, listErr)
+	}
+
+	invalid := &toySource{jobs: []*toyJob{getToyJob("ns-a", "a", nil, "0 1 * * *", "invalid")}}
+	_, err := Match(context.Background(), []Source{invalid}, Options{Window: Window{From: window.From, To: window.From}})
+	want := "failed to parse schedule spec 'invalid' of ToyJob 'ns-a/a': expected exactly 5 fields, found 1: [invalid]"
+	if err == nil || err.Error() != want {
+		t.Errorf("Match() error = %v, want %s", err, want)
+	}
+}
+
+func Test_Match_trace(t *testing.T) {
+	t.Parallel()
+	window := Window{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+	toys := &toySource{jobs: []*toyJob{
+		getToyJob("ns-a", "a", nil, "0 12 * * *", "CRON_TZ=Asia/Tokyo  0 9 * * *", "0 18 * * *"),
+	}}
+
+	var got []Evaluation
+	_, err := Match(context.Background(), []Source{toys}, Options{Window: window, Trace: func(e Evaluation) {
+		e.Object = nil
+		got = append(got, e)
+	}})
+	if err != nil {
+		t.Fatalf("Match() error = %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last schedule is not evaluated, as the object is already included.
+	want := []Evaluation{
+		{
+			Schedule:   "0 12 * * *",
+			Expression: "0 12 * * *",
+			Location:   time.UTC,
+			Reference:  getTime("2023-01-23T23:59:59Z"),
+			Next:       getTime("2023-01-24T12:00:00Z"),
+			Window:     window,
+		},
+		{
+			Schedule:   "CRON_TZ=Asia/Tokyo  0 9 * * *",
+			Expression: "0 9 * * *",
+			Location:   tokyo,
+			Reference:  getTime("2023-01-23T23:59:59Z"),
+			Next:       getTime("2023-01-24T00:00:00Z"),
+			Window:     window,
+			Included:   true,
+		},
+	}
+	opts := cmp.Comparer(func(a, b *time.Location) bool { return a.String() == b.String() })
+	if diff := cmp.Diff(want, got, opts); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_builtinSources(t *testing.T) {
+	t.Parallel()
+	tokyo := "Asia/Tokyo"
+	cronjob := batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "cj"},
+		Spec:       batchv1.CronJobSpec{Schedule: "0 1 * * *", TimeZone: &tokyo},
+	}
+	cronworkflow := wfv1alpha1.CronWorkflow{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns-a", Name: "cwf"},
+		Spec:       wfv1alpha1.CronWorkflowSpec{Schedule: "0 2 * * *", Timezone: "UTC"},
+	}
+	sources := []Source{
+		CronJobSource{Client: k8sfake.NewSimpleClientset(&cronjob)},
+		CronWorkflowSource{Client: argofake.NewSimpleClientset(&cronworkflow).ArgoprojV1alpha1()},
+	}
+	got, err := Match(context.Background(), sources, Options{Window: Window{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type summary struct {
+		Kind, Name, TimeZone string
+		Schedules            []string
+	}
+	summaries := make([]summary, len(got))
+	for i, s := range got {
+		summaries[i] = summary{Kind: s.Kind(), Name: s.Meta().GetName(), TimeZone: s.TimeZone(), Schedules: s.Schedules()}
+	}
+	want := []summary{
+		{Kind: "CronJob", Name: "cj", TimeZone: "Asia/Tokyo", Schedules: []string{"0 1 * * *"}},
+		{Kind: "CronWorkflow", Name: "cwf", TimeZone: "UTC", Schedules: []string{"0 2 * * *"}},
+	}
+	if diff := cmp.Diff(want, summaries); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
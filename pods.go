@@ -0,0 +1,82 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// podEstimate is the number of the pods estimated to be started by an item in
+// the from-to period, see getPodsPerRun.
+type podEstimate struct {
+	pods int
+	// truncated is set when the fires are capped at maxFireOccurrences, so
+	// pods is a lower bound.
+	truncated bool
+}
+
+// Estimate the pods started by the item in the from-to period as the fires
+// times the pods of a run. The suspended items start none.
+func estimatePods(item item, from, to time.Time, bounds boundaries) (podEstimate, error) {
+	if item.suspended() {
+		return podEstimate{}, nil
+	}
+	fires, truncated, err := getItemFires(item, from, to, bounds)
+	if err != nil {
+		return podEstimate{}, err
+	}
+	return podEstimate{pods: len(fires) * getPodsPerRun(item), truncated: truncated}, nil
+}
+
+// Format the estimate in the table, ">N" when truncated.
+func (e podEstimate) format() string {
+	if e.truncated {
+		return ">" + strconv.Itoa(e.pods)
+	}
+	return strconv.Itoa(e.pods)
+}
+
+// Get the number of the pods a run of the item starts. The retries are not
+// counted, so it is the number when all the pods succeed. The items of the
+// providers are a pod a run.
+func getPodsPerRun(item item) int {
+	switch {
+	case item.cronJob != nil:
+		return getJobPods(item.cronJob.Spec.JobTemplate.Spec)
+	case item.cronWorkflow != nil:
+		return getWorkflowPods(item.cronWorkflow.Spec.WorkflowSpec)
+	}
+	return 1
+}
+
+// Get the pods of a Job. Every completion is a pod, in both the NonIndexed
+// and the Indexed completionMode. Without completions, the Job is a work queue
+// of parallelism pods. Both default to 1, and parallelism 0 pauses the Job.
+func getJobPods(spec batchv1.JobSpec) int {
+	parallelism := 1
+	if spec.Parallelism != nil {
+		parallelism = int(*spec.Parallelism)
+	}
+	if parallelism <= 0 {
+		return 0
+	}
+	if spec.Completions == nil {
+		return parallelism
+	}
+	if *spec.Completions < 0 {
+		return 0
+	}
+	return int(*spec.Completions)
+}
+
+// Get the pods of a Workflow, which depend on its steps and DAG. The
+// parallelism of the Workflow is the number of the pods running at once, so
+// it is taken as the hint, and 1 without it.
+func getWorkflowPods(spec wfv1alpha1.WorkflowSpec) int {
+	if spec.Parallelism != nil && *spec.Parallelism > 1 {
+		return int(*spec.Parallelism)
+	}
+	return 1
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_getJobPods(t *testing.T) {
+	t.Parallel()
+	int32Ptr := func(v int32) *int32 { return &v }
+	indexed := batchv1.IndexedCompletion
+	tests := []struct {
+		name string
+		spec batchv1.JobSpec
+		want int
+	}{
+		{name: "defaults", spec: batchv1.JobSpec{}, want: 1},
+		{name: "work queue", spec: batchv1.JobSpec{Parallelism: int32Ptr(50)}, want: 50},
+		{name: "completions", spec: batchv1.JobSpec{Completions: int32Ptr(5)}, want: 5},
+		{name: "completions over parallelism", spec: batchv1.JobSpec{Parallelism: int32Ptr(2), Completions: int32Ptr(10)}, want: 10},
+		{name: "parallelism over completions", spec: batchv1.JobSpec{Parallelism: int32Ptr(10), Completions: int32Ptr(3)}, want: 3},
+		{name: "indexed", spec: batchv1.JobSpec{Parallelism: int32Ptr(4), Completions: int32Ptr(8), CompletionMode: &indexed}, want: 8},
+		{name: "paused", spec: batchv1.JobSpec{Parallelism: int32Ptr(0), Completions: int32Ptr(8)}, want: 0},
+		{name: "zero completions", spec: batchv1.JobSpec{Completions: int32Ptr(0)}, want: 0},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := getJobPods(tt.spec); got != tt.want {
+				t.Errorf("getJobPods() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getWorkflowPods(t *testing.T) {
+	t.Parallel()
+	int64Ptr := func(v int64) *int64 { return &v }
+	for _, tt := range []struct {
+		parallelism *int64
+		want        int
+	}{
+		{parallelism: nil, want: 1},
+		{parallelism: int64Ptr(0), want: 1},
+		{parallelism: int64Ptr(8), want: 8},
+	} {
+		if got := getWorkflowPods(wfv1alpha1.WorkflowSpec{Parallelism: tt.parallelism}); got != tt.want {
+			t.Errorf("getWorkflowPods(%v) = %d, want %d", tt.parallelism, got, tt.want)
+		}
+	}
+}
+
+func Test_estimatePods(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T01:00:00Z")
+	parallelism := int32(50)
+	wide := getCronJob("ns-a", "wide", "*/10 * * * *", false)
+	wide.Spec.JobTemplate.Spec.Parallelism = &parallelism
+	suspended := getCronJob("ns-a", "suspended", "*/10 * * * *", true)
+	suspended.Spec.JobTemplate.Spec.Parallelism = &parallelism
+	every := getCronJob("ns-a", "every", "* * * * *", false)
+	cronworkflow := getCronWorkflow("ns-b", "cw", "0 * * * *", false)
+
+	tests := []struct {
+		name string
+		item item
+		to   string
+		want podEstimate
+	}{
+		// 7 fires from 00:00 to 01:00, both inclusive.
+		{name: "parallelism", item: item{cronJob: &wide}, want: podEstimate{pods: 350}},
+		{name: "suspended", item: item{cronJob: &suspended}, want: podEstimate{}},
+		{name: "cronworkflow", item: item{cronWorkflow: &cronworkflow}, want: podEstimate{pods: 2}},
+		{name: "truncated", item: item{cronJob: &every}, to: "2023-01-31T00:00:00Z", want: podEstimate{pods: maxFireOccurrences, truncated: true}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			end := to
+			if tt.to != "" {
+				end = getTime(tt.to)
+			}
+			got, err := estimatePods(tt.item, from, end, boundaries{})
+			if err != nil {
+				t.Fatalf("estimatePods() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(podEstimate{})); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+	if got := (podEstimate{pods: maxFireOccurrences, truncated: true}).format(); got != ">10000" {
+		t.Errorf("format() = %q", got)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+// policyIntervalHorizon is how far from '--from' the fires are enumerated for
+// the 'minInterval' rules, so that weekly schedules are covered.
+const policyIntervalHorizon = 7 * 24 * time.Hour
+
+// policyFile is the schema of the '--policy' file, e.g.
+//
+//	rules:
+//	  - name: no-every-minute
+//	    namespaces: ["prod-*"]
+//	    denySchedule: '^\*\s+\*\s+\*\s+\*\s+\*$'
+//	  - name: min-5m
+//	    namespaces: ["prod-*"]
+//	    minInterval: 5m
+//	  - name: explicit-tz
+//	    requireTimeZone: true
+type policyFile struct {
+	Rules []policyRuleSpec `json:"rules"`
+}
+
+type policyRuleSpec struct {
+	Name string `json:"name"`
+	// Namespaces are the glob patterns of the namespaces in scope, all
+	// namespaces when empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Exactly one of the following.
+	DenySchedule    string `json:"denySchedule,omitempty"`
+	MinInterval     string `json:"minInterval,omitempty"`
+	RequireTimeZone bool   `json:"requireTimeZone,omitempty"`
+}
+
+type policyRule struct {
+	name         string
+	namespaces   []string
+	denySchedule *regexp.Regexp
+	minInterval  time.Duration
+	requireTZ    bool
+}
+
+type violation struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+}
+
+type violationReport struct {
+	ApiVersion string      `json:"apiVersion"`
+	Violations []violation `json:"violations"`
+}
+
+// Load and validate the '--policy' file.
+func loadPolicy(file string) ([]policyRule, error) {
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read '--policy' file: %w", err)
+	}
+	return parsePolicy(b)
+}
+
+func parsePolicy(b []byte) ([]policyRule, error) {
+	var spec policyFile
+	if err := yaml.UnmarshalStrict(b, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse '--policy' file: %w", err)
+	}
+	if len(spec.Rules) == 0 {
+		return nil, errors.New("'--policy' file has no rules")
+	}
+
+	rules := make([]policyRule, 0, len(spec.Rules))
+	names := map[string]bool{}
+	for i, r := range spec.Rules {
+		where := fmt.Sprintf("rules[%d]", i)
+		if r.Name == "" {
+			return nil, fmt.Errorf("%s: 'name' is required", where)
+		}
+		where = fmt.Sprintf("rules[%d] '%s'", i, r.Name)
+		if names[r.Name] {
+			return nil, fmt.Errorf("%s: 'name' is duplicated", where)
+		}
+		names[r.Name] = true
+		for _, pattern := range r.Namespaces {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: invalid 'namespaces' pattern '%s': %w", where, pattern, err)
+			}
+		}
+
+		rule := policyRule{name: r.Name, namespaces: r.Namespaces, requireTZ: r.RequireTimeZone}
+		var err error
+		kinds := 0
+		if r.DenySchedule != "" {
+			kinds++
+			rule.denySchedule, err = regexp.Compile(r.DenySchedule)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid 'denySchedule': %w", where, err)
+			}
+		}
+		if r.MinInterval != "" {
+			kinds++
+			rule.minInterval, err = parseDuration(r.MinInterval)
+			if err != nil {
+				return nil, fmt.Errorf("%s: invalid 'minInterval': %w", where, err)
+			}
+			if rule.minInterval <= 0 {
+				return nil, fmt.Errorf("%s: 'minInterval' must be greater than zero", where)
+			}
+		}
+		if r.RequireTimeZone {
+			kinds++
+		}
+		if kinds != 1 {
+			return nil, fmt.Errorf("%s: exactly one of 'denySchedule', 'minInterval', or 'requireTimeZone' is required", where)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (r policyRule) inScope(namespace string) bool {
+	if len(r.namespaces) == 0 {
+		return true
+	}
+	for _, pattern := range r.namespaces {
+		if ok, _ := path.Match(pattern, namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Check the item against the rule from start, and return the message of the
+// violation, or "" when it complies.
+func (r policyRule) check(item item, start time.Time) (string, error) {
+	switch {
+	case r.denySchedule != nil:
+		schedule := strings.Join(strings.Fields(item.schedule()), " ")
+		if r.denySchedule.MatchString(schedule) {
+			return fmt.Sprintf("schedule '%s' is denied", schedule), nil
+		}
+	case r.minInterval > 0:
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return "", err
+		}
+		end := start.Add(policyIntervalHorizon)
+		prev := sched.Next(start)
+		for t := sched.Next(prev); !t.IsZero() && t.Before(end); prev, t = t, sched.Next(t) {
+			if gap := t.Sub(prev); gap < r.minInterval {
+				return fmt.Sprintf("fires %s apart at %s, more often than every %s", gap, t.UTC().Format(time.RFC3339), r.minInterval), nil
+			}
+		}
+	case r.requireTZ:
+		if item.timezone() == "" {
+			return "timeZone is unset", nil
+		}
+	}
+	return "", nil
+}
+
+// Check the items against the rules in scope, in the order of the items and
+// then of the rules.
+func findViolations(items []item, rules []policyRule, start time.Time) ([]violation, error) {
+	violations := []violation{}
+	for _, item := range items {
+		obj := item.object()
+		for _, rule := range rules {
+			if !rule.inScope(obj.GetNamespace()) {
+				continue
+			}
+			msg, err := rule.check(item, start)
+			if err != nil {
+				return nil, err
+			}
+			if msg != "" {
+				violations = append(violations, violation{Kind: item.kind(), Namespace: obj.GetNamespace(), Name: obj.GetName(), Rule: rule.name, Message: msg})
+			}
+		}
+	}
+	return violations, nil
+}
+
+func printViolations(stdout io.Writer, output string, noHeaders bool, violations []violation) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(violationReport{ApiVersion: "v1", Violations: violations}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(violationReport{ApiVersion: "v1", Violations: violations})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Kind", "Rule", "Message"}, "\t"))
+	}
+	for _, v := range violations {
+		fmt.Fprintln(tw, strings.Join([]string{v.Namespace, v.Name, v.Kind, v.Rule, v.Message}, "\t"))
+	}
+	return tw.Flush()
+}
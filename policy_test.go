@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_parsePolicy_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		policy  string
+		wantErr string
+	}{
+		{name: "no rules", policy: `rules: []`, wantErr: "'--policy' file has no rules"},
+		{name: "unknown field", policy: "rules:\n- name: a\n  denySchedul: x", wantErr: `unknown field "denySchedul"`},
+		{name: "missing name", policy: "rules:\n- requireTimeZone: true", wantErr: "rules[0]: 'name' is required"},
+		{name: "duplicated name", policy: "rules:\n- name: a\n  requireTimeZone: true\n- name: a\n  requireTimeZone: true", wantErr: "rules[1] 'a': 'name' is duplicated"},
+		{name: "invalid regex", policy: "rules:\n- name: a\n  denySchedule: '('", wantErr: "rules[0] 'a': invalid 'denySchedule'"},
+		{name: "invalid interval", policy: "rules:\n- name: a\n  minInterval: 5", wantErr: "rules[0] 'a': invalid 'minInterval'"},
+		{name: "zero interval", policy: "rules:\n- name: a\n  minInterval: 0s", wantErr: "rules[0] 'a': 'minInterval' must be greater than zero"},
+		{name: "no rule type", policy: "rules:\n- name: a", wantErr: "rules[0] 'a': exactly one of"},
+		{name: "two rule types", policy: "rules:\n- name: a\n  minInterval: 5m\n  requireTimeZone: true", wantErr: "rules[0] 'a': exactly one of"},
+		{name: "invalid namespace pattern", policy: "rules:\n- name: a\n  namespaces: ['prod-[']\n  requireTimeZone: true", wantErr: "rules[0] 'a': invalid 'namespaces' pattern 'prod-['"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parsePolicy([]byte(tt.policy))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("want error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func Test_findViolations(t *testing.T) {
+	t.Parallel()
+	rules, err := parsePolicy([]byte(`
+rules:
+  - name: no-every-minute
+    namespaces: ["prod-*"]
+    denySchedule: '^\*\s+\*\s+\*\s+\*\s+\*$'
+  - name: min-5m
+    namespaces: ["prod-*"]
+    minInterval: 5m
+  - name: explicit-tz
+    namespaces: ["prod-a"]
+    requireTimeZone: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokyo := "Asia/Tokyo"
+	everyMinute := getCronJob("prod-a", "every-minute", "*  * * * *", false)
+	everyMinute.Spec.TimeZone = &tokyo
+	every5m := getCronJob("prod-a", "every-5m", "*/5 * * * *", false)
+	every5m.Spec.TimeZone = &tokyo
+	// Twice at the top of the hour, only caught by the interval.
+	burst := getCronJob("prod-b", "burst", "0,2 9 * * *", false)
+	// Out of scope of all the rules.
+	dev := getCronJob("dev", "every-minute", "* * * * *", false)
+	cw := getCronWorkflow("prod-a", "hourly", "0 * * * *", false)
+
+	items := mergeItems([]batchv1.CronJob{everyMinute, every5m, burst, dev}, []wfv1alpha1.CronWorkflow{cw})
+	got, err := findViolations(items, rules, getTime("2023-01-24T00:00:00Z"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []violation{
+		{Kind: "CronJob", Namespace: "prod-a", Name: "every-minute", Rule: "no-every-minute", Message: "schedule '* * * * *' is denied"},
+		{Kind: "CronJob", Namespace: "prod-a", Name: "every-minute", Rule: "min-5m", Message: "fires 1m0s apart at 2023-01-24T00:02:00Z, more often than every 5m0s"},
+		{Kind: "CronWorkflow", Namespace: "prod-a", Name: "hourly", Rule: "explicit-tz", Message: "timeZone is unset"},
+		{Kind: "CronJob", Namespace: "prod-b", Name: "burst", Rule: "min-5m", Message: "fires 2m0s apart at 2023-01-24T09:02:00Z, more often than every 5m0s"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
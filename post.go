@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"golang.org/x/exp/slog"
+)
+
+// reportFlags are the flags printing a report or the results of the patches
+// instead of the matched items, which are the results delivered by
+// '--write-configmap' and '--post-url', so they can't be used together.
+var reportFlags = []string{
+	"compare",
+	"suspend",
+	"resume",
+	"resume-all",
+	"shift",
+	"set-timezone",
+	"tz-report",
+	"stats",
+	"rollup-label",
+	"duplicates",
+	"policy",
+	"deadline-risk",
+	"runtime-risk",
+	"assert-fires-every",
+	"heatmap",
+	"demand",
+	"concurrency-conflicts",
+}
+
+// Validate the flags used with the deliveries of the results.
+func validateDeliveryFlags(fsets *pflag.FlagSet) error {
+	for _, delivery := range []string{"write-configmap", "post-url"} {
+		if !fsets.Changed(delivery) {
+			continue
+		}
+		for _, name := range reportFlags {
+			if fsets.Changed(name) {
+				return fmt.Errorf("'--%s' can't be used with '--%s'", delivery, name)
+			}
+		}
+	}
+	return nil
+}
+
+type postOptions struct {
+	url                   string
+	headers               http.Header
+	caFile                string
+	insecureSkipTLSVerify bool
+	retries               int
+	retryWait             time.Duration
+}
+
+// Parse '--post-header' values in the 'Name: value' form.
+func parsePostHeaders(values []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, value := range values {
+		name, v, ok := strings.Cut(value, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("failed to parse '--post-header' value '%s': must be in the 'Name: value' form", value)
+		}
+		headers.Add(name, strings.TrimSpace(v))
+	}
+	return headers, nil
+}
+
+// Build an HTTP client which verifies the server certificate like the default
+// Go client. '--post-ca-file' adds a CA to the system roots.
+func newPostClient(opts postOptions) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: opts.insecureSkipTLSVerify,
+	}
+	if opts.caFile != "" {
+		pem, err := os.ReadFile(opts.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read '--post-ca-file': %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to load certificates from '--post-ca-file' '%s'", opts.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport, Timeout: 30 * time.Second}, nil
+}
+
+// POST the JSON output document to the URL. 5xx responses and transport
+// errors are retried; any other non-2xx response fails immediately.
+func postResults(ctx context.Context, logger *slog.Logger, opts postOptions, body []byte) error {
+	client, err := newPostClient(opts)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= opts.retries; attempt++ {
+		if attempt > 0 {
+			logger.Info(fmt.Sprintf("retrying POST to '%s' (%d/%d): %s", opts.url, attempt, opts.retries, lastErr),
+				"url", opts.url, "attempt", attempt, "retries", opts.retries, "error", lastErr.Error())
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.retryWait):
+			}
+		}
+
+		retryable, err := postOnce(ctx, client, opts, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+
+	return fmt.Errorf("failed to POST results to '%s': %w", opts.url, lastErr)
+}
+
+func postOnce(ctx context.Context, client *http.Client, opts postOptions, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, opts.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for name, values := range opts.headers {
+		req.Header[name] = values
+	}
+	if req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	return res.StatusCode >= 500, fmt.Errorf("unexpected status %s", res.Status)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parsePostHeaders(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		values  []string
+		want    http.Header
+		wantErr bool
+	}{
+		{
+			name:   "basic test",
+			values: []string{"Authorization: Bearer token", "x-foo:bar", "X-Foo: baz"},
+			want: http.Header{
+				"Authorization": {"Bearer token"},
+				"X-Foo":         {"bar", "baz"},
+			},
+		},
+		{
+			name:    "missing colon",
+			values:  []string{"Authorization Bearer token"},
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			values:  []string{": value"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parsePostHeaders(tt.values)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parsePostHeaders() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("parsePostHeaders() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_postResults(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		statuses     []int // responded in order, the last one repeats
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{
+			name:         "success",
+			statuses:     []int{http.StatusOK},
+			wantAttempts: 1,
+		},
+		{
+			name:         "retry then success",
+			statuses:     []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusNoContent},
+			wantAttempts: 3,
+		},
+		{
+			name:         "hard failure after retries",
+			statuses:     []int{http.StatusInternalServerError},
+			wantErr:      true,
+			wantAttempts: 3,
+		},
+		{
+			name:         "client error is not retried",
+			statuses:     []int{http.StatusUnauthorized},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var attempts int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				if got := r.Header.Get("Authorization"); got != "Bearer token" {
+					t.Errorf("Authorization = %q, want %q", got, "Bearer token")
+				}
+				if got := r.Header.Get("Content-Type"); got != "application/json" {
+					t.Errorf("Content-Type = %q, want %q", got, "application/json")
+				}
+				if b, _ := io.ReadAll(r.Body); string(b) != `{"apiVersion":"v1"}` {
+					t.Errorf("body = %q", b)
+				}
+				i := int(n) - 1
+				if i >= len(tt.statuses) {
+					i = len(tt.statuses) - 1
+				}
+				w.WriteHeader(tt.statuses[i])
+			}))
+			defer srv.Close()
+
+			opts := postOptions{
+				url:     srv.URL,
+				headers: http.Header{"Authorization": {"Bearer token"}},
+				retries: 2,
+			}
+			var stderr strings.Builder
+			err := postResults(context.Background(), newLogger(&stderr, logFormatText, false), opts, []byte(`{"apiVersion":"v1"}`))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("postResults() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func Test_postResults_tls(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // suppress the expected handshake error
+	srv.StartTLS()
+	defer srv.Close()
+
+	// The self-signed certificate is rejected like the default Go client does.
+	opts := postOptions{url: srv.URL}
+	if err := postResults(context.Background(), newLogger(io.Discard, logFormatText, false), opts, nil); err == nil {
+		t.Error("postResults() error = nil, want certificate error")
+	}
+
+	opts.insecureSkipTLSVerify = true
+	if err := postResults(context.Background(), newLogger(io.Discard, logFormatText, false), opts, nil); err != nil {
+		t.Errorf("postResults() error = %v, want nil", err)
+	}
+}
+
+func Test_run_delivery_reportFlags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--post-url", "http://localhost", "--stats"}, want: "'--post-url' can't be used with '--stats'"},
+		{args: []string{"--post-url", "http://localhost", "--heatmap"}, want: "'--post-url' can't be used with '--heatmap'"},
+		{args: []string{"--write-configmap", "ns-a/results", "--tz-report"}, want: "'--write-configmap' can't be used with '--tz-report'"},
+		{args: []string{"--write-configmap", "ns-a/results", "--suspend"}, want: "'--write-configmap' can't be used with '--suspend'"},
+	}
+	for _, tt := range tests {
+		_, _, err := runFake(newRunClients(), append(tt.args, runPeriod...)...)
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("%v: want %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+)
+
+// The statuses of the last expected fire of an item in a past window, told
+// from status.lastScheduleTime.
+const (
+	prevRan       = "ran"
+	prevMissed    = "missed"
+	prevSuspended = "suspended"
+	// The item was scheduled after the window, or the fires were truncated, so
+	// whether it ran at the last expected fire is unknown.
+	prevUnknown = "unknown"
+)
+
+// expected is the fires of an item expected in a past window.
+type expected struct {
+	Kind      string      `json:"kind"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Schedule  string      `json:"schedule"`
+	Suspend   bool        `json:"suspend"`
+	Fires     []time.Time `json:"fires"`
+	// FiresTruncated is set when only the first maxFireOccurrences fires are
+	// in Fires.
+	FiresTruncated   bool         `json:"firesTruncated,omitempty"`
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	Status           string       `json:"status"`
+}
+
+type expectedReport struct {
+	ApiVersion string         `json:"apiVersion"`
+	Window     documentWindow `json:"window"`
+	Expected   []expected     `json:"expected"`
+}
+
+// runPrev prints the fires of the items expected in a past window, and whether
+// they ran then. now is the clock, replaced in the tests.
+func runPrev(stdout, stderr io.Writer, args []string, buildClients clientsFactory, now func() time.Time) (retErr error) {
+	// Parse flags
+	// -----------------
+	var (
+		lastFlag            time.Duration
+		fromFlag            string
+		toFlag              string
+		selectorFlag        string
+		outputFlag          string
+		noHeadersFlag       bool
+		displayTimezoneFlag string
+		serverTimeFlag      bool
+		logFormatFlag       string
+		inClusterFlag       bool
+	)
+	fsets := pflag.NewFlagSet(commandName+" prev", pflag.ContinueOnError)
+	fsets.SetOutput(stderr)
+	durationVarP(fsets, &lastFlag, "last", "", 0, "The duration of the window ending now, e.g. 6h.")
+	fsets.StringVarP(&fromFlag, "from", "", "", "Start time of the past window in RFC3339 format, instead of '--last'.")
+	fsets.StringVarP(&toFlag, "to", "", "", "End time of the past window in RFC3339 format, instead of '--last'. It must not be after now.")
+	fsets.StringVarP(&selectorFlag, "selector", "l", "", "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2).")
+	fsets.StringVarP(&outputFlag, "output", "o", "", "Output format. One of: ''|wide|json|yaml.")
+	fsets.BoolVarP(&noHeadersFlag, "no-headers", "", false, "If present, print output without headers.")
+	fsets.StringVarP(&displayTimezoneFlag, "display-timezone", "", "UTC", "The IANA time zone the timestamps in the table are printed in, or 'local'.")
+	fsets.BoolVarP(&serverTimeFlag, "server-time", "", false, "If present, take now from the clock of the API server instead of the local one.")
+	fsets.StringVarP(&logFormatFlag, "log-format", "", logFormatText, "The format of the logs written into stderr. One of: text|json.")
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.AddFlags(fsets)
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
+
+	fsets.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of %s prev:\n", commandName)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example")
+		fmt.Fprintln(stderr, "  $ kubectl-cls prev --last 6h -o wide")
+		fmt.Fprintln(stderr, "  $ kubectl-cls prev --from 2023-01-24T02:00:00Z --to 2023-01-24T03:30:00Z")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Flags")
+		fmt.Fprint(stderr, fsets.FlagUsages())
+	}
+
+	if err := fsets.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+	if err := validateLogFormat(logFormatFlag); err != nil {
+		return err
+	}
+	logger := newLogger(stderr, logFormatFlag, false)
+	defer func() { retErr = logCommandError(logger, logFormatFlag, retErr) }()
+
+	// Validation
+	// -----------------
+	// The window ends at the time of the API server with '--server-time', so
+	// the clients are built first.
+	var c *clients
+	if serverTimeFlag {
+		var err error
+		c, err = buildClients(cfgFlags)
+		if err != nil {
+			return err
+		}
+		now = getServerClock(context.Background(), c, now, logger)
+	}
+	start := now()
+	from, to, err := parsePastWindow(fsets, lastFlag, fromFlag, toFlag, start)
+	if err != nil {
+		return err
+	}
+	if outputFlag != "" && outputFlag != "wide" && outputFlag != "json" && outputFlag != "yaml" {
+		return fmt.Errorf("%s is unsupported output format", outputFlag)
+	}
+	if _, err := parseSelector("selector", selectorFlag); err != nil {
+		return err
+	}
+	displayLocation, err := parseLocation("display-timezone", displayTimezoneFlag)
+	if err != nil {
+		return err
+	}
+
+	// List the expected fires
+	// -----------------
+	if c == nil {
+		c, err = buildClients(cfgFlags)
+		if err != nil {
+			return err
+		}
+	}
+	namespace := ""
+	if cfgFlags.Namespace != nil {
+		namespace = *cfgFlags.Namespace
+	}
+	items, err := listItems(context.Background(), c, namespace, selectorFlag)
+	if err != nil {
+		return err
+	}
+	fires, err := findExpected(items, from, to)
+	if err != nil {
+		return err
+	}
+
+	switch outputFlag {
+	case "json":
+		b, err := json.MarshalIndent(expectedReport{ApiVersion: "v1", Window: documentWindow{From: from, To: to}, Expected: fires}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(expectedReport{ApiVersion: "v1", Window: documentWindow{From: from, To: to}, Expected: fires})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+	return printExpected(stdout, noHeadersFlag, outputFlag == "wide", timeFormat{location: displayLocation, now: start}, fires)
+}
+
+// Get the past window of '--last' ending now, or of '--from' and '--to' not
+// after now.
+func parsePastWindow(fsets *pflag.FlagSet, last time.Duration, fromFlag, toFlag string, now time.Time) (from, to time.Time, err error) {
+	if fsets.Changed("last") {
+		if fromFlag != "" || toFlag != "" {
+			return from, to, errors.New("'--last' can't be used with '--from' and '--to'")
+		}
+		if last <= 0 {
+			return from, to, errors.New("'--last' must be positive")
+		}
+		return now.Add(-last).UTC(), now.UTC(), nil
+	}
+	if fromFlag == "" || toFlag == "" {
+		return from, to, errors.New("please set --last flag, or --from and --to flags")
+	}
+	from, err = time.Parse(time.RFC3339, fromFlag)
+	if err != nil {
+		return from, to, fmt.Errorf("failed to parse '--from' value: %w", err)
+	}
+	from = from.UTC() // Convert to UTC for easy comparison with the schedule.
+	to, err = time.Parse(time.RFC3339, toFlag)
+	if err != nil {
+		return from, to, fmt.Errorf("failed to parse '--to' value: %w", err)
+	}
+	to = to.UTC() // Convert to UTC for easy comparison with the schedule.
+	if from.After(to) {
+		return from, to, errors.New("'--from' '--to' times are reversed")
+	}
+	if to.After(now) {
+		return from, to, errors.New("'--to' must not be after now")
+	}
+	return from, to, nil
+}
+
+// Get the fires of the items expected in the from-to window, and the status
+// of the last one of each item. The items without a fire in the window are
+// excluded.
+func findExpected(items []item, from, to time.Time) ([]expected, error) {
+	ret := []expected{}
+	for _, item := range items {
+		fires, truncated, err := getItemFires(item, from, to, boundaries{})
+		if err != nil {
+			return nil, err
+		}
+		if len(fires) == 0 {
+			continue
+		}
+		for i := range fires {
+			fires[i] = fires[i].UTC()
+		}
+		obj := item.object()
+		last := item.lastScheduleTime()
+		status := prevUnknown
+		if !truncated {
+			status = getPrevStatus(item.suspended(), fires[len(fires)-1], to, last)
+		}
+		ret = append(ret, expected{
+			Kind:             item.kind(),
+			Namespace:        obj.GetNamespace(),
+			Name:             obj.GetName(),
+			Schedule:         item.schedule(),
+			Suspend:          item.suspended(),
+			Fires:            fires,
+			FiresTruncated:   truncated,
+			LastScheduleTime: last,
+			Status:           status,
+		})
+	}
+	return ret, nil
+}
+
+// Tell whether the item ran at the last expected fire from the last time it
+// was scheduled. Only the last schedule is recorded in the status of the
+// items, so the earlier fires in the window can't be told.
+func getPrevStatus(suspended bool, fire, to time.Time, last *metav1.Time) string {
+	switch {
+	case suspended:
+		return prevSuspended
+	case last == nil || last.Time.Before(fire):
+		return prevMissed
+	case last.Time.After(to):
+		return prevUnknown
+	}
+	return prevRan
+}
+
+func printExpected(stdout io.Writer, noHeaders, wide bool, tf timeFormat, fires []expected) error {
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		headers := []string{"Namespace", "Name", "Schedule", "Suspend", "Kind", "Fires", "Last Expected"}
+		if wide {
+			headers = append(headers, "Last Schedule", "Status")
+		}
+		fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	}
+	for _, e := range fires {
+		count := strconv.Itoa(len(e.Fires))
+		lastFire := metav1.NewTime(e.Fires[len(e.Fires)-1])
+		last := tf.format(&lastFire)
+		if e.FiresTruncated {
+			count = ">" + strconv.Itoa(maxFireOccurrences)
+			last = count
+		}
+		columns := []string{e.Namespace, e.Name, e.Schedule, strconv.FormatBool(e.Suspend), e.Kind, count, last}
+		if wide {
+			columns = append(columns, tf.format(e.LastScheduleTime), e.Status)
+		}
+		fmt.Fprintln(tw, strings.Join(columns, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+// newPrevClients returns the fake clients with a healthy CronJob, a missed
+// CronWorkflow which last ran the day before, a suspended CronJob, and a
+// CronJob not firing in the window.
+func newPrevClients() *clients {
+	ran := getCronJob("ns-a", "backup", "0 1 * * *", false)
+	lastSchedule := metav1.NewTime(getTime("2023-01-24T01:00:00Z"))
+	ran.Status.LastScheduleTime = &lastSchedule
+	suspended := getCronJob("ns-b", "cleanup", "0 2 * * *", true)
+	noon := getCronJob("ns-b", "nightly", "0 12 * * *", false)
+	missed := getCronWorkflow("ns-b", "report", "0 3 * * *", false)
+	lastScheduled := metav1.NewTime(getTime("2023-01-23T03:00:00Z"))
+	missed.Status.LastScheduledTime = &lastScheduled
+	return newFakeClients(
+		[]batchv1.CronJob{ran, suspended, noon},
+		[]wfv1alpha1.CronWorkflow{missed},
+	)
+}
+
+// Run the prev subcommand with the clients and the clock at now.
+func runFakePrev(c *clients, now string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+		return c, nil
+	}
+	clock := func() time.Time { return getTime(now) }
+	err := runPrev(&stdout, &stderr, append([]string{"prev"}, args...), buildClients, clock)
+	return stdout.String(), err
+}
+
+func Test_runPrev(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "last",
+			args: []string{"--last", "6h"},
+			want: "" +
+				"Namespace   Name      Schedule    Suspend   Kind           Fires   Last Expected\n" +
+				"ns-a        backup    0 1 * * *   false     CronJob        1       2023-01-24T01:00:00Z\n" +
+				"ns-b        cleanup   0 2 * * *   true      CronJob        1       2023-01-24T02:00:00Z\n" +
+				"ns-b        report    0 3 * * *   false     CronWorkflow   1       2023-01-24T03:00:00Z\n",
+		},
+		{
+			name: "wide",
+			args: []string{"--no-headers", "--last", "6h", "-o", "wide"},
+			want: "" +
+				"ns-a   backup    0 1 * * *   false   CronJob        1   2023-01-24T01:00:00Z   2023-01-24T01:00:00Z   ran\n" +
+				"ns-b   cleanup   0 2 * * *   true    CronJob        1   2023-01-24T02:00:00Z   <none>                 suspended\n" +
+				"ns-b   report    0 3 * * *   false   CronWorkflow   1   2023-01-24T03:00:00Z   2023-01-23T03:00:00Z   missed\n",
+		},
+		{
+			name: "explicit window",
+			args: []string{"--no-headers", "--from", "2023-01-23T00:00:00Z", "--to", "2023-01-24T00:30:00Z", "-o", "wide", "-n", "ns-a"},
+			want: "ns-a   backup   0 1 * * *   false   CronJob   1   2023-01-23T01:00:00Z   2023-01-24T01:00:00Z   unknown\n",
+		},
+		{
+			name: "several fires",
+			args: []string{"--no-headers", "--last", "48h", "-l", "app!=none", "-n", "ns-b"},
+			want: "" +
+				"ns-b   cleanup   0 2 * * *    true    CronJob        2   2023-01-24T02:00:00Z\n" +
+				"ns-b   nightly   0 12 * * *   false   CronJob        2   2023-01-23T12:00:00Z\n" +
+				"ns-b   report    0 3 * * *    false   CronWorkflow   2   2023-01-24T03:00:00Z\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := runFakePrev(newPrevClients(), "2023-01-24T04:00:00Z", tt.args...)
+			if err != nil {
+				t.Fatalf("runPrev() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("output mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_runPrev_json(t *testing.T) {
+	t.Parallel()
+	got, err := runFakePrev(newPrevClients(), "2023-01-24T04:00:00Z", "--last", "2h", "-o", "json")
+	if err != nil {
+		t.Fatalf("runPrev() error = %v", err)
+	}
+	var report expectedReport
+	if err := json.Unmarshal([]byte(got), &report); err != nil {
+		t.Fatal(err)
+	}
+	lastScheduled := metav1.NewTime(getTime("2023-01-23T03:00:00Z"))
+	want := expectedReport{
+		ApiVersion: "v1",
+		Window:     documentWindow{From: getTime("2023-01-24T02:00:00Z"), To: getTime("2023-01-24T04:00:00Z")},
+		Expected: []expected{
+			{Kind: "CronJob", Namespace: "ns-b", Name: "cleanup", Schedule: "0 2 * * *", Suspend: true, Fires: []time.Time{getTime("2023-01-24T02:00:00Z")}, Status: prevSuspended},
+			{Kind: "CronWorkflow", Namespace: "ns-b", Name: "report", Schedule: "0 3 * * *", Fires: []time.Time{getTime("2023-01-24T03:00:00Z")}, LastScheduleTime: &lastScheduled, Status: prevMissed},
+		},
+	}
+	if diff := cmp.Diff(want, report); diff != "" {
+		t.Errorf("report mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_runPrev_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no window", args: nil, want: "please set --last flag, or --from and --to flags"},
+		{name: "last and from", args: []string{"--last", "1h", "--from", "2023-01-24T00:00:00Z"}, want: "'--last' can't be used with '--from' and '--to'"},
+		{name: "zero last", args: []string{"--last", "0s"}, want: "'--last' must be positive"},
+		{name: "future", args: []string{"--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}, want: "'--to' must not be after now"},
+		{name: "output", args: []string{"--last", "1h", "-o", "mermaid"}, want: "mermaid is unsupported output format"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := runFakePrev(newPrevClients(), "2023-01-24T04:00:00Z", tt.args...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("want error %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/unblee/kubectl-cls/pkg/cls"
+	"golang.org/x/exp/slog"
+	"golang.org/x/term"
+)
+
+// The stages of a progressEvent.
+const (
+	progressListing    = "listing"
+	progressReceived   = "received"
+	progressEvaluating = "evaluating"
+)
+
+// progressEvent is reported by the listing while it is slow, see
+// clients.progress.
+type progressEvent struct {
+	stage string
+	// resource is the resource being listed, e.g. "cronjobs".
+	resource string
+	// count is the number of the objects received, or being evaluated.
+	count int
+}
+
+func (e progressEvent) String() string {
+	switch e.stage {
+	case progressReceived:
+		return fmt.Sprintf("listing %s… %d received", e.resource, e.count)
+	case progressEvaluating:
+		return fmt.Sprintf("evaluating %d items…", e.count)
+	}
+	return fmt.Sprintf("listing %s…", e.resource)
+}
+
+// progressSource reports the listing of the source and the evaluation of the
+// objects listed, which cls.Match does right after listing each source.
+type progressSource struct {
+	cls.Source
+	resource string
+	report   func(progressEvent)
+}
+
+func (s progressSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	s.report(progressEvent{stage: progressListing, resource: s.resource})
+	objects, err := s.Source.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	s.report(progressEvent{stage: progressReceived, resource: s.resource, count: len(objects)})
+	s.report(progressEvent{stage: progressEvaluating, count: len(objects)})
+	return objects, nil
+}
+
+// Log the progress event as a debug record, e.g. with '--log-format json'
+// where the progress line isn't written.
+func logProgress(logger *slog.Logger, e progressEvent) {
+	attrs := []any{"stage", e.stage, "count", e.count}
+	if e.resource != "" {
+		attrs = append(attrs, "resource", e.resource)
+	}
+	logger.Debug(e.String(), attrs...)
+}
+
+// progressLine renders the progress events as a single line updated in place,
+// so it must only be written to a terminal. It is cleared before the output.
+type progressLine struct {
+	mu      sync.Mutex
+	w       io.Writer
+	written bool
+}
+
+// Get the progress line of stderr, or nil when stderr is not a terminal.
+func newProgressLine(stderr io.Writer) *progressLine {
+	f, ok := stderr.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return nil
+	}
+	return &progressLine{w: stderr}
+}
+
+func (p *progressLine) update(e progressEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// Return to the beginning of the line and erase it before writing.
+	fmt.Fprintf(p.w, "\r\x1b[K%s", e)
+	p.written = true
+}
+
+// Clear the line, if anything is written. It is safe to call more than once
+// and on nil.
+func (p *progressLine) clear() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.written {
+		fmt.Fprint(p.w, "\r\x1b[K")
+		p.written = false
+	}
+}
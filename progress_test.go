@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_listScheduleIncluded_progress(t *testing.T) {
+	t.Parallel()
+	c := newRunClients()
+	var mu sync.Mutex
+	got := []string{}
+	c.progress = func(e progressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e.String())
+	}
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	if _, err := listScheduleIncluded(context.Background(), c, "ns-b", "", from, to, boundaries{}); err != nil {
+		t.Fatalf("listScheduleIncluded() error = %v", err)
+	}
+	want := []string{
+		"listing cronjobs…",
+		"listing cronjobs… 2 received",
+		"evaluating 2 items…",
+		"listing cronworkflows…",
+		"listing cronworkflows… 1 received",
+		"evaluating 1 items…",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("events mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_progressLine(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	p := &progressLine{w: &buf}
+	p.clear()
+	p.update(progressEvent{stage: progressListing, resource: "cronjobs"})
+	p.update(progressEvent{stage: progressReceived, resource: "cronjobs", count: 3200})
+	p.clear()
+	p.clear()
+	want := "\r\x1b[Klisting cronjobs…\r\x1b[Klisting cronjobs… 3200 received\r\x1b[K"
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	var nilLine *progressLine
+	nilLine.clear()
+	if newProgressLine(&buf) != nil {
+		t.Errorf("want nil for a writer which is not a terminal")
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/spf13/pflag"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The contract of '--provider':
+//
+// The executable is invoked with the '--namespace=NAMESPACE' and
+// '--selector=SELECTOR' arguments, and the same query as a JSON object on
+// stdin. An empty namespace means all namespaces. It must print a JSON array
+// of the providerItems in the namespace and matching the selector on stdout,
+// and exit with 0 within '--provider-timeout'. Any other exit code is a
+// failure, and its stderr is reported. The items are validated by
+// validateProviderItem. An item has either a 'schedule', or 'schedules' for the
+// kinds with multiple schedules, optionally with their 'scheduleNames'.
+const defaultProviderTimeout = 30 * time.Second
+
+// providerQuery is the query written to the stdin of a provider.
+type providerQuery struct {
+	Namespace string `json:"namespace"`
+	Selector  string `json:"selector"`
+}
+
+// providerItem is an element of the array printed by a provider. The unknown
+// fields are rejected.
+type providerItem struct {
+	Name      string   `json:"name"`
+	Namespace string   `json:"namespace"`
+	Kind      string   `json:"kind"`
+	Schedule  string   `json:"schedule,omitempty"`
+	Schedules []string `json:"schedules,omitempty"`
+	// ScheduleNames are the names of the schedules in the same order.
+	ScheduleNames []string `json:"scheduleNames,omitempty"`
+	Timezone      string   `json:"timezone,omitempty"`
+	Suspend       bool     `json:"suspend"`
+}
+
+// Get either the schedule or the schedules.
+func (pi providerItem) schedules() []string {
+	if pi.Schedule != "" {
+		return []string{pi.Schedule}
+	}
+	return pi.Schedules
+}
+
+// providedObject is an object listed by a provider, which is a Scheduled of
+// the library.
+type providedObject struct {
+	metav1.ObjectMeta
+	kind string
+	// schedules has at least one schedule.
+	schedules []string
+	// scheduleNames has the names of schedules, or is nil.
+	scheduleNames []string
+	timezone      string
+	suspend       bool
+}
+
+func (o *providedObject) Schedules() []string { return o.schedules }
+func (o *providedObject) TimeZone() string    { return o.timezone }
+func (o *providedObject) Meta() metav1.Object { return o }
+func (o *providedObject) Kind() string        { return o.kind }
+
+// document is the object of the item in the json/yaml output document, with
+// the matched-th schedule moved to the front, see item.matchedScheduleIndex.
+// 'schedule' is the first one, and 'schedules' lists all of them when there
+// are multiple.
+func (o *providedObject) document(matched int) providerItem {
+	schedules := moveToFront(o.schedules, matched)
+	doc := providerItem{
+		Name:          o.Name,
+		Namespace:     o.Namespace,
+		Kind:          o.kind,
+		Schedule:      schedules[0],
+		ScheduleNames: moveToFront(o.scheduleNames, matched),
+		Timezone:      o.timezone,
+		Suspend:       o.suspend,
+	}
+	if len(schedules) > 1 {
+		doc.Schedules = schedules
+	}
+	return doc
+}
+
+// providerFlags are the flags of the sources of the items which can be used
+// with '--provider' alongside tableFlags. The namespaces of
+// '--namespace-selector' and '--namespaces' can't be passed to the providers,
+// and the exporter doesn't run them.
+var providerFlags = []string{
+	"provider",
+	"provider-timeout",
+	"include-crds",
+	"fixtures",
+}
+
+// Validate the flags used with '--provider'. The objects of the providers only
+// have the namespace, name, kind, schedules, time zone and suspend, so only
+// tableFlags and providerFlags can be used, and only the table, the json/yaml,
+// the mermaid, the junit and the raw output are supported. The '-o wide'
+// columns other than the schedules and the fires are left blank.
+func validateProviderFlags(fsets *pflag.FlagSet, output string) error {
+	if output != "" && output != "wide" && output != "json" && output != "yaml" && output != "mermaid" && output != "junit" && output != "raw" {
+		return fmt.Errorf("'--provider' can't be used with '-o %s'", output)
+	}
+	if name := unsupportedFlag(fsets, tableFlags, providerFlags); name != "" {
+		return fmt.Errorf("'--provider' can't be used with '--%s'", name)
+	}
+	return nil
+}
+
+// List the objects of the providers in the order of the flags.
+func listProviders(ctx context.Context, providers []string, timeout time.Duration, query providerQuery) ([]item, error) {
+	items := []item{}
+	for _, provider := range providers {
+		objects, err := runProvider(ctx, provider, timeout, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			items = append(items, item{provided: obj})
+		}
+	}
+	return items, nil
+}
+
+// Run the provider and validate its output.
+func runProvider(ctx context.Context, provider string, timeout time.Duration, query providerQuery) ([]*providedObject, error) {
+	stdin, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, provider, "--namespace="+query.Namespace, "--selector="+query.Selector)
+	cmd.Stdin = bytes.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("provider '%s' timed out after %s", provider, timeout)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("provider '%s' failed with exit code %d: %s", provider, exitErr.ExitCode(), strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("failed to run provider '%s': %w", provider, err)
+	}
+
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	var providerItems []providerItem
+	if err := dec.Decode(&providerItems); err != nil {
+		return nil, fmt.Errorf("provider '%s' printed an invalid JSON array of items: %w", provider, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("provider '%s' printed more than a JSON array of items", provider)
+	}
+
+	objects := make([]*providedObject, len(providerItems))
+	for i, pi := range providerItems {
+		if err := validateProviderItem(pi, query); err != nil {
+			return nil, fmt.Errorf("provider '%s' printed an invalid items[%d]: %w", provider, i, err)
+		}
+		objects[i] = &providedObject{
+			ObjectMeta:    metav1.ObjectMeta{Namespace: pi.Namespace, Name: pi.Name},
+			kind:          pi.Kind,
+			schedules:     pi.schedules(),
+			scheduleNames: pi.ScheduleNames,
+			timezone:      pi.Timezone,
+			suspend:       pi.Suspend,
+		}
+	}
+	return objects, nil
+}
+
+// The name, namespace, kind and either schedule or schedules are required, the
+// namespace must be the one of the query unless all namespaces, the kind must
+// not be a built-in kind, and the schedules must be parsed.
+func validateProviderItem(pi providerItem, query providerQuery) error {
+	for _, field := range []struct{ name, value string }{
+		{"name", pi.Name},
+		{"namespace", pi.Namespace},
+		{"kind", pi.Kind},
+	} {
+		if field.value == "" {
+			return fmt.Errorf("'%s' is required", field.name)
+		}
+	}
+	if pi.Schedule == "" && len(pi.Schedules) == 0 {
+		return errors.New("'schedule' or 'schedules' is required")
+	}
+	if pi.Schedule != "" && len(pi.Schedules) > 0 {
+		return errors.New("'schedule' and 'schedules' can't be set together")
+	}
+	if len(pi.ScheduleNames) > 0 && len(pi.ScheduleNames) != len(pi.schedules()) {
+		return errors.New("'scheduleNames' must have a name for each of the schedules")
+	}
+	if query.Namespace != "" && pi.Namespace != query.Namespace {
+		return fmt.Errorf("'%s/%s' is not in the '%s' namespace of the query", pi.Namespace, pi.Name, query.Namespace)
+	}
+	if pi.Kind == "CronJob" || pi.Kind == "CronWorkflow" {
+		return fmt.Errorf("'kind' must not be the built-in kind '%s'", pi.Kind)
+	}
+	for _, schedule := range pi.schedules() {
+		if _, err := cron.ParseStandard(schedule); err != nil {
+			return fmt.Errorf("failed to parse schedule spec '%s' of '%s/%s': %w", schedule, pi.Namespace, pi.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Write a provider script printing the stdout, and recording the arguments
+// and the stdin into the directory.
+func writeProvider(t *testing.T, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "provider")
+	content := "#!/bin/sh\n" +
+		"echo \"$@\" > " + filepath.Join(dir, "args") + "\n" +
+		"cat > " + filepath.Join(dir, "stdin") + "\n" +
+		script + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_runProvider(t *testing.T) {
+	t.Parallel()
+	path := writeProvider(t, `cat <<'EOF'
+[
+  {"name": "backup", "namespace": "ns-a", "kind": "Backup", "schedule": "0 1 * * *", "timezone": "Asia/Tokyo"},
+  {"name": "report", "namespace": "ns-a", "kind": "Report", "schedule": "0 2 * * *", "suspend": true},
+  {"name": "scale", "namespace": "ns-a", "kind": "CronHPA", "schedules": ["0 8 * * *", "0 20 * * *"]}
+]
+EOF`)
+	got, err := runProvider(context.Background(), path, time.Minute, providerQuery{Namespace: "ns-a", Selector: "app=a"})
+	if err != nil {
+		t.Fatalf("runProvider() error = %v", err)
+	}
+	want := []providerItem{
+		{Name: "backup", Namespace: "ns-a", Kind: "Backup", Schedule: "0 1 * * *", Timezone: "Asia/Tokyo"},
+		{Name: "report", Namespace: "ns-a", Kind: "Report", Schedule: "0 2 * * *", Suspend: true},
+		{Name: "scale", Namespace: "ns-a", Kind: "CronHPA", Schedule: "0 8 * * *", Schedules: []string{"0 8 * * *", "0 20 * * *"}},
+	}
+	docs := make([]providerItem, len(got))
+	for i, obj := range got {
+		docs[i] = obj.document(0)
+	}
+	if diff := cmp.Diff(want, docs); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	args, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "args"))
+	if diff := cmp.Diff("--namespace=ns-a --selector=app=a\n", string(args)); diff != "" {
+		t.Errorf("args (-want +got):\n%s", diff)
+	}
+	stdin, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "stdin"))
+	if diff := cmp.Diff(`{"namespace":"ns-a","selector":"app=a"}`, string(stdin)); diff != "" {
+		t.Errorf("stdin (-want +got):\n%s", diff)
+	}
+}
+
+func Test_runProvider_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		want    string
+	}{
+		{
+			name:   "exit code",
+			script: "echo 'no credentials' >&2; exit 3",
+			want:   "provider '%s' failed with exit code 3: no credentials",
+		},
+		{
+			name:    "timeout",
+			script:  "exec sleep 10",
+			timeout: 100 * time.Millisecond,
+			want:    "provider '%s' timed out after 100ms",
+		},
+		{
+			name:   "not a JSON array",
+			script: `echo '{"name": "backup"}'`,
+			want:   "provider '%s' printed an invalid JSON array of items: ",
+		},
+		{
+			name:   "more than an array",
+			script: `echo '[] []'`,
+			want:   "provider '%s' printed more than a JSON array of items",
+		},
+		{
+			name:   "unknown field",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "Backup", "schedule": "0 1 * * *", "labels": {}}]'`,
+			want:   `provider '%s' printed an invalid JSON array of items: json: unknown field "labels"`,
+		},
+		{
+			name:   "missing kind",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "schedule": "0 1 * * *"}]'`,
+			want:   "provider '%s' printed an invalid items[0]: 'kind' is required",
+		},
+		{
+			name:   "missing schedule",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "Backup"}]'`,
+			want:   "provider '%s' printed an invalid items[0]: 'schedule' or 'schedules' is required",
+		},
+		{
+			name:   "schedule and schedules",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "Backup", "schedule": "0 1 * * *", "schedules": ["0 2 * * *"]}]'`,
+			want:   "provider '%s' printed an invalid items[0]: 'schedule' and 'schedules' can't be set together",
+		},
+		{
+			name:   "schedule names",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "Backup", "schedules": ["0 1 * * *", "0 2 * * *"], "scheduleNames": ["daily"]}]'`,
+			want:   "provider '%s' printed an invalid items[0]: 'scheduleNames' must have a name for each of the schedules",
+		},
+		{
+			name:   "another namespace",
+			script: `echo '[{"name": "backup", "namespace": "ns-b", "kind": "Backup", "schedule": "0 1 * * *"}]'`,
+			want:   "provider '%s' printed an invalid items[0]: 'ns-b/backup' is not in the 'ns-a' namespace of the query",
+		},
+		{
+			name:   "built-in kind",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "CronJob", "schedule": "0 1 * * *"}]'`,
+			want:   "provider '%s' printed an invalid items[0]: 'kind' must not be the built-in kind 'CronJob'",
+		},
+		{
+			name:   "invalid schedules",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "Backup", "schedules": ["0 1 * * *", "hourly"]}]'`,
+			want:   "provider '%s' printed an invalid items[0]: failed to parse schedule spec 'hourly' of 'ns-a/backup': ",
+		},
+		{
+			name:   "invalid schedule",
+			script: `echo '[{"name": "backup", "namespace": "ns-a", "kind": "Backup", "schedule": "daily"}]'`,
+			want:   "provider '%s' printed an invalid items[0]: failed to parse schedule spec 'daily' of 'ns-a/backup': ",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := writeProvider(t, tt.script)
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = time.Minute
+			}
+			_, err := runProvider(context.Background(), path, timeout, providerQuery{Namespace: "ns-a"})
+			if err == nil {
+				t.Fatal("want an error")
+			}
+			if want := strings.ReplaceAll(tt.want, "%s", path); !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("want the error starting with %q, got %q", want, err.Error())
+			}
+		})
+	}
+}
+
+func Test_provider_run(t *testing.T) {
+	t.Parallel()
+	path := writeProvider(t, `echo '[{"name": "cleanup", "namespace": "default", "kind": "Backup", "schedule": "0 3 * * *"}, {"name": "late", "namespace": "default", "kind": "Backup", "schedule": "0 12 * * *"}]'`)
+	var got bytes.Buffer
+	args := []string{commandName, "--fixtures", "testdata/drift/cronjobs.yaml", "--provider", path, "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z", "--no-headers"}
+	if err := run(&got, &bytes.Buffer{}, args); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(got.String()), "\n") {
+		fields := strings.Fields(line)
+		names = append(names, fields[len(fields)-1]+" "+fields[1])
+	}
+	want := []string{"CronJob backup", "Backup cleanup", "CronJob cleanup"}
+	if diff := cmp.Diff(want, names[:3]); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if strings.Contains(got.String(), "late") {
+		t.Errorf("want the provided items out of the period excluded, got:\n%s", got.String())
+	}
+}
+
+func Test_validateProviderFlags(t *testing.T) {
+	t.Parallel()
+	path := writeProvider(t, "echo '[]'")
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "filter", args: []string{"--unowned"}, want: "'--provider' can't be used with '--unowned'"},
+		{name: "namespace selector", args: []string{"--namespace-selector", "team=a"}, want: "'--provider' can't be used with '--namespace-selector'"},
+		{name: "out of the table flags", args: []string{"--sort-by", "name"}, want: "'--provider' can't be used with '--sort-by'"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			args := append([]string{commandName, "--fixtures", "testdata/drift", "--provider", path, "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}, tt.args...)
+			err := run(&bytes.Buffer{}, &bytes.Buffer{}, args)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("run() error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
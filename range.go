@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse the ISO 8601 interval of '--range', either 'START/END' or
+// 'START/DURATION', e.g. '2024-06-01T00:00:00Z/PT6H'. The times are parsed
+// with layout, and the end of a duration is in the zone of the start.
+func parseRange(value, layout string) (time.Time, time.Time, error) {
+	start, end, ok := strings.Cut(value, "/")
+	if !ok || strings.Contains(end, "/") {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse '--range' value: want START/END or START/DURATION: %s", value)
+	}
+	from, err := time.Parse(layout, start)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse the start of '--range' value: %w", err)
+	}
+
+	if strings.HasPrefix(end, "P") || strings.HasPrefix(end, "-P") || strings.HasPrefix(end, "+P") {
+		d, err := parseISODuration(end)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("failed to parse the duration of '--range' value: %w", err)
+		}
+		if d.negative {
+			return time.Time{}, time.Time{}, fmt.Errorf("the duration of '--range' must not be negative: %s", end)
+		}
+		return from, d.addTo(from), nil
+	}
+
+	to, err := time.Parse(layout, end)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to parse the end of '--range' value: %w", err)
+	}
+	return from, to, nil
+}
+
+// isoDuration is an ISO 8601 duration. The years, months, weeks and days are
+// calendar units, so they are only added to a time.
+type isoDuration struct {
+	negative bool
+	years    int
+	months   int
+	days     int
+	clock    time.Duration
+}
+
+// Add the duration to t. The calendar units are added before the clock, as
+// time.Time.AddDate normalizes them.
+func (d isoDuration) addTo(t time.Time) time.Time {
+	if d.negative {
+		return t.AddDate(-d.years, -d.months, -d.days).Add(-d.clock)
+	}
+	return t.AddDate(d.years, d.months, d.days).Add(d.clock)
+}
+
+// Parse an ISO 8601 duration in the 'PnYnMnWnDTnHnMnS' form, with an optional
+// sign. Only the hours, minutes and seconds can be fractional, e.g. 'PT1.5H'.
+func parseISODuration(value string) (isoDuration, error) {
+	var d isoDuration
+	s := value
+	switch {
+	case strings.HasPrefix(s, "-"):
+		d.negative = true
+		s = s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	if !strings.HasPrefix(s, "P") {
+		return isoDuration{}, fmt.Errorf("want a duration starting with 'P': %s", value)
+	}
+	s = s[1:]
+	if s == "" || s == "T" {
+		return isoDuration{}, fmt.Errorf("want at least one component: %s", value)
+	}
+
+	date, clock, hasClock := strings.Cut(s, "T")
+	if hasClock && clock == "" {
+		return isoDuration{}, fmt.Errorf("want a component after 'T': %s", value)
+	}
+
+	// The designators must be in this order, each at most once.
+	if err := parseISOComponents(date, "YMWD", false, func(unit byte, n float64) {
+		switch unit {
+		case 'Y':
+			d.years = int(n)
+		case 'M':
+			d.months = int(n)
+		case 'W':
+			d.days += 7 * int(n)
+		case 'D':
+			d.days += int(n)
+		}
+	}); err != nil {
+		return isoDuration{}, fmt.Errorf("%w: %s", err, value)
+	}
+	if err := parseISOComponents(clock, "HMS", true, func(unit byte, n float64) {
+		switch unit {
+		case 'H':
+			d.clock += time.Duration(n * float64(time.Hour))
+		case 'M':
+			d.clock += time.Duration(n * float64(time.Minute))
+		case 'S':
+			d.clock += time.Duration(n * float64(time.Second))
+		}
+	}); err != nil {
+		return isoDuration{}, fmt.Errorf("%w: %s", err, value)
+	}
+	return d, nil
+}
+
+// Parse the number-designator pairs of s, whose designators must be in the
+// order of units.
+func parseISOComponents(s, units string, fractional bool, set func(unit byte, n float64)) error {
+	next := 0
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' && r != ',' })
+		if i < 0 {
+			return fmt.Errorf("want a designator after '%s'", s)
+		}
+		if i == 0 {
+			return fmt.Errorf("want a number before '%c'", s[0])
+		}
+		number, unit := strings.Replace(s[:i], ",", ".", 1), s[i]
+		pos := strings.IndexByte(units[next:], unit)
+		if pos < 0 {
+			if strings.IndexByte(units, unit) >= 0 {
+				return fmt.Errorf("'%c' is out of order or repeated", unit)
+			}
+			return fmt.Errorf("unknown designator '%c'", unit)
+		}
+		next += pos + 1
+
+		if !fractional && strings.Contains(number, ".") {
+			return errors.New("only the hours, minutes and seconds can be fractional")
+		}
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number '%s'", number)
+		}
+		set(unit, n)
+		s = s[i+1:]
+	}
+	return nil
+}
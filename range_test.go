@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parseRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		value    string
+		wantFrom string
+		wantTo   string
+		wantErr  string
+	}{
+		{
+			name:     "start and end",
+			value:    "2024-06-01T00:00:00Z/2024-06-01T06:00:00Z",
+			wantFrom: "2024-06-01T00:00:00Z",
+			wantTo:   "2024-06-01T06:00:00Z",
+		},
+		{
+			name:     "start and duration",
+			value:    "2024-06-01T00:00:00Z/PT6H",
+			wantFrom: "2024-06-01T00:00:00Z",
+			wantTo:   "2024-06-01T06:00:00Z",
+		},
+		{
+			name:     "duration in the zone of the start",
+			value:    "2024-06-01T00:00:00+09:00/PT1H30M",
+			wantFrom: "2024-06-01T00:00:00+09:00",
+			wantTo:   "2024-06-01T01:30:00+09:00",
+		},
+		{
+			name:     "calendar units",
+			value:    "2024-01-31T00:00:00Z/P1M1DT12H",
+			wantFrom: "2024-01-31T00:00:00Z",
+			wantTo:   "2024-03-03T12:00:00Z", // Feb 31 is normalized to Mar 2
+		},
+		{
+			name:     "weeks",
+			value:    "2024-06-01T00:00:00Z/P2W",
+			wantFrom: "2024-06-01T00:00:00Z",
+			wantTo:   "2024-06-15T00:00:00Z",
+		},
+		{
+			name:     "fractional seconds with a comma",
+			value:    "2024-06-01T00:00:00Z/PT0,5S",
+			wantFrom: "2024-06-01T00:00:00Z",
+			wantTo:   "2024-06-01T00:00:00.5Z",
+		},
+		{
+			name:     "zero duration",
+			value:    "2024-06-01T00:00:00Z/PT0S",
+			wantFrom: "2024-06-01T00:00:00Z",
+			wantTo:   "2024-06-01T00:00:00Z",
+		},
+		{
+			name:    "no separator",
+			value:   "2024-06-01T00:00:00Z",
+			wantErr: "failed to parse '--range' value: want START/END or START/DURATION: 2024-06-01T00:00:00Z",
+		},
+		{
+			name:    "too many separators",
+			value:   "2024-06-01T00:00:00Z/PT1H/PT1H",
+			wantErr: "failed to parse '--range' value: want START/END or START/DURATION",
+		},
+		{
+			name:    "invalid start",
+			value:   "2024-06-01/PT6H",
+			wantErr: "failed to parse the start of '--range' value: ",
+		},
+		{
+			name:    "invalid end",
+			value:   "2024-06-01T00:00:00Z/2024-06-01",
+			wantErr: "failed to parse the end of '--range' value: ",
+		},
+		{
+			name:    "negative duration",
+			value:   "2024-06-01T00:00:00Z/-PT6H",
+			wantErr: "the duration of '--range' must not be negative: -PT6H",
+		},
+		{
+			name:    "duration without components",
+			value:   "2024-06-01T00:00:00Z/PT",
+			wantErr: "failed to parse the duration of '--range' value: want at least one component: PT",
+		},
+		{
+			name:    "minutes without T",
+			value:   "2024-06-01T00:00:00Z/P6H",
+			wantErr: "failed to parse the duration of '--range' value: unknown designator 'H': P6H",
+		},
+		{
+			name:    "out of order",
+			value:   "2024-06-01T00:00:00Z/PT30M1H",
+			wantErr: "failed to parse the duration of '--range' value: 'H' is out of order or repeated: PT30M1H",
+		},
+		{
+			name:    "fractional days",
+			value:   "2024-06-01T00:00:00Z/P1.5D",
+			wantErr: "failed to parse the duration of '--range' value: only the hours, minutes and seconds can be fractional: P1.5D",
+		},
+		{
+			name:    "missing designator",
+			value:   "2024-06-01T00:00:00Z/PT6",
+			wantErr: "failed to parse the duration of '--range' value: want a designator after '6': PT6",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			from, to, err := parseRange(tt.value, time.RFC3339)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("parseRange() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseRange() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantFrom, from.Format(time.RFC3339Nano)); diff != "" {
+				t.Errorf("from (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTo, to.Format(time.RFC3339Nano)); diff != "" {
+				t.Errorf("to (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_range_exclusive(t *testing.T) {
+	t.Parallel()
+	err := run(&bytes.Buffer{}, &bytes.Buffer{}, []string{commandName, "--range", "2024-06-01T00:00:00Z/PT6H", "--from", "2024-06-01T00:00:00Z"})
+	if want := "'--range' can't be used with '--from' and '--to'"; err == nil || err.Error() != want {
+		t.Errorf("run() error = %v, want %s", err, want)
+	}
+}
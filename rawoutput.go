@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// defaultRawDelimiter joins the fields of '-o raw' without '--delimiter'.
+const defaultRawDelimiter = "\t"
+
+// Validate the '--delimiter' value, which must split the lines into the
+// fields.
+func validateRawDelimiter(delimiter string) error {
+	if delimiter == "" {
+		return errors.New("'--delimiter' must not be empty")
+	}
+	if strings.ContainsAny(delimiter, "\r\n") {
+		return errors.New("'--delimiter' must not contain a newline")
+	}
+	return nil
+}
+
+// Print the namespace, the name, the kind, the schedule, and the next fire
+// from now of the items joined by the delimiter, one item per line in the
+// order of the items, for the selection UIs such as fzf. The next fire is
+// empty for the suspended items and those which never fire. The fields
+// containing the delimiter are refused before anything is printed, as the
+// lines couldn't be split back.
+func printRaw(stdout io.Writer, items []item, delimiter string, now time.Time, location *time.Location) error {
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		obj := item.object()
+		next := ""
+		if !item.suspended() {
+			t, _, err := getNextFire(item, now)
+			if err != nil {
+				return err
+			}
+			if !t.IsZero() {
+				next = t.In(location).Format(time.RFC3339)
+			}
+		}
+		fields := []string{obj.GetNamespace(), obj.GetName(), item.kind(), item.schedule(), next}
+		for i, field := range fields {
+			if strings.Contains(field, delimiter) {
+				name := []string{"namespace", "name", "kind", "schedule", "next fire"}[i]
+				return fmt.Errorf("the delimiter '%s' appears in the %s '%s' of %s '%s/%s', use another '--delimiter'", delimiter, name, field, item.kind(), obj.GetNamespace(), obj.GetName())
+			}
+		}
+		lines = append(lines, strings.Join(fields, delimiter))
+	}
+	for _, line := range lines {
+		fmt.Fprintln(stdout, line)
+	}
+	return nil
+}
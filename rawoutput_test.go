@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_printRaw(t *testing.T) {
+	t.Parallel()
+	items := mergeItems([]batchv1.CronJob{
+		getCronJob("ns-a", "backup", "0 1 * * *", false),
+		getCronJob("ns-b", "cleanup", "0 2 * * *", true),
+	}, []wfv1alpha1.CronWorkflow{
+		getCronWorkflow("ns-b", "report", "30 3 * * *", false),
+	})
+	now := getTime("2023-01-24T01:30:00Z")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		delimiter string
+		location  *time.Location
+		want      string
+		wantErr   string
+	}{
+		{
+			name:      "tab",
+			delimiter: defaultRawDelimiter,
+			location:  time.UTC,
+			want: "" +
+				"ns-a\tbackup\tCronJob\t0 1 * * *\t2023-01-25T01:00:00Z\n" +
+				"ns-b\tcleanup\tCronJob\t0 2 * * *\t\n" +
+				"ns-b\treport\tCronWorkflow\t30 3 * * *\t2023-01-24T03:30:00Z\n",
+		},
+		{
+			name:      "pipe in the display zone",
+			delimiter: "|",
+			location:  tokyo,
+			want: "" +
+				"ns-a|backup|CronJob|0 1 * * *|2023-01-25T10:00:00+09:00\n" +
+				"ns-b|cleanup|CronJob|0 2 * * *|\n" +
+				"ns-b|report|CronWorkflow|30 3 * * *|2023-01-24T12:30:00+09:00\n",
+		},
+		{
+			name:      "delimiter in a field",
+			delimiter: " ",
+			location:  time.UTC,
+			wantErr:   "the delimiter ' ' appears in the schedule '0 1 * * *' of CronJob 'ns-a/backup', use another '--delimiter'",
+		},
+		{
+			name:      "delimiter in the namespace",
+			delimiter: "-",
+			location:  time.UTC,
+			wantErr:   "the delimiter '-' appears in the namespace 'ns-a' of CronJob 'ns-a/backup', use another '--delimiter'",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stdout bytes.Buffer
+			err := printRaw(&stdout, items, tt.delimiter, now, tt.location)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want %q, got %v", tt.wantErr, err)
+				}
+				if stdout.Len() > 0 {
+					t.Errorf("want nothing printed, got %q", stdout.String())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, stdout.String()); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_raw_errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--delimiter", "|"}, want: "'--delimiter' requires '-o raw'"},
+		{args: []string{"-o", "raw", "--delimiter", ""}, want: "'--delimiter' must not be empty"},
+		{args: []string{"-o", "raw", "--delimiter", "\n"}, want: "'--delimiter' must not contain a newline"},
+		{args: []string{"-o", "raw", "--stats"}, want: "'-o raw' can't be used with '--stats'"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := runFake(newRunClients(), append(tt.args, runPeriod...)...)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("want %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"regexp"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	defaultRedactPattern = "(?i)(password|token|secret|key)"
+	redactedValue        = "REDACTED"
+)
+
+// Drop the last applied configuration of 'kubectl apply', which is the whole
+// manifest with the literal values of the env vars.
+func redactObjectMeta(meta *metav1.ObjectMeta) {
+	if _, ok := meta.Annotations[corev1.LastAppliedConfigAnnotation]; !ok {
+		return
+	}
+	annotations := make(map[string]string, len(meta.Annotations)-1)
+	for k, v := range meta.Annotations {
+		if k != corev1.LastAppliedConfigAnnotation {
+			annotations[k] = v
+		}
+	}
+	meta.Annotations = annotations
+}
+
+// Replace the literal values of the env vars whose names match the pattern in
+// the pod spec.
+func redactPodSpec(spec *corev1.PodSpec, pattern *regexp.Regexp) {
+	for i := range spec.InitContainers {
+		redactEnv(spec.InitContainers[i].Env, pattern)
+	}
+	for i := range spec.Containers {
+		redactEnv(spec.Containers[i].Env, pattern)
+	}
+	for i := range spec.EphemeralContainers {
+		redactEnv(spec.EphemeralContainers[i].Env, pattern)
+	}
+}
+
+// Replace the literal values of the env vars whose names match the pattern in
+// every template of the workflow spec.
+func redactWorkflowSpec(spec *wfv1alpha1.WorkflowSpec, pattern *regexp.Regexp) {
+	for i := range spec.Templates {
+		tmpl := &spec.Templates[i]
+		if tmpl.Container != nil {
+			redactEnv(tmpl.Container.Env, pattern)
+		}
+		if tmpl.Script != nil {
+			redactEnv(tmpl.Script.Env, pattern)
+		}
+		if tmpl.ContainerSet != nil {
+			for j := range tmpl.ContainerSet.Containers {
+				redactEnv(tmpl.ContainerSet.Containers[j].Env, pattern)
+			}
+		}
+		for j := range tmpl.InitContainers {
+			redactEnv(tmpl.InitContainers[j].Env, pattern)
+		}
+		for j := range tmpl.Sidecars {
+			redactEnv(tmpl.Sidecars[j].Env, pattern)
+		}
+	}
+}
+
+// Only literal values are replaced. Values from valueFrom and envFrom are
+// references to ConfigMaps or Secrets and don't leak anything by themselves.
+func redactEnv(env []corev1.EnvVar, pattern *regexp.Regexp) {
+	for i := range env {
+		if env[i].Value != "" && pattern.MatchString(env[i].Name) {
+			env[i].Value = redactedValue
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func getRedactEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "DB_PASSWORD", Value: "hunter2"},
+		{Name: "api_token", Value: "abc"},
+		{Name: "AWS_ACCESS_KEY_ID", Value: "AKIA"},
+		{Name: "CLIENT_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "secret"}}},
+		{Name: "LOG_LEVEL", Value: "debug"},
+	}
+}
+
+func getWantRedactEnv() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "DB_PASSWORD", Value: "REDACTED"},
+		{Name: "api_token", Value: "REDACTED"},
+		{Name: "AWS_ACCESS_KEY_ID", Value: "REDACTED"},
+		{Name: "CLIENT_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "secret"}}},
+		{Name: "LOG_LEVEL", Value: "debug"},
+	}
+}
+
+func Test_redactEnv(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		pattern string
+		env     []corev1.EnvVar
+		want    []corev1.EnvVar
+	}{
+		{
+			name:    "default pattern",
+			pattern: defaultRedactPattern,
+			env:     getRedactEnv(),
+			want:    getWantRedactEnv(),
+		},
+		{
+			name:    "custom pattern",
+			pattern: "^LOG_",
+			env:     getRedactEnv(),
+			want: []corev1.EnvVar{
+				{Name: "DB_PASSWORD", Value: "hunter2"},
+				{Name: "api_token", Value: "abc"},
+				{Name: "AWS_ACCESS_KEY_ID", Value: "AKIA"},
+				{Name: "CLIENT_SECRET", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{Key: "secret"}}},
+				{Name: "LOG_LEVEL", Value: "REDACTED"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			redactEnv(tt.env, regexp.MustCompile(tt.pattern))
+			if diff := cmp.Diff(tt.want, tt.env); diff != "" {
+				t.Errorf("redactEnv() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_buildPrintformat_redact(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "cj", "0 1 * * *", false)
+	cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main", Env: getRedactEnv()}}
+	cronworkflow := getCronWorkflow("ns-a", "cwf", "0 2 * * *", false)
+	cronworkflow.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "main", Container: &corev1.Container{Env: getRedactEnv()}},
+		{Name: "script", Script: &wfv1alpha1.ScriptTemplate{Container: corev1.Container{Env: getRedactEnv()}}},
+	}
+	items := mergeItems([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{cronworkflow})
+
+	pf := buildPrintformat(items, documentOptions{redact: regexp.MustCompile(defaultRedactPattern)})
+
+	gotCronJob := pf.Items[0].(cronJobDocument)
+	if diff := cmp.Diff(getWantRedactEnv(), gotCronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("CronJob env mismatch (-want +got):\n%s", diff)
+	}
+	gotCronWorkflow := pf.Items[1].(cronWorkflowDocument)
+	if diff := cmp.Diff(getWantRedactEnv(), gotCronWorkflow.Spec.WorkflowSpec.Templates[0].Container.Env); diff != "" {
+		t.Errorf("CronWorkflow container env mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(getWantRedactEnv(), gotCronWorkflow.Spec.WorkflowSpec.Templates[1].Script.Env); diff != "" {
+		t.Errorf("CronWorkflow script env mismatch (-want +got):\n%s", diff)
+	}
+
+	// The objects used for matching keep the original values.
+	if diff := cmp.Diff(getRedactEnv(), items[0].cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env); diff != "" {
+		t.Errorf("source CronJob was mutated (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(getRedactEnv(), items[1].cronWorkflow.Spec.WorkflowSpec.Templates[0].Container.Env); diff != "" {
+		t.Errorf("source CronWorkflow was mutated (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_redact_lastApplied(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "backup", "0 1 * * *", false)
+	cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main", Env: getRedactEnv()}}
+	manifest, err := json.Marshal(cronjob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cronjob.Annotations = map[string]string{corev1.LastAppliedConfigAnnotation: string(manifest), "team": "infra"}
+	cronworkflow := getCronWorkflow("ns-a", "report", "0 2 * * *", false)
+	cronworkflow.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{{Name: "main", Container: &corev1.Container{Env: getRedactEnv()}}}
+	manifest, err = json.Marshal(cronworkflow)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cronworkflow.Annotations = map[string]string{corev1.LastAppliedConfigAnnotation: string(manifest)}
+	c := newFakeClients([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{cronworkflow})
+
+	for _, output := range []string{"json", "yaml"} {
+		for _, raw := range []bool{false, true} {
+			args := append([]string{"-n", "ns-a", "--redact", "-o", output}, runPeriod...)
+			if raw {
+				args = append(args, "--raw")
+			}
+			stdout, _, err := runFake(c, args...)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if strings.Contains(stdout, "hunter2") {
+				t.Errorf("-o %s, raw %t: want no secret value, got %s", output, raw, stdout)
+			}
+			if !strings.Contains(stdout, "infra") {
+				t.Errorf("-o %s, raw %t: want the other annotations kept, got %s", output, raw, stdout)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// defaultRegistry is the registry of the images without one, e.g. 'busybox'.
+const defaultRegistry = "docker.io"
+
+// Parse the image reference into the registry and the repository without the
+// tag and the digest, like the container runtimes do:
+//
+//   - The first component is the registry only when it has a '.' or a ':',
+//     e.g. 'ghcr.io' and 'localhost:5000', or is 'localhost'.
+//   - Otherwise the registry is docker.io, and the official images without a
+//     '/' are in 'library/', e.g. 'busybox' is 'docker.io/library/busybox'.
+//   - 'index.docker.io' is docker.io.
+func parseImageRegistry(image string) (registry, repository string) {
+	name := image
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name = name[:i]
+	}
+	// The tag is after the last ':' following the last '/', so that the port
+	// of the registry is kept.
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name = name[:i]
+	}
+
+	first, rest, found := strings.Cut(name, "/")
+	if !found || (!strings.ContainsAny(first, ".:") && first != "localhost") {
+		registry, repository = defaultRegistry, name
+	} else {
+		registry, repository = strings.ToLower(first), rest
+	}
+	if registry == "index.docker.io" {
+		registry = defaultRegistry
+	}
+	if registry == defaultRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository
+}
+
+// Parse the '--registry-allowlist' value, a comma separated list of
+// registries such as 'registry.example.com', optionally with a repository
+// prefix such as 'ghcr.io/ourorg'.
+func parseRegistryAllowlist(value string) ([]string, error) {
+	allowlist := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSuffix(strings.TrimSpace(entry), "/")
+		if entry == "" {
+			return nil, fmt.Errorf("failed to parse '--registry-allowlist' value: empty registry in '%s'", value)
+		}
+		registry, prefix, _ := strings.Cut(entry, "/")
+		registry = strings.ToLower(registry)
+		if registry == "index.docker.io" {
+			registry = defaultRegistry
+		}
+		if prefix != "" {
+			registry += "/" + prefix
+		}
+		allowlist = append(allowlist, registry)
+	}
+	return allowlist, nil
+}
+
+// Whether the registry of the image, or the registry and a prefix of the
+// repository by the path components, is in the allowlist.
+func isImageAllowed(image string, allowlist []string) bool {
+	registry, repository := parseImageRegistry(image)
+	name := registry + "/" + repository
+	for _, entry := range allowlist {
+		if entry == registry || strings.HasPrefix(name, entry+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Get the images of the item whose registries are not in the allowlist, in
+// the order of appearance.
+func getDisallowedImages(item item, allowlist []string) []string {
+	var images []string
+	switch {
+	case item.cronJob != nil:
+		images = getImages(getCronJobContainers(item.cronJob))
+	case item.cronWorkflow != nil:
+		images = getImages(getCronWorkflowContainers(item.cronWorkflow))
+	}
+	disallowed := []string{}
+	for _, image := range images {
+		if !isImageAllowed(image, allowlist) {
+			disallowed = append(disallowed, image)
+		}
+	}
+	return disallowed
+}
+
+// Keep only items with any image whose registry is not in the allowlist. The
+// images of the init containers count, as they are pulled as well.
+func registryAllowlistFilter(allowlist []string) filter {
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return len(getDisallowedImages(item{cronJob: cronjob}, allowlist)) > 0
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return len(getDisallowedImages(item{cronWorkflow: cronworkflow}, allowlist)) > 0
+		},
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func Test_parseImageRegistry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		image      string
+		registry   string
+		repository string
+	}{
+		{image: "busybox", registry: "docker.io", repository: "library/busybox"},
+		{image: "busybox:1.36", registry: "docker.io", repository: "library/busybox"},
+		{image: "bitnami/kubectl:1.28", registry: "docker.io", repository: "bitnami/kubectl"},
+		{image: "docker.io/busybox", registry: "docker.io", repository: "library/busybox"},
+		{image: "index.docker.io/library/busybox", registry: "docker.io", repository: "library/busybox"},
+		{image: "ghcr.io/ourorg/app:v1", registry: "ghcr.io", repository: "ourorg/app"},
+		{image: "registry.example.com:5000/team/app", registry: "registry.example.com:5000", repository: "team/app"},
+		{image: "registry.example.com:5000/team/app:v1", registry: "registry.example.com:5000", repository: "team/app"},
+		{image: "localhost/app", registry: "localhost", repository: "app"},
+		{image: "localhost:5000/app:latest", registry: "localhost:5000", repository: "app"},
+		{image: "Registry.Example.com/app", registry: "registry.example.com", repository: "app"},
+		{image: "busybox@sha256:0123456789abcdef", registry: "docker.io", repository: "library/busybox"},
+		{image: "ghcr.io/ourorg/app:v1@sha256:0123456789abcdef", registry: "ghcr.io", repository: "ourorg/app"},
+		{image: "registry.example.com:5000/app@sha256:0123456789abcdef", registry: "registry.example.com:5000", repository: "app"},
+		// The first component without '.' nor ':' is a Docker Hub user.
+		{image: "myregistry/app", registry: "docker.io", repository: "myregistry/app"},
+	}
+	for _, tt := range tests {
+		registry, repository := parseImageRegistry(tt.image)
+		if registry != tt.registry || repository != tt.repository {
+			t.Errorf("parseImageRegistry(%s) = %s, %s, want %s, %s", tt.image, registry, repository, tt.registry, tt.repository)
+		}
+	}
+}
+
+func Test_isImageAllowed(t *testing.T) {
+	t.Parallel()
+	allowlist, err := parseRegistryAllowlist("registry.example.com, ghcr.io/ourorg/ ,docker.io/library,localhost:5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{image: "registry.example.com/team/app:v1", want: true},
+		// Another port is another registry.
+		{image: "registry.example.com:5000/team/app", want: false},
+		{image: "ghcr.io/ourorg/app", want: true},
+		{image: "ghcr.io/ourorg-fork/app", want: false},
+		{image: "ghcr.io/other/app", want: false},
+		{image: "busybox", want: true},
+		{image: "bitnami/kubectl", want: false},
+		{image: "localhost:5000/app@sha256:0123456789abcdef", want: true},
+	}
+	for _, tt := range tests {
+		if got := isImageAllowed(tt.image, allowlist); got != tt.want {
+			t.Errorf("isImageAllowed(%s) = %v, want %v", tt.image, got, tt.want)
+		}
+	}
+
+	if _, err := parseRegistryAllowlist("ghcr.io,,docker.io"); err == nil {
+		t.Errorf("want an error for an empty registry")
+	}
+}
+
+func Test_run_registryAllowlist(t *testing.T) {
+	t.Parallel()
+	allowed := getCronJob("ns-a", "allowed", "0 1 * * *", false)
+	allowed.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Name: "app", Image: "ghcr.io/ourorg/app:v1"}}
+	mixed := getCronJob("ns-a", "mixed", "0 1 * * *", false)
+	mixed.Spec.JobTemplate.Spec.Template.Spec.InitContainers = []corev1.Container{{Name: "setup", Image: "busybox"}}
+	mixed.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{
+		{Name: "app", Image: "ghcr.io/ourorg/app:v1"},
+		{Name: "proxy", Image: "quay.io/proxy/proxy:2"},
+	}
+	c := newFakeClients([]batchv1.CronJob{allowed, mixed}, nil)
+	stdout, _, err := runFake(c, append(runPeriod, "--registry-allowlist", "ghcr.io/ourorg", "--no-headers")...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "ns-a   mixed   0 1 * * *   false   CronJob   busybox,quay.io/proxy/proxy:2\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
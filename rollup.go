@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// unlabelledGroup is the group of '--rollup-label' of the items without the
+// label on themselves nor on their namespaces.
+const unlabelledGroup = "<unlabelled>"
+
+type rollupGroup struct {
+	Value string `json:"value"`
+	Items int    `json:"items"`
+	Fires int    `json:"fires"`
+	// FiresTruncated is set when the fires of any item are capped at
+	// maxFireOccurrences, so Fires is a lower bound.
+	FiresTruncated bool     `json:"firesTruncated,omitempty"`
+	Namespaces     []string `json:"namespaces"`
+}
+
+type rollupReport struct {
+	ApiVersion string         `json:"apiVersion"`
+	Window     documentWindow `json:"window"`
+	Label      string         `json:"label"`
+	Groups     []rollupGroup  `json:"groups"`
+}
+
+// Group the items by the value of the label on the item, or on its namespace
+// when the item has none or an empty one, counting the items and their fires
+// in the from-to period. The namespaces are only looked up for the items
+// without the label, and not at all when client is nil, e.g. with
+// '--fixtures'. The groups are sorted by the value, and unlabelledGroup is
+// last.
+func rollupItems(ctx context.Context, client kubernetes.Interface, items []item, label string, from, to time.Time, bounds boundaries) ([]rollupGroup, error) {
+	values := make([]string, len(items))
+	var lookup []string
+	for i, item := range items {
+		obj := item.object()
+		if v := obj.GetLabels()[label]; v != "" {
+			values[i] = v
+			continue
+		}
+		lookup = append(lookup, obj.GetNamespace())
+	}
+	namespaceLabels := map[string]map[string]string{}
+	if client != nil && len(lookup) > 0 {
+		var err error
+		namespaceLabels, err = getNamespaceLabels(ctx, client, lookup)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	groups := map[string]*rollupGroup{}
+	namespaces := map[string]map[string]struct{}{}
+	for i, item := range items {
+		obj := item.object()
+		value := values[i]
+		if value == "" {
+			value = unlabelledGroup
+			if v := namespaceLabels[obj.GetNamespace()][label]; v != "" {
+				value = v
+			}
+		}
+		fires, truncated, err := getItemFires(item, from, to, bounds)
+		if err != nil {
+			return nil, err
+		}
+		g, ok := groups[value]
+		if !ok {
+			g = &rollupGroup{Value: value}
+			groups[value] = g
+			namespaces[value] = map[string]struct{}{}
+		}
+		g.Items++
+		g.Fires += len(fires)
+		g.FiresTruncated = g.FiresTruncated || truncated
+		namespaces[value][obj.GetNamespace()] = struct{}{}
+	}
+
+	keys := maps.Keys(groups)
+	sort.Slice(keys, func(i, j int) bool {
+		if (keys[i] == unlabelledGroup) != (keys[j] == unlabelledGroup) {
+			return keys[j] == unlabelledGroup
+		}
+		return keys[i] < keys[j]
+	})
+	ret := make([]rollupGroup, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		g.Namespaces = maps.Keys(namespaces[key])
+		sort.Strings(g.Namespaces)
+		ret = append(ret, *g)
+	}
+	return ret, nil
+}
+
+func printRollup(stdout io.Writer, output string, noHeaders bool, label string, from, to time.Time, groups []rollupGroup) error {
+	report := rollupReport{ApiVersion: "v1", Window: documentWindow{From: from, To: to}, Label: label, Groups: groups}
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{label, "Items", "Fires", "Namespaces"}, "\t"))
+	}
+	for _, g := range groups {
+		fires := strconv.Itoa(g.Fires)
+		if g.FiresTruncated {
+			fires = ">" + fires
+		}
+		fmt.Fprintln(tw, strings.Join([]string{g.Value, strconv.Itoa(g.Items), fires, strings.Join(g.Namespaces, ",")}, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_rollupItems(t *testing.T) {
+	t.Parallel()
+	// Labelled on the object.
+	backup := getCronJob("ns-a", "backup", "0 * * * *", false)
+	backup.Labels = map[string]string{"team": "infra"}
+	// Labelled on the namespace, and the object wins.
+	report := getCronWorkflow("ns-b", "report", "0 1 * * *", false)
+	report.Labels = map[string]string{"team": "infra"}
+	// Labelled on the namespace only.
+	cleanup := getCronJob("ns-b", "cleanup", "0 2 * * *", false)
+	// Labelled on neither.
+	nightly := getCronJob("ns-c", "nightly", "0 3 * * *", false)
+	items := mergeItems([]batchv1.CronJob{backup, cleanup, nightly}, []wfv1alpha1.CronWorkflow{report})
+
+	c := newFakeClients(nil, nil)
+	for _, ns := range []*corev1.Namespace{
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns-b", Labels: map[string]string{"team": "data"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "ns-c", Labels: map[string]string{"owner": "someone"}}},
+	} {
+		if _, err := c.k8s.CoreV1().Namespaces().Create(context.Background(), ns, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z")
+	got, err := rollupItems(context.Background(), c.k8s, items, "team", from, to, boundaries{})
+	if err != nil {
+		t.Fatalf("rollupItems() error = %v", err)
+	}
+	want := []rollupGroup{
+		{Value: "data", Items: 1, Fires: 1, Namespaces: []string{"ns-b"}},
+		{Value: "infra", Items: 2, Fires: 8, Namespaces: []string{"ns-a", "ns-b"}},
+		{Value: unlabelledGroup, Items: 1, Fires: 1, Namespaces: []string{"ns-c"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	// Without the clients, e.g. with '--fixtures', the namespaces aren't looked up.
+	got, err = rollupItems(context.Background(), nil, items, "team", from, to, boundaries{})
+	if err != nil {
+		t.Fatalf("rollupItems() error = %v", err)
+	}
+	if len(got) != 2 || got[1].Value != unlabelledGroup || got[1].Items != 2 {
+		t.Errorf("rollupItems() = %+v", got)
+	}
+}
+
+func Test_rollupItems_namespaceError(t *testing.T) {
+	t.Parallel()
+	cleanup := getCronJob("ns-missing", "cleanup", "0 2 * * *", false)
+	_, err := rollupItems(context.Background(), newFakeClients(nil, nil).k8s, []item{{cronJob: &cleanup}}, "team", getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T06:00:00Z"), boundaries{})
+	if err == nil {
+		t.Errorf("want an error for the missing namespace")
+	}
+}
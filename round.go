@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Parse the '--round' value. It must divide a day, so that the rounded times
+// are aligned to the wall clock, e.g. ':00, :05, :10' for '5m'.
+func parseRound(value string) (time.Duration, error) {
+	d, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse '--round' value: %w", err)
+	}
+	if d <= 0 || (24*time.Hour)%d != 0 {
+		return 0, fmt.Errorf("'--round' must be a positive duration dividing 24h, e.g. 1m, 5m, 1h: %s", value)
+	}
+	return d, nil
+}
+
+// Round down t to a multiple of d in the wall clock of its zone. A zero d
+// returns t as is.
+func floorTime(t time.Time, d time.Duration) time.Time {
+	if d <= 0 {
+		return t
+	}
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(d).Add(-shift)
+}
+
+// Round up t to a multiple of d in the wall clock of its zone. A zero d
+// returns t as is.
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	floor := floorTime(t, d)
+	if floor.Equal(t) {
+		return t
+	}
+	return floor.Add(d)
+}
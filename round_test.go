@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func Test_floorTime_ceilTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		t         string
+		d         time.Duration
+		wantFloor string
+		wantCeil  string
+	}{
+		{
+			name:      "minutes",
+			t:         "2023-01-24T13:47:23Z",
+			d:         time.Minute,
+			wantFloor: "2023-01-24T13:47:00Z",
+			wantCeil:  "2023-01-24T13:48:00Z",
+		},
+		{
+			name:      "ceil across an hour boundary",
+			t:         "2023-01-24T13:57:23Z",
+			d:         5 * time.Minute,
+			wantFloor: "2023-01-24T13:55:00Z",
+			wantCeil:  "2023-01-24T14:00:00Z",
+		},
+		{
+			name:      "ceil across a day boundary",
+			t:         "2023-01-24T23:00:01Z",
+			d:         time.Hour,
+			wantFloor: "2023-01-24T23:00:00Z",
+			wantCeil:  "2023-01-25T00:00:00Z",
+		},
+		{
+			name:      "already aligned",
+			t:         "2023-01-24T14:00:00Z",
+			d:         time.Hour,
+			wantFloor: "2023-01-24T14:00:00Z",
+			wantCeil:  "2023-01-24T14:00:00Z",
+		},
+		{
+			name:      "wall clock of a half-hour offset",
+			t:         "2023-01-24T13:47:23+05:30",
+			d:         time.Hour,
+			wantFloor: "2023-01-24T13:00:00+05:30",
+			wantCeil:  "2023-01-24T14:00:00+05:30",
+		},
+		{
+			name:      "zero is disabled",
+			t:         "2023-01-24T13:47:23Z",
+			d:         0,
+			wantFloor: "2023-01-24T13:47:23Z",
+			wantCeil:  "2023-01-24T13:47:23Z",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			in, err := time.Parse(time.RFC3339, tt.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := floorTime(in, tt.d).Format(time.RFC3339); got != tt.wantFloor {
+				t.Errorf("floorTime() = %v, want %v", got, tt.wantFloor)
+			}
+			if got := ceilTime(in, tt.d).Format(time.RFC3339); got != tt.wantCeil {
+				t.Errorf("ceilTime() = %v, want %v", got, tt.wantCeil)
+			}
+		})
+	}
+}
+
+func Test_round_equalFromTo(t *testing.T) {
+	t.Parallel()
+	// An equal from-to widens to the granules around it, unless it is aligned.
+	at := getTime("2023-01-24T13:47:23Z")
+	from, to := floorTime(at, 5*time.Minute), ceilTime(at, 5*time.Minute)
+	if !from.Equal(getTime("2023-01-24T13:45:00Z")) || !to.Equal(getTime("2023-01-24T13:50:00Z")) {
+		t.Errorf("rounded period = %v - %v, want 13:45 - 13:50", from, to)
+	}
+	// A job at 13:50 is now included, as isInclude includes 'to'.
+	if !isInclude(getSchedule("50 13 * * *"), from, to, boundaries{}) {
+		t.Error("isInclude() = false, want true")
+	}
+
+	aligned := getTime("2023-01-24T13:45:00Z")
+	from, to = floorTime(aligned, 5*time.Minute), ceilTime(aligned, 5*time.Minute)
+	if !from.Equal(aligned) || !to.Equal(aligned) {
+		t.Errorf("rounded period = %v - %v, want both %v", from, to, aligned)
+	}
+}
+
+func Test_parseRound(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "1m", want: time.Minute},
+		{value: "5m", want: 5 * time.Minute},
+		{value: "1h", want: time.Hour},
+		{value: "7m", wantErr: true},
+		{value: "0s", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "hourly", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseRound(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRound() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("parseRound() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_printJSON_window(t *testing.T) {
+	t.Parallel()
+	window := &documentWindow{From: getTime("2023-01-24T13:45:00Z"), To: getTime("2023-01-24T13:50:00Z"), Round: "5m"}
+	var got bytes.Buffer
+	if err := printJSON(&got, nil, documentOptions{window: window}); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Window map[string]string `json:"window"`
+	}
+	if err := json.Unmarshal(got.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"from": "2023-01-24T13:45:00Z", "to": "2023-01-24T13:50:00Z", "round": "5m"}
+	for k, v := range want {
+		if doc.Window[k] != v {
+			t.Errorf("window.%s = %q, want %q", k, doc.Window[k], v)
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofakev1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/typed/workflow/v1alpha1/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newRunClients returns the fake clients of the CronJobs and CronWorkflows
+// used by the run tests.
+func newRunClients() *clients {
+	cronjob1 := getCronJob("ns-a", "backup", "0 1 * * *", false)
+	cronjob1.Labels = map[string]string{"app": "a"}
+	cronjob2 := getCronJob("ns-b", "cleanup", "0 2 * * *", true)
+	cronjob3 := getCronJob("ns-b", "nightly", "0 12 * * *", false)
+	cronworkflow := getCronWorkflow("ns-b", "report", "0 3 * * *", false)
+	cronworkflow.Labels = map[string]string{"app": "a"}
+	return newFakeClients(
+		[]batchv1.CronJob{cronjob1, cronjob2, cronjob3},
+		[]wfv1alpha1.CronWorkflow{cronworkflow},
+	)
+}
+
+// Run the command with the clients instead of building them from the flags.
+func runFake(c *clients, args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+		return c, nil
+	}
+	err := runWithClients(&stdout, &stderr, append([]string{commandName}, args...), buildClients)
+	return stdout.String(), stderr.String(), err
+}
+
+var runPeriod = []string{"--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}
+
+func Test_run_clients(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "all namespaces",
+			args: []string{"--no-headers"},
+			want: "" +
+				"ns-a   backup    0 1 * * *   false   CronJob\n" +
+				"ns-b   cleanup   0 2 * * *   true    CronJob\n" +
+				"ns-b   report    0 3 * * *   false   CronWorkflow\n",
+		},
+		{
+			name: "namespace",
+			args: []string{"--no-headers", "-n", "ns-b"},
+			want: "" +
+				"ns-b   cleanup   0 2 * * *   true    CronJob\n" +
+				"ns-b   report    0 3 * * *   false   CronWorkflow\n",
+		},
+		{
+			name: "namespaces",
+			args: []string{"--no-headers", "--namespaces", "ns-a,ns-c"},
+			want: "ns-a   backup   0 1 * * *   false   CronJob\n",
+		},
+		{
+			name: "selector",
+			args: []string{"--no-headers", "-l", "app=a"},
+			want: "" +
+				"ns-a   backup   0 1 * * *   false   CronJob\n" +
+				"ns-b   report   0 3 * * *   false   CronWorkflow\n",
+		},
+		{
+			name: "filter",
+			args: []string{"--no-headers", "--daily-between", "01:30-02:30"},
+			want: "ns-b   cleanup   0 2 * * *   true   CronJob\n",
+		},
+		{
+			name: "mermaid",
+			args: []string{"-o", "mermaid", "-n", "ns-a"},
+			want: "" +
+				"```mermaid\n" +
+				"gantt\n" +
+				"    title Scheduled from 2023-01-24T00:00:00Z to 2023-01-24T06:00:00Z\n" +
+				"    dateFormat YYYY-MM-DDTHH:mm:ssZ\n" +
+				"    axisFormat %H:%M\n" +
+				"    section ns-a\n" +
+				"    backup (CronJob) :2023-01-24T01:00:00Z, 5m\n" +
+				"```\n",
+		},
+		{
+			name: "headers",
+			args: []string{"-n", "ns-a"},
+			want: "" +
+				"Namespace   Name     Schedule    Suspend   Kind\n" +
+				"ns-a        backup   0 1 * * *   false     CronJob\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, _, err := runFake(newRunClients(), append(tt.args, runPeriod...)...)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_clients_json(t *testing.T) {
+	t.Parallel()
+	for _, output := range []string{"json", "yaml"} {
+		output := output
+		t.Run(output, func(t *testing.T) {
+			t.Parallel()
+			got, _, err := runFake(newRunClients(), append([]string{"-o", output}, runPeriod...)...)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if output == "yaml" {
+				if !strings.Contains(got, "name: report") || strings.Contains(got, "name: nightly") {
+					t.Errorf("unexpected yaml output:\n%s", got)
+				}
+				return
+			}
+			var document struct {
+				Items []struct {
+					Kind     string            `json:"kind"`
+					Metadata metav1.ObjectMeta `json:"metadata"`
+				} `json:"items"`
+			}
+			if err := json.Unmarshal([]byte(got), &document); err != nil {
+				t.Fatalf("failed to decode the output: %v", err)
+			}
+			var names []string
+			for _, item := range document.Items {
+				names = append(names, item.Kind+"/"+item.Metadata.Namespace+"/"+item.Metadata.Name)
+			}
+			want := []string{"CronJob/ns-a/backup", "CronJob/ns-b/cleanup", "CronWorkflow/ns-b/report"}
+			if diff := cmp.Diff(want, names); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_clients_patch(t *testing.T) {
+	t.Parallel()
+	c := newRunClients()
+	if _, _, err := runFake(c, append([]string{"--suspend", "--yes", "-n", "ns-a"}, runPeriod...)...); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	cronjob, err := c.k8s.BatchV1().CronJobs("ns-a").Get(context.Background(), "backup", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get the CronJob: %v", err)
+	}
+	if cronjob.Spec.Suspend == nil || !*cronjob.Spec.Suspend {
+		t.Errorf("want the CronJob suspended, got %v", cronjob.Spec.Suspend)
+	}
+	if _, ok := cronjob.Annotations[suspendedAtAnnotation]; !ok {
+		t.Errorf("want the %s annotation, got %v", suspendedAtAnnotation, cronjob.Annotations)
+	}
+}
+
+func Test_run_clients_errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("building clients", func(t *testing.T) {
+		t.Parallel()
+		var stdout bytes.Buffer
+		buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+			return nil, errors.New("no kubeconfig")
+		}
+		err := runWithClients(&stdout, &bytes.Buffer{}, append([]string{commandName}, runPeriod...), buildClients)
+		if err == nil || err.Error() != "no kubeconfig" {
+			t.Errorf("want the error of the clients, got %v", err)
+		}
+	})
+
+	t.Run("validation before building clients", func(t *testing.T) {
+		t.Parallel()
+		buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+			t.Error("clients must not be built")
+			return nil, errors.New("unexpected")
+		}
+		args := []string{commandName, "--from", "2023-01-24T06:00:00Z", "--to", "2023-01-24T00:00:00Z"}
+		err := runWithClients(&bytes.Buffer{}, &bytes.Buffer{}, args, buildClients)
+		if err == nil || err.Error() != "'--from' '--to' times are reversed" {
+			t.Errorf("want the validation error, got %v", err)
+		}
+	})
+
+	t.Run("listing", func(t *testing.T) {
+		t.Parallel()
+		c := newRunClients()
+		failList(&c.k8s.(*k8sfake.Clientset).Fake, "cronjobs", errors.New("connection refused"))
+		failList(c.argo.(*argofakev1alpha1.FakeArgoprojV1alpha1).Fake, "cronworkflows", errors.New("connection refused"))
+		got, _, err := runFake(c, append([]string{"-n", "ns-a"}, runPeriod...)...)
+		if err == nil || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("want the error of the listing, got %v", err)
+		}
+		if got != "" {
+			t.Errorf("want nothing printed, got %q", got)
+		}
+	})
+}
+
+func Test_run_clients_partial(t *testing.T) {
+	t.Parallel()
+	newClients := func() *clients {
+		c := newRunClients()
+		failList(c.argo.(*argofakev1alpha1.FakeArgoprojV1alpha1).Fake, "cronworkflows", errors.New("connection refused"))
+		return c
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		t.Parallel()
+		got, stderr, err := runFake(newClients(), append([]string{"--no-headers", "-n", "ns-b"}, runPeriod...)...)
+		if !errors.Is(err, errPartialFailure) {
+			t.Errorf("want errPartialFailure, got %v", err)
+		}
+		if diff := cmp.Diff("ns-b   cleanup   0 2 * * *   true   CronJob\n", got); diff != "" {
+			t.Errorf("(-want +got):\n%s", diff)
+		}
+		wantWarning := "warning: failed to get CronWorkflow in 'ns-b' namespace: connection refused\n"
+		if diff := cmp.Diff(wantWarning, stderr); diff != "" {
+			t.Errorf("stderr (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		t.Parallel()
+		got, _, err := runFake(newClients(), append([]string{"--strict", "-n", "ns-b"}, runPeriod...)...)
+		if err == nil || errors.Is(err, errPartialFailure) || !strings.Contains(err.Error(), "connection refused") {
+			t.Errorf("want the error of the listing, got %v", err)
+		}
+		if got != "" {
+			t.Errorf("want nothing printed, got %q", got)
+		}
+	})
+}
+
+// failingWriter fails every write, like a closed pipe.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func Test_run_outputWriteError(t *testing.T) {
+	t.Parallel()
+	for _, output := range []string{"json", "yaml"} {
+		buildClients := func(*genericclioptions.ConfigFlags) (*clients, error) {
+			return newRunClients(), nil
+		}
+		var stderr bytes.Buffer
+		args := append([]string{commandName, "-o", output}, runPeriod...)
+		err := runWithClients(failingWriter{}, &stderr, args, buildClients)
+		if err == nil || !strings.Contains(err.Error(), "broken pipe") {
+			t.Errorf("-o %s: want the write error, got %v", output, err)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// defaultRuntimeRiskRuns is the number of the last completed Jobs looked at by
+// '--runtime-risk' without a value.
+const defaultRuntimeRiskRuns = "10"
+
+// runtimeRiskJobLimit bounds the Jobs listed in each namespace by
+// '--runtime-risk', so that a namespace with a long history is not listed in
+// full. The newest Jobs are not guaranteed to be in the first page, but the
+// Jobs kept by the history limits of the CronJobs usually fit.
+const runtimeRiskJobLimit = 1000
+
+// The ratio of the p95 duration to the interval from which the runs approach
+// the interval.
+const runtimeRiskApproachingRatio = 0.8
+
+// The risks of '--runtime-risk'.
+const (
+	runtimeExceeds     = "exceeds"
+	runtimeApproaching = "approaching"
+	runtimeOK          = "ok"
+	runtimeNoHistory   = "no-history"
+)
+
+type runtimeRisk struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Schedule  string `json:"schedule"`
+	// Interval is the typical interval of the schedule, or "" when unknown.
+	Interval string `json:"interval,omitempty"`
+	// Runs is the number of the completed Jobs the duration is estimated from.
+	Runs int `json:"runs"`
+	// P95 is the 95th percentile of the durations, or "" without runs.
+	P95 string `json:"p95,omitempty"`
+	// Ratio is P95 divided by Interval, or nil when either is unknown.
+	Ratio *float64 `json:"ratio"`
+	Risk  string   `json:"risk"`
+}
+
+type runtimeRiskReport struct {
+	ApiVersion string        `json:"apiVersion"`
+	Risks      []runtimeRisk `json:"risks"`
+}
+
+// Estimate the durations of the runs of the CronJobs from up to runs of their
+// last completed Jobs, and compare them with their typical intervals from
+// start. The Jobs are listed only in the namespaces of the CronJobs, once
+// each. The risks are sorted by the ratio, highest first, and the items
+// without the ratio are last.
+func findRuntimeRisks(ctx context.Context, c *clients, items []item, start time.Time, runs int) ([]runtimeRisk, error) {
+	risks := []runtimeRisk{}
+	jobs := map[string][]batchv1.Job{}
+	for _, item := range items {
+		if item.cronJob == nil {
+			continue
+		}
+		cronjob := item.cronJob
+		namespaceJobs, ok := jobs[cronjob.Namespace]
+		if !ok {
+			list, err := c.k8s.BatchV1().Jobs(cronjob.Namespace).List(ctx, metav1.ListOptions{Limit: runtimeRiskJobLimit})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get Jobs in '%s' namespace: %w", cronjob.Namespace, err)
+			}
+			namespaceJobs = list.Items
+			jobs[cronjob.Namespace] = namespaceJobs
+		}
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return nil, err
+		}
+		interval, _ := getTypicalInterval(sched, start)
+		durations := getLastJobDurations(namespaceJobs, cronjob, runs)
+
+		r := runtimeRisk{
+			Namespace: cronjob.Namespace,
+			Name:      cronjob.Name,
+			Schedule:  item.schedule(),
+			Runs:      len(durations),
+			Risk:      runtimeNoHistory,
+		}
+		if interval > 0 {
+			r.Interval = interval.String()
+		}
+		if len(durations) > 0 {
+			p95 := getP95(durations)
+			r.P95 = p95.String()
+			r.Risk = runtimeOK
+			if interval > 0 {
+				ratio := float64(p95) / float64(interval)
+				r.Ratio = &ratio
+				switch {
+				case ratio >= 1:
+					r.Risk = runtimeExceeds
+				case ratio >= runtimeRiskApproachingRatio:
+					r.Risk = runtimeApproaching
+				}
+			}
+		}
+		risks = append(risks, r)
+	}
+	sort.SliceStable(risks, func(i, j int) bool {
+		a, b := risks[i].Ratio, risks[j].Ratio
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a > *b
+	})
+	return risks, nil
+}
+
+// Get the durations from status.startTime to status.completionTime of up to
+// runs of the last Jobs owned by the CronJob. completionTime is set only when
+// the Job succeeds, so the failed and running ones are not counted.
+func getLastJobDurations(jobs []batchv1.Job, cronjob *batchv1.CronJob, runs int) []time.Duration {
+	var completed []*batchv1.Job
+	for i := range jobs {
+		job := &jobs[i]
+		if isOwnedBy(job.OwnerReferences, cronjob.UID) && job.Status.StartTime != nil && job.Status.CompletionTime != nil {
+			completed = append(completed, job)
+		}
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return completed[i].Status.StartTime.After(completed[j].Status.StartTime.Time)
+	})
+	if len(completed) > runs {
+		completed = completed[:runs]
+	}
+	durations := make([]time.Duration, 0, len(completed))
+	for _, job := range completed {
+		durations = append(durations, job.Status.CompletionTime.Sub(job.Status.StartTime.Time))
+	}
+	return durations
+}
+
+// Get the 95th percentile of the durations by the nearest rank.
+func getP95(durations []time.Duration) time.Duration {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(0.95 * float64(len(sorted))))
+	return sorted[rank-1]
+}
+
+func printRuntimeRisks(stdout io.Writer, output string, noHeaders bool, risks []runtimeRisk) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(runtimeRiskReport{ApiVersion: "v1", Risks: risks}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(runtimeRiskReport{ApiVersion: "v1", Risks: risks})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Schedule", "Interval", "Runs", "P95", "Ratio", "Risk"}, "\t"))
+	}
+	for _, r := range risks {
+		interval, p95, ratio := "<unknown>", "<none>", "<none>"
+		if r.Interval != "" {
+			interval = r.Interval
+		}
+		if r.P95 != "" {
+			p95 = r.P95
+		}
+		if r.Ratio != nil {
+			ratio = strconv.FormatFloat(*r.Ratio, 'f', 2, 64)
+		}
+		fmt.Fprintln(tw, strings.Join([]string{r.Namespace, r.Name, r.Schedule, interval, strconv.Itoa(r.Runs), p95, ratio, r.Risk}, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Get the Job owned by the CronJob of the UID, started at start and completed
+// after the duration, or running when it is 0.
+func getCompletedJob(namespace, name string, owner types.UID, start string, duration time.Duration) *batchv1.Job {
+	job := getJob(namespace, name, owner, start, "")
+	startTime := metav1.NewTime(getTime(start))
+	job.Status.StartTime = &startTime
+	if duration > 0 {
+		completionTime := metav1.NewTime(startTime.Add(duration))
+		job.Status.CompletionTime = &completionTime
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: "True"}}
+	}
+	return job
+}
+
+// Get the clients with an hourly CronJob running longer than an hour at times,
+// a CronJob every 10 minutes approaching it, a daily one well within a day, one
+// without history, and a CronWorkflow, which is not looked at.
+func newRuntimeClients(t *testing.T) *clients {
+	t.Helper()
+	slow := getCronJob("ns-a", "slow", "0 * * * *", false)
+	slow.UID = "uid-slow"
+	near := getCronJob("ns-a", "near", "*/10 * * * *", false)
+	near.UID = "uid-near"
+	fast := getCronJob("ns-b", "fast", "0 1 * * *", false)
+	fast.UID = "uid-fast"
+	fresh := getCronJob("ns-b", "fresh", "0 2 * * *", false)
+	fresh.UID = "uid-fresh"
+	etl := getCronWorkflow("ns-a", "etl", "30 * * * *", false)
+	c := newFakeClients([]batchv1.CronJob{fast, fresh, near, slow}, []wfv1alpha1.CronWorkflow{etl})
+
+	jobs := []*batchv1.Job{
+		getCompletedJob("ns-a", "slow-0", "uid-slow", "2023-01-23T00:00:00Z", 50*time.Minute),
+		getCompletedJob("ns-a", "slow-1", "uid-slow", "2023-01-23T01:00:00Z", 70*time.Minute),
+		getCompletedJob("ns-a", "slow-2", "uid-slow", "2023-01-23T02:00:00Z", 40*time.Minute),
+		getCompletedJob("ns-a", "slow-3", "uid-slow", "2023-01-23T03:00:00Z", 55*time.Minute),
+		getCompletedJob("ns-a", "slow-4", "uid-slow", "2023-01-23T04:00:00Z", 0), // running
+		getCompletedJob("ns-a", "near-0", "uid-near", "2023-01-23T00:00:00Z", 8*time.Minute),
+		getCompletedJob("ns-a", "near-1", "uid-near", "2023-01-23T00:10:00Z", 2*time.Minute),
+		getCompletedJob("ns-a", "near-2", "uid-near", "2023-01-23T00:20:00Z", 3*time.Minute),
+		getCompletedJob("ns-b", "fast-0", "uid-fast", "2023-01-23T01:00:00Z", 5*time.Minute),
+		getCompletedJob("ns-b", "fast-1", "uid-fast", "2023-01-24T01:00:00Z", 0), // failed
+		getCompletedJob("ns-b", "manual", "", "2023-01-23T02:00:00Z", 30*time.Hour),
+	}
+	for _, job := range jobs {
+		if _, err := c.k8s.BatchV1().Jobs(job.Namespace).Create(context.Background(), job, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return c
+}
+
+func Test_run_runtimeRisk(t *testing.T) {
+	t.Parallel()
+	stdout, _, err := runFake(newRuntimeClients(t), append(runPeriod, "--runtime-risk")...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "" +
+		"Namespace   Name    Schedule       Interval   Runs   P95       Ratio    Risk\n" +
+		"ns-a        slow    0 * * * *      1h0m0s     4      1h10m0s   1.17     exceeds\n" +
+		"ns-a        near    */10 * * * *   10m0s      3      8m0s      0.80     approaching\n" +
+		"ns-b        fast    0 1 * * *      24h0m0s    1      5m0s      0.00     ok\n" +
+		"ns-b        fresh   0 2 * * *      24h0m0s    0      <none>    <none>   no-history\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+
+	if _, _, err := runFake(newRuntimeClients(t), append(runPeriod, "--runtime-risk=0")...); err == nil || err.Error() != "'--runtime-risk' must be positive" {
+		t.Errorf("error = %v", err)
+	}
+}
+
+func Test_findRuntimeRisks_runs(t *testing.T) {
+	t.Parallel()
+	c := newRuntimeClients(t)
+	slow := getCronJob("ns-a", "slow", "0 * * * *", false)
+	slow.UID = "uid-slow"
+	// Only the last 2 completed Jobs, of 40m and 55m.
+	got, err := findRuntimeRisks(context.Background(), c, []item{{cronJob: &slow}}, getTime("2023-01-24T00:00:00Z"), 2)
+	if err != nil {
+		t.Fatalf("findRuntimeRisks() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Runs != 2 || got[0].P95 != "55m0s" || got[0].Risk != runtimeApproaching {
+		t.Errorf("findRuntimeRisks() = %+v", got)
+	}
+}
+
+func Test_getP95(t *testing.T) {
+	t.Parallel()
+	durations := make([]time.Duration, 0, 20)
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Minute)
+	}
+	if got := getP95(durations); got != 19*time.Minute {
+		t.Errorf("getP95() = %s", got)
+	}
+	if got := getP95([]time.Duration{time.Minute}); got != time.Minute {
+		t.Errorf("getP95() = %s", got)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Print the JSON Schema of the json/yaml output document.
+func printOutputSchema(stdout io.Writer) error {
+	b, err := json.MarshalIndent(outputSchema(), "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal to json: %w", err)
+	}
+	fmt.Fprintln(stdout, string(b))
+	return nil
+}
+
+// Build the JSON Schema of the output document from the printformat struct.
+func outputSchema() map[string]any {
+	s := reflectSchema(reflect.TypeOf(printformat{}))
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = fmt.Sprintf("%s output document (schemaVersion %d)", commandName, schemaVersion)
+	s["properties"].(map[string]any)["schemaVersion"].(map[string]any)["const"] = schemaVersion
+	return s
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Build the JSON Schema of the type following the encoding/json rules.
+// Interface types accept any value.
+func reflectSchema(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Pointer {
+		return reflectSchema(t.Elem())
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		addStructProperties(t, properties, &required)
+		s := map[string]any{"type": "object", "properties": properties}
+		if len(required) != 0 {
+			s["required"] = required
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": reflectSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": reflectSchema(t.Elem())}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+}
+
+func addStructProperties(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name are inlined.
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructProperties(ft, properties, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		properties[name] = reflectSchema(f.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_printOutputSchema(t *testing.T) {
+	t.Parallel()
+	var got bytes.Buffer
+	if err := printOutputSchema(&got); err != nil {
+		t.Fatal(err)
+	}
+	assertGolden(t, "schema.golden.json", got.Bytes())
+}
+
+func Test_reflectSchema(t *testing.T) {
+	t.Parallel()
+	type Embedded struct {
+		Inlined string `json:"inlined"`
+	}
+	type sample struct {
+		Embedded
+		Name     string            `json:"name"`
+		Count    *int              `json:"count,omitempty"`
+		Ratio    float64           `json:"ratio"`
+		Enabled  bool              `json:"enabled"`
+		Tags     []string          `json:"tags"`
+		Labels   map[string]string `json:"labels,omitempty"`
+		At       time.Time         `json:"at"`
+		Anything any               `json:"anything"`
+		Ignored  string            `json:"-"`
+		private  string
+	}
+
+	got := reflectSchema(reflect.TypeOf(sample{}))
+	want := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"inlined":  map[string]any{"type": "string"},
+			"name":     map[string]any{"type": "string"},
+			"count":    map[string]any{"type": "integer"},
+			"ratio":    map[string]any{"type": "number"},
+			"enabled":  map[string]any{"type": "boolean"},
+			"tags":     map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"labels":   map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}},
+			"at":       map[string]any{"type": "string", "format": "date-time"},
+			"anything": map[string]any{},
+		},
+		"required": []string{"inlined", "name", "ratio", "enabled", "tags", "at", "anything"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("reflectSchema() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_outputSchema_matchesDocument(t *testing.T) {
+	t.Parallel()
+	// Every top-level key of the document must be declared in the schema.
+	var doc bytes.Buffer
+	if err := printJSON(&doc, nil, documentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(doc.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	properties := outputSchema()["properties"].(map[string]any)
+	for key := range got {
+		if _, ok := properties[key]; !ok {
+			t.Errorf("document key %q is not declared in the schema", key)
+		}
+	}
+	if got["schemaVersion"] != float64(schemaVersion) {
+		t.Errorf("schemaVersion = %v, want %d", got["schemaVersion"], schemaVersion)
+	}
+}
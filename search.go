@@ -0,0 +1,128 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The sort keys of '--sort-by'.
+const (
+	sortByName  = "name"
+	sortByScore = "score"
+)
+
+// The scores of fuzzyScore, loosely following fzf: every matched character
+// scores, and more when it starts a word or follows the previous match, while
+// every character skipped between two matches costs.
+const (
+	scoreMatch       = 16
+	bonusBoundary    = 8
+	bonusConsecutive = 6
+	penaltyGap       = 1
+)
+
+// Score how well the term matches the text as a case-insensitive subsequence,
+// e.g. 'setl' matches 'nightly-settlement'. The best alignment of the
+// characters is scored, so that the matches at the start of the words and
+// the contiguous ones are preferred. It returns false when the text doesn't
+// contain all the characters of the term in order. An empty term matches
+// anything with zero.
+func fuzzyScore(term, text string) (int, bool) {
+	pattern := []rune(strings.ToLower(term))
+	runes := []rune(strings.ToLower(text))
+	if len(pattern) == 0 {
+		return 0, true
+	}
+	if len(pattern) > len(runes) {
+		return 0, false
+	}
+
+	// best[j] is the best score of the pattern so far with its last character
+	// matched at runes[j], or unmatched when ok[j] is false.
+	best := make([]int, len(runes))
+	ok := make([]bool, len(runes))
+	for j, r := range runes {
+		if r == pattern[0] {
+			best[j], ok[j] = scoreMatch+boundaryBonus(runes, j), true
+		}
+	}
+	for i := 1; i < len(pattern); i++ {
+		next := make([]int, len(runes))
+		nextOK := make([]bool, len(runes))
+		for j := i; j < len(runes); j++ {
+			if runes[j] != pattern[i] {
+				continue
+			}
+			for k := i - 1; k < j; k++ {
+				if !ok[k] {
+					continue
+				}
+				score := best[k] + scoreMatch + boundaryBonus(runes, j)
+				if k == j-1 {
+					score += bonusConsecutive
+				} else {
+					score -= penaltyGap * (j - k - 1)
+				}
+				if !nextOK[j] || score > next[j] {
+					next[j], nextOK[j] = score, true
+				}
+			}
+		}
+		best, ok = next, nextOK
+	}
+
+	score, found := 0, false
+	for j := range runes {
+		if ok[j] && (!found || best[j] > score) {
+			score, found = best[j], true
+		}
+	}
+	return score, found
+}
+
+// The bonus of the character starting a word: the first one, or the one after
+// a separator such as '-' or '/'.
+func boundaryBonus(runes []rune, j int) int {
+	if j == 0 {
+		return bonusBoundary
+	}
+	prev := runes[j-1]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return bonusBoundary
+	}
+	return 0
+}
+
+// Keep only the items whose 'NAMESPACE/NAME' matches the term of '--search'
+// by fuzzyScore. They are sorted by the score, the best first, with byScore,
+// and the shorter one first on a tie like fzf does. They stay in the order of
+// the items otherwise.
+func searchItems(items []item, term string, byScore bool) []item {
+	type scored struct {
+		item   item
+		score  int
+		length int
+	}
+	matched := []scored{}
+	for _, item := range items {
+		obj := item.object()
+		text := obj.GetNamespace() + "/" + obj.GetName()
+		if score, ok := fuzzyScore(term, text); ok {
+			matched = append(matched, scored{item: item, score: score, length: len(text)})
+		}
+	}
+	if byScore {
+		sort.SliceStable(matched, func(i, j int) bool {
+			if matched[i].score != matched[j].score {
+				return matched[i].score > matched[j].score
+			}
+			return matched[i].length < matched[j].length
+		})
+	}
+	ret := make([]item, len(matched))
+	for i, m := range matched {
+		ret[i] = m.item
+	}
+	return ret
+}
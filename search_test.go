@@ -0,0 +1,164 @@
+package main
+
+import (
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_fuzzyScore_match(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		term string
+		text string
+		want bool
+	}{
+		{term: "setl", text: "batch/nightly-settlement", want: true},
+		{term: "SETL", text: "batch/Nightly-Settlement", want: true},
+		{term: "b/ns", text: "batch/nightly-settlement", want: true},
+		{term: "", text: "batch/backup", want: true},
+		{term: "lets", text: "batch/nightly-settlement", want: false},
+		{term: "backups", text: "batch/backup", want: false},
+		{term: "x", text: "batch/backup", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.term+" "+tt.text, func(t *testing.T) {
+			t.Parallel()
+			if _, got := fuzzyScore(tt.term, tt.text); got != tt.want {
+				t.Errorf("fuzzyScore(%q, %q) matched = %v, want %v", tt.term, tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_fuzzyScore_ranking(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		term          string
+		better, worse string
+	}{
+		{
+			name:   "contiguous over scattered",
+			term:   "setl",
+			better: "batch/settlement",
+			worse:  "batch/stale-trial",
+		},
+		{
+			name:   "start of a word over the middle",
+			term:   "back",
+			better: "ops/db-backup",
+			worse:  "ops/feedback",
+		},
+		{
+			name:   "start of the name over the middle of a word",
+			term:   "report",
+			better: "ops/report",
+			worse:  "ops/sales-xreport",
+		},
+		{
+			name:   "smaller gaps",
+			term:   "ns",
+			better: "ops/nightly-s",
+			worse:  "ops/nightly-long-s",
+		},
+		{
+			name:   "the best alignment, not the first one",
+			term:   "sync",
+			better: "ops/s-mirror-sync",
+			worse:  "ops/s-mirror-s-y-n-xc",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			better, ok := fuzzyScore(tt.term, tt.better)
+			if !ok {
+				t.Fatalf("%q doesn't match %q", tt.term, tt.better)
+			}
+			worse, ok := fuzzyScore(tt.term, tt.worse)
+			if !ok {
+				t.Fatalf("%q doesn't match %q", tt.term, tt.worse)
+			}
+			if better <= worse {
+				t.Errorf("want %q (%d) to score more than %q (%d) for %q", tt.better, better, tt.worse, worse, tt.term)
+			}
+		})
+	}
+}
+
+func newSearchClients() *clients {
+	return newFakeClients(
+		[]batchv1.CronJob{
+			getCronJob("batch", "nightly-settlement", "0 1 * * *", false),
+			getCronJob("batch", "stale-trial", "0 2 * * *", false),
+			getCronJob("ops", "backup", "0 3 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("batch", "settlement", "0 4 * * *", false),
+		},
+	)
+}
+
+func Test_run_search(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "sorted by name",
+			args: []string{"--search", "setl"},
+			want: "batch   nightly-settlement   0 1 * * *   false   CronJob\n" +
+				"batch   settlement           0 4 * * *   false   CronWorkflow\n" +
+				"batch   stale-trial          0 2 * * *   false   CronJob\n",
+		},
+		{
+			name: "sorted by score",
+			args: []string{"--search", "SETL", "--sort-by", "score"},
+			want: "batch   settlement           0 4 * * *   false   CronWorkflow\n" +
+				"batch   nightly-settlement   0 1 * * *   false   CronJob\n" +
+				"batch   stale-trial          0 2 * * *   false   CronJob\n",
+		},
+		{
+			name: "with the namespace",
+			args: []string{"--search", "o/bk"},
+			want: "ops   backup   0 3 * * *   false   CronJob\n",
+		},
+		{
+			name:    "score without search",
+			args:    []string{"--sort-by", "score"},
+			wantErr: "'--sort-by score' requires '--search'",
+		},
+		{
+			name:    "unsupported sort key",
+			args:    []string{"--sort-by", "schedule"},
+			wantErr: "schedule is unsupported '--sort-by'",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, _, err := runFake(newSearchClients(), append(append([]string{"--no-headers"}, tt.args...), runPeriod...)...)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, stdout); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
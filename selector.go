@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Parse the label selector value of the flag, so that a malformed selector
+// fails with the offending text before any API call, instead of coming back
+// as an error of the API server.
+func parseSelector(name, value string) (labels.Selector, error) {
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse '--%s' value '%s': %w", name, value, err)
+	}
+	return selector, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func Test_parseSelector(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "", want: ""},
+		{value: "app=foo", want: "app=foo"},
+		{value: "app==foo,tier!=db", want: "app==foo,tier!=db"},
+		{value: "env in (prod,staging)", want: "env in (prod,staging)"},
+		{value: "!canary", want: "!canary"},
+		{value: "app in foo", wantErr: "failed to parse '--selector' value 'app in foo': "},
+		{value: "app=foo=bar", wantErr: "failed to parse '--selector' value 'app=foo=bar': "},
+		{value: "app=", want: "app="},
+		{value: "a b", wantErr: "failed to parse '--selector' value 'a b': "},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseSelector("selector", tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("want an error starting with %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSelector() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("parseSelector() = %q, want %q", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func Test_run_selectorValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		flag    string
+		value   string
+		wantErr string
+	}{
+		{flag: "--selector", value: "app in foo", wantErr: "failed to parse '--selector' value 'app in foo': "},
+		{flag: "--namespace-selector", value: "team=a=b", wantErr: "failed to parse '--namespace-selector' value 'team=a=b': "},
+		{flag: "--template-selector", value: "a b", wantErr: "failed to parse '--template-selector' value 'a b': "},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.flag, func(t *testing.T) {
+			t.Parallel()
+			// No kubeconfig is needed, it fails before building the clients.
+			args := []string{commandName, "--kubeconfig", "/nonexistent", "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z", tt.flag, tt.value}
+			err := run(&bytes.Buffer{}, &bytes.Buffer{}, args)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("want an error starting with %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+)
+
+func runServe(stdout, stderr io.Writer, args []string) (retErr error) {
+	// Parse flags
+	// -----------------
+	var (
+		listenFlag    string
+		timeoutFlag   time.Duration
+		logFormatFlag string
+		inClusterFlag bool
+	)
+	fsets := pflag.NewFlagSet(commandName+" serve", pflag.ContinueOnError)
+	fsets.SetOutput(stderr)
+	fsets.StringVarP(&listenFlag, "listen", "", ":8080", "The address to listen on for HTTP requests.")
+	durationVarP(fsets, &timeoutFlag, "timeout", "", 30*time.Second, "The maximum duration to evaluate a single HTTP request.")
+	fsets.StringVarP(&logFormatFlag, "log-format", "", logFormatText, "The format of the logs written into stderr. One of: text|json.")
+	cfgFlags := genericclioptions.NewConfigFlags(true)
+	cfgFlags.AddFlags(fsets)
+	fsets.BoolVarP(&inClusterFlag, "in-cluster", "", false, "If present, use the service account of the pod instead of the kubeconfig. It's used without a kubeconfig anyway.")
+
+	fsets.Usage = func() {
+		fmt.Fprintf(stderr, "Usage of %s serve:\n", commandName)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Example")
+		fmt.Fprintln(stderr, "  $ kubectl-cls serve --listen :8080")
+		fmt.Fprintln(stderr, "  $ curl 'localhost:8080/v1/matches?from=2023-01-24T00:00:00Z&to=2023-01-24T06:00:00Z&namespace=default'")
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, "Flags")
+		fmt.Fprint(stderr, fsets.FlagUsages())
+	}
+
+	if err := fsets.Parse(args[1:]); err != nil {
+		return err
+	}
+	if err := useInCluster(cfgFlags, inClusterFlag); err != nil {
+		return err
+	}
+	if err := validateLogFormat(logFormatFlag); err != nil {
+		return err
+	}
+	logger := newLogger(stderr, logFormatFlag, false)
+	defer func() { retErr = logCommandError(logger, logFormatFlag, retErr) }()
+
+	if timeoutFlag <= 0 {
+		return errors.New("'--timeout' must be greater than zero")
+	}
+
+	// Serve
+	// -----------------
+	// The clients are built once and reused across requests.
+	c, err := newClients(cfgFlags)
+	if err != nil {
+		return err
+	}
+
+	srv := &http.Server{
+		Addr:              listenFlag,
+		Handler:           newServeHandler(c, timeoutFlag),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info(fmt.Sprintf("listening on %s", listenFlag), "address", listenFlag)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve: %w", err)
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeoutFlag)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shutdown server: %w", err)
+		}
+		return nil
+	}
+}
+
+func newServeHandler(c *clients, timeout time.Duration) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/matches", func(w http.ResponseWriter, r *http.Request) {
+		handleMatches(w, r, c, timeout)
+	})
+	return mux
+}
+
+// GET /v1/matches?from=...&to=...&namespace=...&selector=...
+//
+// Responds with the same JSON document as '--output json'.
+func handleMatches(w http.ResponseWriter, r *http.Request, c *clients, timeout time.Duration) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method))
+		return
+	}
+
+	// Validation
+	// -----------------
+	query := r.URL.Query()
+	from, err := parseQueryTime(query.Get("from"), "from")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	to, err := parseQueryTime(query.Get("to"), "to")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if from.After(to) {
+		writeError(w, http.StatusBadRequest, errors.New("'from' 'to' times are reversed"))
+		return
+	}
+	selector := query.Get("selector")
+	if _, err := labels.Parse(selector); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse 'selector' value '%s': %w", selector, err))
+		return
+	}
+
+	// Evaluate
+	// -----------------
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	items, err := listScheduleIncluded(ctx, c, query.Get("namespace"), selector, from, to, boundaries{})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, context.DeadlineExceeded) {
+			status = http.StatusGatewayTimeout
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := printJSON(w, items, documentOptions{window: &documentWindow{From: from, To: to}}); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func parseQueryTime(value, name string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("please set '%s' query parameter", name)
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse '%s' value: %w", name, err)
+	}
+	return t.UTC(), nil // Convert to UTC for easy comparison with the schedule.
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
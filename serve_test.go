@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_handleMatches_validation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "missing from",
+			method:     http.MethodGet,
+			target:     "/v1/matches?to=2023-01-24T01:00:00Z",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "please set 'from' query parameter",
+		},
+		{
+			name:       "missing to",
+			method:     http.MethodGet,
+			target:     "/v1/matches?from=2023-01-24T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "please set 'to' query parameter",
+		},
+		{
+			name:       "invalid from",
+			method:     http.MethodGet,
+			target:     "/v1/matches?from=2023-01-24&to=2023-01-24T01:00:00Z",
+			wantStatus: http.StatusBadRequest,
+			wantError:  `failed to parse 'from' value: parsing time "2023-01-24" as "2006-01-02T15:04:05Z07:00": cannot parse "" as "T"`,
+		},
+		{
+			name:       "reversed",
+			method:     http.MethodGet,
+			target:     "/v1/matches?from=2023-01-24T01:00:00Z&to=2023-01-24T00:00:00Z",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "'from' 'to' times are reversed",
+		},
+		{
+			name:       "invalid selector",
+			method:     http.MethodGet,
+			target:     "/v1/matches?from=2023-01-24T00:00:00Z&to=2023-01-24T01:00:00Z&selector=app%20in%20(a",
+			wantStatus: http.StatusBadRequest,
+			wantError:  "failed to parse 'selector' value 'app in (a': unable to parse requirement: found '', expected: ',' or ')'",
+		},
+		{
+			name:       "method not allowed",
+			method:     http.MethodPost,
+			target:     "/v1/matches?from=2023-01-24T00:00:00Z&to=2023-01-24T01:00:00Z",
+			wantStatus: http.StatusMethodNotAllowed,
+			wantError:  "method POST is not allowed",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h := newServeHandler(newFakeClients(nil, nil), time.Second)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var body map[string]string
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal body %q: %s", rec.Body.String(), err)
+			}
+			if body["error"] != tt.wantError {
+				t.Errorf("error = %q, want %q", body["error"], tt.wantError)
+			}
+		})
+	}
+}
+
+func Test_handleMatches(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{
+			getCronJob("ns-a", "n-1", "0 0 * * *", false),
+			getCronJob("ns-a", "n-2", "0 3 * * *", false),
+			getCronJob("ns-b", "n-3", "0 0 * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			getCronWorkflow("ns-a", "n-4", "30 0 * * *", false),
+		},
+	)
+	srv := httptest.NewServer(newServeHandler(c, time.Second))
+	defer srv.Close()
+
+	res, err := http.Get(srv.URL + "/v1/matches?from=2023-01-24T00:00:00Z&to=2023-01-24T01:00:00Z&namespace=ns-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.StatusCode, http.StatusOK)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want %q", ct, "application/json")
+	}
+
+	var got struct {
+		ApiVersion string `json:"apiVersion"`
+		Items      []struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Namespace string `json:"namespace"`
+				Name      string `json:"name"`
+			} `json:"metadata"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	var gotNames []string
+	for _, item := range got.Items {
+		gotNames = append(gotNames, item.Kind+"/"+item.Metadata.Namespace+"/"+item.Metadata.Name)
+	}
+	want := []string{"CronJob/ns-a/n-1", "CronWorkflow/ns-a/n-4"}
+	if diff := cmp.Diff(want, gotNames); diff != "" {
+		t.Errorf("items mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/pflag"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// tableAcceptHeader asks the API server for a Table of partial data, or for
+// the full objects when it can't respond with one.
+const tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+
+// errTableUnsupported is returned when the Table lacks what the items are built
+// from, so that the full objects have to be listed.
+var errTableUnsupported = errors.New("the table doesn't have the required columns")
+
+// tableFlags are the flags which only need the namespace, name, schedule, time
+// zone and suspend of the items, which the server-side Table has, and the
+// flags formatting the table. Any other flag, including a new one, needs the
+// full objects.
+var tableFlags = []string{
+	"selector",
+	"namespace",
+	"from",
+	"to",
+	"window",
+	"range",
+	"today",
+	"tomorrow",
+	"this-week",
+	"week-start",
+	"exclusive-from",
+	"exclusive-to",
+	"round",
+	"output",
+	"no-headers",
+	"max-column-width",
+	"max-width",
+	"no-truncate",
+	"no-pager",
+	"display-timezone",
+	"relative-times",
+}
+
+// sessionFlags are the flags of the logs, the cache and the clock, which don't
+// change the items, so they can be used alongside tableFlags.
+var sessionFlags = []string{
+	"verbose",
+	"quiet",
+	"log-format",
+	"timing",
+	"no-cache",
+	"cache-ttl",
+	"server-time",
+}
+
+// kubeconfigFlags are the flags of genericclioptions connecting to the
+// cluster, which can be used alongside tableFlags too.
+var kubeconfigFlags = func() []string {
+	fsets := pflag.NewFlagSet("kubeconfig", pflag.ContinueOnError)
+	genericclioptions.NewConfigFlags(true).AddFlags(fsets)
+	names := []string{}
+	fsets.VisitAll(func(f *pflag.Flag) {
+		names = append(names, f.Name)
+	})
+	return names
+}()
+
+// Get the first changed flag in lexicographical order which is in none of the
+// allowed flags, sessionFlags and kubeconfigFlags, or "" when there's none.
+func unsupportedFlag(fsets *pflag.FlagSet, allowed ...[]string) string {
+	supported := map[string]bool{}
+	for _, names := range append(allowed, sessionFlags, kubeconfigFlags) {
+		for _, name := range names {
+			supported[name] = true
+		}
+	}
+	var unsupported string
+	fsets.Visit(func(f *pflag.Flag) {
+		if unsupported == "" && !supported[f.Name] {
+			unsupported = f.Name
+		}
+	})
+	return unsupported
+}
+
+// Whether the CronJobs can be listed as a server-side Table, which is only
+// the case for the plain table output with tableFlags.
+func useServerTable(fsets *pflag.FlagSet, output string) bool {
+	return output == "" && unsupportedFlag(fsets, tableFlags) == ""
+}
+
+// List CronJobs as a server-side Table, and build them from the cells of the
+// Name, Schedule, Suspend and, when present, Timezone columns and from the
+// metadata of the rows. The full objects are listed instead when the server
+// responds with them, or when the Table lacks any of the required columns.
+func listCronJobsAsTable(ctx context.Context, c *clients, namespace, selector string) ([]batchv1.CronJob, error) {
+	opts := metav1.ListOptions{LabelSelector: selector}
+	body, err := c.k8s.BatchV1().RESTClient().Get().
+		Namespace(namespace).
+		Resource("cronjobs").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		SetHeader("Accept", tableAcceptHeader).
+		Do(ctx).
+		Raw()
+	if err != nil {
+		return nil, err
+	}
+
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(body, &typeMeta); err != nil {
+		return nil, fmt.Errorf("failed to decode the response: %w", err)
+	}
+	if typeMeta.Kind == "Table" {
+		var table metav1.Table
+		if err := json.Unmarshal(body, &table); err != nil {
+			return nil, fmt.Errorf("failed to decode the table: %w", err)
+		}
+		cronjobs, err := cronJobsFromTable(&table)
+		if !errors.Is(err, errTableUnsupported) {
+			return cronjobs, err
+		}
+		// Fall back to the full objects
+		list, err := c.k8s.BatchV1().CronJobs(namespace).List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return list.Items, nil
+	}
+
+	// The server ignored the Table and responded with the full objects.
+	var list batchv1.CronJobList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to decode the response: %w", err)
+	}
+	return list.Items, nil
+}
+
+func cronJobsFromTable(table *metav1.Table) ([]batchv1.CronJob, error) {
+	columns := map[string]int{}
+	for i, column := range table.ColumnDefinitions {
+		columns[column.Name] = i
+	}
+	for _, name := range []string{"Name", "Schedule", "Suspend"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%w: no '%s' column", errTableUnsupported, name)
+		}
+	}
+	tzColumn, hasTZ := columns["Timezone"]
+
+	cronjobs := make([]batchv1.CronJob, len(table.Rows))
+	for i, row := range table.Rows {
+		if len(row.Cells) != len(table.ColumnDefinitions) {
+			return nil, fmt.Errorf("%w: row %d has %d cells for %d columns", errTableUnsupported, i, len(row.Cells), len(table.ColumnDefinitions))
+		}
+		// The namespace and the labels are only in the metadata of the row.
+		var meta metav1.PartialObjectMetadata
+		if len(row.Object.Raw) == 0 {
+			return nil, fmt.Errorf("%w: row %d has no metadata", errTableUnsupported, i)
+		}
+		if err := json.Unmarshal(row.Object.Raw, &meta); err != nil {
+			return nil, fmt.Errorf("failed to decode the metadata of row %d: %w", i, err)
+		}
+
+		schedule, ok := row.Cells[columns["Schedule"]].(string)
+		if !ok {
+			return nil, fmt.Errorf("%w: the schedule of row %d is not a string", errTableUnsupported, i)
+		}
+		suspend, err := parseSuspendCell(row.Cells[columns["Suspend"]])
+		if err != nil {
+			return nil, fmt.Errorf("%w: the suspend of row %d: %s", errTableUnsupported, i, err)
+		}
+
+		cronjob := batchv1.CronJob{
+			ObjectMeta: meta.ObjectMeta,
+			Spec: batchv1.CronJobSpec{
+				Schedule: schedule,
+				Suspend:  suspend,
+			},
+		}
+		if hasTZ {
+			if tz, ok := row.Cells[tzColumn].(string); ok && tz != "" && tz != "<none>" {
+				cronjob.Spec.TimeZone = &tz
+			}
+		}
+		cronjobs[i] = cronjob
+	}
+	return cronjobs, nil
+}
+
+// The printer of CronJobs writes the suspend as "True", "False", or "<unset>",
+// but a boolean cell is accepted too.
+func parseSuspendCell(cell any) (*bool, error) {
+	switch v := cell.(type) {
+	case bool:
+		return &v, nil
+	case string:
+		if v == "<unset>" || v == "" {
+			return nil, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, err
+		}
+		return &b, nil
+	}
+	return nil, fmt.Errorf("unexpected cell %v", cell)
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/pflag"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func getTableColumns(names ...string) []metav1.TableColumnDefinition {
+	columns := make([]metav1.TableColumnDefinition, len(names))
+	for i, name := range names {
+		columns[i] = metav1.TableColumnDefinition{Name: name, Type: "string"}
+	}
+	return columns
+}
+
+func getTableRow(namespace, name string, cells ...any) metav1.TableRow {
+	meta := metav1.PartialObjectMetadata{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "PartialObjectMetadata"},
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	}
+	raw, _ := json.Marshal(meta)
+	return metav1.TableRow{Cells: append([]any{name}, cells...), Object: runtime.RawExtension{Raw: raw}}
+}
+
+func getCronJobsTable() *metav1.Table {
+	return &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+		ColumnDefinitions: getTableColumns("Name", "Schedule", "Suspend", "Active", "Last Schedule", "Age"),
+		Rows: []metav1.TableRow{
+			getTableRow("ns-a", "cj1", "0 1 * * *", "False", int64(0), "<none>", "1d"),
+			getTableRow("ns-b", "cj2", "0 2 * * *", "True", int64(0), "<none>", "1d"),
+		},
+	}
+}
+
+func Test_cronJobsFromTable(t *testing.T) {
+	t.Parallel()
+	tokyo := "Asia/Tokyo"
+	tests := []struct {
+		name    string
+		table   *metav1.Table
+		want    []batchv1.CronJob
+		wantErr bool
+	}{
+		{
+			name:  "table",
+			table: getCronJobsTable(),
+			want: []batchv1.CronJob{
+				getCronJob("ns-a", "cj1", "0 1 * * *", false),
+				getCronJob("ns-b", "cj2", "0 2 * * *", true),
+			},
+		},
+		{
+			name: "timezone column and unset suspend",
+			table: &metav1.Table{
+				ColumnDefinitions: getTableColumns("Name", "Schedule", "Timezone", "Suspend"),
+				Rows: []metav1.TableRow{
+					getTableRow("ns-a", "cj1", "0 1 * * *", "Asia/Tokyo", "<unset>"),
+					getTableRow("ns-a", "cj2", "0 2 * * *", "<none>", true),
+				},
+			},
+			want: func() []batchv1.CronJob {
+				cj1 := getCronJob("ns-a", "cj1", "0 1 * * *", false)
+				cj1.Spec.Suspend = nil
+				cj1.Spec.TimeZone = &tokyo
+				return []batchv1.CronJob{cj1, getCronJob("ns-a", "cj2", "0 2 * * *", true)}
+			}(),
+		},
+		{
+			name: "no schedule column",
+			table: &metav1.Table{
+				ColumnDefinitions: getTableColumns("Name", "Suspend"),
+				Rows:              []metav1.TableRow{getTableRow("ns-a", "cj1", "False")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "no metadata",
+			table: &metav1.Table{
+				ColumnDefinitions: getTableColumns("Name", "Schedule", "Suspend"),
+				Rows:              []metav1.TableRow{{Cells: []any{"cj1", "0 1 * * *", "False"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unexpected suspend",
+			table: &metav1.Table{
+				ColumnDefinitions: getTableColumns("Name", "Schedule", "Suspend"),
+				Rows:              []metav1.TableRow{getTableRow("ns-a", "cj1", "0 1 * * *", "maybe")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := cronJobsFromTable(tt.table)
+			if tt.wantErr {
+				if !errors.Is(err, errTableUnsupported) {
+					t.Fatalf("cronJobsFromTable() error = %v, want errTableUnsupported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// fakeAPIServer responds to the CronJob lists with the table for the Table
+// requests, and with the full objects otherwise.
+type fakeAPIServer struct {
+	table   *metav1.Table
+	list    batchv1.CronJobList
+	mu      sync.Mutex
+	accepts []string
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.accepts = append(s.accepts, r.Header.Get("Accept"))
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	if s.table != nil && strings.Contains(r.Header.Get("Accept"), "as=Table") {
+		json.NewEncoder(w).Encode(s.table)
+		return
+	}
+	json.NewEncoder(w).Encode(s.list)
+}
+
+func Test_listCronJobsAsTable(t *testing.T) {
+	t.Parallel()
+	full := []batchv1.CronJob{
+		getCronJob("ns-a", "cj1", "0 1 * * *", false),
+		getCronJob("ns-b", "cj2", "0 2 * * *", true),
+	}
+	tests := []struct {
+		name         string
+		table        *metav1.Table
+		wantRequests int
+	}{
+		{
+			name:         "table",
+			table:        getCronJobsTable(),
+			wantRequests: 1,
+		},
+		{
+			name:         "server without tables",
+			table:        nil,
+			wantRequests: 1,
+		},
+		{
+			name: "fallback to full objects",
+			table: &metav1.Table{
+				TypeMeta:          metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "Table"},
+				ColumnDefinitions: getTableColumns("Name", "Age"),
+				Rows:              []metav1.TableRow{getTableRow("ns-a", "cj1", "1d")},
+			},
+			wantRequests: 2,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := &fakeAPIServer{
+				table: tt.table,
+				list:  batchv1.CronJobList{TypeMeta: metav1.TypeMeta{APIVersion: "batch/v1", Kind: "CronJobList"}, Items: full},
+			}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+			k8s, err := kubernetes.NewForConfig(&rest.Config{Host: ts.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := listCronJobsAsTable(context.Background(), &clients{k8s: k8s, serverTable: true}, "", "")
+			if err != nil {
+				t.Fatalf("listCronJobsAsTable() error = %v", err)
+			}
+			if len(srv.accepts) != tt.wantRequests {
+				t.Fatalf("want %d requests, got %d: %v", tt.wantRequests, len(srv.accepts), srv.accepts)
+			}
+			if srv.accepts[0] != tableAcceptHeader {
+				t.Errorf("want the Accept header %s, got %s", tableAcceptHeader, srv.accepts[0])
+			}
+
+			if diff := cmp.Diff(full, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_useServerTable(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		args   []string
+		output string
+		want   bool
+	}{
+		{name: "plain table", args: []string{"--from", "x"}, want: true},
+		{name: "wide", output: "wide", want: false},
+		{name: "json", output: "json", want: false},
+		{name: "labels", args: []string{"--show-labels"}, want: false},
+		{name: "filter", args: []string{"--unowned"}, want: false},
+		{name: "report", args: []string{"--stats"}, want: false},
+		{name: "kubeconfig", args: []string{"--context", "x", "--verbose"}, want: true},
+		{name: "new flag", args: []string{"--new-flag"}, want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fsets := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fsets.String("from", "", "")
+			fsets.Bool("show-labels", false, "")
+			fsets.Bool("unowned", false, "")
+			fsets.Bool("stats", false, "")
+			fsets.String("context", "", "")
+			fsets.Bool("verbose", false, "")
+			fsets.Bool("new-flag", false, "")
+			if err := fsets.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			if got := useServerTable(fsets, tt.output); got != tt.want {
+				t.Errorf("useServerTable() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+// specFlags are the flags reading the spec beyond the schedule, the suspend and
+// the time zone, which the Table doesn't have.
+var specFlags = []string{
+	"missing-history-limits",
+	"priority-class",
+	"min-cpu-request",
+	"min-memory-request",
+	"missing-limits",
+	"missing-limits-include-init",
+	"registry-allowlist",
+	"mounts-secret",
+	"mounts-configmap",
+	"mounts-pvc",
+	"template-selector",
+	"ttl",
+	"entrypoint",
+	"concurrency-conflicts",
+	"duplicates",
+	"demand",
+	"policy",
+	"deadline-risk",
+	"tz-report",
+	"shift",
+	"set-timezone",
+	"save",
+	"compare",
+}
+
+func Test_useServerTable_specFlags(t *testing.T) {
+	t.Parallel()
+	for _, name := range specFlags {
+		fsets := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fsets.String(name, "", "")
+		if err := fsets.Parse([]string{"--" + name + "=x"}); err != nil {
+			t.Fatal(err)
+		}
+		if useServerTable(fsets, "") {
+			t.Errorf("want the full objects with '--%s'", name)
+		}
+	}
+}
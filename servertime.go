@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+// serverTime gets the current time of the API server.
+type serverTime func(ctx context.Context) (time.Time, error)
+
+// Get the time of the API server by the Date header of the response to
+// /version, which every client may request.
+func newServerTime(cfg *rest.Config) serverTime {
+	return func(ctx context.Context) (time.Time, error) {
+		client, err := rest.HTTPClientFor(cfg)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to get the HTTP client: %w", err)
+		}
+		u, _, err := rest.DefaultServerURL(cfg.Host, "", schema.GroupVersion{}, rest.IsConfigTransportTLS(*cfg))
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to get the URL of the API server: %w", err)
+		}
+		u.Path = path.Join(u.Path, "/version")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to request /version: %w", err)
+		}
+		defer resp.Body.Close()
+		date := resp.Header.Get("Date")
+		if date == "" {
+			return time.Time{}, fmt.Errorf("no Date header in the response to /version")
+		}
+		t, err := http.ParseTime(date)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse the Date header '%s': %w", date, err)
+		}
+		return t, nil
+	}
+}
+
+// Get the clock of '--server-time', the local clock shifted by the drift of
+// the API server measured once, so that the times read later stay
+// consistent. The drift is logged as a debug record. The local clock is kept
+// with a warning when the time of the server can't be determined.
+func getServerClock(ctx context.Context, c *clients, local func() time.Time, logger *slog.Logger) func() time.Time {
+	if c.serverTime == nil {
+		logger.Warn("the time of the API server is unknown, using the local clock")
+		return local
+	}
+	before := local()
+	server, err := c.serverTime(ctx)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("failed to get the time of the API server, using the local clock: %s", err), "error", err.Error())
+		return local
+	}
+	after := local()
+	// The Date header is truncated to the second, so half a second is added to
+	// center the error, and compared with the midpoint of the request.
+	drift := server.Add(500 * time.Millisecond).Sub(before.Add(after.Sub(before) / 2)).Round(time.Millisecond)
+	logger.Debug(fmt.Sprintf("the clock of the API server is %s ahead of the local clock", drift), "drift", drift)
+	return func() time.Time { return local().Add(drift) }
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/client-go/rest"
+)
+
+// Get the fake serverTime returning the time, or the error when not empty.
+func fakeServerTime(t string, err string) serverTime {
+	return func(context.Context) (time.Time, error) {
+		if err != "" {
+			return time.Time{}, errors.New(err)
+		}
+		return getTime(t), nil
+	}
+}
+
+func Test_getServerClock(t *testing.T) {
+	t.Parallel()
+	local := func() time.Time { return getTime("2023-01-24T00:00:00Z") }
+	tests := []struct {
+		name       string
+		serverTime serverTime
+		verbose    bool
+		want       string
+		wantStderr string
+	}{
+		{
+			name:       "ahead",
+			serverTime: fakeServerTime("2023-01-24T00:05:00Z", ""),
+			verbose:    true,
+			want:       "2023-01-24T00:05:00.5Z",
+			wantStderr: "the clock of the API server is 5m0.5s ahead of the local clock\n",
+		},
+		{
+			name:       "behind without verbose",
+			serverTime: fakeServerTime("2023-01-23T23:58:00Z", ""),
+			want:       "2023-01-23T23:58:00.5Z",
+		},
+		{
+			name:       "failed",
+			serverTime: fakeServerTime("", "connection refused"),
+			verbose:    true,
+			want:       "2023-01-24T00:00:00Z",
+			wantStderr: "warning: failed to get the time of the API server, using the local clock: connection refused\n",
+		},
+		{
+			name:       "unknown",
+			want:       "2023-01-24T00:00:00Z",
+			wantStderr: "warning: the time of the API server is unknown, using the local clock\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stderr bytes.Buffer
+			now := getServerClock(context.Background(), &clients{serverTime: tt.serverTime}, local, newLogger(&stderr, logFormatText, tt.verbose))
+			if diff := cmp.Diff(tt.want, now().Format(time.RFC3339Nano)); diff != "" {
+				t.Errorf("now (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantStderr, stderr.String()); diff != "" {
+				t.Errorf("stderr (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_newServerTime(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/version" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Date", "Tue, 24 Jan 2023 00:05:00 GMT")
+		w.Write([]byte(`{"gitVersion":"v1.26.1"}`))
+	}))
+	defer server.Close()
+
+	got, err := newServerTime(&rest.Config{Host: server.URL})(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(getTime("2023-01-24T00:05:00Z"), got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_serverTime(t *testing.T) {
+	t.Parallel()
+	c := newRunClients()
+	c.serverTime = fakeServerTime("2023-01-24T00:30:00Z", "")
+	stdout, _, err := runFake(c, append([]string{"--server-time", "-o", "raw", "-n", "ns-a"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if diff := cmp.Diff("ns-a\tbackup\tCronJob\t0 1 * * *\t2023-01-24T01:00:00Z\n", stdout); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_runPrev_serverTime(t *testing.T) {
+	t.Parallel()
+	c := newPrevClients()
+	c.serverTime = fakeServerTime("2023-01-24T06:00:00Z", "")
+	// The local clock is a day behind.
+	stdout, err := runFakePrev(c, "2023-01-23T06:00:00Z", "--server-time", "--last", "6h", "-o", "json")
+	if err != nil {
+		t.Fatalf("runPrev() error = %v", err)
+	}
+	var report expectedReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatal(err)
+	}
+	// The half a second added to the Date header is dropped.
+	want := documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+	got := documentWindow{From: report.Window.From.Truncate(time.Second), To: report.Window.To.Truncate(time.Second)}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+}
+
+// Get the window of the json output of run with the args.
+func runServerTimeWindow(t *testing.T, server string, args ...string) documentWindow {
+	t.Helper()
+	c := newRunClients()
+	c.serverTime = fakeServerTime(server, "")
+	stdout, _, err := runFake(c, append([]string{"--server-time", "-o", "json"}, args...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var doc printformat
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatal(err)
+	}
+	// The half a second added to the Date header is dropped.
+	return documentWindow{From: doc.Window.From.Truncate(time.Second), To: doc.Window.To.Truncate(time.Second)}
+}
+
+func Test_run_serverTime_defaultWindow(t *testing.T) {
+	t.Parallel()
+	got := runServerTimeWindow(t, "2023-01-24T06:00:00Z")
+	want := documentWindow{From: getTime("2023-01-24T05:00:00Z"), To: getTime("2023-01-24T06:00:00Z")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_serverTime_naturalTime(t *testing.T) {
+	t.Parallel()
+	got := runServerTimeWindow(t, "2023-01-24T06:00:00Z", "--from", "yesterday 9am", "--to", "in 2 hours")
+	want := documentWindow{From: getTime("2023-01-23T09:00:00Z"), To: getTime("2023-01-24T08:00:00Z")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_serverTime_offset(t *testing.T) {
+	t.Parallel()
+	got := runServerTimeWindow(t, "2023-01-24T06:00:00Z", "--from", "-2h", "--to", "now+30m")
+	want := documentWindow{From: getTime("2023-01-24T04:00:00Z"), To: getTime("2023-01-24T06:30:00Z")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_serverTime_presetWindow(t *testing.T) {
+	t.Parallel()
+	// The local clock is not on 2023-01-24.
+	got := runServerTimeWindow(t, "2023-01-24T06:00:00Z", "--today")
+	want := documentWindow{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T23:59:59Z")}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("window (-want +got):\n%s", diff)
+	}
+}
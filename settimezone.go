@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Parse the '--set-timezone' value. It must be a zone of the zone database, as
+// the controllers don't accept "Local" nor offsets.
+func parseSetTimezone(value string) (string, error) {
+	if value == "Local" {
+		return "", fmt.Errorf("'--set-timezone' must be a zone name such as Asia/Tokyo: %s", value)
+	}
+	if _, err := time.LoadLocation(value); err != nil {
+		return "", fmt.Errorf("failed to parse '--set-timezone' value: %w", err)
+	}
+	return value, nil
+}
+
+// Plan the '--set-timezone' patches of spec.timeZone of CronJobs and
+// spec.timezone of CronWorkflows. The items which already have the zone are
+// skipped, and with onlyMissing the items with any zone too.
+func planSetTimezone(items []item, tz string, onlyMissing bool) []patchPlan {
+	plans := make([]patchPlan, len(items))
+	for i, item := range items {
+		current := item.timezone()
+		plans[i] = patchPlan{item: item, before: current, after: tz}
+		if current == tz || (onlyMissing && current != "") {
+			plans[i].after = current
+			continue
+		}
+		field := "timeZone"
+		if item.cronWorkflow != nil {
+			field = "timezone"
+		}
+		plans[i].patch = map[string]any{"spec": map[string]any{field: tz}}
+	}
+	return plans
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_parseSetTimezone(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "Asia/Tokyo"},
+		{value: "UTC"},
+		{value: "Local", wantErr: true},
+		{value: "Mars/Olympus", wantErr: true},
+		{value: "+09:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			if _, err := parseSetTimezone(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("parseSetTimezone() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func getSetTimezoneFixtures() ([]batchv1.CronJob, []wfv1alpha1.CronWorkflow) {
+	tokyo, utc := "Asia/Tokyo", "UTC"
+	already := getCronJob("ns-a", "already", "0 1 * * *", false)
+	already.Spec.TimeZone = &tokyo
+	other := getCronJob("ns-a", "other", "0 1 * * *", false)
+	other.Spec.TimeZone = &utc
+	missing := getCronJob("ns-a", "missing", "0 1 * * *", false)
+	cwMissing := getCronWorkflow("ns-b", "missing", "0 1 * * *", false)
+	cwOther := getCronWorkflow("ns-b", "other", "0 1 * * *", false)
+	cwOther.Spec.Timezone = "UTC"
+	return []batchv1.CronJob{already, other, missing}, []wfv1alpha1.CronWorkflow{cwMissing, cwOther}
+}
+
+func Test_planSetTimezone(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		onlyMissing bool
+		wantResults []patchResult
+		wantPatches []string
+	}{
+		{
+			name: "all",
+			wantResults: []patchResult{
+				{Kind: "CronJob", Namespace: "ns-a", Name: "already", Before: "Asia/Tokyo", After: "Asia/Tokyo", Result: patchResultSkipped},
+				{Kind: "CronJob", Namespace: "ns-a", Name: "missing", After: "Asia/Tokyo", Result: patchResultPatched},
+				{Kind: "CronJob", Namespace: "ns-a", Name: "other", Before: "UTC", After: "Asia/Tokyo", Result: patchResultPatched},
+				{Kind: "CronWorkflow", Namespace: "ns-b", Name: "missing", After: "Asia/Tokyo", Result: patchResultPatched},
+				{Kind: "CronWorkflow", Namespace: "ns-b", Name: "other", Before: "UTC", After: "Asia/Tokyo", Result: patchResultPatched},
+			},
+			wantPatches: []string{
+				`cronjobs ns-a/missing: {"spec":{"timeZone":"Asia/Tokyo"}}`,
+				`cronjobs ns-a/other: {"spec":{"timeZone":"Asia/Tokyo"}}`,
+				`cronworkflows ns-b/missing: {"spec":{"timezone":"Asia/Tokyo"}}`,
+				`cronworkflows ns-b/other: {"spec":{"timezone":"Asia/Tokyo"}}`,
+			},
+		},
+		{
+			name:        "only missing",
+			onlyMissing: true,
+			wantResults: []patchResult{
+				{Kind: "CronJob", Namespace: "ns-a", Name: "already", Before: "Asia/Tokyo", After: "Asia/Tokyo", Result: patchResultSkipped},
+				{Kind: "CronJob", Namespace: "ns-a", Name: "missing", After: "Asia/Tokyo", Result: patchResultPatched},
+				{Kind: "CronJob", Namespace: "ns-a", Name: "other", Before: "UTC", After: "UTC", Result: patchResultSkipped},
+				{Kind: "CronWorkflow", Namespace: "ns-b", Name: "missing", After: "Asia/Tokyo", Result: patchResultPatched},
+				{Kind: "CronWorkflow", Namespace: "ns-b", Name: "other", Before: "UTC", After: "UTC", Result: patchResultSkipped},
+			},
+			wantPatches: []string{
+				`cronjobs ns-a/missing: {"spec":{"timeZone":"Asia/Tokyo"}}`,
+				`cronworkflows ns-b/missing: {"spec":{"timezone":"Asia/Tokyo"}}`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cronjobs, cronworkflows := getSetTimezoneFixtures()
+			c := newFakeMutateClients(cronjobs, cronworkflows)
+			plans := planSetTimezone(mergeItems(cronjobs, cronworkflows), "Asia/Tokyo", tt.onlyMissing)
+			results, err := applyPatchPlans(context.Background(), c.clients, plans, patchOptions{yes: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantResults, results); diff != "" {
+				t.Errorf("results (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantPatches, c.patches()); diff != "" {
+				t.Errorf("patches (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_planSetTimezone_nothingToPatch(t *testing.T) {
+	t.Parallel()
+	cronjobs, _ := getSetTimezoneFixtures()
+	c := newFakeMutateClients(cronjobs[:1], nil)
+	// No confirmation is asked without anything to patch.
+	results, err := applyPatchPlans(context.Background(), c.clients, planSetTimezone(mergeItems(cronjobs[:1], nil), "Asia/Tokyo", false), patchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Result != patchResultSkipped {
+		t.Errorf("want the item skipped, got %v", results)
+	}
+}
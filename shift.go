@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse the '--shift' value. It must be a non-zero multiple of a minute, the
+// resolution of the schedules.
+func parseShift(value string) (time.Duration, error) {
+	d, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse '--shift' value: %w", err)
+	}
+	if d == 0 || d%time.Minute != 0 {
+		return 0, fmt.Errorf("'--shift' must be a non-zero multiple of a minute, e.g. 2h, -30m: %s", value)
+	}
+	return d, nil
+}
+
+// Rewrite the schedule so that each fire happens d later, or earlier for a
+// negative d. Only the fixed minute and hour fields are rewritten, and the
+// day-of-week field when the fires wrap past midnight. An expression which
+// can't be shifted exactly is refused with the reason:
+//
+//   - '@' descriptors, e.g. '@every 1h' and '@daily'.
+//   - Steps on the hour field, e.g. '0 */2 * * *'.
+//   - Anything but a single minute when d isn't whole hours, e.g. '0,30 9 * * *'
+//     by 15m, as the minutes would carry differently.
+//   - Wrapping past midnight with a day-of-month or month field, as the month
+//     lengths differ, or with hours wrapping by different days.
+//   - Wrapping an hourly schedule past midnight with any day field.
+func shiftSchedule(schedule string, d time.Duration) (string, error) {
+	prefix := ""
+	spec := strings.TrimSpace(schedule)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		prefix = tz + " "
+		spec = strings.TrimSpace(rest)
+	}
+	if strings.HasPrefix(spec, "@") {
+		return "", fmt.Errorf("the descriptor '%s' can't be shifted", spec)
+	}
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return "", fmt.Errorf("the schedule '%s' doesn't have 5 fields", spec)
+	}
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	total := int(d / time.Minute)
+	shiftMinutes := floorMod(total, 60)
+	shiftHours := floorDiv(total, 60)
+
+	// Minute
+	// -----------------
+	carry := 0
+	if shiftMinutes != 0 {
+		m, err := strconv.Atoi(minute)
+		if err != nil || m < 0 || m > 59 {
+			return "", fmt.Errorf("the minute field '%s' can't be shifted by a fraction of an hour, only a single minute can", minute)
+		}
+		carry = (m + shiftMinutes) / 60
+		minute = strconv.Itoa((m + shiftMinutes) % 60)
+	}
+
+	daysRestricted := !isWildcard(dom) || !isWildcard(month) || !isWildcard(dow)
+
+	// Hour
+	// -----------------
+	if isWildcard(hour) {
+		// Every hour fires again after any shift, but the first and the last
+		// fires of a day move to the neighbor days.
+		if daysRestricted && (carry != 0 || shiftHours != 0) {
+			return "", fmt.Errorf("the hourly schedule '%s' can't be shifted across the hours with the day fields", spec)
+		}
+		return prefix + strings.Join([]string{minute, hour, dom, month, dow}, " "), nil
+	}
+	if strings.Contains(hour, "/") {
+		return "", fmt.Errorf("steps on the hour field '%s' can't be shifted", hour)
+	}
+	hours, err := parseCronValues(hour, 0, 23)
+	if err != nil {
+		return "", fmt.Errorf("the hour field '%s' can't be shifted: %w", hour, err)
+	}
+	shifted := map[int]bool{}
+	dayOffsets := map[int]bool{}
+	for _, h := range hours {
+		v := h + shiftHours + carry
+		shifted[floorMod(v, 24)] = true
+		dayOffsets[floorDiv(v, 24)] = true
+	}
+	hour = formatCronValues(shifted)
+
+	// Days
+	// -----------------
+	if !daysRestricted {
+		return prefix + strings.Join([]string{minute, hour, dom, month, dow}, " "), nil
+	}
+	if len(dayOffsets) != 1 {
+		return "", fmt.Errorf("the hours '%s' would wrap past midnight by different days, which can't be expressed with the day fields", fields[1])
+	}
+	var dayOffset int
+	for offset := range dayOffsets {
+		dayOffset = offset
+	}
+	if dayOffset == 0 {
+		return prefix + strings.Join([]string{minute, hour, dom, month, dow}, " "), nil
+	}
+	if !isWildcard(dom) || !isWildcard(month) {
+		return "", fmt.Errorf("wrapping past midnight can't be shifted with the day-of-month field '%s' and the month field '%s'", dom, month)
+	}
+	if strings.Contains(dow, "/") {
+		return "", fmt.Errorf("steps on the day-of-week field '%s' can't be shifted", dow)
+	}
+	days, err := parseCronValues(dow, 0, 7)
+	if err != nil {
+		return "", fmt.Errorf("the day-of-week field '%s' can't be shifted: %w", dow, err)
+	}
+	shiftedDays := map[int]bool{}
+	for _, day := range days {
+		shiftedDays[floorMod(day+dayOffset, 7)] = true
+	}
+	dow = formatCronValues(shiftedDays)
+	return prefix + strings.Join([]string{minute, hour, dom, month, dow}, " "), nil
+}
+
+func isWildcard(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// Parse a comma separated list of numbers and ranges without steps into the
+// values, e.g. '1,3-5' into 1, 3, 4, 5.
+func parseCronValues(field string, min, max int) ([]int, error) {
+	values := []int{}
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number nor a range", part)
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(hi)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a number nor a range", part)
+			}
+		}
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("'%s' is out of %d-%d", part, min, max)
+		}
+		for v := start; v <= end; v++ {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// Format the values as a comma separated list in ascending order, with the
+// runs of 3 or more values as ranges, e.g. 1, 2, 4, 5, 6 as '1,2,4-6'.
+func formatCronValues(values map[int]bool) string {
+	sorted := make([]int, 0, len(values))
+	for v := range values {
+		sorted = append(sorted, v)
+	}
+	sort.Ints(sorted)
+
+	parts := []string{}
+	for i := 0; i < len(sorted); {
+		j := i
+		for j+1 < len(sorted) && sorted[j+1] == sorted[j]+1 {
+			j++
+		}
+		switch {
+		case j-i >= 2:
+			parts = append(parts, fmt.Sprintf("%d-%d", sorted[i], sorted[j]))
+		case j > i:
+			parts = append(parts, strconv.Itoa(sorted[i]), strconv.Itoa(sorted[j]))
+		default:
+			parts = append(parts, strconv.Itoa(sorted[i]))
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func floorMod(a, b int) int {
+	return a - floorDiv(a, b)*b
+}
+
+// Plan the '--shift' patches of spec.schedule of the items. The items whose
+// schedule can't be shifted are refused.
+func planShift(items []item, d time.Duration) []patchPlan {
+	plans := make([]patchPlan, len(items))
+	for i, item := range items {
+		plans[i] = patchPlan{item: item, before: item.schedule()}
+		shifted, err := shiftSchedule(item.schedule(), d)
+		if err != nil {
+			plans[i].refusal = "refused: " + err.Error()
+			continue
+		}
+		plans[i].after = shifted
+		plans[i].patch = map[string]any{"spec": map[string]any{"schedule": shifted}}
+	}
+	return plans
+}
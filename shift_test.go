@@ -0,0 +1,146 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_shiftSchedule(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		schedule string
+		shift    time.Duration
+		want     string
+		wantErr  string
+	}{
+		// Whole hours.
+		{schedule: "0 9 * * *", shift: 2 * time.Hour, want: "0 11 * * *"},
+		{schedule: "*/5 9 * * *", shift: 2 * time.Hour, want: "*/5 11 * * *"},
+		{schedule: "0 9 * * *", shift: -2 * time.Hour, want: "0 7 * * *"},
+		{schedule: "0 9,21 * * *", shift: time.Hour, want: "0 10,22 * * *"},
+		{schedule: "0 9-17 * * 1-5", shift: time.Hour, want: "0 10-18 * * 1-5"},
+		{schedule: "0   9  * * *", shift: time.Hour, want: "0 10 * * *"},
+		{schedule: "CRON_TZ=Asia/Tokyo 0 9 * * *", shift: time.Hour, want: "CRON_TZ=Asia/Tokyo 0 10 * * *"},
+		{schedule: "TZ=UTC 0 9 * * *", shift: time.Hour, want: "TZ=UTC 0 10 * * *"},
+		// Minutes, with a carry.
+		{schedule: "30 9 * * *", shift: 15 * time.Minute, want: "45 9 * * *"},
+		{schedule: "50 9 * * *", shift: 15 * time.Minute, want: "5 10 * * *"},
+		{schedule: "10 9 * * *", shift: -15 * time.Minute, want: "55 8 * * *"},
+		{schedule: "30 9 * * *", shift: 90 * time.Minute, want: "0 11 * * *"},
+		{schedule: "15 9,12 * * *", shift: 45 * time.Minute, want: "0 10,13 * * *"},
+		// Hourly.
+		{schedule: "0 * * * *", shift: 2 * time.Hour, want: "0 * * * *"},
+		{schedule: "50 * * * *", shift: 20 * time.Minute, want: "10 * * * *"},
+		{schedule: "10 * * * 1-5", shift: 20 * time.Minute, want: "30 * * * 1-5"},
+		{schedule: "50 * * * 1-5", shift: 20 * time.Minute, wantErr: "the hourly schedule '50 * * * 1-5' can't be shifted across the hours with the day fields"},
+		{schedule: "0 * * * 1-5", shift: time.Hour, wantErr: "can't be shifted across the hours with the day fields"},
+		// Wrap-around past midnight every day.
+		{schedule: "0 23 * * *", shift: 2 * time.Hour, want: "0 1 * * *"},
+		{schedule: "0 1 * * *", shift: -2 * time.Hour, want: "0 23 * * *"},
+		{schedule: "45 23 * * *", shift: 30 * time.Minute, want: "15 0 * * *"},
+		{schedule: "0 20-23 * * *", shift: 2 * time.Hour, want: "0 0,1,22,23 * * *"},
+		{schedule: "0 9 * * *", shift: 48 * time.Hour, want: "0 9 * * *"},
+		// Wrap-around past midnight with the day-of-week.
+		{schedule: "0 23 * * 1-5", shift: 2 * time.Hour, want: "0 1 * * 2-6"},
+		{schedule: "0 23 * * 5", shift: 2 * time.Hour, want: "0 1 * * 6"},
+		{schedule: "0 23 * * 6", shift: 2 * time.Hour, want: "0 1 * * 0"},
+		{schedule: "0 23 * * 7", shift: 2 * time.Hour, want: "0 1 * * 1"},
+		{schedule: "0 1 * * 0", shift: -2 * time.Hour, want: "0 23 * * 6"},
+		{schedule: "0 1 * * 1,3,5", shift: -2 * time.Hour, want: "0 23 * * 0,2,4"},
+		{schedule: "0 22 * * 1", shift: 50 * time.Hour, want: "0 0 * * 4"},
+		{schedule: "0 9 * * 1", shift: 2 * time.Hour, want: "0 11 * * 1"},
+		{schedule: "0 9 1 * *", shift: 2 * time.Hour, want: "0 11 1 * *"},
+		{schedule: "0 9 * 1 *", shift: -2 * time.Hour, want: "0 7 * 1 *"},
+		// Refused.
+		{schedule: "@every 1h", shift: time.Hour, wantErr: "the descriptor '@every 1h' can't be shifted"},
+		{schedule: "@daily", shift: time.Hour, wantErr: "the descriptor '@daily' can't be shifted"},
+		{schedule: "0 9 * *", shift: time.Hour, wantErr: "doesn't have 5 fields"},
+		{schedule: "0 */2 * * *", shift: time.Hour, wantErr: "steps on the hour field '*/2' can't be shifted"},
+		{schedule: "0,30 9 * * *", shift: 15 * time.Minute, wantErr: "the minute field '0,30' can't be shifted by a fraction of an hour"},
+		{schedule: "*/5 9 * * *", shift: 15 * time.Minute, wantErr: "the minute field '*/5' can't be shifted"},
+		{schedule: "0 9 * * *", shift: 0, want: "0 9 * * *"},
+		{schedule: "0 24 * * *", shift: time.Hour, wantErr: "'24' is out of 0-23"},
+		{schedule: "0 9-x * * *", shift: time.Hour, wantErr: "'9-x' is not a number nor a range"},
+		{schedule: "0 23 1 * *", shift: 2 * time.Hour, wantErr: "wrapping past midnight can't be shifted with the day-of-month field '1'"},
+		{schedule: "0 23 * 1 *", shift: 2 * time.Hour, wantErr: "with the day-of-month field '*' and the month field '1'"},
+		{schedule: "0 22,23 * * 1", shift: 90 * time.Minute, wantErr: "the hours '22,23' would wrap past midnight by different days"},
+		{schedule: "0 23 * * */2", shift: 2 * time.Hour, wantErr: "steps on the day-of-week field '*/2' can't be shifted"},
+		{schedule: "0 23 * * MON", shift: 2 * time.Hour, wantErr: "the day-of-week field 'MON' can't be shifted"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.schedule+" by "+tt.shift.String(), func(t *testing.T) {
+			t.Parallel()
+			got, err := shiftSchedule(tt.schedule, tt.shift)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("want error containing %q, got %q, %v", tt.wantErr, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_parseShift(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "2h", want: 2 * time.Hour},
+		{value: "-30m", want: -30 * time.Minute},
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "0s", wantErr: true},
+		{value: "90s", wantErr: true},
+		{value: "2", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseShift(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseShift() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("want %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func Test_formatCronValues(t *testing.T) {
+	t.Parallel()
+	set := func(values ...int) map[int]bool {
+		m := map[int]bool{}
+		for _, v := range values {
+			m[v] = true
+		}
+		return m
+	}
+	tests := []struct {
+		values map[int]bool
+		want   string
+	}{
+		{values: set(5), want: "5"},
+		{values: set(2, 1), want: "1,2"},
+		{values: set(1, 2, 4, 5, 6), want: "1,2,4-6"},
+		{values: set(0, 1, 2, 3, 4, 5, 6), want: "0-6"},
+	}
+	for _, tt := range tests {
+		if got := formatCronValues(tt.values); got != tt.want {
+			t.Errorf("want %s, got %s", tt.want, got)
+		}
+	}
+}
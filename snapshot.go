@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// The changes of '--compare'.
+const (
+	snapshotAdded   = "added"
+	snapshotRemoved = "removed"
+	snapshotChanged = "changed"
+)
+
+// snapshotChange is an item added, removed, or changed since the snapshot.
+type snapshotChange struct {
+	Change    string `json:"change"`
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Fields are the changed fields, only for snapshotChanged.
+	Fields []fieldChange `json:"fields,omitempty"`
+}
+
+type fieldChange struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type snapshotDiffReport struct {
+	ApiVersion string           `json:"apiVersion"`
+	Snapshot   string           `json:"snapshot"`
+	Changes    []snapshotChange `json:"changes"`
+}
+
+// Save the items into the file as the JSON output document, which
+// '--compare' and '--fixtures' read. The objects of the providers are not
+// saved, as the document of the CronJobs and the CronWorkflows is read back.
+func saveSnapshot(path string, items []item, opts documentOptions) (retErr error) {
+	saved := []item{}
+	for _, item := range items {
+		if item.provided == nil {
+			saved = append(saved, item)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to save the snapshot: %w", err)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && retErr == nil {
+			retErr = fmt.Errorf("failed to save the snapshot: %w", err)
+		}
+	}()
+	return printJSON(f, saved, opts)
+}
+
+// Load the items of the snapshot saved by '--save'.
+func loadSnapshot(path string) ([]item, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the snapshot: %w", err)
+	}
+	var doc struct {
+		SchemaVersion *int              `json:"schemaVersion"`
+		Items         []json.RawMessage `json:"items"`
+	}
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode the snapshot '%s': %w", path, err)
+	}
+	if doc.SchemaVersion == nil {
+		return nil, fmt.Errorf("failed to decode the snapshot '%s': schemaVersion is missing, save it with '--save'", path)
+	}
+	return decodeDocumentItems(path, *doc.SchemaVersion, doc.Items, "")
+}
+
+// Compare the items with those of the snapshot by the kind, the namespace,
+// and the name, and return the changes of the schedules, suspend, and the
+// time zone, sorted like the items.
+func compareSnapshot(snapshot, items []item) []snapshotChange {
+	type key struct{ kind, namespace, name string }
+	keyOf := func(item item) key {
+		obj := item.object()
+		return key{kind: item.kind(), namespace: obj.GetNamespace(), name: obj.GetName()}
+	}
+	before := map[key]item{}
+	for _, item := range snapshot {
+		before[keyOf(item)] = item
+	}
+
+	var changed []item
+	changes := map[key]snapshotChange{}
+	for _, item := range items {
+		k := keyOf(item)
+		old, ok := before[k]
+		if !ok {
+			changes[k] = snapshotChange{Change: snapshotAdded, Kind: k.kind, Namespace: k.namespace, Name: k.name}
+			changed = append(changed, item)
+			continue
+		}
+		delete(before, k)
+		if fields := diffSnapshotItem(old, item); len(fields) > 0 {
+			changes[k] = snapshotChange{Change: snapshotChanged, Kind: k.kind, Namespace: k.namespace, Name: k.name, Fields: fields}
+			changed = append(changed, item)
+		}
+	}
+	for k, item := range before {
+		changes[k] = snapshotChange{Change: snapshotRemoved, Kind: k.kind, Namespace: k.namespace, Name: k.name}
+		changed = append(changed, item)
+	}
+
+	sortItems(changed)
+	ret := make([]snapshotChange, 0, len(changed))
+	for _, item := range changed {
+		ret = append(ret, changes[keyOf(item)])
+	}
+	return ret
+}
+
+func diffSnapshotItem(before, after item) []fieldChange {
+	var fields []fieldChange
+	for _, f := range []struct {
+		field         string
+		before, after string
+	}{
+		{field: "schedule", before: strings.Join(before.schedules(), "; "), after: strings.Join(after.schedules(), "; ")},
+		{field: "suspend", before: strconv.FormatBool(before.suspended()), after: strconv.FormatBool(after.suspended())},
+		{field: "timezone", before: before.timezone(), after: after.timezone()},
+	} {
+		if f.before != f.after {
+			fields = append(fields, fieldChange{Field: f.field, Before: f.before, After: f.after})
+		}
+	}
+	return fields
+}
+
+// Format the changed fields as "schedule: '0 1 * * *' -> '0 2 * * *'".
+func formatFieldChanges(fields []fieldChange) string {
+	if len(fields) == 0 {
+		return "<none>"
+	}
+	diffs := make([]string, len(fields))
+	for i, f := range fields {
+		diffs[i] = fmt.Sprintf("%s: '%s' -> '%s'", f.Field, f.Before, f.After)
+	}
+	return strings.Join(diffs, ", ")
+}
+
+func printSnapshotChanges(stdout io.Writer, output string, noHeaders bool, snapshot string, changes []snapshotChange) error {
+	report := snapshotDiffReport{ApiVersion: "v1", Snapshot: snapshot, Changes: changes}
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Change", "Namespace", "Name", "Kind", "Fields"}, "\t"))
+	}
+	for _, c := range changes {
+		fmt.Fprintln(tw, strings.Join([]string{c.Change, c.Namespace, c.Name, c.Kind, formatFieldChanges(c.Fields)}, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Write the items into a fixtures file in the directory.
+func writeFixtures(t *testing.T, dir, name string, cronjobs []batchv1.CronJob, cronworkflows []wfv1alpha1.CronWorkflow) string {
+	t.Helper()
+	var doc bytes.Buffer
+	if err := printJSON(&doc, mergeItems(cronjobs, cronworkflows), documentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, doc.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func Test_run_saveCompare(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	period := []string{"--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}
+	backup := getCronJob("ns-a", "backup", "0 1 * * *", false)
+	cleanup := getCronJob("ns-b", "cleanup", "0 2 * * *", false)
+	// Not firing in the period, but saved and compared.
+	nightly := getCronJob("ns-b", "nightly", "0 12 * * *", false)
+	report := getCronWorkflow("ns-b", "report", "0 3 * * *", false)
+	before := writeFixtures(t, dir, "before.json", []batchv1.CronJob{backup, cleanup, nightly}, []wfv1alpha1.CronWorkflow{report})
+	snapshot := filepath.Join(dir, "snapshot.json")
+
+	var stdout bytes.Buffer
+	if err := run(&stdout, &bytes.Buffer{}, append([]string{commandName, "--fixtures", before, "--save", snapshot, "--no-headers"}, period...)); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	// The items are printed as without '--save'.
+	wantItems := "" +
+		"ns-a   backup    0 1 * * *   false   CronJob\n" +
+		"ns-b   cleanup   0 2 * * *   false   CronJob\n" +
+		"ns-b   report    0 3 * * *   false   CronWorkflow\n"
+	if diff := cmp.Diff(wantItems, stdout.String()); diff != "" {
+		t.Errorf("items (-want +got):\n%s", diff)
+	}
+	saved, err := loadSnapshot(snapshot)
+	if err != nil {
+		t.Fatalf("loadSnapshot() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"CronJob/ns-a/backup", "CronJob/ns-b/cleanup", "CronJob/ns-b/nightly", "CronWorkflow/ns-b/report"}, getItemNames(saved)); diff != "" {
+		t.Errorf("saved (-want +got):\n%s", diff)
+	}
+
+	// Remove cleanup, change nightly and report, and add hourly.
+	tokyo := "Asia/Tokyo"
+	nightly.Spec.Schedule = "0 13 * * *"
+	nightly.Spec.TimeZone = &tokyo
+	report.Spec.Suspend = true
+	hourly := getCronJob("ns-c", "hourly", "0 * * * *", false)
+	after := writeFixtures(t, dir, "after.json", []batchv1.CronJob{backup, nightly, hourly}, []wfv1alpha1.CronWorkflow{report})
+
+	stdout.Reset()
+	if err := run(&stdout, &bytes.Buffer{}, append([]string{commandName, "--fixtures", after, "--compare", snapshot, "--no-headers"}, period...)); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := "" +
+		"removed   ns-b   cleanup   CronJob        <none>\n" +
+		"changed   ns-b   nightly   CronJob        schedule: '0 12 * * *' -> '0 13 * * *', timezone: '' -> 'Asia/Tokyo'\n" +
+		"changed   ns-b   report    CronWorkflow   suspend: 'false' -> 'true'\n" +
+		"added     ns-c   hourly    CronJob        <none>\n"
+	if diff := cmp.Diff(want, stdout.String()); diff != "" {
+		t.Errorf("changes (-want +got):\n%s", diff)
+	}
+}
+
+func Test_loadSnapshot_errors(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := os.WriteFile(path, []byte(`{"items": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSnapshot(path); err == nil {
+		t.Errorf("want an error without schemaVersion")
+	}
+	if _, err := loadSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("want an error for a missing file")
+	}
+}
+
+func Test_run_save_redact(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	backup := getCronJob("ns-a", "backup", "0 1 * * *", false)
+	backup.Spec.JobTemplate.Spec.Template.Spec.Containers = []corev1.Container{{Name: "main", Env: getRedactEnv()}}
+	fixtures := writeFixtures(t, dir, "fixtures.json", []batchv1.CronJob{backup}, nil)
+	snapshot := filepath.Join(dir, "snapshot.json")
+	args := []string{commandName, "--fixtures", fixtures, "--save", snapshot, "--redact", "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}
+	if err := run(&bytes.Buffer{}, &bytes.Buffer{}, args); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	b, err := os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("hunter2")) {
+		t.Errorf("want the snapshot redacted, got %s", b)
+	}
+}
+
+func Test_run_saveCompare_invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--save", "x.json", "--namespaces", "ns-a"}, want: "'--namespaces' can't be used with '--save'"},
+		{args: []string{"--compare", "x.json", "--namespaces", "ns-a"}, want: "'--namespaces' can't be used with '--compare'"},
+		// The named items are only those in the period.
+		{args: []string{"--save", "x.json", "--names-from", "-"}, want: "'--names-from' can't be used with '--save'"},
+		{args: []string{"--compare", "x.json", "--names-from", "-"}, want: "'--names-from' can't be used with '--compare'"},
+	}
+	for _, tt := range tests {
+		args := append([]string{commandName, "--from", "2023-01-24T00:00:00Z", "--to", "2023-01-24T06:00:00Z"}, tt.args...)
+		if err := run(&bytes.Buffer{}, &bytes.Buffer{}, args); err == nil || err.Error() != tt.want {
+			t.Errorf("%v: want %q, got %v", tt.args, tt.want, err)
+		}
+	}
+}
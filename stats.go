@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"golang.org/x/exp/maps"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+type busiestHour struct {
+	Time  time.Time `json:"time"`
+	Fires int       `json:"fires"`
+}
+
+// stats is the aggregate of the items for '--stats'. Items, Fires, and the
+// breakdowns only count the items executed during the period.
+type stats struct {
+	ApiVersion string `json:"apiVersion"`
+	Items      int    `json:"items"`
+	Fires      int    `json:"fires"`
+	// Pods is the estimate of the pods started by the items which are not
+	// suspended, see getPodsPerRun.
+	Pods              int            `json:"pods"`
+	FiresPerKind      map[string]int `json:"firesPerKind"`
+	FiresPerNamespace map[string]int `json:"firesPerNamespace"`
+	// BusiestHour is the clock hour in the display time zone with the most
+	// fires, the earliest on a tie, or nil without fires.
+	BusiestHour *busiestHour `json:"busiestHour"`
+	Suspended   int          `json:"suspended"`
+	// NeverFiring is the number of the items not executed during the period.
+	NeverFiring int `json:"neverFiring"`
+	ParseErrors int `json:"parseErrors"`
+}
+
+// Aggregate the items over the from-to period. The schedules are evaluated
+// like the matching, and the fires are bucketed by the hours in loc.
+func computeStats(items []item, from, to time.Time, bounds boundaries, loc *time.Location) stats {
+	s := stats{ApiVersion: "v1", FiresPerKind: map[string]int{}, FiresPerNamespace: map[string]int{}}
+	hours := map[time.Time]int{}
+	for _, item := range items {
+		sched, err := cron.ParseStandard(item.schedule())
+		if err != nil {
+			s.ParseErrors++
+			continue
+		}
+		fires := 0
+		for next := sched.Next(bounds.start(from)); !next.IsZero() && bounds.beforeEnd(next, to); next = sched.Next(next) {
+			fires++
+			hours[floorTime(next.In(loc), time.Hour).UTC()]++
+		}
+		if fires == 0 {
+			s.NeverFiring++
+			continue
+		}
+		s.Items++
+		s.Fires += fires
+		s.FiresPerKind[item.kind()] += fires
+		s.FiresPerNamespace[item.object().GetNamespace()] += fires
+		if item.suspended() {
+			s.Suspended++
+		} else {
+			s.Pods += fires * getPodsPerRun(item)
+		}
+	}
+
+	keys := maps.Keys(hours)
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Before(keys[j]) })
+	for _, hour := range keys {
+		if s.BusiestHour == nil || hours[hour] > s.BusiestHour.Fires {
+			s.BusiestHour = &busiestHour{Time: hour, Fires: hours[hour]}
+		}
+	}
+	return s
+}
+
+func printStats(stdout io.Writer, output string, tf timeFormat, s stats) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(s, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(s)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	busiest := "<none>"
+	if s.BusiestHour != nil {
+		t := metav1.NewTime(s.BusiestHour.Time)
+		busiest = fmt.Sprintf("%s (%d fires)", tf.format(&t), s.BusiestHour.Fires)
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	fmt.Fprintf(tw, "Items:\t%d\n", s.Items)
+	fmt.Fprintf(tw, "Fires:\t%d\n", s.Fires)
+	fmt.Fprintf(tw, "Pods:\t%d\n", s.Pods)
+	fmt.Fprintf(tw, "Busiest hour:\t%s\n", busiest)
+	fmt.Fprintf(tw, "Suspended:\t%d\n", s.Suspended)
+	fmt.Fprintf(tw, "Never firing:\t%d\n", s.NeverFiring)
+	fmt.Fprintf(tw, "Parse errors:\t%d\n", s.ParseErrors)
+	for _, breakdown := range []struct {
+		title  string
+		counts map[string]int
+	}{
+		{title: "Fires per kind:", counts: s.FiresPerKind},
+		{title: "Fires per namespace:", counts: s.FiresPerNamespace},
+	} {
+		fmt.Fprintln(tw, breakdown.title)
+		keys := maps.Keys(breakdown.counts)
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(tw, "  %s\t%s\n", key, strconv.Itoa(breakdown.counts[key]))
+		}
+	}
+	return tw.Flush()
+}
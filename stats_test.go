@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func getStatsFixtures() []item {
+	return mergeItems(
+		[]batchv1.CronJob{
+			// 6 fires at :00, :10, ... :50 of 01:00.
+			getCronJob("ns-a", "every-10m", "*/10 1 * * *", false),
+			// 3 fires at 00:00, 01:00, and 02:00.
+			getCronJob("ns-a", "hourly", "0 * * * *", true),
+			getCronJob("ns-b", "never", "0 12 * * *", false),
+			getCronJob("ns-b", "broken", "61 * * * *", false),
+		},
+		[]wfv1alpha1.CronWorkflow{
+			// 2 fires at 00:30 and 01:30.
+			getCronWorkflow("ns-b", "half-past", "30 * * * *", false),
+			getCronWorkflow("ns-c", "feb-30th", "0 0 30 2 *", false),
+		},
+	)
+}
+
+func Test_computeStats(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T02:00:00Z")
+	got := computeStats(getStatsFixtures(), from, to, boundaries{}, time.UTC)
+	want := stats{
+		ApiVersion:        "v1",
+		Items:             3,
+		Fires:             11,
+		Pods:              8,
+		FiresPerKind:      map[string]int{"CronJob": 9, "CronWorkflow": 2},
+		FiresPerNamespace: map[string]int{"ns-a": 9, "ns-b": 2},
+		BusiestHour:       &busiestHour{Time: getTime("2023-01-24T01:00:00Z"), Fires: 8},
+		Suspended:         1,
+		NeverFiring:       2,
+		ParseErrors:       1,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_computeStats_empty(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T02:00:00Z")
+	got := computeStats(nil, from, to, boundaries{}, time.UTC)
+	want := stats{ApiVersion: "v1", FiresPerKind: map[string]int{}, FiresPerNamespace: map[string]int{}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_printStats(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T02:00:00Z")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := printStats(&got, "", timeFormat{location: tokyo}, computeStats(getStatsFixtures(), from, to, boundaries{}, tokyo)); err != nil {
+		t.Fatal(err)
+	}
+	want := `Items:          3
+Fires:          11
+Pods:           8
+Busiest hour:   2023-01-24T10:00:00+09:00 (8 fires)
+Suspended:      1
+Never firing:   2
+Parse errors:   1
+Fires per kind:
+  CronJob        9
+  CronWorkflow   2
+Fires per namespace:
+  ns-a   9
+  ns-b   2
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
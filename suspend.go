@@ -0,0 +1,77 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// suspendedAtAnnotation marks the items suspended by '--suspend', so that
+// '--resume' only resumes them and not the items paused for other reasons.
+const suspendedAtAnnotation = "cls.unblee.io/suspended-at"
+
+// suspendMarker is the value of the suspendedAtAnnotation annotation.
+type suspendMarker struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	RunID string    `json:"runID"`
+}
+
+// Generate the ID shared by the items suspended by a single run.
+func newRunID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate a run ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Plan the '--suspend' patches, which suspend the items and mark them with the
+// annotation. The items already suspended are skipped and not marked.
+func planSuspend(items []item, marker suspendMarker) ([]patchPlan, error) {
+	value, err := json.Marshal(marker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the '%s' annotation: %w", suspendedAtAnnotation, err)
+	}
+	plans := make([]patchPlan, len(items))
+	for i, item := range items {
+		suspended := strconv.FormatBool(item.suspended())
+		plans[i] = patchPlan{item: item, before: suspended, after: "true"}
+		if item.suspended() {
+			continue
+		}
+		plans[i].patch = map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{suspendedAtAnnotation: string(value)}},
+			"spec":     map[string]any{"suspend": true},
+		}
+	}
+	return plans, nil
+}
+
+// Plan the '--resume' patches, which resume the suspended items marked by
+// '--suspend' and remove the annotation. With all, the items suspended without
+// the annotation are resumed too, instead of refused.
+func planResume(items []item, all bool) []patchPlan {
+	plans := make([]patchPlan, len(items))
+	for i, item := range items {
+		plans[i] = patchPlan{item: item, before: strconv.FormatBool(item.suspended()), after: "false"}
+		if !item.suspended() {
+			continue
+		}
+		_, marked := item.object().GetAnnotations()[suspendedAtAnnotation]
+		if !marked && !all {
+			plans[i].after = "true"
+			plans[i].refusal = fmt.Sprintf("skipped: suspended without the '%s' annotation, use '--resume-all' to resume it", suspendedAtAnnotation)
+			continue
+		}
+		plans[i].patch = map[string]any{"spec": map[string]any{"suspend": false}}
+		if marked {
+			// null removes the annotation by the merge patch.
+			plans[i].patch["metadata"] = map[string]any{"annotations": map[string]any{suspendedAtAnnotation: nil}}
+		}
+	}
+	return plans
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// Get "Kind namespace/name suspend=B marked=B" of the items in the cluster.
+func getSuspendStates(t *testing.T, c fakeMutateClients) []string {
+	t.Helper()
+	items, err := listItems(context.Background(), c.clients, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	states := []string{}
+	for _, item := range items {
+		obj := item.object()
+		_, marked := obj.GetAnnotations()[suspendedAtAnnotation]
+		states = append(states, item.kind()+" "+obj.GetNamespace()+"/"+obj.GetName()+" suspend="+strconv.FormatBool(item.suspended())+" marked="+strconv.FormatBool(marked))
+	}
+	return states
+}
+
+// Get the last patch of the object, e.g. "cronjobs ns-a/active".
+func lastPatch(c fakeMutateClients, object string) string {
+	last := ""
+	for _, patch := range c.patches() {
+		if strings.HasPrefix(patch, object+": ") {
+			last = patch
+		}
+	}
+	return last
+}
+
+func Test_suspendResume_lifecycle(t *testing.T) {
+	t.Parallel()
+	c := newFakeMutateClients(
+		[]batchv1.CronJob{
+			getCronJob("ns-a", "active", "0 1 * * *", false),
+			// Paused long before, for other reasons.
+			getCronJob("ns-a", "paused", "0 1 * * *", true),
+		},
+		[]wfv1alpha1.CronWorkflow{getCronWorkflow("ns-b", "active", "0 1 * * *", false)},
+	)
+	ctx := context.Background()
+	listed := func() []item {
+		items, err := listItems(ctx, c.clients, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return items
+	}
+
+	// Suspend
+	// -----------------
+	marker := suspendMarker{From: getTime("2023-01-24T00:00:00Z"), To: getTime("2023-01-24T06:00:00Z"), RunID: "0123456789abcdef"}
+	plans, err := planSuspend(listed(), marker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := applyPatchPlans(ctx, c.clients, plans, patchOptions{yes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotResults := []string{}
+	for _, r := range results {
+		gotResults = append(gotResults, r.Name+" "+r.Result)
+	}
+	if diff := cmp.Diff([]string{"active patched", "paused skipped", "active patched"}, gotResults); diff != "" {
+		t.Errorf("suspend results (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{
+		"CronJob ns-a/active suspend=true marked=true",
+		"CronJob ns-a/paused suspend=true marked=false",
+		"CronWorkflow ns-b/active suspend=true marked=true",
+	}, getSuspendStates(t, c)); diff != "" {
+		t.Errorf("states after suspend (-want +got):\n%s", diff)
+	}
+
+	wantPatch := `cronjobs ns-a/active: {"metadata":{"annotations":{"cls.unblee.io/suspended-at":"{\"from\":\"2023-01-24T00:00:00Z\",\"to\":\"2023-01-24T06:00:00Z\",\"runID\":\"0123456789abcdef\"}"}},"spec":{"suspend":true}}`
+	if diff := cmp.Diff(wantPatch, c.patches()[0]); diff != "" {
+		t.Errorf("suspend patch (-want +got):\n%s", diff)
+	}
+	var gotMarker suspendMarker
+	if err := json.Unmarshal([]byte(listed()[0].object().GetAnnotations()[suspendedAtAnnotation]), &gotMarker); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(marker, gotMarker); diff != "" {
+		t.Errorf("marker (-want +got):\n%s", diff)
+	}
+
+	// Resume
+	// -----------------
+	results, err = applyPatchPlans(ctx, c.clients, planResume(listed(), false), patchOptions{yes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := results[1].Result; !strings.HasPrefix(got, "skipped: suspended without the 'cls.unblee.io/suspended-at' annotation") {
+		t.Errorf("want the unmarked item skipped, got %s", got)
+	}
+	if diff := cmp.Diff([]string{
+		"CronJob ns-a/active suspend=false marked=false",
+		"CronJob ns-a/paused suspend=true marked=false",
+		"CronWorkflow ns-b/active suspend=false marked=false",
+	}, getSuspendStates(t, c)); diff != "" {
+		t.Errorf("states after resume (-want +got):\n%s", diff)
+	}
+	wantPatch = `cronjobs ns-a/active: {"metadata":{"annotations":{"cls.unblee.io/suspended-at":null}},"spec":{"suspend":false}}`
+	if diff := cmp.Diff(wantPatch, lastPatch(c, "cronjobs ns-a/active")); diff != "" {
+		t.Errorf("resume patch (-want +got):\n%s", diff)
+	}
+
+	// Resume all
+	// -----------------
+	if _, err := applyPatchPlans(ctx, c.clients, planResume(listed(), true), patchOptions{yes: true}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{
+		"CronJob ns-a/active suspend=false marked=false",
+		"CronJob ns-a/paused suspend=false marked=false",
+		"CronWorkflow ns-b/active suspend=false marked=false",
+	}, getSuspendStates(t, c)); diff != "" {
+		t.Errorf("states after resume all (-want +got):\n%s", diff)
+	}
+	wantPatch = `cronjobs ns-a/paused: {"spec":{"suspend":false}}`
+	if diff := cmp.Diff(wantPatch, lastPatch(c, "cronjobs ns-a/paused")); diff != "" {
+		t.Errorf("resume all patch (-want +got):\n%s", diff)
+	}
+}
+
+func Test_newRunID(t *testing.T) {
+	t.Parallel()
+	a, err := newRunID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newRunID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a) != 16 || a == b {
+		t.Errorf("want distinct 16 hex digits, got %s and %s", a, b)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const (
+	// tablePadding is the padding between the table columns.
+	tablePadding = 3
+	// minTruncatedWidth is the narrowest a column is truncated to by
+	// '--max-width', unless its header is wider.
+	minTruncatedWidth = 8
+)
+
+// Returns the width of the terminal, or zero when w is not a terminal.
+func terminalWidth(w io.Writer) int {
+	width, _, _ := terminalSize(w)
+	return width
+}
+
+// Returns the width and the height of the terminal, or false when w is not a
+// terminal.
+func terminalSize(w io.Writer) (width, height int, ok bool) {
+	f, ok := w.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return 0, 0, false
+	}
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// Compute the column widths so that the table fits into maxWidth. The widest
+// column is narrowed one rune at a time, so the widest columns end up
+// truncated to the same width while the narrow ones are kept intact. A column
+// is never narrowed below its header or minTruncatedWidth, so the table can
+// still be wider than maxWidth.
+func fitColumnWidths(headers []string, rows [][]string, padding, maxWidth int) []int {
+	columns := len(headers)
+	if len(rows) > 0 {
+		columns = len(rows[0])
+	}
+	widths := make([]int, columns)
+	floors := make([]int, columns)
+	for i := range floors {
+		floors[i] = minTruncatedWidth
+	}
+	for i, header := range headers {
+		n := utf8.RuneCountInString(header)
+		widths[i] = n
+		if n > floors[i] {
+			floors[i] = n
+		}
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if n := utf8.RuneCountInString(cell); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	total := padding * (columns - 1)
+	for _, width := range widths {
+		total += width
+	}
+	for total > maxWidth {
+		widest := -1
+		for i, width := range widths {
+			if width > floors[i] && (widest < 0 || width > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+	return widths
+}
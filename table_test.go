@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_printList_maxWidth(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "short", "0 2 * * *", false)
+	cronjob.Labels = map[string]string{"team": "データ基盤チームの夜間バッチ処理"}
+	cronworkflow := getCronWorkflow("ns-a", "nightly-data-pipeline-generated-by-the-orchestrator-for-tenant-0123456789", "0 1 * * *", false)
+
+	tests := []struct {
+		name string
+		opts printListOptions
+		want string
+	}{
+		{
+			name: "long name",
+			opts: printListOptions{maxWidth: 60},
+			want: "" +
+				"Namespace   Name          Schedule    Suspend   Kind\n" +
+				"ns-a        nightly-...   0 1 * * *   false     CronWorkflow\n" +
+				"ns-a        short         0 2 * * *   false     CronJob\n",
+		},
+		{
+			name: "multi-byte runes",
+			opts: printListOptions{showLabels: true, maxWidth: 80},
+			want: "" +
+				"Namespace   Name             Schedule    Suspend   Kind           Labels\n" +
+				"ns-a        nightly-dat...   0 1 * * *   false     CronWorkflow   \n" +
+				"ns-a        short            0 2 * * *   false     CronJob        team=データ基盤チ...\n",
+		},
+		{
+			name: "narrower than the floors",
+			opts: printListOptions{noHeaders: true, maxWidth: 10},
+			want: "" +
+				"ns-a   night...   0 1 *...   false   CronW...\n" +
+				"ns-a   short      0 2 *...   false   CronJob\n",
+		},
+		{
+			name: "no truncation when wide enough",
+			opts: printListOptions{maxWidth: 200},
+			want: "" +
+				"Namespace   Name                                                                        Schedule    Suspend   Kind\n" +
+				"ns-a        nightly-data-pipeline-generated-by-the-orchestrator-for-tenant-0123456789   0 1 * * *   false     CronWorkflow\n" +
+				"ns-a        short                                                                       0 2 * * *   false     CronJob\n",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got bytes.Buffer
+			printList(&got, tt.opts, mergeItems([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{cronworkflow}))
+			if diff := cmp.Diff(tt.want, got.String()); diff != "" {
+				t.Errorf("printList() mismatch (-want +got):\n%s", diff)
+			}
+			if tt.opts.maxWidth >= 60 {
+				for _, line := range strings.Split(strings.TrimSuffix(got.String(), "\n"), "\n") {
+					if n := utf8.RuneCountInString(strings.TrimRight(line, " ")); n > tt.opts.maxWidth {
+						t.Errorf("line is %d runes wide, want <= %d: %q", n, tt.opts.maxWidth, line)
+					}
+				}
+			}
+		})
+	}
+}
+
+func Test_terminalWidth(t *testing.T) {
+	t.Parallel()
+	// Neither a buffer nor a regular file is a terminal, so nothing is
+	// truncated by default when the output is piped or redirected.
+	if got := terminalWidth(&bytes.Buffer{}); got != 0 {
+		t.Errorf("terminalWidth(buffer) = %d, want 0", got)
+	}
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if got := terminalWidth(f); got != 0 {
+		t.Errorf("terminalWidth(file) = %d, want 0", got)
+	}
+}
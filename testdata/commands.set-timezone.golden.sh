@@ -0,0 +1,7 @@
+#!/usr/bin/env bash
+set -euo pipefail
+# CronJob ns-a/already: skipped
+kubectl -n ns-a patch cronjob missing --type=merge -p '{"spec":{"timeZone":"Asia/Tokyo"}}'
+kubectl -n ns-a patch cronjob other --type=merge -p '{"spec":{"timeZone":"Asia/Tokyo"}}'
+kubectl -n ns-b patch cronworkflow missing --type=merge -p '{"spec":{"timezone":"Asia/Tokyo"}}'
+kubectl -n ns-b patch cronworkflow other --type=merge -p '{"spec":{"timezone":"Asia/Tokyo"}}'
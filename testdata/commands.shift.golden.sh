@@ -0,0 +1,5 @@
+#!/usr/bin/env bash
+set -euo pipefail
+# CronJob ns-a/every-2h: refused: steps on the hour field '*/2' can't be shifted
+kubectl -n ns-a patch cronjob nightly --type=merge -p '{"spec":{"schedule":"0 1 * * 2-6"}}'
+kubectl -n ns-b patch cronworkflow report --type=merge -p '{"spec":{"schedule":"30 11 * * *"}}'
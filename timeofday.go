@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// timeOfDayRange is a range of the time of day in minutes since midnight.
+// Both ends are inclusive, and start > end is a range crossing midnight.
+type timeOfDayRange struct {
+	start int
+	end   int
+}
+
+func (r timeOfDayRange) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if r.start <= r.end {
+		return r.start <= m && m <= r.end
+	}
+	return m >= r.start || m <= r.end
+}
+
+// Parse the '--daily-between' value in the 'HH:MM-HH:MM' form.
+func parseTimeOfDayRange(value string) (timeOfDayRange, error) {
+	start, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return timeOfDayRange{}, fmt.Errorf("failed to parse '--daily-between' value: '%s' is not in the 'HH:MM-HH:MM' form", value)
+	}
+	startMinutes, err := parseTimeOfDay(start)
+	if err != nil {
+		return timeOfDayRange{}, fmt.Errorf("failed to parse '--daily-between' value: %w", err)
+	}
+	endMinutes, err := parseTimeOfDay(end)
+	if err != nil {
+		return timeOfDayRange{}, fmt.Errorf("failed to parse '--daily-between' value: %w", err)
+	}
+	return timeOfDayRange{start: startMinutes, end: endMinutes}, nil
+}
+
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Keep only items which have a fire time in the from-to period whose time of
+// day in loc is in r. The fire times are evaluated in the time zone of each
+// item. An item whose schedule or time zone can't be parsed is not kept.
+func dailyBetweenFilter(r timeOfDayRange, loc *time.Location, from, to time.Time, bounds boundaries) filter {
+	match := func(item item) bool {
+		sched, err := parseItemSchedule(item)
+		if err != nil {
+			return false
+		}
+		for t := sched.Next(bounds.start(from)); !t.IsZero() && bounds.beforeEnd(t, to); t = sched.Next(t) {
+			if r.contains(t.In(loc)) {
+				return true
+			}
+		}
+		return false
+	}
+	return filter{
+		cronJob: func(cronjob *batchv1.CronJob) bool {
+			return match(item{cronJob: cronjob})
+		},
+		cronWorkflow: func(cronworkflow *wfv1alpha1.CronWorkflow) bool {
+			return match(item{cronWorkflow: cronworkflow})
+		},
+	}
+}
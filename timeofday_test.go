@@ -0,0 +1,141 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func Test_parseTimeOfDayRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    timeOfDayRange
+		wantErr bool
+	}{
+		{value: "09:00-17:00", want: timeOfDayRange{start: 9 * 60, end: 17 * 60}},
+		{value: "22:00-02:30", want: timeOfDayRange{start: 22 * 60, end: 2*60 + 30}},
+		{value: "9:05 - 9:10", want: timeOfDayRange{start: 9*60 + 5, end: 9*60 + 10}},
+		{value: "09:00", wantErr: true},
+		{value: "09:00-25:00", wantErr: true},
+		{value: "morning-evening", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseTimeOfDayRange(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseTimeOfDayRange() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if diff := cmp.Diff(tt.want, got, cmp.AllowUnexported(timeOfDayRange{})); diff != "" {
+				t.Errorf("parseTimeOfDayRange() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_timeOfDayRange_contains(t *testing.T) {
+	t.Parallel()
+	businessHours := timeOfDayRange{start: 9 * 60, end: 17 * 60}
+	overnight := timeOfDayRange{start: 22 * 60, end: 2 * 60}
+	tests := []struct {
+		name string
+		r    timeOfDayRange
+		t    string
+		want bool
+	}{
+		{name: "business hours start", r: businessHours, t: "2023-01-24T09:00:00Z", want: true},
+		{name: "business hours middle", r: businessHours, t: "2023-01-24T12:34:00Z", want: true},
+		{name: "business hours end", r: businessHours, t: "2023-01-24T17:00:00Z", want: true},
+		{name: "business hours before", r: businessHours, t: "2023-01-24T08:59:00Z", want: false},
+		{name: "business hours after", r: businessHours, t: "2023-01-24T17:01:00Z", want: false},
+		{name: "overnight before midnight", r: overnight, t: "2023-01-24T23:00:00Z", want: true},
+		{name: "overnight midnight", r: overnight, t: "2023-01-24T00:00:00Z", want: true},
+		{name: "overnight after midnight", r: overnight, t: "2023-01-24T02:00:00Z", want: true},
+		{name: "overnight outside", r: overnight, t: "2023-01-24T12:00:00Z", want: false},
+		{name: "overnight just after", r: overnight, t: "2023-01-24T02:01:00Z", want: false},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.r.contains(getTime(tt.t)); got != tt.want {
+				t.Errorf("contains(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dailyBetweenFilter(t *testing.T) {
+	t.Parallel()
+	from := getTime("2023-01-24T00:00:00Z")
+	to := getTime("2023-01-25T00:00:00Z")
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 08:30 UTC is 09:30 in Berlin and 17:30 in Tokyo.
+	morning := getCronJob("ns-a", "morning", "30 8 * * *", false)
+	// 22:30 UTC is 23:30 in Berlin and 07:30 in Tokyo the next day.
+	night := getCronJob("ns-a", "night", "30 22 * * *", false)
+	// Every 6 hours from 03:00 UTC: 04:00, 10:00, 16:00, 22:00 in Berlin.
+	sixHourly := getCronWorkflow("ns-a", "six-hourly", "0 3-23/6 * * *", false)
+	// 10:00 in Tokyo is 02:00 in Berlin, in the schedule's own zone.
+	zoned := getCronWorkflow("ns-a", "zoned", "0 10 * * *", false)
+	zoned.Spec.Timezone = "Asia/Tokyo"
+
+	tests := []struct {
+		name string
+		r    timeOfDayRange
+		loc  *time.Location
+		want []string
+	}{
+		{
+			name: "business hours in Berlin",
+			r:    timeOfDayRange{start: 9 * 60, end: 17 * 60},
+			loc:  berlin,
+			want: []string{"CronJob/ns-a/morning", "CronWorkflow/ns-a/six-hourly"},
+		},
+		{
+			name: "business hours in Tokyo",
+			r:    timeOfDayRange{start: 9 * 60, end: 17 * 60},
+			loc:  tokyo,
+			want: []string{"CronWorkflow/ns-a/six-hourly", "CronWorkflow/ns-a/zoned"},
+		},
+		{
+			name: "overnight in Berlin",
+			r:    timeOfDayRange{start: 22 * 60, end: 2 * 60},
+			loc:  berlin,
+			want: []string{"CronJob/ns-a/night", "CronWorkflow/ns-a/six-hourly", "CronWorkflow/ns-a/zoned"},
+		},
+		{
+			name: "overnight in UTC",
+			r:    timeOfDayRange{start: 22 * 60, end: 2 * 60},
+			loc:  time.UTC,
+			want: []string{"CronJob/ns-a/night", "CronWorkflow/ns-a/zoned"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := applyFilters(
+				[]filter{dailyBetweenFilter(tt.r, tt.loc, from, to, boundaries{})},
+				mergeItems([]batchv1.CronJob{morning, night}, []wfv1alpha1.CronWorkflow{sixHourly, zoned}),
+			)
+			if diff := cmp.Diff(tt.want, getItemNames(got)); diff != "" {
+				t.Errorf("applyFilters() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
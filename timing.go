@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/unblee/kubectl-cls/pkg/cls"
+	"golang.org/x/exp/slog"
+)
+
+// The phases of '--timing'.
+const (
+	timingConfig     = "config"
+	timingList       = "list"
+	timingEvaluation = "evaluation"
+	timingPrint      = "print"
+)
+
+// timingPhase is the wall time of a phase in the json/yaml output document
+// and in the breakdown of '--timing'.
+type timingPhase struct {
+	Phase string `json:"phase"`
+	// Resource is the resource listed by the list phase, e.g. "cronjobs".
+	Resource string `json:"resource,omitempty"`
+	// Namespace is the namespace listed and evaluated, empty for all
+	// namespaces and for the phases of the whole run.
+	Namespace string `json:"namespace,omitempty"`
+	Duration  string `json:"duration"`
+}
+
+type timingEntry struct {
+	phase     string
+	resource  string
+	namespace string
+	duration  time.Duration
+}
+
+// timings records the wall time of the phases of '--timing'. It is safe for
+// concurrent use, as the namespaces are listed in parallel, and a nil timings
+// records nothing.
+type timings struct {
+	mu      sync.Mutex
+	entries []timingEntry
+}
+
+// Record the wall time of the phase.
+func (t *timings) record(phase, resource, namespace string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.entries = append(t.entries, timingEntry{phase: phase, resource: resource, namespace: namespace, duration: d})
+}
+
+// Get the phases recorded so far: the config first and the print last, and
+// the others grouped by the namespace in the order of the recording, which is
+// sequential in a namespace. Nil for a nil timings.
+func (t *timings) phases() []timingPhase {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	entries := append([]timingEntry{}, t.entries...)
+	t.mu.Unlock()
+
+	rank := func(phase string) int {
+		switch phase {
+		case timingConfig:
+			return 0
+		case timingPrint:
+			return 2
+		}
+		return 1
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if rank(a.phase) != rank(b.phase) {
+			return rank(a.phase) < rank(b.phase)
+		}
+		return a.namespace < b.namespace
+	})
+	ret := make([]timingPhase, len(entries))
+	for i, e := range entries {
+		ret[i] = timingPhase{Phase: e.phase, Resource: e.resource, Namespace: e.namespace, Duration: e.duration.Round(time.Microsecond).String()}
+	}
+	return ret
+}
+
+// Write the breakdown of the phases into stderr as a table, or log each phase
+// with '--log-format json' so that stderr stays JSON lines.
+func printTimings(stderr io.Writer, logger *slog.Logger, logFormat string, phases []timingPhase) error {
+	if logFormat == logFormatJSON {
+		for _, p := range phases {
+			attrs := []any{"phase", p.Phase, "duration", p.Duration}
+			if p.Resource != "" {
+				attrs = append(attrs, "resource", p.Resource)
+			}
+			if p.Namespace != "" {
+				attrs = append(attrs, "namespace", p.Namespace)
+			}
+			logger.Info(fmt.Sprintf("%s took %s", p.Phase, p.Duration), attrs...)
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(stderr, 0, 1, tablePadding, ' ', 0)
+	fmt.Fprintln(tw, strings.Join([]string{"Phase", "Resource", "Namespace", "Duration"}, "\t"))
+	for _, p := range phases {
+		resource, namespace := "<none>", "<none>"
+		if p.Resource != "" {
+			resource = p.Resource
+		}
+		if p.Phase == timingList || p.Phase == timingEvaluation {
+			namespace = displayNamespace(p.Namespace)
+		}
+		fmt.Fprintln(tw, strings.Join([]string{p.Phase, resource, namespace, p.Duration}, "\t"))
+	}
+	return tw.Flush()
+}
+
+// timingSource records the wall time of the listing of the source, and adds
+// it to listed, so that the evaluation by cls.Match right after each listing
+// is the rest of its wall time.
+type timingSource struct {
+	cls.Source
+	resource string
+	timings  *timings
+	listed   *time.Duration
+}
+
+func (s timingSource) List(ctx context.Context, opts cls.Options) ([]cls.Scheduled, error) {
+	start := time.Now()
+	objects, err := s.Source.List(ctx, opts)
+	d := time.Since(start)
+	s.timings.record(timingList, s.resource, opts.Namespace, d)
+	*s.listed += d
+	return objects, err
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_timings_phases(t *testing.T) {
+	t.Parallel()
+	timing := &timings{}
+	// The namespaces are listed in parallel, so they are recorded interleaved.
+	timing.record(timingConfig, "", "", time.Millisecond)
+	timing.record(timingList, "cronjobs", "ns-b", 2*time.Millisecond)
+	timing.record(timingList, "cronjobs", "ns-a", 3*time.Millisecond)
+	timing.record(timingList, "cronworkflows", "ns-b", 4*time.Millisecond)
+	timing.record(timingEvaluation, "", "ns-b", 5*time.Microsecond)
+	timing.record(timingList, "cronworkflows", "ns-a", 6*time.Millisecond)
+	timing.record(timingEvaluation, "", "ns-a", 1234*time.Nanosecond)
+	timing.record(timingPrint, "", "", 8*time.Millisecond)
+
+	want := []timingPhase{
+		{Phase: timingConfig, Duration: "1ms"},
+		{Phase: timingList, Resource: "cronjobs", Namespace: "ns-a", Duration: "3ms"},
+		{Phase: timingList, Resource: "cronworkflows", Namespace: "ns-a", Duration: "6ms"},
+		{Phase: timingEvaluation, Namespace: "ns-a", Duration: "1µs"},
+		{Phase: timingList, Resource: "cronjobs", Namespace: "ns-b", Duration: "2ms"},
+		{Phase: timingList, Resource: "cronworkflows", Namespace: "ns-b", Duration: "4ms"},
+		{Phase: timingEvaluation, Namespace: "ns-b", Duration: "5µs"},
+		{Phase: timingPrint, Duration: "8ms"},
+	}
+	if diff := cmp.Diff(want, timing.phases()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+	if got := (*timings)(nil).phases(); got != nil {
+		t.Errorf("want nil for a nil timings, got %v", got)
+	}
+}
+
+// Get the phases of the breakdown written into stderr, without the durations.
+func getTimingRows(stderr string) []string {
+	lines := strings.Split(strings.TrimSuffix(stderr, "\n"), "\n")
+	rows := []string{}
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		rows = append(rows, strings.Join(fields[:len(fields)-1], " "))
+	}
+	return rows
+}
+
+func Test_run_timing(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		args     []string
+		wantRows []string
+	}{
+		{
+			name: "namespace",
+			args: []string{"-n", "ns-a"},
+			wantRows: []string{
+				"config <none> <none>",
+				"list cronjobs ns-a",
+				"list cronworkflows ns-a",
+				"evaluation <none> ns-a",
+				"print <none> <none>",
+			},
+		},
+		{
+			name: "namespaces",
+			args: []string{"--namespaces", "ns-b,ns-a"},
+			wantRows: []string{
+				"config <none> <none>",
+				"list cronjobs ns-a",
+				"list cronworkflows ns-a",
+				"evaluation <none> ns-a",
+				"list cronjobs ns-b",
+				"list cronworkflows ns-b",
+				"evaluation <none> ns-b",
+				"print <none> <none>",
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, stderr, err := runFake(newRunClients(), append(append([]string{"--timing", "--no-headers"}, tt.args...), runPeriod...)...)
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			if !strings.Contains(stdout, "backup") {
+				t.Errorf("want the results on stdout, got %q", stdout)
+			}
+			if !strings.HasPrefix(stderr, "Phase ") {
+				t.Fatalf("want the breakdown with the headers, got %q", stderr)
+			}
+			if diff := cmp.Diff(tt.wantRows, getTimingRows(stderr)); diff != "" {
+				t.Errorf("phases (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_timing_json(t *testing.T) {
+	t.Parallel()
+	stdout, _, err := runFake(newRunClients(), append([]string{"--timing", "-n", "ns-a", "-o", "json"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	var doc printformat
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("failed to decode the output: %s", err)
+	}
+	// The printing isn't done yet when the document is written.
+	got := []string{}
+	for _, p := range doc.Timing {
+		if _, err := time.ParseDuration(p.Duration); err != nil {
+			t.Errorf("invalid duration of %s: %s", p.Phase, err)
+		}
+		got = append(got, strings.TrimSpace(p.Phase+" "+p.Resource+" "+p.Namespace))
+	}
+	want := []string{"config", "list cronjobs ns-a", "list cronworkflows ns-a", "evaluation  ns-a"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("timing (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_withoutTiming(t *testing.T) {
+	t.Parallel()
+	stdout, stderr, err := runFake(newRunClients(), append([]string{"-n", "ns-a", "-o", "json"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if stderr != "" || strings.Contains(stdout, `"timing"`) {
+		t.Errorf("want no timing, got stdout %q and stderr %q", stdout, stderr)
+	}
+}
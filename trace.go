@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/unblee/kubectl-cls/pkg/cls"
+)
+
+// traceTarget is a '--trace-eval' value. '*' matches any namespace or name.
+type traceTarget struct {
+	namespace string
+	name      string
+}
+
+// Parse the '--trace-eval' values in the 'NAMESPACE/NAME' form.
+func parseTraceTargets(values []string) ([]traceTarget, error) {
+	targets := make([]traceTarget, 0, len(values))
+	for _, value := range values {
+		ns, name, ok := strings.Cut(value, "/")
+		if !ok || ns == "" || name == "" {
+			return nil, fmt.Errorf("failed to parse '--trace-eval' value: must be NAMESPACE/NAME, e.g. 'default/backup' or '*/*': %s", value)
+		}
+		targets = append(targets, traceTarget{namespace: ns, name: name})
+	}
+	return targets, nil
+}
+
+func (t traceTarget) matches(namespace, name string) bool {
+	return (t.namespace == "*" || t.namespace == namespace) && (t.name == "*" || t.name == name)
+}
+
+// Get the cls.Options.Trace writing the evaluations of the targets into w, or
+// nil when there is no target.
+func newEvaluationTracer(w io.Writer, targets []traceTarget) func(cls.Evaluation) {
+	if len(targets) == 0 {
+		return nil
+	}
+	// The namespaces may be evaluated concurrently.
+	var mu sync.Mutex
+	return func(e cls.Evaluation) {
+		meta := e.Object.Meta()
+		for _, t := range targets {
+			if t.matches(meta.GetNamespace(), meta.GetName()) {
+				mu.Lock()
+				defer mu.Unlock()
+				writeEvaluation(w, e)
+				return
+			}
+		}
+	}
+}
+
+func writeEvaluation(w io.Writer, e cls.Evaluation) {
+	meta := e.Object.Meta()
+	fmt.Fprintf(w, "trace %s %s/%s: schedule '%s'\n", e.Object.Kind(), meta.GetNamespace(), meta.GetName(), e.Schedule)
+	fmt.Fprintf(w, "  expression: %s\n", e.Expression)
+	fmt.Fprintf(w, "  location:   %s\n", e.Location)
+	fmt.Fprintf(w, "  reference:  %s (from %s, %s)\n", e.Reference.Format(time.RFC3339), e.Window.From.Format(time.RFC3339), inclusivity(e.Window.ExclusiveFrom))
+	next := "none"
+	if !e.Next.IsZero() {
+		next = e.Next.Format(time.RFC3339)
+	}
+	fmt.Fprintf(w, "  next:       %s\n", next)
+	fmt.Fprintf(w, "  to:         %s (%s)\n", e.Window.To.Format(time.RFC3339), inclusivity(e.Window.ExclusiveTo))
+	switch {
+	case e.Included:
+		fmt.Fprintln(w, "  result:     included")
+	case e.Next.IsZero():
+		fmt.Fprintln(w, "  result:     excluded, never fires")
+	case e.Window.ExclusiveTo && e.Next.Equal(e.Window.To):
+		fmt.Fprintln(w, "  result:     excluded, next is at the exclusive to")
+	default:
+		fmt.Fprintln(w, "  result:     excluded, next is after to")
+	}
+}
+
+func inclusivity(exclusive bool) string {
+	if exclusive {
+		return "exclusive"
+	}
+	return "inclusive"
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_traceEval(t *testing.T) {
+	t.Parallel()
+	from, to := "2023-01-24T00:00:00Z", "2023-01-24T06:00:00Z"
+	path, _ := captureFixtures(t, "2023-01-24T00:00:00Z", "2023-01-25T00:00:00Z")
+
+	tests := []struct {
+		name string
+		args []string
+		// headersOnly compares only the first line of each trace.
+		headersOnly bool
+		want        []string
+		wantErr     string
+	}{
+		{
+			name: "included",
+			args: []string{"--trace-eval", "ns-a/cj1"},
+			want: []string{
+				"trace CronJob ns-a/cj1: schedule '0 1 * * *'",
+				"  expression: 0 1 * * *",
+				"  location:   UTC",
+				"  reference:  2023-01-23T23:59:59Z (from 2023-01-24T00:00:00Z, inclusive)",
+				"  next:       2023-01-24T01:00:00Z",
+				"  to:         2023-01-24T06:00:00Z (inclusive)",
+				"  result:     included",
+			},
+		},
+		{
+			name: "excluded",
+			args: []string{"--trace-eval", "ns-b/cj3", "--exclusive-from"},
+			want: []string{
+				"trace CronJob ns-b/cj3: schedule '0 12 * * *'",
+				"  expression: 0 12 * * *",
+				"  location:   UTC",
+				"  reference:  2023-01-24T00:00:00Z (from 2023-01-24T00:00:00Z, exclusive)",
+				"  next:       2023-01-24T12:00:00Z",
+				"  to:         2023-01-24T06:00:00Z (inclusive)",
+				"  result:     excluded, next is after to",
+			},
+		},
+		{
+			name:        "wildcard",
+			args:        []string{"--trace-eval", "*/cwf1", "--trace-eval", "ns-b/*"},
+			headersOnly: true,
+			want: []string{
+				"trace CronWorkflow ns-a/cwf1: schedule '30 1 * * *'",
+				"trace CronJob ns-b/cj2: schedule '0 2 * * *'",
+				"trace CronJob ns-b/cj3: schedule '0 12 * * *'",
+			},
+		},
+		{
+			name:    "invalid",
+			args:    []string{"--trace-eval", "cj1"},
+			wantErr: "failed to parse '--trace-eval' value: must be NAMESPACE/NAME, e.g. 'default/backup' or '*/*': cj1",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var stderr bytes.Buffer
+			args := append([]string{commandName, "--fixtures", path, "--from", from, "--to", to}, tt.args...)
+			err := run(&bytes.Buffer{}, &stderr, args)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("run() error = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("run() error = %v", err)
+			}
+			got := []string{}
+			for _, line := range strings.Split(stderr.String(), "\n") {
+				if strings.HasPrefix(line, "trace ") || (!tt.headersOnly && strings.HasPrefix(line, "  ")) {
+					got = append(got, line)
+				}
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("(-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// tzEntry compares the evaluation of an item in UTC, as the matching does,
+// with the evaluation in its declared time zone, as the controller does.
+type tzEntry struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Timezone is the declared time zone, or "UTC" when unset.
+	Timezone string `json:"timezone"`
+	// The first fires in the period, null without fires.
+	UTCFirstFire  *time.Time `json:"utcFirstFire"`
+	ZoneFirstFire *time.Time `json:"zoneFirstFire"`
+	// Mismatch is set when only one of the evaluations fires in the period.
+	Mismatch bool `json:"mismatch"`
+}
+
+type tzReport struct {
+	ApiVersion string    `json:"apiVersion"`
+	Items      []tzEntry `json:"items"`
+}
+
+// Get the first fire of the schedule in the period, or nil.
+func getFirstFire(sched cron.Schedule, from, to time.Time, bounds boundaries) *time.Time {
+	next := sched.Next(bounds.start(from))
+	if next.IsZero() || !bounds.beforeEnd(next, to) {
+		return nil
+	}
+	next = next.UTC()
+	return &next
+}
+
+// Evaluate the items both in UTC and in their time zones, and keep the items
+// firing in the period under either evaluation, in the order of the items.
+func buildTZReport(items []item, from, to time.Time, bounds boundaries) ([]tzEntry, error) {
+	entries := []tzEntry{}
+	for _, item := range items {
+		obj := item.object()
+		utcSched, err := cron.ParseStandard(item.schedule())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse schedule spec '%s' of %s '%s/%s': %w", item.schedule(), item.kind(), obj.GetNamespace(), obj.GetName(), err)
+		}
+		zoneSched, err := parseItemSchedule(item)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := tzEntry{
+			Kind:          item.kind(),
+			Namespace:     obj.GetNamespace(),
+			Name:          obj.GetName(),
+			Timezone:      item.timezone(),
+			UTCFirstFire:  getFirstFire(utcSched, from, to, bounds),
+			ZoneFirstFire: getFirstFire(zoneSched, from, to, bounds),
+		}
+		if entry.Timezone == "" {
+			entry.Timezone = "UTC"
+		}
+		if entry.UTCFirstFire == nil && entry.ZoneFirstFire == nil {
+			continue
+		}
+		entry.Mismatch = (entry.UTCFirstFire == nil) != (entry.ZoneFirstFire == nil)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func printTZReport(stdout io.Writer, output string, noHeaders bool, tf timeFormat, entries []tzEntry) error {
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(tzReport{ApiVersion: "v1", Items: entries}, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(tzReport{ApiVersion: "v1", Items: entries})
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	}
+
+	toMeta := func(t *time.Time) *metav1.Time {
+		if t == nil {
+			return nil
+		}
+		mt := metav1.NewTime(*t)
+		return &mt
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 1, tablePadding, ' ', 0)
+	if !noHeaders {
+		fmt.Fprintln(tw, strings.Join([]string{"Namespace", "Name", "Kind", "Timezone", "UTC First Fire", "Zone First Fire", "Mismatch"}, "\t"))
+	}
+	for _, e := range entries {
+		fmt.Fprintln(tw, strings.Join([]string{e.Namespace, e.Name, e.Kind, e.Timezone, tf.format(toMeta(e.UTCFirstFire)), tf.format(toMeta(e.ZoneFirstFire)), strconv.FormatBool(e.Mismatch)}, "\t"))
+	}
+	return tw.Flush()
+}
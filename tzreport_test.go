@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+func getTZReportFixtures() []item {
+	tokyo := "Asia/Tokyo"
+	// 01:00 in UTC, but 01:00 in Tokyo is 16:00 UTC of the previous day.
+	utcOnly := getCronJob("ns-a", "utc-only", "0 1 * * *", false)
+	utcOnly.Spec.TimeZone = &tokyo
+	// 00:30 either way.
+	unset := getCronJob("ns-a", "unset", "30 0 * * *", false)
+	// Neither way.
+	neither := getCronJob("ns-a", "neither", "0 12 * * *", false)
+	neither.Spec.TimeZone = &tokyo
+	// 11:00 in Sydney (UTC+11 in January) is 00:00 UTC.
+	zoneOnly := getCronWorkflow("ns-b", "zone-only", "0 11 * * *", false)
+	zoneOnly.Spec.Timezone = "Australia/Sydney"
+	return mergeItems([]batchv1.CronJob{utcOnly, unset, neither}, []wfv1alpha1.CronWorkflow{zoneOnly})
+}
+
+func Test_buildTZReport(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T02:00:00Z")
+	got, err := buildTZReport(getTZReportFixtures(), from, to, boundaries{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := func(value string) *time.Time {
+		t := getTime(value)
+		return &t
+	}
+	want := []tzEntry{
+		{Kind: "CronJob", Namespace: "ns-a", Name: "unset", Timezone: "UTC", UTCFirstFire: at("2023-01-24T00:30:00Z"), ZoneFirstFire: at("2023-01-24T00:30:00Z")},
+		{Kind: "CronJob", Namespace: "ns-a", Name: "utc-only", Timezone: "Asia/Tokyo", UTCFirstFire: at("2023-01-24T01:00:00Z"), Mismatch: true},
+		{Kind: "CronWorkflow", Namespace: "ns-b", Name: "zone-only", Timezone: "Australia/Sydney", ZoneFirstFire: at("2023-01-24T00:00:00Z"), Mismatch: true},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_buildTZReport_invalidTimezone(t *testing.T) {
+	t.Parallel()
+	cronjob := getCronJob("ns-a", "invalid", "0 1 * * *", false)
+	tz := "Mars/Olympus"
+	cronjob.Spec.TimeZone = &tz
+	_, err := buildTZReport(mergeItems([]batchv1.CronJob{cronjob}, nil), getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T02:00:00Z"), boundaries{})
+	if err == nil {
+		t.Error("want an error for the invalid time zone")
+	}
+}
+
+func Test_printTZReport(t *testing.T) {
+	t.Parallel()
+	from, to := getTime("2023-01-24T00:00:00Z"), getTime("2023-01-24T02:00:00Z")
+	entries, err := buildTZReport(getTZReportFixtures(), from, to, boundaries{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got bytes.Buffer
+	if err := printTZReport(&got, "", false, timeFormat{}, entries); err != nil {
+		t.Fatal(err)
+	}
+	want := `Namespace   Name        Kind           Timezone           UTC First Fire         Zone First Fire        Mismatch
+ns-a        unset       CronJob        UTC                2023-01-24T00:30:00Z   2023-01-24T00:30:00Z   false
+ns-a        utc-only    CronJob        Asia/Tokyo         2023-01-24T01:00:00Z   <none>                 true
+ns-b        zone-only   CronWorkflow   Australia/Sydney   <none>                 2023-01-24T00:00:00Z   true
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
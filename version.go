@@ -1,7 +1,85 @@
 package main
 
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
+	"sigs.k8s.io/yaml"
+)
+
 // These variables are set in build step
 var (
-	Version  = "VERSION"
-	Revision = "REVISION"
+	Version   = "VERSION"
+	Revision  = "REVISION"
+	BuildDate = "BUILD_DATE"
+)
+
+// The modules whose versions are reported by '--version -o json'.
+const (
+	clientGoModule = "k8s.io/client-go"
+	argoModule     = "github.com/argoproj/argo-workflows/v3"
 )
+
+type versionInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	ClientGo  string `json:"clientGo"`
+	Argo      string `json:"argoWorkflows"`
+}
+
+func getVersionInfo() versionInfo {
+	info := versionInfo{
+		Version:   Version,
+		Revision:  Revision,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		ClientGo:  "unknown",
+		Argo:      "unknown",
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range build.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			switch dep.Path {
+			case clientGoModule:
+				info.ClientGo = dep.Version
+			case argoModule:
+				info.Argo = dep.Version
+			}
+		}
+	}
+	return info
+}
+
+// Print the version in one line, or the build information as a document.
+func printVersion(stdout io.Writer, output string) error {
+	info := getVersionInfo()
+	switch output {
+	case "json":
+		b, err := json.MarshalIndent(info, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal to json: %w", err)
+		}
+		fmt.Fprintln(stdout, string(b))
+		return nil
+	case "yaml":
+		b, err := yaml.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("failed to marshal to yaml: %w", err)
+		}
+		fmt.Fprint(stdout, string(b))
+		return nil
+	case "":
+		fmt.Fprintf(stdout, "%s %s (rev:%s)\n", commandName, info.Version, info.Revision)
+		return nil
+	}
+	return fmt.Errorf("%s is unsupported output format for '--version'", output)
+}
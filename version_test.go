@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_printVersion(t *testing.T) {
+	t.Parallel()
+	var got bytes.Buffer
+	if err := printVersion(&got, ""); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("kubectl-cls VERSION (rev:REVISION)\n", got.String()); diff != "" {
+		t.Errorf("(-want +got):\n%s", diff)
+	}
+}
+
+func Test_printVersion_json(t *testing.T) {
+	t.Parallel()
+	var got bytes.Buffer
+	if err := printVersion(&got, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var info map[string]string
+	if err := json.Unmarshal(got.Bytes(), &info); err != nil {
+		t.Fatalf("failed to parse the json form: %s", err)
+	}
+	for _, key := range []string{"version", "revision", "buildDate", "goVersion", "platform", "clientGo", "argoWorkflows"} {
+		if info[key] == "" {
+			t.Errorf("want the '%s' key, got %v", key, info)
+		}
+	}
+	if info["goVersion"] != runtime.Version() {
+		t.Errorf("want the goVersion %s, got %s", runtime.Version(), info["goVersion"])
+	}
+	if info["platform"] != runtime.GOOS+"/"+runtime.GOARCH {
+		t.Errorf("want the platform %s/%s, got %s", runtime.GOOS, runtime.GOARCH, info["platform"])
+	}
+}
+
+func Test_printVersion_unsupported(t *testing.T) {
+	t.Parallel()
+	if err := printVersion(&bytes.Buffer{}, "wide"); err == nil {
+		t.Error("want an error for '-o wide'")
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The codes of the warnings of the listing in the json/yaml output document.
+// The schedule warnings have the codes of lintSchedule.
+const (
+	warningKindSkipped        = "KIND_SKIPPED"
+	warningNamespaceForbidden = "NAMESPACE_FORBIDDEN"
+)
+
+// objectReference is the object a warning is about. Name is empty for a kind
+// in a namespace, and Namespace is empty for all namespaces.
+type objectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+}
+
+// documentWarning is a warning written into stderr, in the warnings of the
+// json/yaml output document, so that the automation reading stdout sees it
+// too.
+type documentWarning struct {
+	Code    string           `json:"code"`
+	Object  *objectReference `json:"object,omitempty"`
+	Message string           `json:"message"`
+	// Schedule and Suggestion are of the schedule warnings: the schedule and
+	// its standard form, which is empty when it can't be fixed automatically.
+	Schedule   string `json:"schedule,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// Get the warnings of the kinds failed to be listed, see kindFailures.warn.
+func (f *kindFailures) documentWarnings() []documentWarning {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var warnings []documentWarning
+	for _, err := range f.errs {
+		w := documentWarning{Code: warningKindSkipped, Message: err.Error()}
+		var kerr kindError
+		if errors.As(err, &kerr) {
+			w.Object = &objectReference{Kind: kerr.kind, Namespace: kerr.namespace}
+		}
+		warnings = append(warnings, w)
+	}
+	return warnings
+}
+
+// Get the warnings of the namespaces skipped as forbidden, see
+// warnSkippedNamespaces.
+func skippedNamespaceWarnings(skipped []string) []documentWarning {
+	var warnings []documentWarning
+	for _, ns := range skipped {
+		warnings = append(warnings, documentWarning{
+			Code:    warningNamespaceForbidden,
+			Object:  &objectReference{Kind: "Namespace", Name: ns},
+			Message: fmt.Sprintf("skipped '%s' namespace where listing CronJobs or CronWorkflows is forbidden", ns),
+		})
+	}
+	return warnings
+}
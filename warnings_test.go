@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	argofake "github.com/argoproj/argo-workflows/v3/pkg/client/clientset/versioned/fake"
+	"github.com/google/go-cmp/cmp"
+	batchv1 "k8s.io/api/batch/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+// Decode the warnings of the json output document.
+func decodeWarnings(t *testing.T, stdout string) []documentWarning {
+	t.Helper()
+	var doc printformat
+	if err := json.Unmarshal([]byte(stdout), &doc); err != nil {
+		t.Fatalf("failed to decode the output: %s", err)
+	}
+	return doc.Warnings
+}
+
+func Test_run_warnings_fixtures(t *testing.T) {
+	t.Parallel()
+	from, to := "2023-01-24T00:00:00Z", "2023-01-24T06:00:00Z"
+	cronjobs := []batchv1.CronJob{
+		getCronJob("ns-a", "backup", "0 1 ? * *", false),
+		getCronJob("ns-a", "cleanup", "0  2 * * *", false),
+		getCronJob("ns-a", "report", "0 3 * * *", false),
+	}
+	items := make([]item, len(cronjobs))
+	for i := range cronjobs {
+		items[i] = item{cronJob: &cronjobs[i]}
+	}
+	var doc bytes.Buffer
+	if err := printJSON(&doc, items, documentOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	if err := os.WriteFile(path, doc.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := run(&stdout, &stderr, []string{commandName, "--fixtures", path, "--from", from, "--to", to, "-o", "json"}); err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := []documentWarning{
+		{
+			Code:       lintQuestionMark,
+			Object:     &objectReference{Kind: "CronJob", Namespace: "ns-a", Name: "backup"},
+			Message:    "'?' is the 'no specific value' of Quartz, use '*' instead",
+			Schedule:   "0 1 ? * *",
+			Suggestion: "0 1 * * *",
+		},
+		{
+			Code:       lintWhitespace,
+			Object:     &objectReference{Kind: "CronJob", Namespace: "ns-a", Name: "cleanup"},
+			Message:    "the fields are separated by extra or nonstandard whitespace",
+			Schedule:   "0  2 * * *",
+			Suggestion: "0 2 * * *",
+		},
+	}
+	if diff := cmp.Diff(want, decodeWarnings(t, stdout.String())); diff != "" {
+		t.Errorf("warnings (-want +got):\n%s", diff)
+	}
+	// Stderr stays as is.
+	if got := bytes.Count(stderr.Bytes(), []byte("warning: ")); got != 2 {
+		t.Errorf("want 2 warnings on stderr, got %q", stderr.String())
+	}
+}
+
+func Test_run_warnings_listing(t *testing.T) {
+	t.Parallel()
+	cronjobs := []batchv1.CronJob{
+		getCronJob("team-a", "backup", "0 1 * * *", false),
+		getCronJob("team-c", "backup", "0 1 * * *", false),
+	}
+	k8sClient := k8sfake.NewSimpleClientset(&cronjobs[0], &cronjobs[1])
+	// The user can't list the CronJobs in team-c, and the CronWorkflows fail
+	// to be listed anywhere.
+	forbidList(&k8sClient.Fake, "cronjobs", "team-c")
+	argoClient := argofake.NewSimpleClientset()
+	failList(&argoClient.Fake, "cronworkflows", errors.New("connection refused"))
+	c := &clients{k8s: k8sClient, argo: argoClient.ArgoprojV1alpha1(), noServerTable: true}
+
+	stdout, _, err := runFake(c, append([]string{"--namespaces", "team-a,team-c", "-o", "json"}, runPeriod...)...)
+	if !errors.Is(err, errPartialFailure) {
+		t.Fatalf("want the partial failure, got %v", err)
+	}
+	want := []documentWarning{
+		{
+			Code:    warningNamespaceForbidden,
+			Object:  &objectReference{Kind: "Namespace", Name: "team-c"},
+			Message: "skipped 'team-c' namespace where listing CronJobs or CronWorkflows is forbidden",
+		},
+		{
+			Code:    warningKindSkipped,
+			Object:  &objectReference{Kind: "CronWorkflow", Namespace: "team-a"},
+			Message: "failed to get CronWorkflow in 'team-a' namespace: connection refused",
+		},
+	}
+	if diff := cmp.Diff(want, decodeWarnings(t, stdout)); diff != "" {
+		t.Errorf("warnings (-want +got):\n%s", diff)
+	}
+}
+
+func Test_run_withoutWarnings(t *testing.T) {
+	t.Parallel()
+	c := newFakeClients(
+		[]batchv1.CronJob{getCronJob("ns-a", "backup", "0 1 * * *", false)},
+		[]wfv1alpha1.CronWorkflow{},
+	)
+	stdout, _, err := runFake(c, append([]string{"-n", "ns-a", "-o", "json"}, runPeriod...)...)
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	if bytes.Contains([]byte(stdout), []byte(`"warnings"`)) {
+		t.Errorf("want no warnings, got %s", stdout)
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Headers of the columns added by '-o wide'.
+// Columns without an equivalent field in a kind are left blank.
+var wideHeaders = []string{"Schedules", "Owner", "Images", "Deadline", "Hist", "Backoff", "Concurrency", "Workflow", "Last Schedule", "First Fire", "Last Fire", "Pods"}
+
+// Get the wide columns of the item. The fires and the pods are "<none>"
+// without a window.
+func wideColumns(item item, tf timeFormat, window *documentWindow, bounds boundaries) []string {
+	columns := []string{strconv.Itoa(len(item.schedules()))}
+	switch {
+	case item.cronJob != nil:
+		columns = append(columns, cronJobWideColumns(item.cronJob, tf)...)
+	case item.cronWorkflow != nil:
+		columns = append(columns, cronWorkflowWideColumns(item.cronWorkflow, tf)...)
+	default:
+		// The objects of the providers have none of these fields.
+		columns = append(columns, make([]string, len(wideHeaders)-4)...)
+	}
+	var fires fireRange
+	pods := "<none>"
+	if window != nil {
+		fires = getFireRange(item, window.From, window.To, bounds)
+		if estimate, err := estimatePods(item, window.From, window.To, bounds); err == nil {
+			pods = estimate.format()
+		}
+	}
+	first, last := fires.format(tf)
+	return append(columns, first, last, pods)
+}
+
+func cronJobWideColumns(cronjob *batchv1.CronJob, tf timeFormat) []string {
+	return []string{
+		getOwner(cronjob),
+		formatCronJobImages(cronjob),
+		formatDeadline(cronjob.Spec.StartingDeadlineSeconds),
+		formatHistoryLimits(cronjob.Spec.SuccessfulJobsHistoryLimit, cronjob.Spec.FailedJobsHistoryLimit),
+		formatCronJobBackoff(cronjob),
+		formatConcurrencyPolicy(string(cronjob.Spec.ConcurrencyPolicy)),
+		"",
+		tf.format(cronjob.Status.LastScheduleTime),
+	}
+}
+
+func cronWorkflowWideColumns(cronworkflow *wfv1alpha1.CronWorkflow, tf timeFormat) []string {
+	return []string{
+		getOwner(cronworkflow),
+		formatCronWorkflowImages(cronworkflow),
+		formatDeadline(cronworkflow.Spec.StartingDeadlineSeconds),
+		formatHistoryLimits(cronworkflow.Spec.SuccessfulJobsHistoryLimit, cronworkflow.Spec.FailedJobsHistoryLimit),
+		formatCronWorkflowBackoff(cronworkflow),
+		formatConcurrencyPolicy(string(cronworkflow.Spec.ConcurrencyPolicy)),
+		formatCronWorkflowWorkflow(cronworkflow),
+		tf.format(cronworkflow.Status.LastScheduledTime),
+	}
+}
+
+// Labels which identify the owning application, in priority order.
+var ownerLabels = []string{
+	"argocd.argoproj.io/instance",
+	"app.kubernetes.io/instance",
+	"helm.sh/chart",
+}
+
+// Get the owning application of the object from its labels, falling back to
+// the controller in ownerReferences, or "<none>".
+func getOwner(obj metav1.Object) string {
+	labels := obj.GetLabels()
+	for _, key := range ownerLabels {
+		if v := labels[key]; v != "" {
+			return v
+		}
+	}
+	if ref := metav1.GetControllerOfNoCopy(obj); ref != nil {
+		return fmt.Sprintf("%s/%s", ref.Kind, ref.Name)
+	}
+	return "<none>"
+}
+
+// Get the init containers and containers in the Job template of the CronJob.
+func getCronJobContainers(cronjob *batchv1.CronJob) []corev1.Container {
+	podSpec := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	containers := make([]corev1.Container, 0, len(podSpec.InitContainers)+len(podSpec.Containers))
+	containers = append(containers, podSpec.InitContainers...)
+	containers = append(containers, podSpec.Containers...)
+	return containers
+}
+
+// Get the init containers, containers, scripts, and container sets in the
+// inline templates of the CronWorkflow. Templates referenced by
+// workflowTemplateRef are not resolved.
+func getCronWorkflowContainers(cronworkflow *wfv1alpha1.CronWorkflow) []corev1.Container {
+	containers := []corev1.Container{}
+	for _, tmpl := range cronworkflow.Spec.WorkflowSpec.Templates {
+		for _, c := range tmpl.InitContainers {
+			containers = append(containers, c.Container)
+		}
+		if tmpl.Container != nil {
+			containers = append(containers, *tmpl.Container)
+		}
+		if tmpl.Script != nil {
+			containers = append(containers, tmpl.Script.Container)
+		}
+		if tmpl.ContainerSet != nil {
+			for _, c := range tmpl.ContainerSet.Containers {
+				containers = append(containers, c.Container)
+			}
+		}
+	}
+	return containers
+}
+
+// Get the deduplicated images of the containers in the order of appearance.
+func getImages(containers []corev1.Container) []string {
+	seen := map[string]bool{}
+	images := []string{}
+	for _, c := range containers {
+		if c.Image == "" || seen[c.Image] {
+			continue
+		}
+		seen[c.Image] = true
+		images = append(images, c.Image)
+	}
+	return images
+}
+
+func formatCronJobImages(cronjob *batchv1.CronJob) string {
+	images := getImages(getCronJobContainers(cronjob))
+	if len(images) == 0 {
+		return "<none>"
+	}
+	return strings.Join(images, ",")
+}
+
+func formatCronWorkflowImages(cronworkflow *wfv1alpha1.CronWorkflow) string {
+	images := getImages(getCronWorkflowContainers(cronworkflow))
+	if len(images) == 0 {
+		if cronworkflow.Spec.WorkflowSpec.WorkflowTemplateRef != nil {
+			return "<template>"
+		}
+		return "<none>"
+	}
+	return strings.Join(images, ",")
+}
+
+// Truncate the value to the width in runes with a trailing "...".
+// A width of zero or less disables the truncation.
+func truncate(value string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(value) <= width {
+		return value
+	}
+	const ellipsis = "..."
+	if width <= len(ellipsis) {
+		return string([]rune(value)[:width])
+	}
+	return string([]rune(value)[:width-len(ellipsis)]) + ellipsis
+}
+
+// Format startingDeadlineSeconds in seconds, or "<unset>" when nil.
+func formatDeadline(seconds *int64) string {
+	if seconds == nil {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%ds", *seconds)
+}
+
+// Format the history limits as 'successful/failed', with "-" for an unset limit.
+func formatHistoryLimits(successful, failed *int32) string {
+	format := func(limit *int32) string {
+		if limit == nil {
+			return "-"
+		}
+		return strconv.Itoa(int(*limit))
+	}
+	return format(successful) + "/" + format(failed)
+}
+
+// Format the Job backoffLimit, or "<unset>" when nil.
+func formatCronJobBackoff(cronjob *batchv1.CronJob) string {
+	limit := cronjob.Spec.JobTemplate.Spec.BackoffLimit
+	if limit == nil {
+		return "<unset>"
+	}
+	return strconv.Itoa(int(*limit))
+}
+
+// Format the workflow-level retryStrategy limit, the CronWorkflow equivalent
+// of backoffLimit, or "<unset>" when nil.
+func formatCronWorkflowBackoff(cronworkflow *wfv1alpha1.CronWorkflow) string {
+	strategy := cronworkflow.Spec.WorkflowSpec.RetryStrategy
+	if strategy == nil || strategy.Limit == nil {
+		return "<unset>"
+	}
+	return strategy.Limit.String()
+}
+
+// Format the concurrencyPolicy. Both kinds treat an empty policy as "Allow".
+func formatConcurrencyPolicy(policy string) string {
+	if policy == "" {
+		return "Allow"
+	}
+	return policy
+}
+
+// Format what the CronWorkflow runs: the workflowTemplateRef, or the
+// entrypoint of the inline workflow spec.
+func formatCronWorkflowWorkflow(cronworkflow *wfv1alpha1.CronWorkflow) string {
+	spec := cronworkflow.Spec.WorkflowSpec
+	if ref := spec.WorkflowTemplateRef; ref != nil {
+		if ref.ClusterScope {
+			return "clusterworkflowtemplate/" + ref.Name
+		}
+		return "workflowtemplate/" + ref.Name
+	}
+	if spec.Entrypoint != "" {
+		return "entrypoint/" + spec.Entrypoint
+	}
+	return "<none>"
+}
+
+// timeFormat is how the timestamps are printed in the table.
+type timeFormat struct {
+	// location is the zone of the absolute timestamps. Nil means UTC.
+	location *time.Location
+	// relative prints the timestamps as offsets from now, e.g. "in 42m".
+	relative bool
+	now      time.Time
+}
+
+// Format the timestamp, or "<none>" when nil.
+func (f timeFormat) format(t *metav1.Time) string {
+	if t == nil {
+		return "<none>"
+	}
+	if f.relative {
+		return humanizeOffset(t.Sub(f.now))
+	}
+	loc := f.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// Humanize the offset from now in its largest unit, from seconds up to weeks,
+// e.g. "in 42m" for the future and "3h ago" for the past.
+func humanizeOffset(d time.Duration) string {
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	var s string
+	switch {
+	case abs < time.Second:
+		return "now"
+	case abs < time.Minute:
+		s = fmt.Sprintf("%ds", abs/time.Second)
+	case abs < time.Hour:
+		s = fmt.Sprintf("%dm", abs/time.Minute)
+	case abs < 24*time.Hour:
+		s = fmt.Sprintf("%dh", abs/time.Hour)
+	case abs < 7*24*time.Hour:
+		s = fmt.Sprintf("%dd", abs/(24*time.Hour))
+	default:
+		s = fmt.Sprintf("%dw", abs/(7*24*time.Hour))
+	}
+	if d < 0 {
+		return s + " ago"
+	}
+	return "in " + s
+}
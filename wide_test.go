@@ -0,0 +1,428 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	wfv1alpha1 "github.com/argoproj/argo-workflows/v3/pkg/apis/workflow/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func Test_getOwner(t *testing.T) {
+	t.Parallel()
+	controller := true
+	tests := []struct {
+		name string
+		meta metav1.ObjectMeta
+		want string
+	}{
+		{
+			name: "no metadata",
+			meta: metav1.ObjectMeta{},
+			want: "<none>",
+		},
+		{
+			name: "argocd instance label wins",
+			meta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"argocd.argoproj.io/instance": "argo-app",
+					"app.kubernetes.io/instance":  "k8s-app",
+					"helm.sh/chart":               "chart-1.0.0",
+				},
+			},
+			want: "argo-app",
+		},
+		{
+			name: "app.kubernetes.io/instance label",
+			meta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"app.kubernetes.io/instance": "k8s-app",
+					"helm.sh/chart":              "chart-1.0.0",
+				},
+			},
+			want: "k8s-app",
+		},
+		{
+			name: "helm chart label",
+			meta: metav1.ObjectMeta{
+				Labels: map[string]string{"helm.sh/chart": "chart-1.0.0"},
+			},
+			want: "chart-1.0.0",
+		},
+		{
+			name: "empty label value is ignored",
+			meta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"argocd.argoproj.io/instance": "",
+					"helm.sh/chart":               "chart-1.0.0",
+				},
+			},
+			want: "chart-1.0.0",
+		},
+		{
+			name: "controller owner reference",
+			meta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ConfigMap", Name: "not-controller"},
+					{Kind: "BackupPolicy", Name: "nightly", Controller: &controller},
+				},
+			},
+			want: "BackupPolicy/nightly",
+		},
+		{
+			name: "owner reference without controller",
+			meta: metav1.ObjectMeta{
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "ConfigMap", Name: "not-controller"},
+				},
+			},
+			want: "<none>",
+		},
+		{
+			name: "labels win over owner reference",
+			meta: metav1.ObjectMeta{
+				Labels: map[string]string{"app.kubernetes.io/instance": "k8s-app"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "BackupPolicy", Name: "nightly", Controller: &controller},
+				},
+			},
+			want: "k8s-app",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := getOwner(&tt.meta); got != tt.want {
+				t.Errorf("getOwner() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatCronJobImages(t *testing.T) {
+	t.Parallel()
+	multi := getCronJob("ns-a", "n-1", "0 0 * * *", false)
+	multi.Spec.JobTemplate.Spec.Template.Spec = corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "busybox:1.36"}},
+		Containers: []corev1.Container{
+			{Name: "main", Image: "ghcr.io/example/app:v1"},
+			{Name: "sidecar", Image: "busybox:1.36"},
+			{Name: "proxy", Image: "envoyproxy/envoy:v1.25"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		cronjob batchv1.CronJob
+		want    string
+	}{
+		{
+			name:    "no containers",
+			cronjob: getCronJob("ns-a", "n-1", "0 0 * * *", false),
+			want:    "<none>",
+		},
+		{
+			name:    "multiple containers with init containers",
+			cronjob: multi,
+			want:    "busybox:1.36,ghcr.io/example/app:v1,envoyproxy/envoy:v1.25",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatCronJobImages(&tt.cronjob); got != tt.want {
+				t.Errorf("formatCronJobImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatCronWorkflowImages(t *testing.T) {
+	t.Parallel()
+	inline := getCronWorkflow("ns-a", "n-1", "0 0 * * *", false)
+	inline.Spec.WorkflowSpec.Templates = []wfv1alpha1.Template{
+		{Name: "main", Steps: []wfv1alpha1.ParallelSteps{}},
+		{
+			Name:           "container",
+			InitContainers: []wfv1alpha1.UserContainer{{Container: corev1.Container{Image: "busybox:1.36"}}},
+			Container:      &corev1.Container{Image: "ghcr.io/example/app:v1"},
+		},
+		{Name: "script", Script: &wfv1alpha1.ScriptTemplate{Container: corev1.Container{Image: "python:3.11"}}},
+		{
+			Name: "set",
+			ContainerSet: &wfv1alpha1.ContainerSetTemplate{
+				Containers: []wfv1alpha1.ContainerNode{
+					{Container: corev1.Container{Image: "ghcr.io/example/app:v1"}},
+					{Container: corev1.Container{Image: "alpine:3.17"}},
+				},
+			},
+		},
+	}
+	templateRef := getCronWorkflow("ns-a", "n-2", "0 0 * * *", false)
+	templateRef.Spec.WorkflowSpec.WorkflowTemplateRef = &wfv1alpha1.WorkflowTemplateRef{Name: "shared"}
+
+	tests := []struct {
+		name         string
+		cronworkflow wfv1alpha1.CronWorkflow
+		want         string
+	}{
+		{
+			name:         "no templates",
+			cronworkflow: getCronWorkflow("ns-a", "n-3", "0 0 * * *", false),
+			want:         "<none>",
+		},
+		{
+			name:         "inline templates",
+			cronworkflow: inline,
+			want:         "busybox:1.36,ghcr.io/example/app:v1,python:3.11,alpine:3.17",
+		},
+		{
+			name:         "workflowTemplateRef only",
+			cronworkflow: templateRef,
+			want:         "<template>",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatCronWorkflowImages(&tt.cronworkflow); got != tt.want {
+				t.Errorf("formatCronWorkflowImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_truncate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value string
+		width int
+		want  string
+	}{
+		{value: "abcdefghij", width: 0, want: "abcdefghij"},
+		{value: "abcdefghij", width: 10, want: "abcdefghij"},
+		{value: "abcdefghij", width: 8, want: "abcde..."},
+		{value: "abcdefghij", width: 2, want: "ab"},
+		{value: "日本語のジョブ名", width: 6, want: "日本語..."},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			if got := truncate(tt.value, tt.width); got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.value, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatDeadline(t *testing.T) {
+	t.Parallel()
+	ptr := func(v int64) *int64 { return &v }
+	tests := []struct {
+		name    string
+		seconds *int64
+		want    string
+	}{
+		{name: "nil", seconds: nil, want: "<unset>"},
+		{name: "zero", seconds: ptr(0), want: "0s"},
+		{name: "small", seconds: ptr(200), want: "200s"},
+		{name: "large", seconds: ptr(8640000), want: "8640000s"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatDeadline(tt.seconds); got != tt.want {
+				t.Errorf("formatDeadline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatHistoryLimits(t *testing.T) {
+	t.Parallel()
+	ptr := func(v int32) *int32 { return &v }
+	tests := []struct {
+		name       string
+		successful *int32
+		failed     *int32
+		want       string
+	}{
+		{name: "both set", successful: ptr(3), failed: ptr(1), want: "3/1"},
+		{name: "zero", successful: ptr(0), failed: ptr(0), want: "0/0"},
+		{name: "successful unset", successful: nil, failed: ptr(1), want: "-/1"},
+		{name: "both unset", successful: nil, failed: nil, want: "-/-"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := formatHistoryLimits(tt.successful, tt.failed); got != tt.want {
+				t.Errorf("formatHistoryLimits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatBackoff(t *testing.T) {
+	t.Parallel()
+	backoffLimit := int32(6)
+	cronjob := getCronJob("ns-a", "n-1", "0 0 * * *", false)
+	cronjob.Spec.JobTemplate.Spec.BackoffLimit = &backoffLimit
+	if got := formatCronJobBackoff(&cronjob); got != "6" {
+		t.Errorf("formatCronJobBackoff() = %v, want 6", got)
+	}
+	unset := getCronJob("ns-a", "n-2", "0 0 * * *", false)
+	if got := formatCronJobBackoff(&unset); got != "<unset>" {
+		t.Errorf("formatCronJobBackoff() = %v, want <unset>", got)
+	}
+
+	limit := intstr.FromInt(2)
+	cronworkflow := getCronWorkflow("ns-a", "n-3", "0 0 * * *", false)
+	cronworkflow.Spec.WorkflowSpec.RetryStrategy = &wfv1alpha1.RetryStrategy{Limit: &limit}
+	if got := formatCronWorkflowBackoff(&cronworkflow); got != "2" {
+		t.Errorf("formatCronWorkflowBackoff() = %v, want 2", got)
+	}
+	noLimit := getCronWorkflow("ns-a", "n-4", "0 0 * * *", false)
+	noLimit.Spec.WorkflowSpec.RetryStrategy = &wfv1alpha1.RetryStrategy{}
+	if got := formatCronWorkflowBackoff(&noLimit); got != "<unset>" {
+		t.Errorf("formatCronWorkflowBackoff() = %v, want <unset>", got)
+	}
+}
+
+func Test_printList_displayTimezone(t *testing.T) {
+	t.Parallel()
+	lastScheduleTime := metav1.NewTime(getTime("2023-01-24T01:00:00Z"))
+	cronjob := getCronJob("ns-a", "n-1", "0 1 * * *", false)
+	cronjob.Status.LastScheduleTime = &lastScheduleTime
+	cronworkflow := getCronWorkflow("ns-a", "n-2", "0 1 * * *", false)
+	cronworkflow.Status.LastScheduledTime = &lastScheduleTime
+	items := mergeItems([]batchv1.CronJob{cronjob}, []wfv1alpha1.CronWorkflow{cronworkflow})
+
+	tokyo, err := parseLocation("display-timezone", "Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := parseLocation("display-timezone", "America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		location *time.Location
+		want     []string
+	}{
+		{
+			name:     "default is UTC",
+			location: nil,
+			want:     []string{"2023-01-24T01:00:00Z", "2023-01-24T01:00:00Z"},
+		},
+		{
+			name:     "Asia/Tokyo",
+			location: tokyo,
+			want:     []string{"2023-01-24T10:00:00+09:00", "2023-01-24T10:00:00+09:00"},
+		},
+		{
+			name:     "America/New_York",
+			location: newYork,
+			want:     []string{"2023-01-23T20:00:00-05:00", "2023-01-23T20:00:00-05:00"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var got bytes.Buffer
+			printList(&got, printListOptions{noHeaders: true, wide: true, timeFormat: timeFormat{location: tt.location}}, items)
+			lines := strings.Split(strings.TrimSuffix(got.String(), "\n"), "\n")
+			if len(lines) != len(tt.want) {
+				t.Fatalf("printList() printed %d lines, want %d", len(lines), len(tt.want))
+			}
+			for i, line := range lines {
+				fields := strings.Fields(line)
+				// Followed by First Fire, Last Fire, and Pods, "<none>" without a
+				// window.
+				if last := fields[len(fields)-4]; last != tt.want[i] {
+					t.Errorf("Last Schedule of line %d = %v, want %v", i, last, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_parseLocation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value   string
+		want    *time.Location
+		wantErr bool
+	}{
+		{value: "UTC", want: time.UTC},
+		{value: "local", want: time.Local},
+		{value: "Local", want: time.Local},
+		{value: "Mars/Olympus_Mons", wantErr: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseLocation("display-timezone", tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseLocation() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.String() != tt.want.String() {
+				t.Errorf("parseLocation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_humanizeOffset(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 0, want: "now"},
+		{d: 500 * time.Millisecond, want: "now"},
+		{d: 42 * time.Second, want: "in 42s"},
+		{d: -59 * time.Second, want: "59s ago"},
+		{d: 42*time.Minute + 30*time.Second, want: "in 42m"},
+		{d: -3*time.Hour - 59*time.Minute, want: "3h ago"},
+		{d: 24 * time.Hour, want: "in 1d"},
+		{d: -6*24*time.Hour - 23*time.Hour, want: "6d ago"},
+		{d: 7 * 24 * time.Hour, want: "in 1w"},
+		{d: -30 * 24 * time.Hour, want: "4w ago"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.want, func(t *testing.T) {
+			t.Parallel()
+			if got := humanizeOffset(tt.d); got != tt.want {
+				t.Errorf("humanizeOffset(%v) = %v, want %v", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_timeFormat_relative(t *testing.T) {
+	t.Parallel()
+	lastScheduleTime := metav1.NewTime(getTime("2023-01-24T01:00:00Z"))
+	tf := timeFormat{relative: true, now: getTime("2023-01-24T04:30:00Z")}
+	if got := tf.format(&lastScheduleTime); got != "3h ago" {
+		t.Errorf("format() = %v, want 3h ago", got)
+	}
+	if got := tf.format(nil); got != "<none>" {
+		t.Errorf("format() = %v, want <none>", got)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// defaultWindow is the length of the period without '--from' nor '--window'.
+const defaultWindow = time.Hour
+
+// Resolve the period of '--from' and '--to' against now in the location, see
+// parseTimeFlag, where a date alone of '--to' is its end. '--to' defaults to
+// now, and '--from' to the window before '--to', which is defaultWindow
+// without '--window', which can't be used with '--from'. The flags given are
+// parsed as they are.
+func resolveWindow(fromFlag, toFlag, windowFlag string, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	var from, to time.Time
+	if fromFlag != "" && windowFlag != "" {
+		return from, to, errors.New("'--window' can only be used without '--from'")
+	}
+	window := defaultWindow
+	if windowFlag != "" {
+		var err error
+		window, err = parseDuration(windowFlag)
+		if err != nil {
+			return from, to, fmt.Errorf("failed to parse '--window' value: %w", err)
+		}
+		if window <= 0 {
+			return from, to, errors.New("'--window' must be positive")
+		}
+	}
+
+	to = now
+	if toFlag != "" {
+		var err error
+		to, err = parseTimeFlag(toFlag, time.RFC3339, true, now, loc)
+		if err != nil {
+			return from, to, fmt.Errorf("failed to parse '--to' value: %w", err)
+		}
+	}
+	if fromFlag == "" {
+		return to.Add(-window), to, nil
+	}
+	from, err := parseTimeFlag(fromFlag, time.RFC3339, false, now, loc)
+	if err != nil {
+		return from, to, fmt.Errorf("failed to parse '--from' value: %w", err)
+	}
+	return from, to, nil
+}
+
+// The preset windows of the flags, each of which is a calendar period.
+const (
+	presetToday    = "today"
+	presetTomorrow = "tomorrow"
+	presetThisWeek = "this-week"
+)
+
+// windowPresets are the flags of the preset windows, exclusive of each other.
+var windowPresets = []string{presetToday, presetTomorrow, presetThisWeek}
+
+// Parse '--week-start', the first day of '--this-week'.
+func parseWeekStart(value string) (time.Weekday, error) {
+	switch strings.ToLower(value) {
+	case "monday":
+		return time.Monday, nil
+	case "sunday":
+		return time.Sunday, nil
+	}
+	return 0, fmt.Errorf("%s is unsupported '--week-start', one of: monday|sunday", value)
+}
+
+// Get the period of the preset containing now in the location, from its
+// midnight to its last second, as '--to' is included. The week starts on
+// weekStart.
+func presetWindow(preset string, weekStart time.Weekday, now time.Time, loc *time.Location) (time.Time, time.Time) {
+	now = now.In(loc)
+	y, m, d := now.Date()
+	days := 1
+	switch preset {
+	case presetTomorrow:
+		d++
+	case presetThisWeek:
+		d -= (int(now.Weekday()) - int(weekStart) + 7) % 7
+		days = 7
+	}
+	from := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	// The next midnight, which keeps the wall clock over a daylight saving
+	// time change.
+	end := time.Date(y, m, d+days, 0, 0, 0, 0, loc)
+	return from, end.Add(-time.Second)
+}
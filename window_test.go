@@ -0,0 +1,150 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_resolveWindow(t *testing.T) {
+	t.Parallel()
+	now := getTime("2023-01-25T10:30:00Z")
+	tests := []struct {
+		name                      string
+		fromFlag, toFlag, window  string
+		wantFrom, wantTo, wantErr string
+	}{
+		{name: "defaults", wantFrom: "2023-01-25T09:30:00Z", wantTo: "2023-01-25T10:30:00Z"},
+		{name: "window", window: "6h", wantFrom: "2023-01-25T04:30:00Z", wantTo: "2023-01-25T10:30:00Z"},
+		{name: "ISO 8601 window", window: "P1D", wantFrom: "2023-01-24T10:30:00Z", wantTo: "2023-01-25T10:30:00Z"},
+		{name: "to only", toFlag: "2023-01-24T06:00:00Z", wantFrom: "2023-01-24T05:00:00Z", wantTo: "2023-01-24T06:00:00Z"},
+		{name: "to with window", toFlag: "tomorrow", window: "2h", wantFrom: "2023-01-25T22:00:00Z", wantTo: "2023-01-26T00:00:00Z"},
+		{name: "from only", fromFlag: "-2h", wantFrom: "2023-01-25T08:30:00Z", wantTo: "2023-01-25T10:30:00Z"},
+		{name: "both", fromFlag: "2023-01-24T00:00:00+09:00", toFlag: "2023-01-24T06:00:00+09:00", wantFrom: "2023-01-24T00:00:00+09:00", wantTo: "2023-01-24T06:00:00+09:00"},
+		{name: "from with window", fromFlag: "2023-01-26T00:00:00Z", window: "6h", wantErr: "'--window' can only be used without '--from'"},
+		{name: "both with window", fromFlag: "-2h", toFlag: "now", window: "1h", wantErr: "'--window' can only be used without '--from'"},
+		{name: "zero window", window: "0s", wantErr: "'--window' must be positive"},
+		{name: "negative window", window: "-1h", wantErr: "'--window' must be positive"},
+		{name: "invalid window", window: "P1M", wantErr: "failed to parse '--window' value: years and months have no fixed length: P1M"},
+		{name: "invalid to", toFlag: "someday", wantErr: `failed to parse '--to' value: parsing time "someday" as "2006-01-02T15:04:05Z07:00": cannot parse "someday" as "2006"` + ", the supported formats are " + supportedTimeFormats},
+		{name: "invalid from", fromFlag: "someday", wantErr: `failed to parse '--from' value: parsing time "someday" as "2006-01-02T15:04:05Z07:00": cannot parse "someday" as "2006"` + ", the supported formats are " + supportedTimeFormats},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			from, to, err := resolveWindow(tt.fromFlag, tt.toFlag, tt.window, now, time.UTC)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantFrom, from.Format(time.RFC3339)); diff != "" {
+				t.Errorf("from (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTo, to.Format(time.RFC3339)); diff != "" {
+				t.Errorf("to (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_defaultWindow(t *testing.T) {
+	t.Parallel()
+	if _, _, err := runFake(newRunClients()); err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	if _, _, err := runFake(newRunClients(), "--window", "6h"); err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	_, _, err := runFake(newRunClients(), "--range", "2023-01-24T00:00:00Z/PT6H", "--window", "6h")
+	if want := "'--range' can't be used with '--window'"; err == nil || err.Error() != want {
+		t.Errorf("want %q, got %v", want, err)
+	}
+}
+
+func Test_presetWindow(t *testing.T) {
+	t.Parallel()
+	// Wednesday.
+	now := getTime("2023-01-25T10:30:00Z")
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		preset           string
+		weekStart        time.Weekday
+		now              time.Time
+		loc              *time.Location
+		wantFrom, wantTo string
+	}{
+		{preset: presetToday, wantFrom: "2023-01-25T00:00:00Z", wantTo: "2023-01-25T23:59:59Z"},
+		{preset: presetTomorrow, wantFrom: "2023-01-26T00:00:00Z", wantTo: "2023-01-26T23:59:59Z"},
+		{preset: presetThisWeek, weekStart: time.Monday, wantFrom: "2023-01-23T00:00:00Z", wantTo: "2023-01-29T23:59:59Z"},
+		{preset: presetThisWeek, weekStart: time.Sunday, wantFrom: "2023-01-22T00:00:00Z", wantTo: "2023-01-28T23:59:59Z"},
+		// The first day of the week is in the week.
+		{preset: presetThisWeek, weekStart: time.Monday, now: getTime("2023-01-23T00:00:00Z"), wantFrom: "2023-01-23T00:00:00Z", wantTo: "2023-01-29T23:59:59Z"},
+		{preset: presetThisWeek, weekStart: time.Monday, now: getTime("2023-01-29T23:59:59Z"), wantFrom: "2023-01-23T00:00:00Z", wantTo: "2023-01-29T23:59:59Z"},
+		{preset: presetThisWeek, weekStart: time.Sunday, now: getTime("2023-01-29T12:00:00Z"), wantFrom: "2023-01-29T00:00:00Z", wantTo: "2023-02-04T23:59:59Z"},
+		// It is already Thursday in Tokyo.
+		{preset: presetToday, now: getTime("2023-01-25T20:00:00Z"), loc: tokyo, wantFrom: "2023-01-26T00:00:00+09:00", wantTo: "2023-01-26T23:59:59+09:00"},
+		{preset: presetTomorrow, now: getTime("2023-01-31T20:00:00Z"), loc: tokyo, wantFrom: "2023-02-02T00:00:00+09:00", wantTo: "2023-02-02T23:59:59+09:00"},
+		// The day of the daylight saving time change is 23 hours.
+		{preset: presetTomorrow, now: getTime("2023-03-11T12:00:00-05:00"), loc: newYork, wantFrom: "2023-03-12T00:00:00-05:00", wantTo: "2023-03-12T23:59:59-04:00"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.preset+"/"+tt.wantFrom, func(t *testing.T) {
+			t.Parallel()
+			ref, loc := now, time.UTC
+			if !tt.now.IsZero() {
+				ref = tt.now
+			}
+			if tt.loc != nil {
+				loc = tt.loc
+			}
+			from, to := presetWindow(tt.preset, tt.weekStart, ref, loc)
+			if diff := cmp.Diff(tt.wantFrom, from.Format(time.RFC3339)); diff != "" {
+				t.Errorf("from (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTo, to.Format(time.RFC3339)); diff != "" {
+				t.Errorf("to (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_run_presetWindow(t *testing.T) {
+	t.Parallel()
+	for _, args := range [][]string{{"--today"}, {"--tomorrow"}, {"--this-week", "--week-start", "sunday"}} {
+		if _, _, err := runFake(newRunClients(), args...); err != nil {
+			t.Errorf("%v: run() error = %v", args, err)
+		}
+	}
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--today", "--tomorrow"}, wantErr: "'--today' can't be used with '--tomorrow'"},
+		{args: []string{"--this-week", "--today"}, wantErr: "'--today' can't be used with '--this-week'"},
+		{args: []string{"--today", "--from", "-1h"}, wantErr: "'--today' can't be used with '--from'"},
+		{args: []string{"--this-week", "--to", "now"}, wantErr: "'--this-week' can't be used with '--to'"},
+		{args: []string{"--tomorrow", "--range", "2023-01-24T00:00:00Z/PT6H"}, wantErr: "'--tomorrow' can't be used with '--range'"},
+		{args: []string{"--today", "--week-start", "sunday"}, wantErr: "'--week-start' requires '--this-week'"},
+		{args: []string{"--this-week", "--week-start", "friday"}, wantErr: "friday is unsupported '--week-start', one of: monday|sunday"},
+	}
+	for _, tt := range tests {
+		if _, _, err := runFake(newRunClients(), tt.args...); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%v: want %q, got %v", tt.args, tt.wantErr, err)
+		}
+	}
+}